@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/xataio/pgroll/pkg/backfill"
+)
+
+func backfillCmd() *cobra.Command {
+	var resume bool
+	var complete bool
+	var bfFlags backfillFlags
+
+	backfillCmd := &cobra.Command{
+		Use:   "backfill",
+		Short: "Backfill the tables modified by the active migration",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			// Create a roll instance and check if pgroll is initialized
+			m, err := NewRollWithInitCheck(ctx)
+			if err != nil {
+				return err
+			}
+			defer m.Close()
+
+			isActive, err := m.State().IsActiveMigrationPeriod(ctx, m.Schema())
+			if err != nil {
+				return fmt.Errorf("unable to determine active migration period: %w", err)
+			}
+			if !isActive {
+				return fmt.Errorf("no active migration to backfill")
+			}
+
+			migration, err := m.State().GetActiveMigration(ctx, m.Schema())
+			if err != nil {
+				return fmt.Errorf("unable to get active migration: %w", err)
+			}
+
+			c, err := bfFlags.config()
+			if err != nil {
+				return err
+			}
+
+			return runBackfill(ctx, m, migration, complete, resume, c)
+		},
+	}
+
+	bfFlags.register(backfillCmd)
+	backfillCmd.Flags().BoolVar(&resume, "resume", false, "Resume the backfill from the last committed batch of each table")
+	backfillCmd.Flags().BoolVarP(&complete, "complete", "c", false, "Complete the migration once the backfill has finished")
+
+	return backfillCmd
+}
+
+// backfillFlags holds the flags that configure backfills, shared by all
+// commands that backfill tables.
+type backfillFlags struct {
+	batchSize   int
+	batchDelay  time.Duration
+	parallelism int
+
+	mode                string
+	minBatchSize        int
+	maxBatchSize        int
+	maxBatchDelay       time.Duration
+	targetBatchDuration time.Duration
+	maxReplicationLag   time.Duration
+	maxLockWaiters      int
+}
+
+// register adds the backfill flags to `cmd`.
+func (f *backfillFlags) register(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&f.batchSize, "backfill-batch-size", backfill.DefaultBatchSize, "Number of rows backfilled in each batch")
+	cmd.Flags().DurationVar(&f.batchDelay, "backfill-batch-delay", backfill.DefaultDelay, "Duration of delay between batch backfills (eg. 1s, 1000ms)")
+	cmd.Flags().IntVar(&f.parallelism, "backfill-parallelism", backfill.DefaultParallelism, "Maximum number of tables backfilled concurrently")
+
+	cmd.Flags().StringVar(&f.mode, "backfill-mode", string(backfill.DefaultMode), "Backfill mode: 'fixed' or 'adaptive'")
+	cmd.Flags().IntVar(&f.minBatchSize, "backfill-min-batch-size", backfill.DefaultMinBatchSize, "Minimum number of rows in each batch of an adaptive backfill")
+	cmd.Flags().IntVar(&f.maxBatchSize, "backfill-max-batch-size", backfill.DefaultMaxBatchSize, "Maximum number of rows in each batch of an adaptive backfill")
+	cmd.Flags().DurationVar(&f.maxBatchDelay, "backfill-max-batch-delay", backfill.DefaultMaxBatchDelay, "Maximum delay between batches of an adaptive backfill")
+	cmd.Flags().DurationVar(&f.targetBatchDuration, "backfill-target-batch-duration", backfill.DefaultTargetBatchDuration, "Longest a batch of an adaptive backfill may take before the backfill backs off")
+	cmd.Flags().DurationVar(&f.maxReplicationLag, "backfill-max-replication-lag", backfill.DefaultMaxReplicationLag, "Largest replication lag tolerated by an adaptive backfill before it backs off")
+	cmd.Flags().IntVar(&f.maxLockWaiters, "backfill-max-lock-waiters", backfill.DefaultMaxLockWaiters, "Largest number of sessions waiting on locks tolerated by an adaptive backfill before it backs off")
+}
+
+// config builds a backfill configuration from the flags.
+func (f *backfillFlags) config() (*backfill.Config, error) {
+	mode, err := backfill.ParseMode(f.mode)
+	if err != nil {
+		return nil, err
+	}
+
+	c := backfill.NewConfig(
+		backfill.WithBatchSize(f.batchSize),
+		backfill.WithBatchDelay(f.batchDelay),
+		backfill.WithParallelism(f.parallelism),
+		backfill.WithMode(mode),
+		backfill.WithBatchSizeBounds(f.minBatchSize, f.maxBatchSize),
+		backfill.WithBatchDelayBounds(0, f.maxBatchDelay),
+		backfill.WithTargetBatchDuration(f.targetBatchDuration),
+		backfill.WithMaxReplicationLag(f.maxReplicationLag),
+		backfill.WithMaxLockWaiters(f.maxLockWaiters),
+	)
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid backfill configuration: %w", err)
+	}
+
+	return c, nil
+}
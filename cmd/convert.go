@@ -3,10 +3,14 @@
 package cmd
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/xataio/pgroll/pkg/migrations"
@@ -15,14 +19,20 @@ import (
 
 func convertCmd() *cobra.Command {
 	var useJSON bool
+	var interactive bool
 
 	convertCmd := &cobra.Command{
-		Use:       "convert <path to file with migrations>",
-		Short:     "Convert SQL statements to a pgroll migration",
-		Long:      "Convert SQL statements to a pgroll migration. The command can read SQL statements from stdin or a file",
+		Use:   "convert <path to file with migrations>",
+		Short: "Convert SQL statements to a pgroll migration",
+		Long: "Convert SQL statements to a pgroll migration. The command can read SQL statements from stdin or a file. " +
+			"With --interactive, the command prompts for the SQL to use in place of each placeholder in the generated migration",
 		Args:      cobra.MaximumNArgs(1),
 		ValidArgs: []string{"migration-file"},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if interactive && len(args) == 0 {
+				return errors.New("--interactive requires the SQL statements to be read from a file")
+			}
+
 			reader, err := openSQLReader(args)
 			if err != nil {
 				return fmt.Errorf("open SQL migration: %w", err)
@@ -33,6 +43,11 @@ func convertCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if interactive {
+				if err := resolvePlaceholders(os.Stdin, os.Stderr, &migration); err != nil {
+					return err
+				}
+			}
 			err = migrations.NewWriter(os.Stdout, migrations.NewMigrationFormat(useJSON)).Write(&migration)
 			if err != nil {
 				return fmt.Errorf("failed to write migration to stdout: %w", err)
@@ -42,6 +57,7 @@ func convertCmd() *cobra.Command {
 	}
 
 	convertCmd.Flags().BoolVarP(&useJSON, "json", "j", false, "Output migration file in JSON format instead of YAML")
+	convertCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Prompt for the SQL to use in place of each placeholder")
 
 	return convertCmd
 }
@@ -67,3 +83,48 @@ func sqlStatementsToMigration(reader io.Reader) (migrations.Migration, error) {
 		Operations: ops,
 	}, nil
 }
+
+// resolvePlaceholders prompts on `out` for the SQL to use in place of each
+// placeholder in the migration, reading one answer per line from `in`. An
+// empty answer leaves the placeholder in place.
+func resolvePlaceholders(in io.Reader, out io.Writer, migration *migrations.Migration) error {
+	scanner := bufio.NewScanner(in)
+	var unresolved int
+
+	for i, op := range migration.Operations {
+		if len(migrations.PlaceholderFields(op)) == 0 {
+			continue
+		}
+
+		opJSON, err := json.Marshal(op)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "operation %d (%s): %s\n", i+1, migrations.OperationName(op), opJSON)
+
+		resolved, err := migrations.ResolvePlaceholders(op, func(field string) (string, error) {
+			fmt.Fprintf(out, "  SQL for %s: ", field)
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return "", err
+				}
+				return "", io.ErrUnexpectedEOF
+			}
+			answer := strings.TrimSpace(scanner.Text())
+			if answer == "" {
+				unresolved++
+				return migrations.PlaceHolderSQL, nil
+			}
+			return answer, nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read SQL for placeholder: %w", err)
+		}
+		migration.Operations[i] = resolved
+	}
+
+	if unresolved > 0 {
+		fmt.Fprintf(out, "%d placeholder(s) left unresolved; replace them before running the migration\n", unresolved)
+	}
+	return nil
+}
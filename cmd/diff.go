@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/xataio/pgroll/pkg/diff"
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func diffCmd() *cobra.Command {
+	var useJSON bool
+
+	diffCmd := &cobra.Command{
+		Use:   "diff <schema file>",
+		Short: "Generate a migration from the current schema to a desired schema",
+		Long: "Generate a migration from the current schema to a desired schema. " +
+			"The desired schema is read from a SQL file of DDL statements, or from a JSON or YAML file in pgroll's schema format. " +
+			"Destructive operations are listed on stderr.",
+		Example:   "diff schema.sql > migrations/03_my_migration.yaml",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"file"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			fileName := args[0]
+
+			desired, err := diff.ReadSchema(os.DirFS(filepath.Dir(fileName)), filepath.Base(fileName))
+			if err != nil {
+				return err
+			}
+
+			m, err := NewRollWithInitCheck(ctx)
+			if err != nil {
+				return err
+			}
+			defer m.Close()
+
+			// The schema is in flux while a migration is active
+			active, err := m.State().IsActiveMigrationPeriod(ctx, m.Schema())
+			if err != nil {
+				return fmt.Errorf("unable to determine active migration period: %w", err)
+			}
+			if active {
+				return fmt.Errorf("a migration is active: complete or roll it back before generating a diff")
+			}
+
+			current, err := m.State().ReadSchema(ctx, m.Schema())
+			if err != nil {
+				return fmt.Errorf("unable to read schema: %w", err)
+			}
+
+			result := diff.Schemas(current, desired)
+
+			for _, d := range result.Destructive {
+				fmt.Fprintln(os.Stderr, pterm.Yellow("DESTRUCTIVE: "+d))
+			}
+			for _, u := range result.Unsupported {
+				fmt.Fprintln(os.Stderr, pterm.Red("UNSUPPORTED: "+u))
+			}
+
+			if result.Empty() {
+				fmt.Fprintln(os.Stderr, "No differences found")
+				return nil
+			}
+			if len(result.Operations) == 0 {
+				return nil
+			}
+
+			migration := &migrations.Migration{Operations: result.Operations}
+			err = migrations.NewWriter(os.Stdout, migrations.NewMigrationFormat(useJSON)).Write(migration)
+			if err != nil {
+				return fmt.Errorf("failed to write migration to stdout: %w", err)
+			}
+			return nil
+		},
+	}
+
+	diffCmd.Flags().BoolVarP(&useJSON, "json", "j", false, "Output migration file in JSON format instead of YAML")
+
+	return diffCmd
+}
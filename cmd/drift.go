@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+)
+
+func driftCmd() *cobra.Command {
+	var useJSON bool
+
+	driftCmd := &cobra.Command{
+		Use:   "drift",
+		Short: "Detect changes made to the schema outside of pgroll",
+		Long: "Compare the schema recorded in the migration history after the latest migration with the live schema " +
+			"and report every table, column, index and constraint that differs. Exits with a non-zero status if any do.",
+		Example: "drift --json",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			m, err := NewRollWithInitCheck(ctx)
+			if err != nil {
+				return err
+			}
+			defer m.Close()
+
+			drift, err := m.Drift(ctx)
+			if err != nil {
+				return err
+			}
+
+			if useJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(drift); err != nil {
+					return fmt.Errorf("failed to encode drift: %w", err)
+				}
+			} else {
+				for _, change := range drift.Changes {
+					fmt.Println(change)
+				}
+			}
+
+			if len(drift.Changes) > 0 {
+				return fmt.Errorf("schema %q has drifted from its state after migration %q: %d changes found",
+					m.Schema(), drift.Migration, len(drift.Changes))
+			}
+
+			if !useJSON {
+				pterm.Success.Printfln("Schema %q matches its state after migration %q", m.Schema(), drift.Migration)
+			}
+			return nil
+		},
+	}
+
+	driftCmd.Flags().BoolVarP(&useJSON, "json", "j", false, "output the drift in JSON format")
+
+	return driftCmd
+}
@@ -3,6 +3,8 @@
 package flags
 
 import (
+	"strings"
+
 	"github.com/spf13/viper"
 )
 
@@ -33,3 +35,31 @@ func Verbose() bool { return viper.GetBool("VERBOSE") }
 func UseVersionSchema() bool {
 	return viper.GetBool("USE_VERSION_SCHEMA")
 }
+
+func ResumableStart() bool { return viper.GetBool("RESUMABLE_START") }
+
+func VersionSchemaGrantRoles() []string {
+	return commaSeparated(viper.GetString("VERSION_SCHEMA_GRANT_ROLES"))
+}
+
+func VersionSchemaGrantPrivileges() []string {
+	return commaSeparated(viper.GetString("VERSION_SCHEMA_GRANT_PRIVILEGES"))
+}
+
+func OTelEndpoint() string { return viper.GetString("OTEL_ENDPOINT") }
+
+func LogFormat() string { return viper.GetString("LOG_FORMAT") }
+
+func ServeToken() string { return viper.GetString("SERVE_TOKEN") }
+
+// commaSeparated splits a comma separated list, ignoring empty entries and
+// surrounding whitespace.
+func commaSeparated(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/xataio/pgroll/pkg/lint"
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func lintCmd() *cobra.Command {
+	var configFile string
+	var format string
+
+	lintCmd := &cobra.Command{
+		Use:   "lint <file or directory>",
+		Short: "Check migration files for unsafe or incomplete operations",
+		Long: "Check migration files for unsafe or incomplete operations. " +
+			"Migrations are checked without connecting to the database. " +
+			"The command fails if any finding has error severity.",
+		Example:   "lint migrations/",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"path"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outputFormat, err := lint.ParseFormat(format)
+			if err != nil {
+				return err
+			}
+
+			var cfg *lint.Config
+			if configFile != "" {
+				cfg, err = lint.ReadConfig(os.DirFS(filepath.Dir(configFile)), filepath.Base(configFile))
+				if err != nil {
+					return err
+				}
+			}
+
+			linter, err := lint.New(cfg)
+			if err != nil {
+				return err
+			}
+
+			files, err := migrationFiles(args[0])
+			if err != nil {
+				return err
+			}
+
+			var findings []lint.Finding
+			for _, file := range files {
+				migration, err := migrations.ReadMigration(os.DirFS(filepath.Dir(file)), filepath.Base(file))
+				if err != nil {
+					return fmt.Errorf("reading migration %q: %w", file, err)
+				}
+				for _, f := range linter.Lint(migration) {
+					f.File = file
+					findings = append(findings, f)
+				}
+			}
+
+			if err := linter.Write(os.Stdout, outputFormat, findings); err != nil {
+				return fmt.Errorf("failed to write lint findings: %w", err)
+			}
+
+			if lint.HasErrors(findings) {
+				return fmt.Errorf("lint found errors in %d migration file(s)", countFilesWithErrors(findings))
+			}
+			return nil
+		},
+	}
+
+	lintCmd.Flags().StringVarP(&configFile, "config", "c", "", "path to a JSON or YAML file that enables, disables and configures lint rules")
+	lintCmd.Flags().StringVarP(&format, "format", "f", string(lint.FormatText), "output format: text, json or sarif")
+
+	return lintCmd
+}
+
+// migrationFiles returns the migration files at `path`, which is either a
+// migration file or a directory of migration files.
+func migrationFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	dirFiles, err := migrations.CollectFilesFromDir(os.DirFS(path))
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(dirFiles))
+	for _, f := range dirFiles {
+		files = append(files, filepath.Join(path, f))
+	}
+	return files, nil
+}
+
+func countFilesWithErrors(findings []lint.Finding) int {
+	files := make(map[string]bool)
+	for _, f := range findings {
+		if f.Severity == lint.SeverityError {
+			files[f.File] = true
+		}
+	}
+	return len(files)
+}
@@ -6,18 +6,15 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"time"
 
 	"github.com/spf13/cobra"
 
-	"github.com/xataio/pgroll/pkg/backfill"
 	"github.com/xataio/pgroll/pkg/migrations"
 )
 
 func migrateCmd() *cobra.Command {
-	var complete, expectOne bool
-	var batchSize int
-	var batchDelay time.Duration
+	var complete, expectOne, strict bool
+	var bfFlags backfillFlags
 
 	migrateCmd := &cobra.Command{
 		Use:       "migrate <directory>",
@@ -68,6 +65,10 @@ func migrateCmd() *cobra.Command {
 				return nil
 			}
 
+			if err := checkModifiedMigrations(ctx, m, migrationsDir, strict); err != nil {
+				return err
+			}
+
 			rawMigs, err := m.UnappliedMigrations(ctx, os.DirFS(migrationsDir))
 			if err != nil {
 				return fmt.Errorf("failed to get migrations to apply: %w", err)
@@ -88,11 +89,14 @@ func migrateCmd() *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("failed to run migrate: %w", err)
 			}
+			if err := checkPlaceholders(migs); err != nil {
+				return fmt.Errorf("failed to run migrate: %w", err)
+			}
 
-			backfillConfig := backfill.NewConfig(
-				backfill.WithBatchSize(batchSize),
-				backfill.WithBatchDelay(batchDelay),
-			)
+			backfillConfig, err := bfFlags.config()
+			if err != nil {
+				return err
+			}
 
 			// Run all migrations after the latest version up to the final migration,
 			// completing each one.
@@ -107,10 +111,10 @@ func migrateCmd() *cobra.Command {
 		},
 	}
 
-	migrateCmd.Flags().IntVar(&batchSize, "backfill-batch-size", backfill.DefaultBatchSize, "Number of rows backfilled in each batch")
-	migrateCmd.Flags().DurationVar(&batchDelay, "backfill-batch-delay", backfill.DefaultDelay, "Duration of delay between batch backfills (eg. 1s, 1000ms)")
+	bfFlags.register(migrateCmd)
 	migrateCmd.Flags().BoolVar(&expectOne, "expect-one", false, "Abort if there is more than one migration to be applied")
 	migrateCmd.Flags().BoolVarP(&complete, "complete", "c", false, "complete the final migration rather than leaving it active")
+	migrateCmd.Flags().BoolVar(&strict, "strict", false, "Abort if any applied migration has been modified in the migrations directory")
 
 	return migrateCmd
 }
@@ -132,3 +136,15 @@ func parseMigrations(migs []*migrations.RawMigration) ([]*migrations.Migration,
 	}
 	return parsedMigrations, nil
 }
+
+// checkPlaceholders returns an error listing the placeholder SQL left in any
+// of the migrations, so that none are run if any can not be.
+func checkPlaceholders(migs []*migrations.Migration) error {
+	var errs error
+	for _, mig := range migs {
+		if err := mig.ValidatePlaceholders(); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("migration %q: %w", mig.Name, err))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/roll"
+)
+
+func planCmd() *cobra.Command {
+	var useJSON bool
+
+	planCmd := &cobra.Command{
+		Use:       "plan <file>",
+		Short:     "Print the SQL that each phase of a migration would execute",
+		Example:   "plan migrations/03_my_migration.yaml",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"file"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			fileName := args[0]
+
+			m, err := NewRollWithInitCheck(ctx)
+			if err != nil {
+				return err
+			}
+			defer m.Close()
+
+			migration, err := migrations.ReadMigration(os.DirFS(filepath.Dir(fileName)), filepath.Base(fileName))
+			if err != nil {
+				return err
+			}
+
+			plan, err := m.Plan(ctx, migration)
+			if err != nil {
+				return err
+			}
+
+			if useJSON {
+				planJSON, err := json.MarshalIndent(plan, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(planJSON))
+				return nil
+			}
+
+			fmt.Print(formatPlan(plan))
+			return nil
+		},
+	}
+
+	planCmd.Flags().BoolVarP(&useJSON, "json", "j", false, "output in JSON format instead of text")
+
+	return planCmd
+}
+
+// formatPlan renders a migration plan as human readable text
+func formatPlan(plan *roll.Plan) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Migration %q\n", plan.Migration)
+	for _, phase := range plan.Phases {
+		fmt.Fprintf(&sb, "\n== %s ==\n", phase.Phase)
+		for _, step := range phase.Steps {
+			if step.Operation != "" {
+				fmt.Fprintf(&sb, "\n-- operation %d: %s\n", step.OperationIndex, step.Operation)
+			} else {
+				fmt.Fprintf(&sb, "\n-- %s\n", step.Description)
+			}
+			for _, stmt := range step.Statements {
+				stmt = strings.TrimSpace(stmt)
+				if !strings.HasSuffix(stmt, ";") {
+					stmt += ";"
+				}
+				fmt.Fprintln(&sb, stmt)
+			}
+		}
+	}
+
+	return sb.String()
+}
@@ -13,9 +13,10 @@ import (
 
 func pullCmd() *cobra.Command {
 	opts := map[string]string{
-		"j": "output each migration in JSON format instead of YAML",
+		"j":      "output each migration in JSON format instead of YAML",
+		"strict": "abort if any migration in the target directory has been modified since it was applied",
 	}
-	var useJSON bool
+	var useJSON, strict bool
 
 	pullCmd := &cobra.Command{
 		Use:       "pull <target directory>",
@@ -38,6 +39,12 @@ func pullCmd() *cobra.Command {
 				return err
 			}
 
+			// Report migrations in the target directory that don't match the
+			// migration history
+			if err := checkModifiedMigrations(ctx, m, targetDir, strict); err != nil {
+				return err
+			}
+
 			// Get the list of missing migrations (those that have been applied to
 			// the target database but are missing in the local directory).
 			migs, err := m.MissingMigrations(ctx, os.DirFS(targetDir))
@@ -57,6 +64,7 @@ func pullCmd() *cobra.Command {
 	}
 
 	pullCmd.Flags().BoolVarP(&useJSON, "json", "j", false, opts["j"])
+	pullCmd.Flags().BoolVar(&strict, "strict", false, opts["strict"])
 
 	return pullCmd
 }
@@ -4,11 +4,14 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"github.com/xataio/pgroll/cmd/flags"
+	"github.com/xataio/pgroll/pkg/migrations"
 	"github.com/xataio/pgroll/pkg/roll"
 	"github.com/xataio/pgroll/pkg/state"
 )
@@ -16,15 +19,36 @@ import (
 // Version is the pgroll version
 var Version = "development"
 
+const (
+	logFormatText   = "text"
+	logFormatJSON   = "json"
+	logFormatLogfmt = "logfmt"
+)
+
 func NewRoll(ctx context.Context) (*roll.Roll, error) {
+	return newRollForSchema(ctx, flags.Schema())
+}
+
+// newRollForSchema creates a roll instance that manages migrations in
+// `schema`, configured by the root command's flags.
+func newRollForSchema(ctx context.Context, schema string) (*roll.Roll, error) {
 	pgURL := flags.PostgresURL()
-	schema := flags.Schema()
 	stateSchema := flags.StateSchema()
 	lockTimeout := flags.LockTimeout()
 	role := flags.Role()
 	skipValidation := flags.SkipValidation()
 	verbose := flags.Verbose()
 	useVersionSchema := flags.UseVersionSchema()
+	resumableStart := flags.ResumableStart()
+	versionSchemaGrant := roll.VersionSchemaGrant{Roles: flags.VersionSchemaGrantRoles()}
+	for _, p := range flags.VersionSchemaGrantPrivileges() {
+		versionSchemaGrant.Privileges = append(versionSchemaGrant.Privileges, migrations.TablePrivilege(p))
+	}
+
+	logOpt, err := loggerOption(flags.LogFormat(), verbose)
+	if err != nil {
+		return nil, err
+	}
 
 	state, err := state.New(ctx, pgURL, stateSchema, state.WithPgrollVersion(Version))
 	if err != nil {
@@ -36,11 +60,29 @@ func NewRoll(ctx context.Context) (*roll.Roll, error) {
 		roll.WithLockTimeoutMs(lockTimeout),
 		roll.WithRole(role),
 		roll.WithSkipValidation(skipValidation),
-		roll.WithLogging(verbose),
+		logOpt,
 		roll.WithVersionSchema(useVersionSchema),
+		roll.WithResumableStart(resumableStart),
+		roll.WithVersionSchemaGrant(versionSchemaGrant),
 	)
 }
 
+// loggerOption returns the roll option that configures logging for the given
+// log format. Structured formats are written to stderr and always enabled;
+// the text format is only enabled in verbose mode.
+func loggerOption(format string, verbose bool) (roll.Option, error) {
+	switch format {
+	case logFormatText:
+		return roll.WithLogging(verbose), nil
+	case logFormatJSON:
+		return roll.WithLogger(migrations.NewJSONLogger(os.Stderr)), nil
+	case logFormatLogfmt:
+		return roll.WithLogger(migrations.NewLogfmtLogger(os.Stderr)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q: must be one of %q, %q or %q", format, logFormatText, logFormatJSON, logFormatLogfmt)
+	}
+}
+
 // EnsureInitialized checks if the pgroll state schema is initialized.
 // Returns an error if the check fails or if pgroll is not initialized.
 func EnsureInitialized(ctx context.Context, state *state.State) error {
@@ -78,6 +120,9 @@ func Prepare() *cobra.Command {
 		SilenceUsage: true,
 		Version:      Version,
 		Long:         "For more information, visit http://pgroll.com/docs",
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			return setupTelemetry(cmd.Context())
+		},
 	}
 
 	viper.SetEnvPrefix("PGROLL")
@@ -89,7 +134,12 @@ func Prepare() *cobra.Command {
 	rootCmd.PersistentFlags().Int("lock-timeout", 500, "Postgres lock timeout in milliseconds for pgroll DDL operations")
 	rootCmd.PersistentFlags().String("role", "", "Optional postgres role to set when executing migrations")
 	rootCmd.PersistentFlags().Bool("use-version-schema", true, "Create version schemas for each migration")
+	rootCmd.PersistentFlags().Bool("resumable-start", false, "Keep a migration whose start fails active, so that the start can be resumed from the failed operation, instead of rolling it back")
+	rootCmd.PersistentFlags().String("version-schema-grant-roles", "", "Comma separated roles to grant privileges on each new version schema and its views")
+	rootCmd.PersistentFlags().String("version-schema-grant-privileges", "select,insert,update,delete", "Comma separated privileges to grant on the views in each new version schema to the roles set with --version-schema-grant-roles")
 	rootCmd.PersistentFlags().Bool("verbose", false, "Enable verbose logging")
+	rootCmd.PersistentFlags().String("log-format", logFormatText, "Log format: text, json or logfmt. json and logfmt logs are always written to stderr")
+	rootCmd.PersistentFlags().String("otel-endpoint", "", "OpenTelemetry OTLP/HTTP endpoint to export traces and metrics to; overrides OTEL_EXPORTER_OTLP_ENDPOINT")
 
 	viper.BindPFlag("PG_URL", rootCmd.PersistentFlags().Lookup("postgres-url"))
 	viper.BindPFlag("SCHEMA", rootCmd.PersistentFlags().Lookup("schema"))
@@ -97,10 +147,16 @@ func Prepare() *cobra.Command {
 	viper.BindPFlag("LOCK_TIMEOUT", rootCmd.PersistentFlags().Lookup("lock-timeout"))
 	viper.BindPFlag("ROLE", rootCmd.PersistentFlags().Lookup("role"))
 	viper.BindPFlag("USE_VERSION_SCHEMA", rootCmd.PersistentFlags().Lookup("use-version-schema"))
+	viper.BindPFlag("RESUMABLE_START", rootCmd.PersistentFlags().Lookup("resumable-start"))
+	viper.BindPFlag("VERSION_SCHEMA_GRANT_ROLES", rootCmd.PersistentFlags().Lookup("version-schema-grant-roles"))
+	viper.BindPFlag("VERSION_SCHEMA_GRANT_PRIVILEGES", rootCmd.PersistentFlags().Lookup("version-schema-grant-privileges"))
 	viper.BindPFlag("VERBOSE", rootCmd.PersistentFlags().Lookup("verbose"))
+	viper.BindPFlag("LOG_FORMAT", rootCmd.PersistentFlags().Lookup("log-format"))
+	viper.BindPFlag("OTEL_ENDPOINT", rootCmd.PersistentFlags().Lookup("otel-endpoint"))
 
 	// register subcommands
 	rootCmd.AddCommand(startCmd())
+	rootCmd.AddCommand(backfillCmd())
 	rootCmd.AddCommand(completeCmd)
 	rootCmd.AddCommand(rollbackCmd)
 	rootCmd.AddCommand(analyzeCmd)
@@ -113,7 +169,14 @@ func Prepare() *cobra.Command {
 	rootCmd.AddCommand(latestCmd())
 	rootCmd.AddCommand(convertCmd())
 	rootCmd.AddCommand(baselineCmd())
+	rootCmd.AddCommand(squashCmd())
+	rootCmd.AddCommand(verifyCmd())
+	rootCmd.AddCommand(driftCmd())
 	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(planCmd())
+	rootCmd.AddCommand(diffCmd())
+	rootCmd.AddCommand(lintCmd())
+	rootCmd.AddCommand(serveCmd())
 
 	return rootCmd
 }
@@ -121,5 +184,14 @@ func Prepare() *cobra.Command {
 // Execute executes the root command.
 func Execute() error {
 	cmd := Prepare()
-	return cmd.Execute()
+	err := cmd.Execute()
+
+	// Flush any telemetry recorded by the command
+	ctx, cancel := context.WithTimeout(context.Background(), telemetryShutdownTimeout)
+	defer cancel()
+	if shutdownErr := shutdownTelemetry(ctx); shutdownErr != nil {
+		fmt.Fprintf(os.Stderr, "failed to export telemetry: %v\n", shutdownErr)
+	}
+
+	return err
 }
@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/xataio/pgroll/cmd/flags"
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/server"
+)
+
+const serverShutdownTimeout = 10 * time.Second
+
+func serveCmd() *cobra.Command {
+	var listenAddr string
+	var schemas []string
+	var migrationsDir string
+	var bfFlags backfillFlags
+
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve an HTTP API to run and observe migrations",
+		Long: "Serve an HTTP JSON API to start, complete and roll back migrations, " +
+			"and to report migration status and backfill progress, for one or more schemas. " +
+			"Requests must be authenticated with the API token set by --token or PGROLL_SERVE_TOKEN.",
+		Example: "serve --schemas public,tenant --migrations-dir migrations/",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if flags.ServeToken() == "" {
+				return errors.New("an API token must be set with --token or PGROLL_SERVE_TOKEN")
+			}
+
+			// Fail early if the backfill flags are invalid
+			if _, err := bfFlags.config(); err != nil {
+				return err
+			}
+
+			if len(schemas) == 0 {
+				schemas = []string{flags.Schema()}
+			}
+
+			var rollers []server.Roller
+			for _, schema := range schemas {
+				m, err := newRollForSchema(ctx, schema)
+				if err != nil {
+					return fmt.Errorf("unable to connect for schema %q: %w", schema, err)
+				}
+				defer m.Close()
+
+				if err := EnsureInitialized(ctx, m.State()); err != nil {
+					return err
+				}
+				rollers = append(rollers, m)
+			}
+
+			opts := []server.Option{
+				server.WithBackfillConfig(func() *backfill.Config {
+					c, _ := bfFlags.config()
+					return c
+				}),
+			}
+			if migrationsDir != "" {
+				opts = append(opts, server.WithMigrationsDir(os.DirFS(migrationsDir)))
+			}
+
+			srv, err := server.New(ctx, flags.ServeToken(), rollers, opts...)
+			if err != nil {
+				return err
+			}
+
+			httpServer := &http.Server{
+				Addr:              listenAddr,
+				Handler:           srv,
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- httpServer.ListenAndServe()
+			}()
+			pterm.Info.Printfln("Serving the pgroll API on %s for schemas %v", listenAddr, schemas)
+
+			select {
+			case err := <-errCh:
+				return err
+			case <-ctx.Done():
+			}
+
+			// Stopping the server cancels any running jobs other than rollbacks.
+			// Wait for the jobs to finish before the connections they use are
+			// closed.
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+			defer cancel()
+			if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("failed to shut down server: %w", err)
+			}
+			if err := srv.Wait(shutdownCtx); err != nil {
+				return fmt.Errorf("failed to shut down server: %w", err)
+			}
+			return nil
+		},
+	}
+
+	bfFlags.register(serveCmd)
+	serveCmd.Flags().StringVar(&listenAddr, "listen", ":8080", "Address on which to serve the API")
+	serveCmd.Flags().StringSliceVar(&schemas, "schemas", nil, "Schemas to manage (default: the value of --schema)")
+	serveCmd.Flags().StringVar(&migrationsDir, "migrations-dir", "", "Directory of migration files that can be started by name")
+	serveCmd.Flags().String("token", "", "API token that clients must present as a bearer token")
+
+	viper.BindPFlag("SERVE_TOKEN", serveCmd.Flags().Lookup("token"))
+
+	return serveCmd
+}
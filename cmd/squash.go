@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func squashCmd() *cobra.Command {
+	var useJSON bool
+	var snapshot bool
+	var yes bool
+
+	squashCmd := &cobra.Command{
+		Use:   "squash <from> <to> <directory>",
+		Short: "Squash a range of migrations into a single migration",
+		Long: "Squash the migrations in a directory from <from> to <to> inclusive into a single equivalent migration named after <to>. " +
+			"The squashed migration files are replaced by the new migration, which records the names of the migrations it replaces " +
+			"so that any database that has applied them sees the new migration as applied. The migration history of the target " +
+			"database is also rewritten to list the new migration in their place.",
+		Example:   "squash 01_create_tables 42_add_indexes migrations/",
+		Args:      cobra.ExactArgs(3),
+		ValidArgs: []string{"from", "to", "directory"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, to, dir := args[0], args[1], args[2]
+			ctx := cmd.Context()
+
+			m, err := NewRollWithInitCheck(ctx)
+			if err != nil {
+				return err
+			}
+			defer m.Close()
+
+			sq, err := m.SquashMigrations(ctx, os.DirFS(dir), from, to, snapshot)
+			if err != nil {
+				return err
+			}
+
+			if len(sq.Unsupported) > 0 {
+				return fmt.Errorf("the squashed migrations make changes that can not be expressed as pgroll operations:\n  %s",
+					strings.Join(sq.Unsupported, "\n  "))
+			}
+			for _, p := range sq.Migration.Placeholders() {
+				fmt.Fprintln(os.Stderr, pterm.Yellow(fmt.Sprintf(
+					"PLACEHOLDER: operation %d (%s): %s must be replaced before the migration can be run",
+					p.OperationIndex, p.Operation, p.Field)))
+			}
+
+			// Prompt for confirmation unless --yes flag is set
+			if !yes {
+				fmt.Printf("Squashing will replace %d migration files with %q and rewrite the migration history.\n", len(sq.Files), to)
+				ok, _ := pterm.DefaultInteractiveConfirm.Show()
+				if !ok {
+					return nil
+				}
+			}
+
+			// The squashed migration records the migrations it replaces, so the
+			// files are replaced before the history is rewritten: a database whose
+			// history still lists the replaced migrations sees it as applied
+			opsJSON, err := json.Marshal(sq.Migration.Operations)
+			if err != nil {
+				return fmt.Errorf("failed to marshal operations: %w", err)
+			}
+			filePath, err := writeMigrationToFile(&migrations.RawMigration{
+				Name:       sq.Migration.Name,
+				Operations: opsJSON,
+				Squashes:   sq.Migration.Squashes,
+			}, dir, "", useJSON)
+			if err != nil {
+				return fmt.Errorf("failed to write squashed migration: %w", err)
+			}
+
+			var errs []error
+			for _, file := range sq.Files {
+				path := filepath.Join(dir, file)
+				if path == filePath {
+					continue
+				}
+				if err := os.Remove(path); err != nil {
+					errs = append(errs, fmt.Errorf("failed to remove squashed migration file: %w", err))
+				}
+			}
+			if err := errors.Join(errs...); err != nil {
+				return err
+			}
+
+			applied, err := m.ApplySquash(ctx, sq)
+			if err != nil {
+				return fmt.Errorf("failed to update migration history: %w", err)
+			}
+
+			if applied {
+				pterm.Success.Printfln("Squashed %d migrations into %q and updated the migration history", len(sq.Files), filePath)
+			} else {
+				pterm.Success.Printfln("Squashed %d migrations into %q. The target database has not applied them, so its history is unchanged", len(sq.Files), filePath)
+			}
+			return nil
+		},
+	}
+
+	squashCmd.Flags().BoolVarP(&useJSON, "json", "j", false, "output in JSON format instead of YAML")
+	squashCmd.Flags().BoolVar(&snapshot, "snapshot", false, "write a migration that creates the whole schema, like a baseline; <from> must be the first migration")
+	squashCmd.Flags().BoolVarP(&yes, "yes", "y", false, "skip confirmation prompt")
+
+	return squashCmd
+}
@@ -8,7 +8,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
-	"time"
+	"sync"
 
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
@@ -22,8 +22,8 @@ import (
 
 func startCmd() *cobra.Command {
 	var complete bool
-	var batchSize int
-	var batchDelay time.Duration
+	var resume bool
+	var bfFlags backfillFlags
 
 	startCmd := &cobra.Command{
 		Use:       "start <file>",
@@ -51,18 +51,22 @@ func startCmd() *cobra.Command {
 				return nil
 			}
 
-			c := backfill.NewConfig(
-				backfill.WithBatchSize(batchSize),
-				backfill.WithBatchDelay(batchDelay),
-			)
+			c, err := bfFlags.config()
+			if err != nil {
+				return err
+			}
+
+			if resume {
+				return resumeMigrationFromFile(ctx, m, fileName, complete, c)
+			}
 
 			return runMigrationFromFile(ctx, m, fileName, complete, c)
 		},
 	}
 
-	startCmd.Flags().IntVar(&batchSize, "backfill-batch-size", backfill.DefaultBatchSize, "Number of rows backfilled in each batch")
-	startCmd.Flags().DurationVar(&batchDelay, "backfill-batch-delay", backfill.DefaultDelay, "Duration of delay between batch backfills (eg. 1s, 1000ms)")
+	bfFlags.register(startCmd)
 	startCmd.Flags().BoolVarP(&complete, "complete", "c", false, "Mark the migration as complete")
+	startCmd.Flags().BoolVar(&resume, "resume", false, "Resume the migration if it has already been started: retry its start if it failed, or resume its backfill")
 	startCmd.Flags().BoolP("skip-validation", "s", false, "skip migration validation")
 
 	viper.BindPFlag("SKIP_VALIDATION", startCmd.Flags().Lookup("skip-validation"))
@@ -79,18 +83,133 @@ func runMigrationFromFile(ctx context.Context, m *roll.Roll, fileName string, co
 	return runMigration(ctx, m, migration, complete, c)
 }
 
-func runMigration(ctx context.Context, m *roll.Roll, migration *migrations.Migration, complete bool, c *backfill.Config) error {
-	sp, _ := pterm.DefaultSpinner.WithText("Starting migration...").Start()
-	c.AddCallback(func(n int64, total int64) {
+// resumeMigrationFromFile resumes the migration in `fileName` if it is the
+// active migration, or starts it if there is no active migration. The start of
+// the migration is resumed if it failed, and otherwise its backfill is
+// resumed.
+func resumeMigrationFromFile(ctx context.Context, m *roll.Roll, fileName string, complete bool, c *backfill.Config) error {
+	migration, err := migrations.ReadMigration(os.DirFS(filepath.Dir(fileName)), filepath.Base(fileName))
+	if err != nil {
+		return err
+	}
+
+	isActive, err := m.State().IsActiveMigrationPeriod(ctx, m.Schema())
+	if err != nil {
+		return fmt.Errorf("unable to determine active migration period: %w", err)
+	}
+	if !isActive {
+		return runMigration(ctx, m, migration, complete, c)
+	}
+
+	active, err := m.State().GetActiveMigration(ctx, m.Schema())
+	if err != nil {
+		return fmt.Errorf("unable to get active migration: %w", err)
+	}
+	if active.Name != migration.Name {
+		return fmt.Errorf("migration %q is in progress; unable to resume migration %q", active.Name, migration.Name)
+	}
+
+	progress, err := m.State().StartProgress(ctx, m.Schema(), active.Name)
+	if err != nil {
+		return fmt.Errorf("unable to read migration progress: %w", err)
+	}
+	if progress != nil && progress.Failed() {
+		return resumeStart(ctx, m, migration, complete, c)
+	}
+
+	return runBackfill(ctx, m, migration, complete, true, c)
+}
+
+// resumeStart resumes the failed start of the active migration and optionally
+// completes the migration afterwards.
+func resumeStart(ctx context.Context, m *roll.Roll, migration *migrations.Migration, complete bool, c *backfill.Config) error {
+	sp, _ := pterm.DefaultSpinner.WithText("Resuming migration start...").Start()
+	c.AddCallback(backfillProgress(sp))
+
+	if err := m.ResumeStart(ctx, c); err != nil {
+		sp.Fail(fmt.Sprintf("Failed to resume migration start: %s", err))
+		return err
+	}
+
+	if complete {
+		if err := m.Complete(ctx); err != nil {
+			sp.Fail(fmt.Sprintf("Failed to complete migration: %s", err))
+			return err
+		}
+		sp.Success(fmt.Sprintf("Migration %q completed successfully", migration.Name))
+		return nil
+	}
+
+	sp.Success(fmt.Sprintf("Migration %q started successfully", migration.Name))
+	return nil
+}
+
+// runBackfill backfills the tables modified by the active migration,
+// optionally resuming from the last committed batch, and optionally completes
+// the migration afterwards.
+func runBackfill(ctx context.Context, m *roll.Roll, migration *migrations.Migration, complete, resume bool, c *backfill.Config) error {
+	sp, _ := pterm.DefaultSpinner.WithText("Backfilling...").Start()
+	c.AddCallback(backfillProgress(sp))
+
+	var err error
+	if resume {
+		err = m.ResumeBackfill(ctx, c)
+	} else {
+		err = m.Backfill(ctx, c)
+	}
+	if err != nil {
+		sp.Fail(fmt.Sprintf("Failed to backfill migration: %s", err))
+		return err
+	}
+
+	if complete {
+		if err = m.Complete(ctx); err != nil {
+			sp.Fail(fmt.Sprintf("Failed to complete migration: %s", err))
+			return err
+		}
+		sp.Success(fmt.Sprintf("Migration %q completed successfully", migration.Name))
+		return nil
+	}
+
+	sp.Success(fmt.Sprintf("Backfill of migration %q completed successfully", migration.Name))
+	return nil
+}
+
+// backfillProgress returns a backfill callback that reports the combined
+// progress of all tables being backfilled on `sp`
+func backfillProgress(sp *pterm.SpinnerPrinter) backfill.CallbackFn {
+	type progress struct{ done, total int64 }
+
+	var mu sync.Mutex
+	tables := make(map[string]progress)
+
+	return func(table string, n int64, total int64) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		tables[table] = progress{done: n, total: total}
+
+		var done, sum int64
+		for _, p := range tables {
+			// Done can be > total if we're on the last batch of a table
+			done += p.done
+			sum += max(p.done, p.total)
+		}
+
 		if total > 0 {
-			percent := float64(n) / float64(total) * 100
+			percent := float64(done) / float64(sum) * 100
 			// Percent can be > 100 if we're on the last batch in which case we still want to display 100.
 			percent = math.Min(percent, 100)
-			sp.UpdateText(fmt.Sprintf("%d records complete... (%.2f%%)", n, percent))
+			sp.UpdateText(fmt.Sprintf("%d records complete... (%.2f%%)", done, percent))
 		} else {
-			sp.UpdateText(fmt.Sprintf("%d records complete...", n))
+			sp.UpdateText(fmt.Sprintf("%d records complete...", done))
 		}
-	})
+	}
+}
+
+func runMigration(ctx context.Context, m *roll.Roll, migration *migrations.Migration, complete bool, c *backfill.Config) error {
+	sp, _ := pterm.DefaultSpinner.WithText("Starting migration...").Start()
+	c.AddCallback(backfillProgress(sp))
 
 	err := m.Start(ctx, migration, c)
 	if err != nil {
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/xataio/pgroll/cmd/flags"
+)
+
+const (
+	otelProtocolHTTP = "http/protobuf"
+
+	telemetryShutdownTimeout = 5 * time.Second
+)
+
+// shutdownTelemetry flushes and stops any telemetry exporters configured by
+// setupTelemetry.
+var shutdownTelemetry = func(context.Context) error { return nil }
+
+// setupTelemetry configures the global OpenTelemetry tracer and meter
+// providers to export spans and metrics using OTLP. Telemetry is only
+// exported if an OTLP endpoint is set with the `--otel-endpoint` flag or the
+// standard OTEL_EXPORTER_OTLP_* environment variables, and OTEL_SDK_DISABLED
+// is not set.
+func setupTelemetry(ctx context.Context) error {
+	endpoint := flags.OTelEndpoint()
+	if !telemetryEnabled(endpoint) {
+		return nil
+	}
+
+	// Only OTLP over HTTP is supported
+	if protocol := os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"); protocol != "" && protocol != otelProtocolHTTP {
+		return fmt.Errorf("unsupported OTLP protocol %q: only %q is supported", protocol, otelProtocolHTTP)
+	}
+
+	var traceOpts []otlptracehttp.Option
+	var metricOpts []otlpmetrichttp.Option
+	if endpoint != "" {
+		traceOpts = append(traceOpts, otlptracehttp.WithEndpointURL(signalURL(endpoint, "v1/traces")))
+		metricOpts = append(metricOpts, otlpmetrichttp.WithEndpointURL(signalURL(endpoint, "v1/metrics")))
+	}
+
+	traceExporter, err := otlptracehttp.New(ctx, traceOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+	metricExporter, err := otlpmetrichttp.New(ctx, metricOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create metric exporter: %w", err)
+	}
+
+	// Attributes set with OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take
+	// precedence over the defaults
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(
+			attribute.String("service.name", "pgroll"),
+			attribute.String("service.version", Version),
+		),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create telemetry resource: %w", err)
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(traceExporter),
+		sdktrace.WithResource(res),
+	)
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)),
+		sdkmetric.WithResource(res),
+	)
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetMeterProvider(meterProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	shutdownTelemetry = func(ctx context.Context) error {
+		return errors.Join(tracerProvider.Shutdown(ctx), meterProvider.Shutdown(ctx))
+	}
+	return nil
+}
+
+func telemetryEnabled(endpoint string) bool {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return false
+	}
+	if endpoint != "" {
+		return true
+	}
+	for _, env := range []string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT",
+		"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT",
+		"OTEL_EXPORTER_OTLP_METRICS_ENDPOINT",
+	} {
+		if os.Getenv(env) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// signalURL returns the URL to which an OTLP/HTTP exporter sends a signal,
+// given the base endpoint URL, as for OTEL_EXPORTER_OTLP_ENDPOINT.
+func signalURL(endpoint, path string) string {
+	return strings.TrimSuffix(endpoint, "/") + "/" + path
+}
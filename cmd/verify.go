@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/xataio/pgroll/pkg/roll"
+)
+
+func verifyCmd() *cobra.Command {
+	verifyCmd := &cobra.Command{
+		Use:   "verify <directory>",
+		Short: "Check that applied migrations have not been modified in a directory",
+		Long: "Compare the migrations in a directory with the migration history of the target database and report " +
+			"every migration file that has been modified since it was applied. Exits with a non-zero status if any have.",
+		Example:   "verify ./migrations",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"directory"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			m, err := NewRollWithInitCheck(ctx)
+			if err != nil {
+				return err
+			}
+			defer m.Close()
+
+			if err := checkModifiedMigrations(ctx, m, args[0], true); err != nil {
+				return err
+			}
+
+			pterm.Success.Println("All applied migrations match the migrations directory")
+			return nil
+		},
+	}
+
+	return verifyCmd
+}
+
+// checkModifiedMigrations reports each migration in `dir` that does not match
+// the migration history of the target database. If `strict` is set, an error
+// listing the mismatches is returned; otherwise they are printed as warnings.
+func checkModifiedMigrations(ctx context.Context, m *roll.Roll, dir string, strict bool) error {
+	mismatches, err := m.VerifyMigrations(ctx, os.DirFS(dir))
+	if err != nil {
+		return fmt.Errorf("failed to verify migrations: %w", err)
+	}
+
+	if strict {
+		errs := make([]error, 0, len(mismatches))
+		for _, mismatch := range mismatches {
+			errs = append(errs, mismatch)
+		}
+		return errors.Join(errs...)
+	}
+
+	for _, mismatch := range mismatches {
+		fmt.Fprintln(os.Stderr, pterm.Yellow(fmt.Sprintf("WARNING: %s", mismatch)))
+	}
+	return nil
+}
@@ -24,7 +24,8 @@ const (
 // system catalogs to see if the fast-path optimization was applied.
 func UsesFastPath(ctx context.Context, conn db.DB, tableName, columnType, defaultExpr string) (bool, error) {
 	// Check if we have a real connection or a fake one
-	if _, ok := conn.(*db.FakeDB); ok {
+	switch conn.(type) {
+	case *db.FakeDB, *db.RecordingDB:
 		return true, nil
 	}
 
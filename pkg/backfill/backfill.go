@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,16 +15,18 @@ import (
 	"github.com/xataio/pgroll/pkg/backfill/templates"
 	"github.com/xataio/pgroll/pkg/db"
 	"github.com/xataio/pgroll/pkg/schema"
+	"github.com/xataio/pgroll/pkg/telemetry"
 )
 
 // CNeedsBackfillColumn is the name of the internal column created
 // by pgroll to mark rows that must be backfilled
 const CNeedsBackfillColumn = "_pgroll_needs_backfill"
 
-// Task represents a backfill task for a specific table from an operation.
+// Task represents a backfill task for the tables changed by an operation.
 type Task struct {
-	table    *schema.Table
+	tables   []*schema.Table
 	triggers []OperationTrigger
+	copies   []TableCopy
 }
 
 // Job is a collection of all tables that need to be backfilled and their associated triggers.
@@ -31,24 +34,36 @@ type Job struct {
 	schemaName   string
 	latestSchema string
 	triggers     map[string]triggerConfig
+	copies       []TableCopy
+	options      []OptionFn
 
 	Tables []*schema.Table
 }
 
 type Backfill struct {
-	conn db.DB
+	conn         db.DB
+	checkpointer Checkpointer
 	*Config
 }
 
-type CallbackFn func(done int64, total int64)
+type CallbackFn func(table string, done int64, total int64)
 
 func NewTask(table *schema.Table, triggers ...OperationTrigger) *Task {
 	return &Task{
-		table:    table,
+		tables:   []*schema.Table{table},
 		triggers: triggers,
 	}
 }
 
+// NewCopyTask returns a task that backfills `table` by copying each of its
+// rows into the copy of the table described by `c`.
+func NewCopyTask(table *schema.Table, c TableCopy) *Task {
+	return &Task{
+		tables: []*schema.Table{table},
+		copies: []TableCopy{c},
+	}
+}
+
 func NewJob(schemaName, latestSchema string) *Job {
 	return &Job{
 		schemaName:   schemaName,
@@ -60,12 +75,35 @@ func NewJob(schemaName, latestSchema string) *Job {
 
 func (t *Task) AddTriggers(other *Task) {
 	t.triggers = append(t.triggers, other.triggers...)
+	t.copies = append(t.copies, other.copies...)
 }
 
-func (j *Job) AddTask(t *Task) {
-	if t.table != nil {
-		j.Tables = append(j.Tables, t.table)
+// AddTable adds another table changed by the operation to the task, with the
+// triggers that backfill it. Each table is backfilled once, however many
+// times it is added.
+func (t *Task) AddTable(table *schema.Table, triggers ...OperationTrigger) {
+	if !slices.Contains(t.tables, table) {
+		t.tables = append(t.tables, table)
 	}
+	t.triggers = append(t.triggers, triggers...)
+}
+
+// AddTask adds the tables, triggers and copies of `t` to the job. `opts`
+// apply only to the backfill of the tables of the task. Each table is
+// backfilled once, however many tasks change it.
+func (j *Job) AddTask(t *Task, opts ...OptionFn) {
+	for _, table := range t.tables {
+		if table != nil {
+			if !slices.ContainsFunc(j.Tables, func(t *schema.Table) bool { return t.Name == table.Name }) {
+				j.Tables = append(j.Tables, table)
+			}
+			if len(opts) > 0 {
+				j.options = append(j.options, WithTableOptions(table.Name, opts...))
+			}
+		}
+	}
+
+	j.copies = append(j.copies, t.copies...)
 
 	for _, trigger := range t.triggers {
 		if tg, exists := j.triggers[trigger.Name]; exists {
@@ -98,6 +136,12 @@ func (j *Job) AddTask(t *Task) {
 	}
 }
 
+// Options returns the options that apply to the backfill of the tables of
+// the job's tasks, to be applied to the config the job is backfilled with.
+func (j *Job) Options() []OptionFn {
+	return j.options
+}
+
 // rewriteTriggerSQL rewrites the SQL migrations expression provided by the user
 // in the up or down attribute of the operations config.
 // The column name are turned from user defined names the physical column name with NEW prefix.
@@ -129,6 +173,13 @@ func New(conn db.DB, c *Config) *Backfill {
 	return b
 }
 
+// SetCheckpointer sets the Checkpointer used to persist the progress of the
+// backfill. If set, `Start` continues the backfill of each table from its last
+// recorded checkpoint.
+func (bf *Backfill) SetCheckpointer(c Checkpointer) {
+	bf.checkpointer = c
+}
+
 // CreateTriggers creates the triggers for the tables before starting the backfill.
 func (bf *Backfill) CreateTriggers(ctx context.Context, j *Job) error {
 	for _, trigger := range j.triggers {
@@ -140,6 +191,21 @@ func (bf *Backfill) CreateTriggers(ctx context.Context, j *Job) error {
 			return fmt.Errorf("creating trigger %q: %w", trigger.Name, err)
 		}
 	}
+	for _, c := range j.copies {
+		a := &createCopyTriggerAction{
+			conn: bf.conn,
+			cfg: copyTriggerConfig{
+				Name:       CopyTriggerName(c.TableName),
+				SchemaName: j.schemaName,
+				TableName:  c.TableName,
+				CopyName:   c.CopyName,
+				PrimaryKey: c.PrimaryKey,
+			},
+		}
+		if err := a.execute(ctx); err != nil {
+			return fmt.Errorf("creating trigger %q: %w", a.cfg.Name, err)
+		}
+	}
 	return nil
 }
 
@@ -149,22 +215,64 @@ func (bf *Backfill) CreateTriggers(ctx context.Context, j *Job) error {
 // 2. Get the first batch of rows from the table, ordered by the primary key.
 // 3. Update each row in the batch, setting the value of the primary key column to itself.
 // 4. Repeat steps 2 and 3 until no more rows are returned.
+//
+// If a Checkpointer is set, progress is recorded after each batch and the
+// backfill continues from the last recorded batch.
+//
+// The table is backfilled with the options set for it by WithTableOptions. If
+// the backfill takes longer than the maximum duration for the table, it is
+// stopped and a MaxDurationExceededError is returned.
 func (bf *Backfill) Start(ctx context.Context, table *schema.Table) error {
+	cfg := bf.ForTable(table.Name)
+	if cfg.maxDuration <= 0 {
+		return bf.start(ctx, table, cfg)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, cfg.maxDuration)
+	defer cancel()
+
+	err := bf.start(timeoutCtx, table, cfg)
+	if err != nil && ctx.Err() == nil && errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+		return MaxDurationExceededError{Table: table.Name, MaxDuration: cfg.maxDuration}
+	}
+	return err
+}
+
+// start backfills `table` using `cfg`.
+func (bf *Backfill) start(ctx context.Context, table *schema.Table, cfg *Config) error {
+	// Load any progress recorded by a previous, interrupted backfill.
+	var cp *Checkpoint
+	if bf.checkpointer != nil {
+		var err error
+		cp, err = bf.checkpointer.Load(ctx, table.Name)
+		if err != nil {
+			return fmt.Errorf("load backfill checkpoint for %q: %w", table.Name, err)
+		}
+		if cp != nil && cp.Done {
+			return nil
+		}
+	}
+
 	// Create a batcher for the table.
 	var b batcher
 	if identityColumns := getIdentityColumns(table); identityColumns != nil {
-		b = &pkBatcher{
+		pkb := &pkBatcher{
 			BatchConfig: templates.BatchConfig{
 				TableName:           table.Name,
 				PrimaryKey:          identityColumns,
-				BatchSize:           bf.batchSize,
+				BatchSize:           cfg.batchSize,
 				NeedsBackfillColumn: CNeedsBackfillColumn,
 			},
+			checkpointer: bf.checkpointer,
+		}
+		if cp != nil && len(cp.LastValue) == len(identityColumns) {
+			pkb.LastValue = cp.LastValue
 		}
+		b = pkb
 	} else {
 		b = &needsBackfillColumnBatcher{
 			table:               table.Name,
-			batchSize:           bf.batchSize,
+			batchSize:           cfg.batchSize,
 			needsBackfillColumn: CNeedsBackfillColumn,
 		}
 	}
@@ -175,28 +283,66 @@ func (bf *Backfill) Start(ctx context.Context, table *schema.Table) error {
 	}
 
 	// Update each batch of rows, invoking callbacks for each one.
-	for batch := 0; ; batch++ {
+	t := newThrottle(cfg)
+	var done int64
+	for {
 		for _, cb := range bf.callbacks {
-			cb(int64(batch*bf.batchSize), total)
+			cb(table.Name, done, total)
 		}
 
-		if err := b.updateBatch(ctx, bf.conn); err != nil {
+		b.setBatchSize(t.batchSize)
+		batchStart := time.Now()
+		updated, err := bf.updateBatch(ctx, b, table.Name, t.batchSize)
+		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				break
 			}
 			return err
 		}
+		done += updated
+		telemetry.RecordBackfillBatch(ctx, table.Name, updated, time.Since(batchStart))
+
+		if err := t.observe(ctx, bf.conn, time.Since(batchStart)); err != nil {
+			return fmt.Errorf("throttle backfill of %q: %w", table.Name, err)
+		}
 
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(bf.batchDelay):
+		case <-time.After(t.batchDelay):
+		}
+	}
+
+	// Record that the table has been fully backfilled.
+	if bf.checkpointer != nil {
+		err := bf.conn.WithRetryableTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+			return bf.checkpointer.Save(ctx, tx, table.Name, Checkpoint{Done: true})
+		})
+		if err != nil {
+			return fmt.Errorf("save backfill checkpoint for %q: %w", table.Name, err)
 		}
 	}
 
 	return nil
 }
 
+// updateBatch updates the next batch of rows using `b`, tracing the update
+// as a child of any span in `ctx`. It returns the number of rows updated.
+func (bf *Backfill) updateBatch(ctx context.Context, b batcher, table string, batchSize int) (int64, error) {
+	ctx, span := telemetry.StartSpan(ctx, "pgroll.backfill.batch",
+		telemetry.TableKey.String(table),
+		telemetry.BatchSizeKey.Int(batchSize))
+
+	updated, err := b.updateBatch(ctx, bf.conn)
+	if errors.Is(err, sql.ErrNoRows) {
+		// There are no more rows to update; this is not an error
+		telemetry.EndSpan(span, nil)
+		return 0, err
+	}
+	telemetry.EndSpan(span, err)
+	return updated, err
+}
+
 // getRowCount will attempt to get the row count for the given table. It first attempts to get an
 // estimate and if that is zero, falls back to a full table scan.
 func getRowCount(ctx context.Context, conn db.DB, tableName string) (int64, error) {
@@ -261,9 +407,11 @@ func getIdentityColumns(table *schema.Table) []string {
 	return nil
 }
 
-// A batcher is responsible for updating a batch of rows in a table.
+// A batcher is responsible for updating a batch of rows in a table. It
+// reports the number of rows each batch updated.
 type batcher interface {
-	updateBatch(context.Context, db.DB) error
+	updateBatch(context.Context, db.DB) (int64, error)
+	setBatchSize(int)
 }
 
 // pkBatcher is responsible for updating a batch of rows in a table.
@@ -271,10 +419,16 @@ type batcher interface {
 // It holds the state necessary to update the next batch of rows.
 type pkBatcher struct {
 	templates.BatchConfig
+	checkpointer Checkpointer
 }
 
-func (b *pkBatcher) updateBatch(ctx context.Context, conn db.DB) error {
-	return conn.WithRetryableTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+func (b *pkBatcher) setBatchSize(size int) {
+	b.BatchSize = size
+}
+
+func (b *pkBatcher) updateBatch(ctx context.Context, conn db.DB) (int64, error) {
+	var updated int64
+	err := conn.WithRetryableTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
 		// Build the query to update the next batch of rows
 		sql, err := templates.BuildSQL(b.BatchConfig)
 		if err != nil {
@@ -286,17 +440,24 @@ func (b *pkBatcher) updateBatch(ctx context.Context, conn db.DB) error {
 		if b.LastValue == nil {
 			b.LastValue = make([]string, len(b.PrimaryKey))
 		}
-		wrapper := make([]any, len(b.LastValue))
+		wrapper := make([]any, len(b.LastValue), len(b.LastValue)+1)
 		for i := range b.LastValue {
 			wrapper[i] = &b.LastValue[i]
 		}
+		wrapper = append(wrapper, &updated)
 		err = tx.QueryRowContext(ctx, sql).Scan(wrapper...)
 		if err != nil {
 			return err
 		}
 
+		// Record the last PK value in the same transaction as the batch update
+		if b.checkpointer != nil {
+			return b.checkpointer.Save(ctx, tx, b.TableName, Checkpoint{LastValue: b.LastValue})
+		}
+
 		return nil
 	})
+	return updated, err
 }
 
 // needsBackfillColumnBatcher is responsible for updating a batch of rows in a table
@@ -307,8 +468,13 @@ type needsBackfillColumnBatcher struct {
 	needsBackfillColumn string
 }
 
-func (b *needsBackfillColumnBatcher) updateBatch(ctx context.Context, conn db.DB) error {
-	return conn.WithRetryableTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+func (b *needsBackfillColumnBatcher) setBatchSize(size int) {
+	b.batchSize = size
+}
+
+func (b *needsBackfillColumnBatcher) updateBatch(ctx context.Context, conn db.DB) (int64, error) {
+	var updated int64
+	err := conn.WithRetryableTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
 		//nolint:gosec // tablenames are column names are checked
 		stmt := fmt.Sprintf("UPDATE %s SET %s = true WHERE ctid IN (SELECT ctid FROM %s WHERE %s = true LIMIT %d)",
 			pq.QuoteIdentifier(b.table),
@@ -320,9 +486,10 @@ func (b *needsBackfillColumnBatcher) updateBatch(ctx context.Context, conn db.DB
 		if err != nil {
 			return err
 		}
-		if count, err := res.RowsAffected(); err != nil || count == 0 {
+		if updated, err = res.RowsAffected(); err != nil || updated == 0 {
 			return sql.ErrNoRows
 		}
 		return nil
 	})
+	return updated, err
 }
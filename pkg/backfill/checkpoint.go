@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package backfill
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Checkpoint records the progress of the backfill of a single table.
+type Checkpoint struct {
+	// LastValue holds the values of the identity columns of the last row in the
+	// last committed batch. It is nil for tables without identity columns.
+	LastValue []string
+
+	// Done is true once every batch of the table has been committed.
+	Done bool
+}
+
+// A Checkpointer persists the progress of a backfill so that an interrupted
+// backfill can be resumed from the last committed batch.
+type Checkpointer interface {
+	// Load returns the progress recorded for `table`, or nil if no progress
+	// has been recorded.
+	Load(ctx context.Context, table string) (*Checkpoint, error)
+
+	// Save records the progress of `table`. It is called with the transaction
+	// that updated the batch, so that the checkpoint is committed if and only
+	// if the batch is.
+	Save(ctx context.Context, tx *sql.Tx, table string, cp Checkpoint) error
+}
@@ -3,27 +3,67 @@
 package backfill
 
 import (
+	"fmt"
+	"maps"
+	"slices"
 	"time"
 )
 
 type Config struct {
-	batchSize  int
-	batchDelay time.Duration
-	callbacks  []CallbackFn
+	batchSize   int
+	batchDelay  time.Duration
+	parallelism int
+	maxDuration time.Duration
+	callbacks   []CallbackFn
+
+	// options that apply to the backfill of individual tables
+	tableOptions map[string][]OptionFn
+
+	// adaptive backfill settings
+	mode                Mode
+	minBatchSize        int
+	maxBatchSize        int
+	minBatchDelay       time.Duration
+	maxBatchDelay       time.Duration
+	targetBatchDuration time.Duration
+	maxReplicationLag   time.Duration
+	maxLockWaiters      int
 }
 
 const (
-	DefaultBatchSize int           = 1000
-	DefaultDelay     time.Duration = 0
+	DefaultBatchSize   int           = 1000
+	DefaultDelay       time.Duration = 0
+	DefaultParallelism int           = 1
+	DefaultMaxDuration time.Duration = 0
+
+	DefaultMode                Mode          = ModeFixed
+	DefaultMinBatchSize        int           = 100
+	DefaultMaxBatchSize        int           = 10000
+	DefaultMinBatchDelay       time.Duration = 0
+	DefaultMaxBatchDelay       time.Duration = 5 * time.Second
+	DefaultTargetBatchDuration time.Duration = 500 * time.Millisecond
+	DefaultMaxReplicationLag   time.Duration = 10 * time.Second
+	DefaultMaxLockWaiters      int           = 0
 )
 
 type OptionFn func(*Config)
 
 func NewConfig(opts ...OptionFn) *Config {
 	c := &Config{
-		batchSize:  DefaultBatchSize,
-		batchDelay: DefaultDelay,
-		callbacks:  make([]CallbackFn, 0),
+		batchSize:   DefaultBatchSize,
+		batchDelay:  DefaultDelay,
+		parallelism: DefaultParallelism,
+		maxDuration: DefaultMaxDuration,
+		callbacks:   make([]CallbackFn, 0),
+
+		mode:                DefaultMode,
+		minBatchSize:        DefaultMinBatchSize,
+		maxBatchSize:        DefaultMaxBatchSize,
+		minBatchDelay:       DefaultMinBatchDelay,
+		maxBatchDelay:       DefaultMaxBatchDelay,
+		targetBatchDuration: DefaultTargetBatchDuration,
+		maxReplicationLag:   DefaultMaxReplicationLag,
+		maxLockWaiters:      DefaultMaxLockWaiters,
 	}
 
 	for _, opt := range opts {
@@ -46,8 +86,154 @@ func WithBatchDelay(delay time.Duration) OptionFn {
 	}
 }
 
+// WithParallelism sets the maximum number of tables that are backfilled
+// concurrently. Each table is backfilled by a worker using its own connection.
+func WithParallelism(parallelism int) OptionFn {
+	return func(o *Config) {
+		o.parallelism = parallelism
+	}
+}
+
+// WithMaxDuration sets the longest time the backfill of a table may take. The
+// backfill of a table that takes longer fails. A zero duration means no limit.
+func WithMaxDuration(d time.Duration) OptionFn {
+	return func(o *Config) {
+		o.maxDuration = d
+	}
+}
+
+// WithTableOptions sets options that apply only to the backfill of `table`,
+// on top of the other options of the config.
+func WithTableOptions(table string, opts ...OptionFn) OptionFn {
+	return func(o *Config) {
+		if o.tableOptions == nil {
+			o.tableOptions = make(map[string][]OptionFn)
+		}
+		o.tableOptions[table] = append(o.tableOptions[table], opts...)
+	}
+}
+
+// WithMode sets the backfill mode. In ModeAdaptive the batch size and batch
+// delay are starting values which are adjusted after every batch.
+func WithMode(mode Mode) OptionFn {
+	return func(o *Config) {
+		o.mode = mode
+	}
+}
+
+// WithBatchSizeBounds sets the minimum and maximum batch size for an adaptive
+// backfill.
+func WithBatchSizeBounds(minSize, maxSize int) OptionFn {
+	return func(o *Config) {
+		o.minBatchSize = minSize
+		o.maxBatchSize = maxSize
+	}
+}
+
+// WithBatchDelayBounds sets the minimum and maximum delay between batches for
+// an adaptive backfill.
+func WithBatchDelayBounds(minDelay, maxDelay time.Duration) OptionFn {
+	return func(o *Config) {
+		o.minBatchDelay = minDelay
+		o.maxBatchDelay = maxDelay
+	}
+}
+
+// WithTargetBatchDuration sets the longest a batch of an adaptive backfill
+// may take before the backfill backs off.
+func WithTargetBatchDuration(d time.Duration) OptionFn {
+	return func(o *Config) {
+		o.targetBatchDuration = d
+	}
+}
+
+// WithMaxReplicationLag sets the largest replication lag tolerated by an
+// adaptive backfill before it backs off.
+func WithMaxReplicationLag(d time.Duration) OptionFn {
+	return func(o *Config) {
+		o.maxReplicationLag = d
+	}
+}
+
+// WithMaxLockWaiters sets the largest number of sessions waiting on locks
+// tolerated by an adaptive backfill before it backs off.
+func WithMaxLockWaiters(n int) OptionFn {
+	return func(o *Config) {
+		o.maxLockWaiters = n
+	}
+}
+
+// With returns a copy of the config with `opts` applied. Callbacks are
+// shared with the config.
+func (c *Config) With(opts ...OptionFn) *Config {
+	cp := *c
+	cp.callbacks = slices.Clone(c.callbacks)
+	cp.tableOptions = maps.Clone(c.tableOptions)
+	for _, opt := range opts {
+		opt(&cp)
+	}
+	return &cp
+}
+
+// ForTable returns the config used to backfill `table`, with the options set
+// for the table by WithTableOptions applied.
+func (c *Config) ForTable(table string) *Config {
+	return c.With(c.tableOptions[table]...)
+}
+
+// Parallelism returns the maximum number of tables that are backfilled
+// concurrently.
+func (c *Config) Parallelism() int {
+	return c.parallelism
+}
+
 // AddCallback adds a callback to the backfill operation.
-// Callbacks are invoked after each batch is processed.
+// Callbacks are invoked after each batch is processed. When tables are
+// backfilled in parallel, callbacks may be invoked concurrently.
 func (c *Config) AddCallback(fn CallbackFn) {
 	c.callbacks = append(c.callbacks, fn)
 }
+
+// Callbacks returns the callbacks added to the backfill operation.
+func (c *Config) Callbacks() []CallbackFn {
+	return c.callbacks
+}
+
+// Validate checks that the configuration, and the configuration of each
+// table with table options, is consistent.
+func (c *Config) Validate() error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	for _, table := range slices.Sorted(maps.Keys(c.tableOptions)) {
+		if err := c.ForTable(table).validate(); err != nil {
+			return fmt.Errorf("table %q: %w", table, err)
+		}
+	}
+	return nil
+}
+
+func (c *Config) validate() error {
+	if c.batchSize < 1 {
+		return fmt.Errorf("batch size must be positive, got %d", c.batchSize)
+	}
+	if c.parallelism < 1 {
+		return fmt.Errorf("parallelism must be positive, got %d", c.parallelism)
+	}
+	if c.maxDuration < 0 {
+		return fmt.Errorf("max duration must not be negative, got %s", c.maxDuration)
+	}
+	if _, err := ParseMode(string(c.mode)); err != nil {
+		return err
+	}
+	if c.mode != ModeAdaptive {
+		return nil
+	}
+	if c.minBatchSize < 1 || c.minBatchSize > c.maxBatchSize {
+		return fmt.Errorf("invalid batch size bounds [%d, %d]", c.minBatchSize, c.maxBatchSize)
+	}
+	if c.minBatchDelay < 0 || c.minBatchDelay > c.maxBatchDelay {
+		return fmt.Errorf("invalid batch delay bounds [%s, %s]", c.minBatchDelay, c.maxBatchDelay)
+	}
+	return nil
+}
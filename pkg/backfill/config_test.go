@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package backfill
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigForTable(t *testing.T) {
+	t.Parallel()
+
+	base := NewConfig(WithBatchSize(500), WithParallelism(2))
+	cfg := base.With(
+		WithBatchDelay(time.Second),
+		WithTableOptions("events", WithBatchSize(100), WithMaxDuration(time.Hour)),
+	)
+
+	t.Run("table options apply to their table only", func(t *testing.T) {
+		events := cfg.ForTable("events")
+		assert.Equal(t, 100, events.batchSize)
+		assert.Equal(t, time.Second, events.batchDelay)
+		assert.Equal(t, time.Hour, events.maxDuration)
+
+		users := cfg.ForTable("users")
+		assert.Equal(t, 500, users.batchSize)
+		assert.Equal(t, time.Duration(0), users.maxDuration)
+	})
+
+	t.Run("options don't change the config they are applied to", func(t *testing.T) {
+		assert.Equal(t, time.Duration(0), base.batchDelay)
+		assert.Empty(t, base.tableOptions)
+	})
+
+	t.Run("the config of each table is validated", func(t *testing.T) {
+		assert.NoError(t, cfg.Validate())
+
+		invalid := cfg.With(WithTableOptions("events", WithBatchSize(0)))
+		assert.EqualError(t, invalid.Validate(), `table "events": batch size must be positive, got 0`)
+	})
+}
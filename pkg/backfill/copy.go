@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package backfill
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/xataio/pgroll/pkg/backfill/templates"
+	"github.com/xataio/pgroll/pkg/db"
+)
+
+// TableCopy describes a copy of a table that is kept in sync with the table
+// by triggers. Rows are copied as they are inserted, updated or deleted, and
+// the copy is truncated when the table is, so backfilling the table copies
+// each of its existing rows. The trigger writes
+// to the copy with the privileges of the role that created it, so roles that
+// can write to the table need no privileges on the copy.
+type TableCopy struct {
+	// The table to copy rows from
+	TableName string
+
+	// The table to copy rows to. It must have the columns of `TableName`,
+	// except for any generated columns, which are computed by the copy.
+	CopyName string
+
+	// The columns that identify a row in both tables
+	PrimaryKey []string
+}
+
+type copyTriggerConfig struct {
+	Name       string
+	SchemaName string
+	TableName  string
+	CopyName   string
+	Columns    []string
+	PrimaryKey []string
+}
+
+type createCopyTriggerAction struct {
+	conn db.DB
+	cfg  copyTriggerConfig
+}
+
+func (a *createCopyTriggerAction) execute(ctx context.Context) error {
+	return a.conn.WithRetryableTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s boolean DEFAULT true",
+				pq.QuoteIdentifier(a.cfg.TableName),
+				pq.QuoteIdentifier(CNeedsBackfillColumn)))
+		if err != nil {
+			return err
+		}
+
+		// Copy every column of the copy that can be written to
+		a.cfg.Columns, err = writableColumns(ctx, tx, a.cfg.SchemaName, a.cfg.CopyName)
+		if err != nil {
+			return err
+		}
+
+		funcSQL, err := buildCopyFunction(a.cfg)
+		if err != nil {
+			return err
+		}
+
+		triggerSQL, err := buildCopyTrigger(a.cfg)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, funcSQL); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, triggerSQL)
+		return err
+	})
+}
+
+func buildCopyFunction(cfg copyTriggerConfig) (string, error) {
+	return executeTemplate("copy_function", templates.CopyFunction, cfg)
+}
+
+func buildCopyTrigger(cfg copyTriggerConfig) (string, error) {
+	return executeTemplate("copy_trigger", templates.CopyTrigger, cfg)
+}
+
+// writableColumns returns the columns of `table` in `schemaName` that are not
+// generated, in column order.
+func writableColumns(ctx context.Context, tx *sql.Tx, schemaName, table string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+	  SELECT attname
+	  FROM pg_attribute
+	  WHERE attrelid = $1::regclass
+	    AND attnum > 0
+	    AND NOT attisdropped
+	    AND attgenerated = ''
+	  ORDER BY attnum`,
+		pq.QuoteIdentifier(schemaName)+"."+pq.QuoteIdentifier(table))
+	if err != nil {
+		return nil, fmt.Errorf("getting columns of %q: %w", table, err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, fmt.Errorf("scanning columns of %q: %w", table, err)
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+// CopyTriggerName returns the name of the trigger, and trigger function, that
+// copies the rows of a table.
+func CopyTriggerName(tableName string) string {
+	return "_pgroll_copy_" + tableName
+}
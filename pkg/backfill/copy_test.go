@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package backfill
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildCopyFunction(t *testing.T) {
+	t.Parallel()
+
+	sql, err := buildCopyFunction(copyTriggerConfig{
+		Name:       "_pgroll_copy_events",
+		SchemaName: "public",
+		TableName:  "events",
+		CopyName:   "_pgroll_new_events",
+		Columns:    []string{"id", "created_at", "payload"},
+		PrimaryKey: []string{"id", "created_at"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `CREATE OR REPLACE FUNCTION "_pgroll_copy_events"()
+    RETURNS TRIGGER
+    LANGUAGE PLPGSQL
+    SECURITY DEFINER
+    AS $$
+    BEGIN
+      IF TG_OP = 'TRUNCATE' THEN
+        TRUNCATE "public"."_pgroll_new_events";
+        RETURN NULL;
+      END IF;
+
+      IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        DELETE FROM "public"."_pgroll_new_events"
+          WHERE "id" = OLD."id" AND "created_at" = OLD."created_at";
+      END IF;
+
+      IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO "public"."_pgroll_new_events" ("id", "created_at", "payload")
+          OVERRIDING SYSTEM VALUE
+          VALUES (NEW."id", NEW."created_at", NEW."payload");
+      END IF;
+
+      RETURN NULL;
+    END; $$
+`, sql)
+}
+
+func TestBuildCopyTrigger(t *testing.T) {
+	t.Parallel()
+
+	sql, err := buildCopyTrigger(copyTriggerConfig{
+		Name:      "_pgroll_copy_events",
+		TableName: "events",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `CREATE OR REPLACE TRIGGER "_pgroll_copy_events"
+    AFTER INSERT OR UPDATE OR DELETE
+    ON "events"
+    FOR EACH ROW
+    EXECUTE PROCEDURE "_pgroll_copy_events"();
+
+CREATE OR REPLACE TRIGGER "_pgroll_copy_events_truncate"
+    AFTER TRUNCATE
+    ON "events"
+    FOR EACH STATEMENT
+    EXECUTE PROCEDURE "_pgroll_copy_events"();
+`, sql)
+}
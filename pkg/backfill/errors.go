@@ -1,3 +1,19 @@
 // SPDX-License-Identifier: Apache-2.0
 
 package backfill
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxDurationExceededError is returned when the backfill of a table takes
+// longer than the maximum duration set with WithMaxDuration.
+type MaxDurationExceededError struct {
+	Table       string
+	MaxDuration time.Duration
+}
+
+func (e MaxDurationExceededError) Error() string {
+	return fmt.Sprintf("backfill of table %q exceeded its maximum duration of %s", e.Table, e.MaxDuration)
+}
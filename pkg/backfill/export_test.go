@@ -2,4 +2,18 @@
 
 package backfill
 
+import "time"
+
 var GetRowCount = getRowCount
+
+// AdaptBatch returns the batch size and delay chosen by a backfill configured
+// by `c` after a single batch with the given load.
+func AdaptBatch(c *Config, batchDuration, replicationLag time.Duration, lockWaiters int) (int, time.Duration) {
+	t := newThrottle(c)
+	t.adjust(load{
+		batchDuration:  batchDuration,
+		replicationLag: replicationLag,
+		lockWaiters:    lockWaiters,
+	})
+	return t.batchSize, t.batchDelay
+}
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package templates
+
+const CopyFunction = `CREATE OR REPLACE FUNCTION {{ .Name | qi }}()
+    RETURNS TRIGGER
+    LANGUAGE PLPGSQL
+    SECURITY DEFINER
+    AS $$
+    BEGIN
+      IF TG_OP = 'TRUNCATE' THEN
+        TRUNCATE {{ .SchemaName | qi }}.{{ .CopyName | qi }};
+        RETURN NULL;
+      END IF;
+
+      IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        DELETE FROM {{ .SchemaName | qi }}.{{ .CopyName | qi }}
+          WHERE {{ range $i, $c := .PrimaryKey }}{{ if $i }} AND {{ end }}{{ $c | qi }} = OLD.{{ $c | qi }}{{ end }};
+      END IF;
+
+      IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO {{ .SchemaName | qi }}.{{ .CopyName | qi }} ({{ range $i, $c := .Columns }}{{ if $i }}, {{ end }}{{ $c | qi }}{{ end }})
+          OVERRIDING SYSTEM VALUE
+          VALUES ({{ range $i, $c := .Columns }}{{ if $i }}, {{ end }}NEW.{{ $c | qi }}{{ end }});
+      END IF;
+
+      RETURN NULL;
+    END; $$
+`
+
+const CopyTrigger = `CREATE OR REPLACE TRIGGER {{ .Name | qi }}
+    AFTER INSERT OR UPDATE OR DELETE
+    ON {{ .TableName | qi }}
+    FOR EACH ROW
+    EXECUTE PROCEDURE {{ .Name | qi }}();
+
+CREATE OR REPLACE TRIGGER {{ printf "%s_truncate" .Name | qi }}
+    AFTER TRUNCATE
+    ON {{ .TableName | qi }}
+    FOR EACH STATEMENT
+    EXECUTE PROCEDURE {{ .Name | qi }}();
+`
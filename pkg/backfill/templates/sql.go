@@ -22,6 +22,6 @@ update AS
   WHERE {{ updateWhereClause .TableName .PrimaryKey }}
   RETURNING {{ updateReturnClause .TableName .PrimaryKey }}
 )
-SELECT {{ selectLastValue .PrimaryKey }}
+SELECT {{ selectLastValue .PrimaryKey }}, COUNT(*) OVER()
 FROM update
 `
@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package backfill
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/xataio/pgroll/pkg/db"
+)
+
+// Mode determines how the size of each batch and the delay between batches
+// are chosen during a backfill.
+type Mode string
+
+const (
+	// ModeFixed uses the configured batch size and batch delay for every batch.
+	ModeFixed Mode = "fixed"
+
+	// ModeAdaptive adjusts the batch size and batch delay after every batch,
+	// within the configured bounds, based on the duration of the batch, the
+	// replication lag of any replicas and the number of sessions waiting on
+	// locks.
+	ModeAdaptive Mode = "adaptive"
+)
+
+// adaptiveDelayStep is the delay introduced between batches when an adaptive
+// backfill first backs off with a zero delay.
+const adaptiveDelayStep = 100 * time.Millisecond
+
+// ParseMode parses a backfill mode from its string representation.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case ModeFixed, ModeAdaptive:
+		return Mode(s), nil
+	default:
+		return "", fmt.Errorf("invalid backfill mode %q: must be one of %q or %q", s, ModeFixed, ModeAdaptive)
+	}
+}
+
+// load describes the load on the database observed after a batch.
+type load struct {
+	batchDuration  time.Duration
+	replicationLag time.Duration
+	lockWaiters    int
+}
+
+// throttle chooses the size of each batch and the delay before the next one.
+type throttle struct {
+	batchSize  int
+	batchDelay time.Duration
+
+	adaptive bool
+	cfg      *Config
+}
+
+func newThrottle(c *Config) *throttle {
+	t := &throttle{
+		batchSize:  c.batchSize,
+		batchDelay: c.batchDelay,
+		adaptive:   c.mode == ModeAdaptive,
+		cfg:        c,
+	}
+
+	if t.adaptive {
+		t.batchSize = min(max(t.batchSize, c.minBatchSize), c.maxBatchSize)
+		t.batchDelay = min(max(t.batchDelay, c.minBatchDelay), c.maxBatchDelay)
+	}
+
+	return t
+}
+
+// observe adjusts the batch size and delay after a batch that took
+// `batchDuration` to complete. It is a no-op unless the backfill is adaptive.
+func (t *throttle) observe(ctx context.Context, conn db.DB, batchDuration time.Duration) error {
+	if !t.adaptive {
+		return nil
+	}
+
+	l, err := readLoad(ctx, conn)
+	if err != nil {
+		return err
+	}
+	l.batchDuration = batchDuration
+
+	t.adjust(l)
+	return nil
+}
+
+// adjust halves the batch size and doubles the delay when the database is
+// under pressure, and otherwise grows the batch size and shrinks the delay
+// gradually, keeping both within the configured bounds.
+func (t *throttle) adjust(l load) {
+	c := t.cfg
+
+	underPressure := l.batchDuration > c.targetBatchDuration ||
+		l.replicationLag > c.maxReplicationLag ||
+		l.lockWaiters > c.maxLockWaiters
+
+	if underPressure {
+		t.batchSize = max(t.batchSize/2, c.minBatchSize)
+		t.batchDelay = min(max(t.batchDelay*2, adaptiveDelayStep, c.minBatchDelay), c.maxBatchDelay)
+		return
+	}
+
+	t.batchSize = min(t.batchSize+max(t.batchSize/4, 1), c.maxBatchSize)
+	t.batchDelay /= 2
+	if t.batchDelay < adaptiveDelayStep {
+		t.batchDelay = 0
+	}
+	t.batchDelay = max(t.batchDelay, c.minBatchDelay)
+}
+
+// readLoad reads the replication lag of the most lagging replica and the
+// number of sessions in the current database that are waiting on a lock.
+func readLoad(ctx context.Context, conn db.DB) (load, error) {
+	var l load
+
+	// replay_lag is NULL for replicas that are up to date and when the role
+	// is not allowed to read replication statistics.
+	var lagSeconds float64
+	rows, err := conn.QueryContext(ctx, `
+	  SELECT COALESCE(EXTRACT(EPOCH FROM max(replay_lag)), 0)::float8
+	  FROM pg_stat_replication`)
+	if err != nil {
+		return l, fmt.Errorf("getting replication lag: %w", err)
+	}
+	if err := db.ScanFirstValue(rows, &lagSeconds); err != nil {
+		rows.Close()
+		return l, fmt.Errorf("scanning replication lag: %w", err)
+	}
+	rows.Close()
+	l.replicationLag = time.Duration(lagSeconds * float64(time.Second))
+
+	rows, err = conn.QueryContext(ctx, `
+	  SELECT count(*)
+	  FROM pg_stat_activity
+	  WHERE datname = current_database() AND wait_event_type = 'Lock'`)
+	if err != nil {
+		return l, fmt.Errorf("getting lock waiters: %w", err)
+	}
+	if err := db.ScanFirstValue(rows, &l.lockWaiters); err != nil {
+		rows.Close()
+		return l, fmt.Errorf("scanning lock waiters: %w", err)
+	}
+	rows.Close()
+
+	return l, nil
+}
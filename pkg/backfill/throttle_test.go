@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package backfill_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xataio/pgroll/pkg/backfill"
+)
+
+func TestAdaptiveThrottle(t *testing.T) {
+	t.Parallel()
+
+	newConfig := func(batchSize int, delay time.Duration) *backfill.Config {
+		return backfill.NewConfig(
+			backfill.WithMode(backfill.ModeAdaptive),
+			backfill.WithBatchSize(batchSize),
+			backfill.WithBatchDelay(delay),
+			backfill.WithBatchSizeBounds(100, 2000),
+			backfill.WithBatchDelayBounds(0, time.Second),
+			backfill.WithTargetBatchDuration(500*time.Millisecond),
+			backfill.WithMaxReplicationLag(5*time.Second),
+			backfill.WithMaxLockWaiters(2),
+		)
+	}
+
+	testCases := []struct {
+		name           string
+		batchSize      int
+		delay          time.Duration
+		batchDuration  time.Duration
+		replicationLag time.Duration
+		lockWaiters    int
+		wantBatchSize  int
+		wantDelay      time.Duration
+	}{
+		{
+			name:          "grows batch size when the database is idle",
+			batchSize:     1000,
+			batchDuration: 100 * time.Millisecond,
+			wantBatchSize: 1250,
+			wantDelay:     0,
+		},
+		{
+			name:          "shrinks delay when the database is idle",
+			batchSize:     1000,
+			delay:         800 * time.Millisecond,
+			batchDuration: 100 * time.Millisecond,
+			wantBatchSize: 1250,
+			wantDelay:     400 * time.Millisecond,
+		},
+		{
+			name:          "grows batch size up to the maximum",
+			batchSize:     1900,
+			batchDuration: 100 * time.Millisecond,
+			wantBatchSize: 2000,
+			wantDelay:     0,
+		},
+		{
+			name:          "backs off when batches are slow",
+			batchSize:     1000,
+			batchDuration: time.Second,
+			wantBatchSize: 500,
+			wantDelay:     100 * time.Millisecond,
+		},
+		{
+			name:           "backs off when replicas lag",
+			batchSize:      1000,
+			delay:          200 * time.Millisecond,
+			batchDuration:  100 * time.Millisecond,
+			replicationLag: 10 * time.Second,
+			wantBatchSize:  500,
+			wantDelay:      400 * time.Millisecond,
+		},
+		{
+			name:          "backs off when sessions wait on locks",
+			batchSize:     150,
+			delay:         800 * time.Millisecond,
+			batchDuration: 100 * time.Millisecond,
+			lockWaiters:   3,
+			wantBatchSize: 100,
+			wantDelay:     time.Second,
+		},
+		{
+			name:          "starting batch size is clamped to the bounds",
+			batchSize:     50000,
+			batchDuration: time.Second,
+			wantBatchSize: 1000,
+			wantDelay:     100 * time.Millisecond,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newConfig(tc.batchSize, tc.delay)
+			require.NoError(t, c.Validate())
+
+			batchSize, delay := backfill.AdaptBatch(c, tc.batchDuration, tc.replicationLag, tc.lockWaiters)
+
+			assert.Equal(t, tc.wantBatchSize, batchSize)
+			assert.Equal(t, tc.wantDelay, delay)
+		})
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	t.Parallel()
+
+	mode, err := backfill.ParseMode("adaptive")
+	require.NoError(t, err)
+	assert.Equal(t, backfill.ModeAdaptive, mode)
+
+	_, err = backfill.ParseMode("fast")
+	assert.Error(t, err)
+}
@@ -96,7 +96,7 @@ func buildTrigger(cfg triggerConfig) (string, error) {
 	return executeTemplate("trigger", templates.Trigger, cfg)
 }
 
-func executeTemplate(name, content string, cfg triggerConfig) (string, error) {
+func executeTemplate(name, content string, cfg any) (string, error) {
 	tmpl := template.Must(template.
 		New(name).
 		Funcs(template.FuncMap{
@@ -10,6 +10,8 @@ import (
 
 	"github.com/cloudflare/backoff"
 	"github.com/lib/pq"
+
+	"github.com/xataio/pgroll/pkg/telemetry"
 )
 
 const (
@@ -43,6 +45,7 @@ func (db *RDB) ExecContext(ctx context.Context, query string, args ...interface{
 
 		pqErr := &pq.Error{}
 		if errors.As(err, &pqErr) && pqErr.Code == lockNotAvailableErrorCode {
+			telemetry.RecordLockRetry(ctx)
 			if err := sleepCtx(ctx, b.Duration()); err != nil {
 				return nil, err
 			}
@@ -65,6 +68,7 @@ func (db *RDB) QueryContext(ctx context.Context, query string, args ...interface
 
 		pqErr := &pq.Error{}
 		if errors.As(err, &pqErr) && pqErr.Code == lockNotAvailableErrorCode {
+			telemetry.RecordLockRetry(ctx)
 			if err := sleepCtx(ctx, b.Duration()); err != nil {
 				return nil, err
 			}
@@ -96,6 +100,7 @@ func (db *RDB) WithRetryableTransaction(ctx context.Context, f func(context.Cont
 
 		pqErr := &pq.Error{}
 		if errors.As(err, &pqErr) && pqErr.Code == lockNotAvailableErrorCode {
+			telemetry.RecordLockRetry(ctx)
 			if err := sleepCtx(ctx, b.Duration()); err != nil {
 				return err
 			}
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+)
+
+// RecordingDB is an implementation of `DB` that records the statements passed
+// to `ExecContext` instead of executing them. Like `FakeDB`, queries return no
+// rows and have no effect on any database.
+type RecordingDB struct {
+	statements []string
+}
+
+// ExecContext records the query and returns without executing it.
+func (db *RecordingDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	db.statements = append(db.statements, query)
+	return nil, nil
+}
+
+// QueryContext returns no rows. The query is not recorded as it can not
+// change the database.
+func (db *RecordingDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, nil
+}
+
+// WithRetryableTransaction invokes `f` with a transaction that behaves like
+// the `RecordingDB`: statements executed on the transaction are recorded and
+// queries return no rows.
+func (db *RecordingDB) WithRetryableTransaction(ctx context.Context, f func(context.Context, *sql.Tx) error) error {
+	conn := sql.OpenDB(recordingConnector{db: db})
+	defer conn.Close()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := f(ctx, tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (db *RecordingDB) Close() error {
+	return nil
+}
+
+// Statements returns the statements recorded since the last call to `Reset`.
+func (db *RecordingDB) Statements() []string {
+	return db.statements
+}
+
+// Reset discards all recorded statements.
+func (db *RecordingDB) Reset() {
+	db.statements = nil
+}
+
+// recordingConnector is a `driver.Connector` for the connections that back
+// the transactions of a `RecordingDB`.
+type recordingConnector struct {
+	db *RecordingDB
+}
+
+func (c recordingConnector) Connect(context.Context) (driver.Conn, error) {
+	return &recordingConn{db: c.db}, nil
+}
+
+func (c recordingConnector) Driver() driver.Driver {
+	return recordingDriver{}
+}
+
+type recordingDriver struct{}
+
+func (recordingDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("recording connections can only be opened through a connector")
+}
+
+// recordingConn is a `driver.Conn` that records the statements it executes in
+// its `RecordingDB`. Queries return no rows.
+type recordingConn struct {
+	db *RecordingDB
+}
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.statements = append(c.db.statements, query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return recordingRows{}, nil
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("recording connections do not support prepared statements")
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return recordingTx{}, nil
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+type recordingTx struct{}
+
+func (recordingTx) Commit() error   { return nil }
+func (recordingTx) Rollback() error { return nil }
+
+// recordingRows is an empty result set.
+type recordingRows struct{}
+
+func (recordingRows) Columns() []string              { return nil }
+func (recordingRows) Close() error                   { return nil }
+func (recordingRows) Next(dest []driver.Value) error { return io.EOF }
@@ -0,0 +1,302 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+// ChangeKind is the way in which a database object differs between two
+// schemas
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// ObjectKind is the kind of a database object in a schema
+type ObjectKind string
+
+const (
+	ObjectEnum              ObjectKind = "enum"
+	ObjectTable             ObjectKind = "table"
+	ObjectColumn            ObjectKind = "column"
+	ObjectIndex             ObjectKind = "index"
+	ObjectPrimaryKey        ObjectKind = "primary key"
+	ObjectForeignKey        ObjectKind = "foreign key"
+	ObjectCheckConstraint   ObjectKind = "check constraint"
+	ObjectUniqueConstraint  ObjectKind = "unique constraint"
+	ObjectExcludeConstraint ObjectKind = "exclude constraint"
+	ObjectView              ObjectKind = "view"
+	ObjectPolicy            ObjectKind = "policy"
+	ObjectSequence          ObjectKind = "sequence"
+)
+
+// Change describes a database object that differs between two schemas
+type Change struct {
+	Kind   ChangeKind `json:"kind"`
+	Object ObjectKind `json:"object"`
+
+	// The table that the object belongs to, empty for tables, enums and views
+	Table string `json:"table,omitempty"`
+
+	// The name of the object
+	Name string `json:"name"`
+
+	// The attributes of a changed object that differ, each as
+	// "attribute: from -> to"
+	Details []string `json:"details,omitempty"`
+}
+
+func (c Change) String() string {
+	name := fmt.Sprintf("%q", c.Name)
+	if c.Table != "" {
+		name = fmt.Sprintf("%q on table %q", c.Name, c.Table)
+	}
+
+	s := fmt.Sprintf("%s %s %s", c.Kind, c.Object, name)
+	if len(c.Details) > 0 {
+		s += ": " + strings.Join(c.Details, ", ")
+	}
+	return s
+}
+
+// Changes compares the `from` schema with the `to` schema object by object,
+// returning each enum, sequence, table, column, index, constraint, policy and
+// view that was added, removed or changed.
+//
+// Unlike Schemas, Changes compares schemas as they are read from the
+// database, without normalizing types or expressions, and reports every
+// difference rather than the operations that resolve them.
+func Changes(from, to *schema.Schema) []Change {
+	changes := []Change{}
+
+	changes = appendMapChanges(changes, ObjectEnum, "", from.Enums, to.Enums, enumDetails)
+	changes = appendMapChanges(changes, ObjectSequence, "", from.Sequences, to.Sequences, sequenceDetails)
+
+	for _, name := range sortedKeys(to.Tables) {
+		if _, ok := from.Tables[name]; !ok {
+			changes = append(changes, Change{Kind: ChangeAdded, Object: ObjectTable, Name: name})
+		}
+	}
+	for _, name := range sortedKeys(from.Tables) {
+		if _, ok := to.Tables[name]; !ok {
+			changes = append(changes, Change{Kind: ChangeRemoved, Object: ObjectTable, Name: name})
+		}
+	}
+	for _, name := range sortedKeys(from.Tables) {
+		if to, ok := to.Tables[name]; ok {
+			changes = appendTableChanges(changes, name, from.Tables[name], to)
+		}
+	}
+
+	changes = appendMapChanges(changes, ObjectView, "", from.Views, to.Views, viewDetails)
+
+	return changes
+}
+
+func appendTableChanges(changes []Change, name string, from, to *schema.Table) []Change {
+	var details []string
+	details = appendDetail(details, "comment", from.Comment, to.Comment)
+	details = appendDetail(details, "partitioning", partitionKey(from.Partitioning), partitionKey(to.Partitioning))
+	details = appendDetail(details, "partitions", partitionBounds(from.Partitioning), partitionBounds(to.Partitioning))
+	details = appendDetail(details, "partition of", from.PartitionOf, to.PartitionOf)
+	details = appendDetail(details, "row level security", from.RowLevelSecurity, to.RowLevelSecurity)
+	details = appendDetail(details, "force row level security", from.ForceRowLevelSecurity, to.ForceRowLevelSecurity)
+	if len(details) > 0 {
+		changes = append(changes, Change{Kind: ChangeChanged, Object: ObjectTable, Name: name, Details: details})
+	}
+
+	changes = appendMapChanges(changes, ObjectColumn, name, from.Columns, to.Columns, columnDetails)
+
+	switch {
+	case len(from.PrimaryKey) == 0 && len(to.PrimaryKey) > 0:
+		changes = append(changes, Change{Kind: ChangeAdded, Object: ObjectPrimaryKey, Table: name, Name: strings.Join(to.PrimaryKey, ", ")})
+	case len(from.PrimaryKey) > 0 && len(to.PrimaryKey) == 0:
+		changes = append(changes, Change{Kind: ChangeRemoved, Object: ObjectPrimaryKey, Table: name, Name: strings.Join(from.PrimaryKey, ", ")})
+	case !sameColumns(from.PrimaryKey, to.PrimaryKey):
+		changes = append(changes, Change{
+			Kind: ChangeChanged, Object: ObjectPrimaryKey, Table: name, Name: strings.Join(to.PrimaryKey, ", "),
+			Details: appendDetail(nil, "columns", from.PrimaryKey, to.PrimaryKey),
+		})
+	}
+
+	changes = appendMapChanges(changes, ObjectIndex, name, from.Indexes, to.Indexes, indexDetails)
+	changes = appendMapChanges(changes, ObjectForeignKey, name, from.ForeignKeys, to.ForeignKeys, foreignKeyDetails)
+	changes = appendMapChanges(changes, ObjectCheckConstraint, name, from.CheckConstraints, to.CheckConstraints, checkConstraintDetails)
+	changes = appendMapChanges(changes, ObjectUniqueConstraint, name, from.UniqueConstraints, to.UniqueConstraints, uniqueConstraintDetails)
+	changes = appendMapChanges(changes, ObjectExcludeConstraint, name, from.ExcludeConstraints, to.ExcludeConstraints, excludeConstraintDetails)
+	changes = appendMapChanges(changes, ObjectPolicy, name, from.Policies, to.Policies, policyDetails)
+
+	return changes
+}
+
+// appendMapChanges appends a change for each object that is only in `from`,
+// only in `to`, or in both with differing details.
+func appendMapChanges[T any](changes []Change, object ObjectKind, table string, from, to map[string]*T, details func(from, to *T) []string) []Change {
+	for _, name := range sortedKeys(to) {
+		if _, ok := from[name]; !ok {
+			changes = append(changes, Change{Kind: ChangeAdded, Object: object, Table: table, Name: name})
+		}
+	}
+	for _, name := range sortedKeys(from) {
+		if _, ok := to[name]; !ok {
+			changes = append(changes, Change{Kind: ChangeRemoved, Object: object, Table: table, Name: name})
+		}
+	}
+	for _, name := range sortedKeys(from) {
+		t, ok := to[name]
+		if !ok {
+			continue
+		}
+		if d := details(from[name], t); len(d) > 0 {
+			changes = append(changes, Change{Kind: ChangeChanged, Object: object, Table: table, Name: name, Details: d})
+		}
+	}
+	return changes
+}
+
+func enumDetails(from, to *schema.Enum) []string {
+	return appendDetail(nil, "values", from.Values, to.Values)
+}
+
+func sequenceDetails(from, to *schema.Sequence) []string {
+	var d []string
+	d = appendDetail(d, "type", from.Type, to.Type)
+	d = appendDetail(d, "start", from.Start, to.Start)
+	d = appendDetail(d, "increment", from.Increment, to.Increment)
+	d = appendDetail(d, "min value", from.MinValue, to.MinValue)
+	d = appendDetail(d, "max value", from.MaxValue, to.MaxValue)
+	d = appendDetail(d, "cache", from.Cache, to.Cache)
+	d = appendDetail(d, "cycle", from.Cycle, to.Cycle)
+	return d
+}
+
+func viewDetails(from, to *schema.View) []string {
+	return appendDetail(nil, "definition", from.Definition, to.Definition)
+}
+
+func columnDetails(from, to *schema.Column) []string {
+	var d []string
+	d = appendDetail(d, "type", from.Type, to.Type)
+	d = appendDetail(d, "default", from.Default, to.Default)
+	d = appendDetail(d, "nullable", from.Nullable, to.Nullable)
+	d = appendDetail(d, "identity", from.Identity, to.Identity)
+	d = appendDetail(d, "sequence", from.Sequence, to.Sequence)
+	d = appendDetail(d, "generated", from.Generated, to.Generated)
+	d = appendDetail(d, "unique", from.Unique, to.Unique)
+	d = appendDetail(d, "comment", from.Comment, to.Comment)
+	d = appendDetail(d, "enum values", from.EnumValues, to.EnumValues)
+	return d
+}
+
+func indexDetails(from, to *schema.Index) []string {
+	var d []string
+	d = appendDetail(d, "unique", from.Unique, to.Unique)
+	d = appendDetail(d, "columns", from.Columns, to.Columns)
+	d = appendDetail(d, "predicate", from.Predicate, to.Predicate)
+	d = appendDetail(d, "method", from.Method, to.Method)
+	d = appendDetail(d, "definition", from.Definition, to.Definition)
+	return d
+}
+
+func foreignKeyDetails(from, to *schema.ForeignKey) []string {
+	var d []string
+	d = appendDetail(d, "columns", from.Columns, to.Columns)
+	d = appendDetail(d, "referenced schema", from.ReferencedSchema, to.ReferencedSchema)
+	d = appendDetail(d, "referenced table", from.ReferencedTable, to.ReferencedTable)
+	d = appendDetail(d, "referenced columns", from.ReferencedColumns, to.ReferencedColumns)
+	d = appendDetail(d, "on delete", from.OnDelete, to.OnDelete)
+	d = appendDetail(d, "on delete set columns", from.OnDeleteSetColumns, to.OnDeleteSetColumns)
+	d = appendDetail(d, "on update", from.OnUpdate, to.OnUpdate)
+	d = appendDetail(d, "match type", from.MatchType, to.MatchType)
+	return d
+}
+
+func checkConstraintDetails(from, to *schema.CheckConstraint) []string {
+	var d []string
+	d = appendDetail(d, "columns", from.Columns, to.Columns)
+	d = appendDetail(d, "definition", from.Definition, to.Definition)
+	d = appendDetail(d, "no inherit", from.NoInherit, to.NoInherit)
+	return d
+}
+
+func uniqueConstraintDetails(from, to *schema.UniqueConstraint) []string {
+	return appendDetail(nil, "columns", from.Columns, to.Columns)
+}
+
+func excludeConstraintDetails(from, to *schema.ExcludeConstraint) []string {
+	var d []string
+	d = appendDetail(d, "method", from.Method, to.Method)
+	d = appendDetail(d, "predicate", from.Predicate, to.Predicate)
+	d = appendDetail(d, "definition", from.Definition, to.Definition)
+	return d
+}
+
+func policyDetails(from, to *schema.Policy) []string {
+	var d []string
+	d = appendDetail(d, "command", from.Command, to.Command)
+	d = appendDetail(d, "permissive", from.Permissive, to.Permissive)
+	d = appendDetail(d, "roles", from.Roles, to.Roles)
+	d = appendDetail(d, "using", from.Using, to.Using)
+	d = appendDetail(d, "with check", from.WithCheck, to.WithCheck)
+	return d
+}
+
+// partitionKey describes how a table is partitioned, for example
+// "range (created_at)", or returns "" if the table is not partitioned.
+func partitionKey(p *schema.Partitioning) string {
+	if p == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", p.Strategy, strings.Join(p.Columns, ", "))
+}
+
+// partitionBounds returns the partitions of a partitioned table with their
+// bounds, in name order.
+func partitionBounds(p *schema.Partitioning) []string {
+	if p == nil {
+		return nil
+	}
+	bounds := make([]string, 0, len(p.Partitions))
+	for _, name := range sortedKeys(p.Partitions) {
+		bounds = append(bounds, fmt.Sprintf("%s %s", name, p.Partitions[name].Bound))
+	}
+	return bounds
+}
+
+// appendDetail appends "attribute: from -> to" to `details` if the values of
+// the attribute differ. Nil and empty slices are equal.
+func appendDetail[T any](details []string, attribute string, from, to T) []string {
+	if reflect.DeepEqual(from, to) {
+		return details
+	}
+
+	fromValue, toValue := reflect.ValueOf(from), reflect.ValueOf(to)
+	if fromValue.Kind() == reflect.Slice && fromValue.Len() == 0 && toValue.Len() == 0 {
+		return details
+	}
+
+	return append(details, fmt.Sprintf("%s: %s -> %s", attribute, formatValue(from), formatValue(to)))
+}
+
+// formatValue formats an attribute value for display, dereferencing pointers
+func formatValue(v any) string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return "none"
+		}
+		v = rv.Elem().Interface()
+	}
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xataio/pgroll/pkg/diff"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+func TestChanges(t *testing.T) {
+	t.Parallel()
+
+	recorded, err := diff.SchemaFromDocument([]byte(current))
+	require.NoError(t, err)
+
+	t.Run("identical schemas have no changes", func(t *testing.T) {
+		live, err := diff.SchemaFromDocument([]byte(current))
+		require.NoError(t, err)
+
+		assert.Empty(t, diff.Changes(recorded, live))
+	})
+
+	t.Run("every kind of object is compared", func(t *testing.T) {
+		live, err := diff.SchemaFromDocument([]byte(current))
+		require.NoError(t, err)
+
+		users := live.GetTable("users")
+		users.Columns["status"].Nullable = false
+		users.Columns["status"].Default = nil
+		delete(users.Columns, "legacy")
+		delete(users.Indexes, "idx_users_status")
+		users.Comment = "people"
+		live.Enums["mood"].Values = append(live.Enums["mood"].Values, "ok")
+		delete(live.Tables, "audit_log")
+
+		assert.Equal(t, []diff.Change{
+			{Kind: diff.ChangeChanged, Object: diff.ObjectEnum, Name: "mood", Details: []string{"values: [sad happy] -> [sad happy ok]"}},
+			{Kind: diff.ChangeRemoved, Object: diff.ObjectTable, Name: "audit_log"},
+			{Kind: diff.ChangeChanged, Object: diff.ObjectTable, Name: "users", Details: []string{`comment: "" -> "people"`}},
+			{Kind: diff.ChangeRemoved, Object: diff.ObjectColumn, Table: "users", Name: "legacy"},
+			{Kind: diff.ChangeChanged, Object: diff.ObjectColumn, Table: "users", Name: "status", Details: []string{
+				`default: "'active'::text" -> none`,
+				"nullable: true -> false",
+			}},
+			{Kind: diff.ChangeRemoved, Object: diff.ObjectIndex, Table: "users", Name: "idx_users_status"},
+		}, diff.Changes(recorded, live))
+	})
+
+	t.Run("views are compared by definition", func(t *testing.T) {
+		from := schema.New()
+		from.AddView(&schema.View{Name: "names", Definition: "SELECT name FROM users"})
+		from.AddView(&schema.View{Name: "emails", Definition: "SELECT email FROM users"})
+
+		to := schema.New()
+		to.AddView(&schema.View{Name: "names", Definition: "SELECT id, name FROM users"})
+
+		assert.Equal(t, []diff.Change{
+			{Kind: diff.ChangeRemoved, Object: diff.ObjectView, Name: "emails"},
+			{Kind: diff.ChangeChanged, Object: diff.ObjectView, Name: "names", Details: []string{
+				`definition: "SELECT name FROM users" -> "SELECT id, name FROM users"`,
+			}},
+		}, diff.Changes(from, to))
+	})
+
+	t.Run("partitioning is compared", func(t *testing.T) {
+		from := schema.New()
+		from.AddTable("events", &schema.Table{Name: "events", Partitioning: &schema.Partitioning{
+			Strategy: "range",
+			Columns:  []string{"created_at"},
+			Partitions: map[string]*schema.Partition{
+				"events_2024": {Name: "events_2024", Bound: "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')"},
+			},
+		}})
+
+		to := schema.New()
+		to.AddTable("events", &schema.Table{Name: "events", Partitioning: &schema.Partitioning{
+			Strategy: "range",
+			Columns:  []string{"created_at"},
+		}})
+
+		assert.Equal(t, []diff.Change{
+			{Kind: diff.ChangeChanged, Object: diff.ObjectTable, Name: "events", Details: []string{
+				`partitions: [events_2024 FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')] -> []`,
+			}},
+		}, diff.Changes(from, to))
+	})
+
+	t.Run("partitions of a table are compared", func(t *testing.T) {
+		from := schema.New()
+		from.AddTable("events_2024", &schema.Table{Name: "events_2024"})
+
+		to := schema.New()
+		to.AddTable("events_2024", &schema.Table{Name: "events_2024", PartitionOf: "events"})
+
+		assert.Equal(t, []diff.Change{
+			{Kind: diff.ChangeChanged, Object: diff.ObjectTable, Name: "events_2024", Details: []string{
+				`partition of: "" -> "events"`,
+			}},
+		}, diff.Changes(from, to))
+	})
+
+	t.Run("row level security and policies are compared", func(t *testing.T) {
+		from := schema.New()
+		from.AddTable("documents", &schema.Table{Name: "documents", Policies: map[string]*schema.Policy{
+			"owner_only": {Name: "owner_only", Command: "ALL", Permissive: true, Roles: []string{"public"}, Using: "(owner = CURRENT_USER)"},
+		}})
+
+		to := schema.New()
+		to.AddTable("documents", &schema.Table{Name: "documents", RowLevelSecurity: true, Policies: map[string]*schema.Policy{
+			"owner_only": {Name: "owner_only", Command: "SELECT", Permissive: true, Roles: []string{"public"}, Using: "(owner = CURRENT_USER)"},
+			"admins":     {Name: "admins", Command: "ALL", Permissive: true, Roles: []string{"admin"}, Using: "true"},
+		}})
+
+		assert.Equal(t, []diff.Change{
+			{Kind: diff.ChangeChanged, Object: diff.ObjectTable, Name: "documents", Details: []string{
+				"row level security: false -> true",
+			}},
+			{Kind: diff.ChangeAdded, Object: diff.ObjectPolicy, Table: "documents", Name: "admins"},
+			{Kind: diff.ChangeChanged, Object: diff.ObjectPolicy, Table: "documents", Name: "owner_only", Details: []string{
+				`command: "ALL" -> "SELECT"`,
+			}},
+		}, diff.Changes(from, to))
+	})
+
+	t.Run("sequences and identity columns are compared", func(t *testing.T) {
+		from := schema.New()
+		from.AddSequence(&schema.Sequence{Name: "invoice_numbers", Type: "bigint", Start: 1, Increment: 1, MinValue: 1, MaxValue: 1000, Cache: 1})
+		from.AddTable("orders", &schema.Table{Name: "orders", Columns: map[string]*schema.Column{
+			"id": {Name: "id", Type: "integer", Sequence: "orders_id_seq"},
+		}})
+
+		to := schema.New()
+		to.AddSequence(&schema.Sequence{Name: "invoice_numbers", Type: "bigint", Start: 1, Increment: 10, MinValue: 1, MaxValue: 1000, Cache: 5})
+		to.AddSequence(&schema.Sequence{Name: "ticket_numbers", Type: "integer", Start: 1, Increment: 1, MinValue: 1, MaxValue: 100, Cache: 1})
+		to.AddTable("orders", &schema.Table{Name: "orders", Columns: map[string]*schema.Column{
+			"id": {Name: "id", Type: "integer", Identity: "ALWAYS", Sequence: "orders_id_seq"},
+		}})
+
+		assert.Equal(t, []diff.Change{
+			{Kind: diff.ChangeAdded, Object: diff.ObjectSequence, Name: "ticket_numbers"},
+			{Kind: diff.ChangeChanged, Object: diff.ObjectSequence, Name: "invoice_numbers", Details: []string{
+				"increment: 1 -> 10",
+				"cache: 1 -> 5",
+			}},
+			{Kind: diff.ChangeChanged, Object: diff.ObjectColumn, Table: "orders", Name: "id", Details: []string{
+				`identity: "" -> "ALWAYS"`,
+			}},
+		}, diff.Changes(from, to))
+	})
+
+	t.Run("changes are described", func(t *testing.T) {
+		change := diff.Change{
+			Kind:    diff.ChangeChanged,
+			Object:  diff.ObjectColumn,
+			Table:   "users",
+			Name:    "status",
+			Details: []string{"nullable: true -> false"},
+		}
+		assert.Equal(t, `changed column "status" on table "users": nullable: true -> false`, change.String())
+	})
+}
@@ -0,0 +1,755 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/oapi-codegen/nullable"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/schema"
+	"github.com/xataio/pgroll/pkg/sql2pgroll"
+)
+
+// Result is the outcome of comparing a current schema with a desired schema.
+type Result struct {
+	// Operations migrate the current schema to the desired schema, in the
+	// order in which they should be run
+	Operations migrations.Operations
+
+	// Destructive describes the operations that drop, or may lose, data
+	Destructive []string
+
+	// Unsupported describes differences between the schemas that can not be
+	// expressed as pgroll operations and must be migrated by hand
+	Unsupported []string
+}
+
+// Empty returns true if the schemas are equivalent
+func (r *Result) Empty() bool {
+	return len(r.Operations) == 0 && len(r.Unsupported) == 0
+}
+
+// Schemas compares the `current` schema with the `desired` schema and returns
+// the operations that migrate one to the other.
+//
+// Tables, columns, constraints and indexes are matched by name, so renames
+// appear as a drop followed by a create. Operations that require data to be
+// migrated between the old and new versions of the schema use
+// `sql2pgroll.PlaceHolderSQL` for their `up` and `down` SQL.
+func Schemas(current, desired *schema.Schema) *Result {
+	d := &differ{}
+
+	d.diffEnums(current, desired)
+	d.diffTables(current, desired)
+	d.diffViews(current, desired)
+
+	return d.result()
+}
+
+// differ accumulates operations by kind so that they can be emitted in an
+// order in which each operation's dependencies are created before, and
+// dropped after, the operation runs.
+type differ struct {
+	dropViews         migrations.Operations
+	createEnums       migrations.Operations
+	alterEnums        migrations.Operations
+	createTables      migrations.Operations
+	addColumns        migrations.Operations
+	attachPartitions  migrations.Operations
+	alterColumns      migrations.Operations
+	createConstraints migrations.Operations
+	createIndexes     migrations.Operations
+	createViews       migrations.Operations
+	dropIndexes       migrations.Operations
+	dropConstraints   migrations.Operations
+	dropColumns       migrations.Operations
+	detachPartitions  migrations.Operations
+	dropTables        migrations.Operations
+	dropEnums         migrations.Operations
+
+	destructive []string
+	unsupported []string
+}
+
+func (d *differ) result() *Result {
+	var ops migrations.Operations
+	for _, group := range []migrations.Operations{
+		d.dropViews,
+		d.createEnums,
+		d.alterEnums,
+		d.createTables,
+		d.addColumns,
+		d.attachPartitions,
+		d.alterColumns,
+		d.createConstraints,
+		d.createIndexes,
+		d.createViews,
+		d.dropIndexes,
+		d.dropConstraints,
+		d.dropColumns,
+		d.detachPartitions,
+		d.dropTables,
+		d.dropEnums,
+	} {
+		ops = append(ops, group...)
+	}
+
+	return &Result{
+		Operations:  ops,
+		Destructive: d.destructive,
+		Unsupported: d.unsupported,
+	}
+}
+
+func (d *differ) diffEnums(current, desired *schema.Schema) {
+	for _, name := range sortedKeys(desired.Enums) {
+		want := desired.Enums[name]
+		have := current.GetEnum(name)
+		if have == nil {
+			d.createEnums = append(d.createEnums, &migrations.OpCreateEnum{
+				Name:   name,
+				Values: slices.Clone(want.Values),
+			})
+			continue
+		}
+		d.diffEnum(have, want)
+	}
+
+	for _, name := range sortedKeys(current.Enums) {
+		if desired.GetEnum(name) == nil {
+			d.dropEnums = append(d.dropEnums, &migrations.OpDropEnum{Name: name})
+			d.destructive = append(d.destructive, fmt.Sprintf("drop enum type %q", name))
+		}
+	}
+}
+
+func (d *differ) diffEnum(have, want *schema.Enum) {
+	values := slices.Clone(have.Values)
+
+	for i, value := range want.Values {
+		if slices.Contains(values, value) {
+			continue
+		}
+
+		// Position the new value relative to its neighbour in the desired
+		// schema, which is either an existing value or was added by a
+		// previous operation.
+		addValue := &migrations.OpAlterEnumAddValue{Value: value}
+		if i > 0 {
+			addValue.After = want.Values[i-1]
+		} else if next := firstExisting(want.Values[1:], values); next != "" {
+			addValue.Before = next
+		}
+		values = append(values, value)
+
+		d.alterEnums = append(d.alterEnums, &migrations.OpAlterEnum{
+			Name:     want.Name,
+			AddValue: addValue,
+		})
+	}
+
+	for _, value := range have.Values {
+		if !want.HasValue(value) {
+			d.unsupported = append(d.unsupported, fmt.Sprintf(
+				"enum type %q has value %q that is not in the desired schema: values can not be removed from an enum type",
+				have.Name, value))
+		}
+	}
+}
+
+// diffViews compares views by definition. Views are created and replaced in
+// an order in which each view comes after the views that it reads from.
+func (d *differ) diffViews(current, desired *schema.Schema) {
+	for _, name := range migrations.SortedViews(desired) {
+		want := desired.Views[name]
+		have := current.GetView(name)
+		switch {
+		case have == nil:
+			d.createViews = append(d.createViews, &migrations.OpCreateView{
+				Name:       name,
+				Definition: want.Definition,
+			})
+		case have.Definition != want.Definition:
+			d.createViews = append(d.createViews, &migrations.OpReplaceView{
+				Name:       name,
+				Definition: want.Definition,
+			})
+		}
+	}
+
+	for _, name := range sortedKeys(current.Views) {
+		if desired.GetView(name) == nil {
+			d.dropViews = append(d.dropViews, &migrations.OpDropView{Name: name})
+		}
+	}
+}
+
+func (d *differ) diffTables(current, desired *schema.Schema) {
+	var created []*schema.Table
+	for _, name := range sortedKeys(desired.Tables) {
+		want := desired.GetTable(name)
+		if want == nil {
+			continue
+		}
+		// Partitions are created and attached through their partitioned table,
+		// and their columns follow those of the partitioned table
+		have := current.GetTable(name)
+		if want.PartitionOf != "" || (have != nil && have.PartitionOf != "") {
+			continue
+		}
+		if have == nil {
+			created = append(created, want)
+			continue
+		}
+		d.diffTable(name, have, want)
+	}
+
+	for _, table := range orderByDependencies(created) {
+		d.createTable(table)
+	}
+
+	d.diffPartitions(current, desired)
+
+	for _, name := range sortedKeys(current.Tables) {
+		have := current.GetTable(name)
+		if have != nil && desired.GetTable(name) == nil {
+			// A partition is dropped along with its partitioned table
+			if have.PartitionOf != "" && desired.GetTable(have.PartitionOf) == nil {
+				continue
+			}
+			d.dropTables = append(d.dropTables, &migrations.OpDropTable{Name: name})
+			d.destructive = append(d.destructive, fmt.Sprintf("drop table %q", name))
+		}
+	}
+}
+
+// diffPartitions attaches the partitions that the partitioned tables of the
+// desired schema gain and detaches those that they lose. A partition that is
+// no longer in the desired schema at all is dropped as a table instead.
+func (d *differ) diffPartitions(current, desired *schema.Schema) {
+	for _, name := range sortedKeys(desired.Tables) {
+		want := desired.GetTable(name)
+		if want == nil || want.Partitioning == nil {
+			continue
+		}
+		have := current.GetTable(name)
+		if have != nil && partitionKey(have.Partitioning) != partitionKey(want.Partitioning) {
+			continue
+		}
+
+		var havePartitions map[string]*schema.Partition
+		if have != nil {
+			havePartitions = have.Partitioning.Partitions
+		}
+
+		for _, partName := range sortedKeys(want.Partitioning.Partitions) {
+			wantPart := want.Partitioning.Partitions[partName]
+			havePart, ok := havePartitions[partName]
+			switch {
+			case !ok:
+				d.attachPartitions = append(d.attachPartitions, &migrations.OpAttachPartition{
+					Table:     name,
+					Partition: partName,
+					Bound:     wantPart.Bound,
+				})
+			case havePart.Bound != wantPart.Bound:
+				d.unsupported = append(d.unsupported, fmt.Sprintf(
+					"bound of partition %q of table %q changes", partName, name))
+			}
+		}
+
+		for _, partName := range sortedKeys(havePartitions) {
+			if _, ok := want.Partitioning.Partitions[partName]; ok {
+				continue
+			}
+			if desired.GetTable(partName) != nil {
+				d.detachPartitions = append(d.detachPartitions, &migrations.OpDetachPartition{
+					Table:     name,
+					Partition: partName,
+				})
+			}
+		}
+	}
+}
+
+func (d *differ) createTable(t *schema.Table) {
+	op := &migrations.OpCreateTable{Name: t.Name}
+	if t.Comment != "" {
+		op.Comment = ptr(t.Comment)
+	}
+	if t.Partitioning != nil {
+		op.PartitionBy = &migrations.PartitionBy{
+			Strategy: migrations.PartitionByStrategy(t.Partitioning.Strategy),
+			Columns:  t.Partitioning.Columns,
+		}
+	}
+
+	for _, name := range columnOrder(t) {
+		col := t.Columns[name]
+		op.Columns = append(op.Columns, migrations.Column{
+			Name:     name,
+			Type:     col.Type,
+			Nullable: col.Nullable,
+			Default:  col.Default,
+			Pk:       slices.Contains(t.PrimaryKey, name),
+			Unique:   needsColumnUnique(t, name, col),
+			Comment:  nonEmpty(col.Comment),
+		})
+	}
+
+	for _, name := range sortedKeys(t.UniqueConstraints) {
+		uc := t.UniqueConstraints[name]
+		op.Constraints = append(op.Constraints, migrations.Constraint{
+			Name:    name,
+			Type:    migrations.ConstraintTypeUnique,
+			Columns: uc.Columns,
+		})
+	}
+	for _, name := range sortedKeys(t.CheckConstraints) {
+		cc := t.CheckConstraints[name]
+		op.Constraints = append(op.Constraints, migrations.Constraint{
+			Name:      name,
+			Type:      migrations.ConstraintTypeCheck,
+			Check:     checkExpression(cc.Definition),
+			NoInherit: cc.NoInherit,
+		})
+	}
+	for _, name := range sortedKeys(t.ForeignKeys) {
+		fk := t.ForeignKeys[name]
+		op.Constraints = append(op.Constraints, migrations.Constraint{
+			Name:       name,
+			Type:       migrations.ConstraintTypeForeignKey,
+			Columns:    fk.Columns,
+			References: foreignKeyReference(fk),
+		})
+	}
+
+	d.createTables = append(d.createTables, op)
+
+	for _, name := range sortedKeys(userIndexes(t)) {
+		d.createIndexes = append(d.createIndexes, createIndexOp(t.Name, t.Indexes[name]))
+	}
+}
+
+func (d *differ) diffTable(name string, have, want *schema.Table) {
+	for _, colName := range sortedKeys(want.Columns) {
+		wantCol := want.GetColumn(colName)
+		if wantCol == nil {
+			continue
+		}
+		haveCol := have.GetColumn(colName)
+		if haveCol == nil {
+			d.addColumn(name, colName, want, wantCol)
+			continue
+		}
+		d.diffColumn(name, colName, have, want, haveCol, wantCol)
+	}
+
+	for _, colName := range sortedKeys(have.Columns) {
+		if have.GetColumn(colName) != nil && want.GetColumn(colName) == nil {
+			d.dropColumns = append(d.dropColumns, &migrations.OpDropColumn{
+				Table:  name,
+				Column: colName,
+				Down:   sql2pgroll.PlaceHolderSQL,
+			})
+			d.destructive = append(d.destructive, fmt.Sprintf("drop column %q from table %q", colName, name))
+		}
+	}
+
+	if !sameColumns(have.PrimaryKey, want.PrimaryKey) {
+		d.unsupported = append(d.unsupported, fmt.Sprintf(
+			"primary key of table %q changes from (%s) to (%s)",
+			name, strings.Join(have.PrimaryKey, ", "), strings.Join(want.PrimaryKey, ", ")))
+	}
+	if have.Comment != want.Comment {
+		d.unsupported = append(d.unsupported, fmt.Sprintf("comment on table %q changes", name))
+	}
+	if partitionKey(have.Partitioning) != partitionKey(want.Partitioning) {
+		d.unsupported = append(d.unsupported, fmt.Sprintf("partitioning of table %q changes", name))
+	}
+
+	d.diffConstraints(name, have, want)
+	d.diffIndexes(name, have, want)
+}
+
+func (d *differ) addColumn(table, name string, t *schema.Table, col *schema.Column) {
+	op := &migrations.OpAddColumn{
+		Table: table,
+		Column: migrations.Column{
+			Name:     name,
+			Type:     col.Type,
+			Nullable: col.Nullable,
+			Default:  col.Default,
+			Unique:   needsColumnUnique(t, name, col),
+			Comment:  nonEmpty(col.Comment),
+		},
+	}
+	if !col.Nullable && col.Default == nil {
+		op.Up = sql2pgroll.PlaceHolderSQL
+	}
+
+	d.addColumns = append(d.addColumns, op)
+}
+
+func (d *differ) diffColumn(table, name string, haveTable, wantTable *schema.Table, have, want *schema.Column) {
+	op := &migrations.OpAlterColumn{
+		Table:  table,
+		Column: name,
+	}
+	changed := false
+	migratesData := false
+
+	if normalizeType(have.Type) != normalizeType(want.Type) {
+		op.Type = ptr(want.Type)
+		changed, migratesData = true, true
+		d.destructive = append(d.destructive, fmt.Sprintf(
+			"change type of column %q on table %q from %q to %q", name, table, have.Type, want.Type))
+	}
+
+	if have.Nullable != want.Nullable {
+		op.Nullable = ptr(want.Nullable)
+		changed, migratesData = true, true
+	}
+
+	// Serial columns have an implicit default drawn from their sequence
+	if !isSerial(want.Type) && normalizeDefault(have.Default) != normalizeDefault(want.Default) {
+		if want.Default != nil {
+			op.Default = nullable.NewNullableWithValue(*want.Default)
+		} else {
+			op.Default = nullable.NewNullNullable[string]()
+		}
+		changed = true
+	}
+
+	if have.Comment != want.Comment {
+		if want.Comment != "" {
+			op.Comment = nullable.NewNullableWithValue(want.Comment)
+		} else {
+			op.Comment = nullable.NewNullNullable[string]()
+		}
+		changed = true
+	}
+
+	// Unique constraints on a single column in the desired schema are added by
+	// diffConstraints
+	if isUnique(wantTable, name, true) && !isUnique(haveTable, name, true) && !isUnique(wantTable, name, false) {
+		op.Unique = &migrations.UniqueConstraint{Name: fmt.Sprintf("%s_%s_key", table, name)}
+		changed = true
+	}
+
+	if !changed {
+		return
+	}
+	if migratesData {
+		op.Up = sql2pgroll.PlaceHolderSQL
+		op.Down = sql2pgroll.PlaceHolderSQL
+	}
+
+	d.alterColumns = append(d.alterColumns, op)
+}
+
+func (d *differ) diffConstraints(table string, have, want *schema.Table) {
+	for _, name := range sortedKeys(want.UniqueConstraints) {
+		if _, ok := have.UniqueConstraints[name]; ok {
+			continue
+		}
+		uc := want.UniqueConstraints[name]
+		d.createConstraints = append(d.createConstraints, &migrations.OpCreateConstraint{
+			Table:   table,
+			Name:    name,
+			Type:    migrations.OpCreateConstraintTypeUnique,
+			Columns: uc.Columns,
+			Up:      placeholders[migrations.MultiColumnUpSQL](uc.Columns),
+			Down:    placeholders[migrations.MultiColumnDownSQL](uc.Columns),
+		})
+	}
+	for _, name := range sortedKeys(want.CheckConstraints) {
+		if _, ok := have.CheckConstraints[name]; ok {
+			continue
+		}
+		cc := want.CheckConstraints[name]
+		d.createConstraints = append(d.createConstraints, &migrations.OpCreateConstraint{
+			Table:     table,
+			Name:      name,
+			Type:      migrations.OpCreateConstraintTypeCheck,
+			Check:     ptr(checkExpression(cc.Definition)),
+			NoInherit: cc.NoInherit,
+			Columns:   cc.Columns,
+			Up:        placeholders[migrations.MultiColumnUpSQL](cc.Columns),
+			Down:      placeholders[migrations.MultiColumnDownSQL](cc.Columns),
+		})
+	}
+	for _, name := range sortedKeys(want.ForeignKeys) {
+		if _, ok := have.ForeignKeys[name]; ok {
+			continue
+		}
+		fk := want.ForeignKeys[name]
+		d.createConstraints = append(d.createConstraints, &migrations.OpCreateConstraint{
+			Table:      table,
+			Name:       name,
+			Type:       migrations.OpCreateConstraintTypeForeignKey,
+			Columns:    fk.Columns,
+			References: foreignKeyReference(fk),
+			Up:         placeholders[migrations.MultiColumnUpSQL](fk.Columns),
+			Down:       placeholders[migrations.MultiColumnDownSQL](fk.Columns),
+		})
+	}
+
+	dropConstraint := func(name string, columns []string) {
+		d.dropConstraints = append(d.dropConstraints, &migrations.OpDropMultiColumnConstraint{
+			Table: table,
+			Name:  name,
+			Up:    placeholders[migrations.MultiColumnUpSQL](columns),
+			Down:  placeholders[migrations.MultiColumnDownSQL](columns),
+		})
+	}
+	for _, name := range sortedKeys(have.UniqueConstraints) {
+		uc := have.UniqueConstraints[name]
+		if _, ok := want.UniqueConstraints[name]; ok {
+			continue
+		}
+		// A unique constraint on a single column may be described in the
+		// desired schema by the column alone
+		if len(uc.Columns) == 1 && isUnique(want, uc.Columns[0], true) {
+			continue
+		}
+		dropConstraint(name, uc.Columns)
+	}
+	for _, name := range sortedKeys(have.CheckConstraints) {
+		if _, ok := want.CheckConstraints[name]; !ok {
+			dropConstraint(name, have.CheckConstraints[name].Columns)
+		}
+	}
+	for _, name := range sortedKeys(have.ForeignKeys) {
+		if _, ok := want.ForeignKeys[name]; !ok {
+			dropConstraint(name, have.ForeignKeys[name].Columns)
+		}
+	}
+}
+
+func (d *differ) diffIndexes(table string, have, want *schema.Table) {
+	haveIndexes := userIndexes(have)
+	wantIndexes := userIndexes(want)
+
+	for _, name := range sortedKeys(wantIndexes) {
+		wantIdx := wantIndexes[name]
+		haveIdx, ok := haveIndexes[name]
+		if ok && sameIndex(haveIdx, wantIdx) {
+			continue
+		}
+		if ok {
+			d.dropIndexes = append(d.dropIndexes, &migrations.OpDropIndex{Name: name})
+		}
+		d.createIndexes = append(d.createIndexes, createIndexOp(table, wantIdx))
+	}
+
+	for _, name := range sortedKeys(haveIndexes) {
+		if _, ok := wantIndexes[name]; !ok {
+			d.dropIndexes = append(d.dropIndexes, &migrations.OpDropIndex{Name: name})
+		}
+	}
+}
+
+func createIndexOp(table string, idx *schema.Index) *migrations.OpCreateIndex {
+	op := &migrations.OpCreateIndex{
+		Table:  table,
+		Name:   idx.Name,
+		Unique: idx.Unique,
+		Method: migrations.OpCreateIndexMethod(idx.Method),
+	}
+	for _, col := range idx.Columns {
+		op.Columns = append(op.Columns, migrations.IndexField{Column: col})
+	}
+	if idx.Predicate != nil {
+		op.Predicate = *idx.Predicate
+	}
+	return op
+}
+
+// userIndexes returns the indexes on the table that are not created
+// implicitly to back the table's primary key or one of its constraints
+func userIndexes(t *schema.Table) map[string]*schema.Index {
+	indexes := make(map[string]*schema.Index, len(t.Indexes))
+	for name, idx := range t.Indexes {
+		if _, ok := t.UniqueConstraints[name]; ok {
+			continue
+		}
+		if _, ok := t.ExcludeConstraints[name]; ok {
+			continue
+		}
+		if idx.Unique && len(t.PrimaryKey) > 0 && sameColumns(idx.Columns, t.PrimaryKey) {
+			continue
+		}
+		indexes[name] = idx
+	}
+	return indexes
+}
+
+func sameIndex(a, b *schema.Index) bool {
+	methodOrDefault := func(m string) string {
+		if m == "" {
+			return string(migrations.OpCreateIndexMethodBtree)
+		}
+		return m
+	}
+
+	return a.Unique == b.Unique &&
+		slices.Equal(a.Columns, b.Columns) &&
+		methodOrDefault(a.Method) == methodOrDefault(b.Method)
+}
+
+// isUnique returns true if the column is unique. If `implicit` is true, a
+// column that makes up the table's primary key on its own or that is marked
+// unique is unique; otherwise only a unique constraint on exactly the column
+// makes it unique.
+func isUnique(t *schema.Table, column string, implicit bool) bool {
+	for _, uc := range t.UniqueConstraints {
+		if len(uc.Columns) == 1 && uc.Columns[0] == column {
+			return true
+		}
+	}
+	if !implicit {
+		return false
+	}
+
+	if len(t.PrimaryKey) == 1 && t.PrimaryKey[0] == column {
+		return true
+	}
+	col := t.GetColumn(column)
+	return col != nil && col.Unique
+}
+
+// needsColumnUnique returns true if the column is unique and its uniqueness is
+// not already enforced by the table's primary key or a unique constraint
+func needsColumnUnique(t *schema.Table, name string, col *schema.Column) bool {
+	if len(t.PrimaryKey) == 1 && t.PrimaryKey[0] == name {
+		return false
+	}
+	return col.Unique && !isUnique(t, name, false)
+}
+
+// columnOrder returns the names of the table's columns with the primary key
+// columns first, in primary key order, followed by the remaining columns in
+// name order.
+func columnOrder(t *schema.Table) []string {
+	names := make([]string, 0, len(t.Columns))
+	for _, name := range t.PrimaryKey {
+		if t.GetColumn(name) != nil {
+			names = append(names, name)
+		}
+	}
+	for _, name := range sortedKeys(t.Columns) {
+		if t.GetColumn(name) != nil && !slices.Contains(t.PrimaryKey, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// orderByDependencies orders new tables so that tables referenced by a
+// foreign key are created before the tables that reference them. Tables with
+// circular references are created in name order.
+func orderByDependencies(tables []*schema.Table) []*schema.Table {
+	pending := make(map[string]*schema.Table, len(tables))
+	for _, t := range tables {
+		pending[t.Name] = t
+	}
+
+	ordered := make([]*schema.Table, 0, len(tables))
+	for len(pending) > 0 {
+		progress := false
+		for _, name := range sortedKeys(pending) {
+			if dependsOnAny(pending[name], pending) {
+				continue
+			}
+			ordered = append(ordered, pending[name])
+			delete(pending, name)
+			progress = true
+		}
+		if !progress {
+			for _, name := range sortedKeys(pending) {
+				ordered = append(ordered, pending[name])
+			}
+			break
+		}
+	}
+	return ordered
+}
+
+func dependsOnAny(t *schema.Table, tables map[string]*schema.Table) bool {
+	for _, fk := range t.ForeignKeys {
+		if _, ok := tables[fk.ReferencedTable]; ok && fk.ReferencedSchema == "" && fk.ReferencedTable != t.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func foreignKeyReference(fk *schema.ForeignKey) *migrations.TableForeignKeyReference {
+	return &migrations.TableForeignKeyReference{
+		Schema:             fk.ReferencedSchema,
+		Table:              fk.ReferencedTable,
+		Columns:            fk.ReferencedColumns,
+		OnDelete:           migrations.ForeignKeyAction(fk.OnDelete),
+		OnDeleteSetColumns: fk.OnDeleteSetColumns,
+		OnUpdate:           migrations.ForeignKeyAction(fk.OnUpdate),
+		MatchType:          migrations.ForeignKeyMatchType(fk.MatchType),
+	}
+}
+
+// checkExpression returns the expression of a check constraint from its
+// definition, which is either a bare expression or, as read from postgres,
+// of the form `CHECK (expr) [NO INHERIT]`.
+func checkExpression(definition string) string {
+	expr := strings.TrimSpace(definition)
+	if !strings.HasPrefix(strings.ToUpper(expr), "CHECK ") {
+		return expr
+	}
+	expr = strings.TrimSpace(expr[len("CHECK "):])
+	expr = strings.TrimSuffix(expr, " NO INHERIT")
+	return expr
+}
+
+func placeholders[T ~map[string]string](columns []string) T {
+	m := make(T, len(columns))
+	for _, col := range columns {
+		m[col] = sql2pgroll.PlaceHolderSQL
+	}
+	return m
+}
+
+func firstExisting(candidates, values []string) string {
+	for _, c := range candidates {
+		if slices.Contains(values, c) {
+			return c
+		}
+	}
+	return ""
+}
+
+func sameColumns(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+func nonEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,365 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/oapi-codegen/nullable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xataio/pgroll/pkg/diff"
+	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/schema"
+	"github.com/xataio/pgroll/pkg/sql2pgroll"
+)
+
+// current is the live schema that the desired schemas in these tests are
+// compared against, in the form returned by `state.ReadSchema`.
+const current = `
+name: public
+tables:
+  users:
+    name: users
+    primaryKey: [id]
+    columns:
+      id:
+        name: id
+        type: integer
+        default: nextval('users_id_seq'::regclass)
+        nullable: false
+        unique: true
+      email:
+        name: email
+        type: character varying(255)
+        nullable: false
+        unique: true
+      status:
+        name: status
+        type: text
+        default: "'active'::text"
+        nullable: true
+      legacy:
+        name: legacy
+        type: text
+        nullable: true
+    uniqueConstraints:
+      users_email_key:
+        name: users_email_key
+        columns: [email]
+    indexes:
+      users_pkey:
+        name: users_pkey
+        unique: true
+        columns: [id]
+      users_email_key:
+        name: users_email_key
+        unique: true
+        columns: [email]
+      idx_users_status:
+        name: idx_users_status
+        columns: [status]
+        method: btree
+  audit_log:
+    name: audit_log
+    columns:
+      entry:
+        name: entry
+        type: text
+        nullable: true
+enums:
+  mood:
+    name: mood
+    values: [sad, happy]
+`
+
+func TestSchemas(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		desired             string
+		expectedOps         migrations.Operations
+		expectedDestructive int
+		expectedUnsupported int
+	}{
+		"equivalent schemas written differently produce no operations": {
+			desired: `
+CREATE TYPE mood AS ENUM ('sad', 'happy');
+CREATE TABLE users (
+  id serial PRIMARY KEY,
+  email varchar(255) NOT NULL UNIQUE,
+  status text DEFAULT 'active',
+  legacy text
+);
+CREATE INDEX idx_users_status ON users (status);
+CREATE TABLE audit_log (entry text);
+`,
+		},
+		"new table, column, index and enum value": {
+			desired: `
+CREATE TYPE mood AS ENUM ('sad', 'ok', 'happy');
+CREATE TABLE users (
+  id serial PRIMARY KEY,
+  email varchar(255) NOT NULL UNIQUE,
+  status text DEFAULT 'active',
+  legacy text,
+  name text NOT NULL
+);
+CREATE INDEX idx_users_status ON users (status);
+CREATE INDEX idx_users_name ON users (name);
+CREATE TABLE audit_log (entry text);
+CREATE TABLE posts (
+  id int PRIMARY KEY,
+  title text
+);
+`,
+			expectedOps: migrations.Operations{
+				&migrations.OpAlterEnum{
+					Name:     "mood",
+					AddValue: &migrations.OpAlterEnumAddValue{Value: "ok", After: "sad"},
+				},
+				&migrations.OpCreateTable{
+					Name: "posts",
+					Columns: []migrations.Column{
+						{Name: "id", Type: "int", Pk: true},
+						{Name: "title", Type: "text", Nullable: true},
+					},
+				},
+				&migrations.OpAddColumn{
+					Table:  "users",
+					Column: migrations.Column{Name: "name", Type: "text"},
+					Up:     sql2pgroll.PlaceHolderSQL,
+				},
+				&migrations.OpCreateIndex{
+					Table:   "users",
+					Name:    "idx_users_name",
+					Columns: []migrations.IndexField{{Column: "name"}},
+					Method:  migrations.OpCreateIndexMethodBtree,
+				},
+			},
+		},
+		"changed and dropped columns": {
+			desired: `
+CREATE TYPE mood AS ENUM ('sad', 'happy');
+CREATE TABLE users (
+  id serial PRIMARY KEY,
+  email text NOT NULL UNIQUE,
+  status text DEFAULT 'pending'
+);
+CREATE INDEX idx_users_status ON users (status);
+CREATE TABLE audit_log (entry text);
+`,
+			expectedOps: migrations.Operations{
+				&migrations.OpAlterColumn{
+					Table:  "users",
+					Column: "email",
+					Type:   ptr("text"),
+					Up:     sql2pgroll.PlaceHolderSQL,
+					Down:   sql2pgroll.PlaceHolderSQL,
+				},
+				&migrations.OpAlterColumn{
+					Table:   "users",
+					Column:  "status",
+					Default: nullable.NewNullableWithValue("'pending'"),
+				},
+				&migrations.OpDropColumn{
+					Table:  "users",
+					Column: "legacy",
+					Down:   sql2pgroll.PlaceHolderSQL,
+				},
+			},
+			expectedDestructive: 2,
+		},
+		"dropped table, index and enum": {
+			desired: `
+CREATE TABLE users (
+  id serial PRIMARY KEY,
+  email varchar(255) NOT NULL UNIQUE,
+  status text DEFAULT 'active',
+  legacy text
+);
+`,
+			expectedOps: migrations.Operations{
+				&migrations.OpDropIndex{Name: "idx_users_status"},
+				&migrations.OpDropTable{Name: "audit_log"},
+				&migrations.OpDropEnum{Name: "mood"},
+			},
+			expectedDestructive: 2,
+		},
+		"removed enum value is unsupported": {
+			desired: `
+CREATE TYPE mood AS ENUM ('happy');
+CREATE TABLE users (
+  id serial PRIMARY KEY,
+  email varchar(255) NOT NULL UNIQUE,
+  status text DEFAULT 'active',
+  legacy text
+);
+CREATE INDEX idx_users_status ON users (status);
+CREATE TABLE audit_log (entry text);
+`,
+			expectedUnsupported: 1,
+		},
+		"new partitioned table": {
+			desired: `
+CREATE TYPE mood AS ENUM ('sad', 'happy');
+CREATE TABLE users (
+  id serial PRIMARY KEY,
+  email varchar(255) NOT NULL UNIQUE,
+  status text DEFAULT 'active',
+  legacy text
+);
+CREATE INDEX idx_users_status ON users (status);
+CREATE TABLE audit_log (entry text);
+CREATE TABLE events (
+  id int,
+  created_at date
+) PARTITION BY RANGE (created_at);
+`,
+			expectedOps: migrations.Operations{
+				&migrations.OpCreateTable{
+					Name: "events",
+					Columns: []migrations.Column{
+						{Name: "created_at", Type: "date", Nullable: true},
+						{Name: "id", Type: "int", Nullable: true},
+					},
+					PartitionBy: &migrations.PartitionBy{
+						Strategy: migrations.PartitionByStrategyRange,
+						Columns:  []string{"created_at"},
+					},
+				},
+			},
+		},
+		"partitioning an existing table is unsupported": {
+			desired: `
+CREATE TYPE mood AS ENUM ('sad', 'happy');
+CREATE TABLE users (
+  id serial PRIMARY KEY,
+  email varchar(255) NOT NULL UNIQUE,
+  status text DEFAULT 'active',
+  legacy text
+);
+CREATE INDEX idx_users_status ON users (status);
+CREATE TABLE audit_log (entry text) PARTITION BY HASH (entry);
+`,
+			expectedUnsupported: 1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			currentSchema, err := diff.SchemaFromDocument([]byte(current))
+			require.NoError(t, err)
+
+			desiredSchema, err := diff.SchemaFromSQL(tc.desired)
+			require.NoError(t, err)
+
+			result := diff.Schemas(currentSchema, desiredSchema)
+
+			assert.Equal(t, tc.expectedOps, result.Operations)
+			assert.Len(t, result.Destructive, tc.expectedDestructive)
+			assert.Len(t, result.Unsupported, tc.expectedUnsupported)
+		})
+	}
+}
+
+func TestSchemasOrdersNewTablesByForeignKeys(t *testing.T) {
+	t.Parallel()
+
+	desired, err := diff.SchemaFromSQL(`
+CREATE TABLE comments (
+  id int PRIMARY KEY,
+  post_id int CONSTRAINT fk_comments_post REFERENCES posts (id)
+);
+CREATE TABLE posts (
+  id int PRIMARY KEY
+);
+`)
+	require.NoError(t, err)
+
+	result := diff.Schemas(schema.New(), desired)
+
+	require.Len(t, result.Operations, 2)
+	assert.Equal(t, "posts", result.Operations[0].(*migrations.OpCreateTable).Name)
+	assert.Equal(t, "comments", result.Operations[1].(*migrations.OpCreateTable).Name)
+}
+
+func TestSchemasDiffsViews(t *testing.T) {
+	t.Parallel()
+
+	currentSchema := schema.New()
+	currentSchema.AddView(&schema.View{Name: "active_users", Definition: "SELECT id FROM users WHERE status = 'active'"})
+	currentSchema.AddView(&schema.View{Name: "legacy_users", Definition: "SELECT id, legacy FROM users"})
+
+	desiredSchema, err := diff.SchemaFromDocument([]byte(`
+views:
+  active_users:
+    definition: SELECT id, email FROM users WHERE status = 'active'
+  active_emails:
+    definition: SELECT email FROM active_users
+`))
+	require.NoError(t, err)
+
+	result := diff.Schemas(currentSchema, desiredSchema)
+
+	assert.Equal(t, migrations.Operations{
+		&migrations.OpDropView{Name: "legacy_users"},
+		&migrations.OpReplaceView{Name: "active_users", Definition: "SELECT id, email FROM users WHERE status = 'active'"},
+		&migrations.OpCreateView{Name: "active_emails", Definition: "SELECT email FROM active_users"},
+	}, result.Operations)
+}
+
+func TestSchemasDiffsPartitions(t *testing.T) {
+	t.Parallel()
+
+	partitioning := func(partitions ...*schema.Partition) *schema.Partitioning {
+		p := &schema.Partitioning{Strategy: "range", Columns: []string{"created_at"}, Partitions: map[string]*schema.Partition{}}
+		for _, partition := range partitions {
+			p.Partitions[partition.Name] = partition
+		}
+		return p
+	}
+	partition2023 := &schema.Partition{Name: "events_2023", Bound: "FOR VALUES FROM ('2023-01-01') TO ('2024-01-01')"}
+	partition2024 := &schema.Partition{Name: "events_2024", Bound: "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')"}
+
+	currentSchema := schema.New()
+	currentSchema.AddTable("events", &schema.Table{Name: "events", Partitioning: partitioning(partition2023)})
+	currentSchema.AddTable("events_2023", &schema.Table{Name: "events_2023", PartitionOf: "events"})
+
+	t.Run("partitions are attached and detached", func(t *testing.T) {
+		desiredSchema := schema.New()
+		desiredSchema.AddTable("events", &schema.Table{Name: "events", Partitioning: partitioning(partition2024)})
+		desiredSchema.AddTable("events_2023", &schema.Table{Name: "events_2023"})
+		desiredSchema.AddTable("events_2024", &schema.Table{Name: "events_2024", PartitionOf: "events"})
+
+		result := diff.Schemas(currentSchema, desiredSchema)
+
+		assert.Equal(t, migrations.Operations{
+			&migrations.OpAttachPartition{Table: "events", Partition: "events_2024", Bound: partition2024.Bound},
+			&migrations.OpDetachPartition{Table: "events", Partition: "events_2023"},
+		}, result.Operations)
+		assert.Empty(t, result.Unsupported)
+	})
+
+	t.Run("partitions are dropped with their partitioned table", func(t *testing.T) {
+		result := diff.Schemas(currentSchema, schema.New())
+
+		assert.Equal(t, migrations.Operations{
+			&migrations.OpDropTable{Name: "events"},
+		}, result.Operations)
+	})
+}
+
+func TestSchemaFromSQLRejectsUnsupportedStatements(t *testing.T) {
+	t.Parallel()
+
+	_, err := diff.SchemaFromSQL("CREATE TABLE t (id int); CREATE SCHEMA s;")
+	assert.Error(t, err)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,210 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/schema"
+	"github.com/xataio/pgroll/pkg/sql2pgroll"
+)
+
+// ReadSchema reads a desired schema from a file. Files with a `.sql` extension
+// are parsed as SQL DDL statements; `.json`, `.yaml` and `.yml` files are read
+// as documents in the `schema.Schema` format.
+func ReadSchema(dir fs.FS, filename string) (*schema.Schema, error) {
+	contents, err := fs.ReadFile(dir, filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading schema file: %w", err)
+	}
+
+	switch filepath.Ext(filename) {
+	case ".sql":
+		return SchemaFromSQL(string(contents))
+	case ".json", ".yaml", ".yml":
+		return SchemaFromDocument(contents)
+	default:
+		return nil, fmt.Errorf("unsupported schema file %q: must be .sql, .json, .yaml or .yml", filename)
+	}
+}
+
+// SchemaFromDocument parses a JSON or YAML document in the `schema.Schema`
+// format, as returned by `state.ReadSchema`.
+func SchemaFromDocument(contents []byte) (*schema.Schema, error) {
+	s := schema.New()
+	if err := yaml.Unmarshal(contents, s); err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+	if s.Tables == nil {
+		s.Tables = make(map[string]*schema.Table)
+	}
+
+	// Names may be omitted when they are the same as the map key
+	for name, table := range s.Tables {
+		if table.Name == "" {
+			table.Name = name
+		}
+		for colName, col := range table.Columns {
+			if col.Name == "" {
+				col.Name = colName
+			}
+		}
+		for idxName, idx := range table.Indexes {
+			if idx.Name == "" {
+				idx.Name = idxName
+			}
+		}
+	}
+	for name, enum := range s.Enums {
+		if enum.Name == "" {
+			enum.Name = name
+		}
+	}
+	for name, view := range s.Views {
+		if view.Name == "" {
+			view.Name = name
+		}
+	}
+
+	return s, nil
+}
+
+// SchemaFromSQL builds a schema from SQL DDL statements. Only `CREATE TABLE`,
+// `CREATE INDEX`, `CREATE TYPE ... AS ENUM` and `ALTER TABLE ... ADD
+// CONSTRAINT` statements that can be converted to pgroll operations are
+// supported.
+func SchemaFromSQL(sql string) (*schema.Schema, error) {
+	ops, err := sql2pgroll.Convert(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	s := schema.New()
+	for _, op := range ops {
+		switch op := op.(type) {
+		case *migrations.OpCreateTable:
+			if _, err := op.Start(ctx, migrations.NewNoopLogger(), &db.FakeDB{}, s); err != nil {
+				return nil, err
+			}
+			addColumnConstraints(s.GetTable(op.Name), op.Columns)
+
+		case *migrations.OpCreateEnum:
+			if _, err := op.Start(ctx, migrations.NewNoopLogger(), &db.FakeDB{}, s); err != nil {
+				return nil, err
+			}
+
+		case *migrations.OpCreateIndex:
+			table := s.GetTable(op.Table)
+			if table == nil {
+				return nil, migrations.TableDoesNotExistError{Name: op.Table}
+			}
+			addIndex(table, op)
+
+		case *migrations.OpCreateConstraint:
+			table := s.GetTable(op.Table)
+			if table == nil {
+				return nil, migrations.TableDoesNotExistError{Name: op.Table}
+			}
+			addConstraint(table, op)
+
+		case *migrations.OpRawSQL:
+			return nil, fmt.Errorf("unsupported statement in schema: %s", op.Up)
+
+		default:
+			return nil, fmt.Errorf("unsupported operation in schema: %s", migrations.OperationName(op))
+		}
+	}
+
+	return s, nil
+}
+
+// addColumnConstraints adds the check constraints and foreign keys defined on
+// individual columns of a new table to the table's schema
+func addColumnConstraints(table *schema.Table, columns []migrations.Column) {
+	for _, col := range columns {
+		if col.Check != nil {
+			table.CheckConstraints[col.Check.Name] = &schema.CheckConstraint{
+				Name:       col.Check.Name,
+				Columns:    []string{col.Name},
+				Definition: col.Check.Constraint,
+				NoInherit:  col.Check.NoInherit,
+			}
+		}
+		if col.References != nil {
+			table.ForeignKeys[col.References.Name] = &schema.ForeignKey{
+				Name:              col.References.Name,
+				Columns:           []string{col.Name},
+				ReferencedTable:   col.References.Table,
+				ReferencedSchema:  col.References.Schema,
+				ReferencedColumns: []string{col.References.Column},
+				OnDelete:          string(col.References.OnDelete),
+				OnUpdate:          string(col.References.OnUpdate),
+				MatchType:         string(col.References.MatchType),
+			}
+		}
+	}
+}
+
+func addIndex(table *schema.Table, op *migrations.OpCreateIndex) {
+	idx := &schema.Index{
+		Name:   op.Name,
+		Unique: op.Unique,
+		Method: string(op.Method),
+	}
+	for _, field := range op.Columns {
+		idx.Columns = append(idx.Columns, field.Column)
+	}
+	if op.Predicate != "" {
+		idx.Predicate = &op.Predicate
+	}
+
+	if table.Indexes == nil {
+		table.Indexes = make(map[string]*schema.Index)
+	}
+	table.Indexes[op.Name] = idx
+}
+
+func addConstraint(table *schema.Table, op *migrations.OpCreateConstraint) {
+	switch op.Type {
+	case migrations.OpCreateConstraintTypeUnique:
+		table.UniqueConstraints[op.Name] = &schema.UniqueConstraint{
+			Name:    op.Name,
+			Columns: op.Columns,
+		}
+	case migrations.OpCreateConstraintTypeCheck:
+		if op.Check == nil {
+			return
+		}
+		table.CheckConstraints[op.Name] = &schema.CheckConstraint{
+			Name:       op.Name,
+			Columns:    op.Columns,
+			Definition: *op.Check,
+			NoInherit:  op.NoInherit,
+		}
+	case migrations.OpCreateConstraintTypeForeignKey:
+		if op.References == nil {
+			return
+		}
+		table.ForeignKeys[op.Name] = &schema.ForeignKey{
+			Name:               op.Name,
+			Columns:            op.Columns,
+			ReferencedTable:    op.References.Table,
+			ReferencedSchema:   op.References.Schema,
+			ReferencedColumns:  op.References.Columns,
+			OnDelete:           string(op.References.OnDelete),
+			OnDeleteSetColumns: op.References.OnDeleteSetColumns,
+			OnUpdate:           string(op.References.OnUpdate),
+			MatchType:          string(op.References.MatchType),
+		}
+	case migrations.OpCreateConstraintTypePrimaryKey:
+		table.PrimaryKey = op.Columns
+	}
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package diff
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// typeAliases maps alternative names of built-in types to the names used by
+// postgres when describing a column's type.
+var typeAliases = map[string]string{
+	"int":         "integer",
+	"int4":        "integer",
+	"serial":      "integer",
+	"serial4":     "integer",
+	"int8":        "bigint",
+	"bigserial":   "bigint",
+	"serial8":     "bigint",
+	"int2":        "smallint",
+	"smallserial": "smallint",
+	"serial2":     "smallint",
+	"bool":        "boolean",
+	"float":       "double precision",
+	"float8":      "double precision",
+	"float4":      "real",
+	"decimal":     "numeric",
+	"varchar":     "character varying",
+	"char":        "character",
+	"bpchar":      "character",
+	"varbit":      "bit varying",
+	"timestamp":   "timestamp without time zone",
+	"timestamptz": "timestamp with time zone",
+	"time":        "time without time zone",
+	"timetz":      "time with time zone",
+}
+
+var serialTypes = []string{"serial", "serial2", "serial4", "serial8", "smallserial", "bigserial"}
+
+var (
+	whitespace     = regexp.MustCompile(`\s+`)
+	typeModifier   = regexp.MustCompile(`\s*\(([^)]*)\)`)
+	arrayDimension = regexp.MustCompile(`\s*\[\d*\]`)
+
+	// trailingCast matches a cast to a built-in type at the end of an
+	// expression, as added by postgres to literal column defaults
+	trailingCast = regexp.MustCompile(`(?i)::[a-z_][a-z0-9_ ]*(\([0-9, ]+\))?(\[\])*$`)
+)
+
+// normalizeType returns the canonical name of a column type so that types
+// written using aliases, such as `int` and `varchar(255)`, compare equal to
+// the names that postgres uses, such as `integer` and `character
+// varying(255)`.
+func normalizeType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	t = strings.ReplaceAll(t, `"`, "")
+	t = whitespace.ReplaceAllString(t, " ")
+
+	// Array dimensions are not enforced by postgres
+	arrays := len(arrayDimension.FindAllString(t, -1))
+	t = arrayDimension.ReplaceAllString(t, "")
+
+	// Separate any type modifier, such as the length of a varchar
+	modifier := ""
+	if m := typeModifier.FindStringSubmatch(t); m != nil {
+		modifier = "(" + strings.ReplaceAll(m[1], " ", "") + ")"
+		t = typeModifier.ReplaceAllString(t, "")
+	}
+
+	if alias, ok := typeAliases[t]; ok {
+		t = alias
+	}
+
+	// Modifiers of time types come before the time zone, as in
+	// `timestamp(3) with time zone`
+	if first, rest, ok := strings.Cut(t, " with"); ok && modifier != "" {
+		t = first + modifier + " with" + rest
+	} else {
+		t += modifier
+	}
+
+	return t + strings.Repeat("[]", arrays)
+}
+
+// isSerial returns true if the type is one of the serial pseudo-types
+func isSerial(t string) bool {
+	return slices.Contains(serialTypes, strings.ToLower(strings.TrimSpace(t)))
+}
+
+// normalizeDefault returns a canonical form of a column default so that
+// defaults written by hand compare equal to the defaults that postgres
+// reports, such as `'foo'` and `'foo'::text`.
+func normalizeDefault(d *string) string {
+	if d == nil {
+		return ""
+	}
+	expr := strings.TrimSpace(*d)
+
+	for {
+		loc := trailingCast.FindStringIndex(expr)
+		if loc == nil {
+			break
+		}
+		operand := strings.TrimSpace(expr[:loc[0]])
+		if !strings.HasSuffix(operand, "'") && !strings.HasSuffix(operand, ")") {
+			break
+		}
+		expr = operand
+	}
+
+	// Numeric literals are quoted when they are cast
+	if len(expr) >= 2 && strings.HasPrefix(expr, "'") && strings.HasSuffix(expr, "'") {
+		if _, err := strconv.ParseFloat(expr[1:len(expr)-1], 64); err == nil {
+			expr = expr[1 : len(expr)-1]
+		}
+	}
+
+	// Keywords and function names are case-insensitive
+	if !strings.HasPrefix(expr, "'") {
+		expr = strings.ToLower(expr)
+	}
+
+	return expr
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Config enables, disables and configures lint rules.
+type Config struct {
+	// Rules is a map of rule name -> rule configuration. Rules that are not
+	// listed are enabled with their default severity and options.
+	Rules map[string]RuleConfig `json:"rules,omitempty"`
+}
+
+// RuleConfig configures a single lint rule.
+type RuleConfig struct {
+	// Enabled disables the rule when set to false
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Severity overrides the rule's default severity
+	Severity Severity `json:"severity,omitempty"`
+
+	// Options are rule-specific settings
+	Options map[string]any `json:"options,omitempty"`
+}
+
+// ReadConfig reads a lint configuration from a JSON or YAML file.
+func ReadConfig(dir fs.FS, filename string) (*Config, error) {
+	contents, err := fs.ReadFile(dir, filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading lint configuration: %w", err)
+	}
+	return ParseConfig(contents)
+}
+
+// ParseConfig parses a lint configuration from a JSON or YAML document.
+func ParseConfig(contents []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := yaml.UnmarshalStrict(contents, cfg); err != nil {
+		return nil, fmt.Errorf("parsing lint configuration: %w", err)
+	}
+
+	for name, rc := range cfg.Rules {
+		if rc.Severity == "" {
+			continue
+		}
+		if _, err := ParseSeverity(string(rc.Severity)); err != nil {
+			return nil, fmt.Errorf("lint rule %q: %w", name, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// decodeOptions decodes rule options into `target`, rejecting unknown options
+func decodeOptions(options map[string]any, target any) error {
+	if len(options) == 0 {
+		return nil
+	}
+
+	raw, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(target); err != nil {
+		return fmt.Errorf("invalid options: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package lint checks migrations for operations that are likely to be unsafe
+// or incomplete, without connecting to a database.
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+// Severity is the severity of a lint finding. The values match the levels
+// used by SARIF.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityNote    Severity = "note"
+)
+
+// ParseSeverity parses a severity from its string representation.
+func ParseSeverity(s string) (Severity, error) {
+	switch Severity(s) {
+	case SeverityError, SeverityWarning, SeverityNote:
+		return Severity(s), nil
+	default:
+		return "", fmt.Errorf("invalid severity %q: must be one of %q, %q or %q", s, SeverityError, SeverityWarning, SeverityNote)
+	}
+}
+
+// A Rule checks individual operations of a migration.
+type Rule interface {
+	// Name uniquely identifies the rule in configuration and output
+	Name() string
+
+	// Description is a short, human readable description of what the rule
+	// checks
+	Description() string
+
+	// DefaultSeverity is the severity of the rule's findings unless it is
+	// overridden in the configuration
+	DefaultSeverity() Severity
+
+	// Check returns a message for each problem that the rule finds in the
+	// operation
+	Check(op migrations.Operation) []string
+}
+
+// A ConfigurableRule is a rule that accepts options from the linter
+// configuration.
+type ConfigurableRule interface {
+	Rule
+
+	// Configure applies the rule's options from the configuration. It is
+	// called once, before any operations are checked.
+	Configure(options map[string]any) error
+}
+
+// Finding is a problem found by a rule in a migration.
+type Finding struct {
+	// The name of the rule that produced the finding
+	Rule string `json:"rule"`
+
+	Severity Severity `json:"severity"`
+
+	// The file that the migration was read from, if any
+	File string `json:"file,omitempty"`
+
+	// The name of the migration
+	Migration string `json:"migration"`
+
+	// The 1-based index of the operation in the migration
+	OperationIndex int `json:"operation_index"`
+
+	// The name of the operation
+	Operation migrations.OpName `json:"operation"`
+
+	Message string `json:"message"`
+}
+
+// Linter runs a set of rules over migrations.
+type Linter struct {
+	rules      []Rule
+	severities map[string]Severity
+}
+
+type options struct {
+	rules []Rule
+}
+
+// Option configures a Linter.
+type Option func(*options)
+
+// WithRules adds rules to the linter in addition to the built-in rules. The
+// rules can be configured in the same way as the built-in rules.
+func WithRules(rules ...Rule) Option {
+	return func(o *options) {
+		o.rules = append(o.rules, rules...)
+	}
+}
+
+// New creates a linter with the built-in rules and any additional rules,
+// enabled and configured according to `cfg`. A nil `cfg` enables all rules
+// with their default severity and options.
+func New(cfg *Config, opts ...Option) (*Linter, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	all := append(BuiltinRules(), o.rules...)
+
+	known := make(map[string]bool, len(all))
+	for _, rule := range all {
+		if known[rule.Name()] {
+			return nil, fmt.Errorf("duplicate lint rule %q", rule.Name())
+		}
+		known[rule.Name()] = true
+	}
+	for name := range cfg.Rules {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown lint rule %q in configuration", name)
+		}
+	}
+
+	l := &Linter{severities: make(map[string]Severity, len(all))}
+	for _, rule := range all {
+		rc := cfg.Rules[rule.Name()]
+		if rc.Enabled != nil && !*rc.Enabled {
+			continue
+		}
+
+		if cr, ok := rule.(ConfigurableRule); ok {
+			if err := cr.Configure(rc.Options); err != nil {
+				return nil, fmt.Errorf("configuring lint rule %q: %w", rule.Name(), err)
+			}
+		} else if len(rc.Options) > 0 {
+			return nil, fmt.Errorf("lint rule %q does not accept options", rule.Name())
+		}
+
+		severity := rule.DefaultSeverity()
+		if rc.Severity != "" {
+			severity = rc.Severity
+		}
+
+		l.rules = append(l.rules, rule)
+		l.severities[rule.Name()] = severity
+	}
+
+	return l, nil
+}
+
+// Rules returns the enabled rules, in the order in which they are run
+func (l *Linter) Rules() []Rule {
+	return slices.Clone(l.rules)
+}
+
+// Severity returns the configured severity of an enabled rule
+func (l *Linter) Severity(rule string) Severity {
+	return l.severities[rule]
+}
+
+// Lint runs the enabled rules over every operation in the migration.
+func (l *Linter) Lint(migration *migrations.Migration) []Finding {
+	var findings []Finding
+	for i, op := range migration.Operations {
+		for _, rule := range l.rules {
+			for _, msg := range rule.Check(op) {
+				findings = append(findings, Finding{
+					Rule:           rule.Name(),
+					Severity:       l.severities[rule.Name()],
+					Migration:      migration.Name,
+					OperationIndex: i + 1,
+					Operation:      migrations.OperationName(op),
+					Message:        msg,
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// HasErrors returns true if any of the findings has error severity
+func HasErrors(findings []Finding) bool {
+	return slices.ContainsFunc(findings, func(f Finding) bool {
+		return f.Severity == SeverityError
+	})
+}
+
+// String formats the finding as a single line of text
+func (f Finding) String() string {
+	var sb strings.Builder
+	if f.File != "" {
+		fmt.Fprintf(&sb, "%s: ", f.File)
+	} else {
+		fmt.Fprintf(&sb, "%s: ", f.Migration)
+	}
+	fmt.Fprintf(&sb, "operation %d (%s): %s: %s [%s]", f.OperationIndex, f.Operation, f.Severity, f.Message, f.Rule)
+	return sb.String()
+}
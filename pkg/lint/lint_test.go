@@ -0,0 +1,253 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package lint_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xataio/pgroll/pkg/lint"
+	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/sql2pgroll"
+)
+
+func TestBuiltinRules(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		op            migrations.Operation
+		expectedRules []string
+	}{
+		"non-concurrent index in raw SQL": {
+			op:            &migrations.OpRawSQL{Up: "CREATE INDEX idx_a ON t (a)"},
+			expectedRules: []string{"raw-sql-non-concurrent-index"},
+		},
+		"concurrent index in raw SQL": {
+			op: &migrations.OpRawSQL{Up: "CREATE INDEX CONCURRENTLY idx_a ON t (a)"},
+		},
+		"type change without up and down": {
+			op:            &migrations.OpAlterColumn{Table: "t", Column: "a", Type: ptr("bigint")},
+			expectedRules: []string{"alter-column-type-without-up-down"},
+		},
+		"type change with up and down": {
+			op: &migrations.OpAlterColumn{Table: "t", Column: "a", Type: ptr("bigint"), Up: "a", Down: "a"},
+		},
+		"drop column without down": {
+			op:            &migrations.OpDropColumn{Table: "t", Column: "a"},
+			expectedRules: []string{"drop-column-without-down"},
+		},
+		"drop column with down": {
+			op: &migrations.OpDropColumn{Table: "t", Column: "a", Down: "'x'"},
+		},
+		"add column with volatile default": {
+			op: &migrations.OpAddColumn{
+				Table:  "t",
+				Column: migrations.Column{Name: "id", Type: "uuid", Default: ptr("gen_random_uuid()")},
+			},
+			expectedRules: []string{"volatile-default"},
+		},
+		"add column with non-volatile default": {
+			op: &migrations.OpAddColumn{
+				Table:  "t",
+				Column: migrations.Column{Name: "created_at", Type: "timestamptz", Default: ptr("now()")},
+			},
+		},
+		"placeholder SQL": {
+			op: &migrations.OpAddColumn{
+				Table:  "t",
+				Column: migrations.Column{Name: "a", Type: "int"},
+				Up:     sql2pgroll.PlaceHolderSQL,
+			},
+			expectedRules: []string{"placeholder-sql"},
+		},
+	}
+
+	linter, err := lint.New(nil)
+	require.NoError(t, err)
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			findings := linter.Lint(&migrations.Migration{
+				Name:       "01_migration",
+				Operations: migrations.Operations{tc.op},
+			})
+
+			var rules []string
+			for _, f := range findings {
+				rules = append(rules, f.Rule)
+				assert.Equal(t, "01_migration", f.Migration)
+				assert.Equal(t, 1, f.OperationIndex)
+			}
+			assert.Equal(t, tc.expectedRules, rules)
+		})
+	}
+}
+
+func TestConfig(t *testing.T) {
+	t.Parallel()
+
+	migration := &migrations.Migration{
+		Name: "01_migration",
+		Operations: migrations.Operations{
+			&migrations.OpDropColumn{Table: "t", Column: "a"},
+			&migrations.OpAddColumn{
+				Table:  "small",
+				Column: migrations.Column{Name: "r", Type: "float8", Default: ptr("random()"), Nullable: true},
+			},
+			&migrations.OpAddColumn{
+				Table:  "big",
+				Column: migrations.Column{Name: "r", Type: "float8", Default: ptr("my_random()"), Nullable: true},
+			},
+		},
+	}
+
+	cfg, err := lint.ParseConfig([]byte(`
+rules:
+  drop-column-without-down:
+    enabled: false
+  volatile-default:
+    severity: error
+    options:
+      tables: [big]
+      functions: [my_random]
+`))
+	require.NoError(t, err)
+
+	linter, err := lint.New(cfg)
+	require.NoError(t, err)
+
+	findings := linter.Lint(migration)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "volatile-default", findings[0].Rule)
+	assert.Equal(t, lint.SeverityError, findings[0].Severity)
+	assert.Equal(t, 3, findings[0].OperationIndex)
+	assert.True(t, lint.HasErrors(findings))
+}
+
+func TestConfigErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"unknown rule": `
+rules:
+  no-such-rule:
+    enabled: false
+`,
+		"unknown option": `
+rules:
+  volatile-default:
+    options:
+      no_such_option: true
+`,
+		"options for a rule without options": `
+rules:
+  placeholder-sql:
+    options:
+      tables: [t]
+`,
+	}
+
+	for name, doc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := lint.ParseConfig([]byte(doc))
+			require.NoError(t, err)
+
+			_, err = lint.New(cfg)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := lint.ParseConfig([]byte(`
+rules:
+  placeholder-sql:
+    severity: fatal
+`))
+	assert.Error(t, err)
+}
+
+func TestCustomRules(t *testing.T) {
+	t.Parallel()
+
+	linter, err := lint.New(nil, lint.WithRules(noDropTable{}))
+	require.NoError(t, err)
+
+	findings := linter.Lint(&migrations.Migration{
+		Name:       "01_migration",
+		Operations: migrations.Operations{&migrations.OpDropTable{Name: "t"}},
+	})
+	require.Len(t, findings, 1)
+	assert.Equal(t, "no-drop-table", findings[0].Rule)
+	assert.Equal(t, migrations.OpNameDropTable, findings[0].Operation)
+}
+
+func TestSARIFOutput(t *testing.T) {
+	t.Parallel()
+
+	linter, err := lint.New(nil)
+	require.NoError(t, err)
+
+	findings := linter.Lint(&migrations.Migration{
+		Name:       "01_migration",
+		Operations: migrations.Operations{&migrations.OpDropColumn{Table: "t", Column: "a"}},
+	})
+	for i := range findings {
+		findings[i].File = "migrations/01_migration.yaml"
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, linter.Write(&buf, lint.FormatSARIF, findings))
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	assert.Len(t, log.Runs[0].Tool.Driver.Rules, len(lint.BuiltinRules()))
+	require.Len(t, log.Runs[0].Results, 1)
+	assert.Equal(t, "drop-column-without-down", log.Runs[0].Results[0].RuleID)
+	assert.Equal(t, "warning", log.Runs[0].Results[0].Level)
+	assert.Equal(t, "migrations/01_migration.yaml", log.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+}
+
+type noDropTable struct{}
+
+func (noDropTable) Name() string                   { return "no-drop-table" }
+func (noDropTable) Description() string            { return "tables must not be dropped" }
+func (noDropTable) DefaultSeverity() lint.Severity { return lint.SeverityError }
+
+func (noDropTable) Check(op migrations.Operation) []string {
+	if _, ok := op.(*migrations.OpDropTable); ok {
+		return []string{"table is dropped"}
+	}
+	return nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	pgq "github.com/xataio/pg_query_go/v6"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+// BuiltinRules returns new instances of the rules that are built into pgroll
+func BuiltinRules() []Rule {
+	return []Rule{
+		&rawSQLNonConcurrentIndex{},
+		&changeTypeWithoutUpDown{},
+		&dropColumnWithoutDown{},
+		&volatileDefault{},
+		&placeholderSQL{},
+	}
+}
+
+// rawSQLNonConcurrentIndex reports `CREATE INDEX` statements in raw SQL
+// operations that do not use `CONCURRENTLY` and so block writes to the table
+// while the index is built.
+type rawSQLNonConcurrentIndex struct{}
+
+func (r *rawSQLNonConcurrentIndex) Name() string { return "raw-sql-non-concurrent-index" }
+
+func (r *rawSQLNonConcurrentIndex) Description() string {
+	return "CREATE INDEX in a raw SQL operation without CONCURRENTLY blocks writes to the table"
+}
+
+func (r *rawSQLNonConcurrentIndex) DefaultSeverity() Severity { return SeverityWarning }
+
+func (r *rawSQLNonConcurrentIndex) Check(op migrations.Operation) []string {
+	raw, ok := op.(*migrations.OpRawSQL)
+	if !ok {
+		return nil
+	}
+
+	var msgs []string
+	for _, sql := range []string{raw.Up, raw.Down} {
+		tree, err := pgq.Parse(sql)
+		if err != nil {
+			continue
+		}
+		for _, stmt := range tree.GetStmts() {
+			idx := stmt.GetStmt().GetIndexStmt()
+			if idx == nil || idx.GetConcurrent() {
+				continue
+			}
+			msgs = append(msgs, fmt.Sprintf(
+				"index %q on table %q is created without CONCURRENTLY; use a create_index operation instead",
+				idx.GetIdxname(), idx.GetRelation().GetRelname()))
+		}
+	}
+	return msgs
+}
+
+// changeTypeWithoutUpDown reports column type changes that rely on the
+// implicit cast between the old and new types to migrate data.
+type changeTypeWithoutUpDown struct{}
+
+func (r *changeTypeWithoutUpDown) Name() string { return "alter-column-type-without-up-down" }
+
+func (r *changeTypeWithoutUpDown) Description() string {
+	return "alter_column operations that change a column's type should specify up and down SQL"
+}
+
+func (r *changeTypeWithoutUpDown) DefaultSeverity() Severity { return SeverityWarning }
+
+func (r *changeTypeWithoutUpDown) Check(op migrations.Operation) []string {
+	alter, ok := op.(*migrations.OpAlterColumn)
+	if !ok || alter.Type == nil {
+		return nil
+	}
+
+	var missing []string
+	if alter.Up == "" {
+		missing = append(missing, "up")
+	}
+	if alter.Down == "" {
+		missing = append(missing, "down")
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return []string{fmt.Sprintf(
+		"type of column %q on table %q is changed to %q without %s SQL",
+		alter.Column, alter.Table, *alter.Type, strings.Join(missing, " or "))}
+}
+
+// dropColumnWithoutDown reports dropped columns that are not populated for
+// rows written by the new version of the schema while the migration is
+// active.
+type dropColumnWithoutDown struct{}
+
+func (r *dropColumnWithoutDown) Name() string { return "drop-column-without-down" }
+
+func (r *dropColumnWithoutDown) Description() string {
+	return "drop_column operations should specify down SQL to populate the column for the old version of the schema"
+}
+
+func (r *dropColumnWithoutDown) DefaultSeverity() Severity { return SeverityWarning }
+
+func (r *dropColumnWithoutDown) Check(op migrations.Operation) []string {
+	drop, ok := op.(*migrations.OpDropColumn)
+	if !ok || drop.Down != "" {
+		return nil
+	}
+
+	return []string{fmt.Sprintf(
+		"column %q on table %q is dropped without down SQL; rows inserted through the new version of the schema will have no value for it in the old version",
+		drop.Column, drop.Table)}
+}
+
+// defaultVolatileFunctions are built-in functions that are volatile, so that
+// a column default that calls them forces postgres to rewrite the table.
+var defaultVolatileFunctions = []string{
+	"clock_timestamp",
+	"gen_random_uuid",
+	"nextval",
+	"random",
+	"timeofday",
+	"txid_current",
+	"uuid_generate_v1",
+	"uuid_generate_v1mc",
+	"uuid_generate_v4",
+}
+
+var functionCall = regexp.MustCompile(`(?i)([a-z_][a-z0-9_$.]*)\s*\(`)
+
+// volatileDefault reports columns added to existing tables with a volatile
+// default, which rewrites the table while holding an ACCESS EXCLUSIVE lock.
+type volatileDefault struct {
+	// Tables limits the rule to the named tables. All tables are checked if
+	// it is empty.
+	Tables []string `json:"tables"`
+
+	// Functions are additional functions to treat as volatile
+	Functions []string `json:"functions"`
+}
+
+func (r *volatileDefault) Name() string { return "volatile-default" }
+
+func (r *volatileDefault) Description() string {
+	return "add_column operations with a volatile default rewrite the table"
+}
+
+func (r *volatileDefault) DefaultSeverity() Severity { return SeverityWarning }
+
+func (r *volatileDefault) Configure(options map[string]any) error {
+	return decodeOptions(options, r)
+}
+
+func (r *volatileDefault) Check(op migrations.Operation) []string {
+	add, ok := op.(*migrations.OpAddColumn)
+	if !ok || add.Column.Default == nil {
+		return nil
+	}
+	if len(r.Tables) > 0 && !slices.Contains(r.Tables, add.Table) {
+		return nil
+	}
+
+	for _, match := range functionCall.FindAllStringSubmatch(*add.Column.Default, -1) {
+		name := strings.ToLower(match[1])
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		if slices.Contains(defaultVolatileFunctions, name) || slices.Contains(r.Functions, name) {
+			return []string{fmt.Sprintf(
+				"column %q is added to table %q with volatile default %q, which rewrites the table",
+				add.Column.Name, add.Table, *add.Column.Default)}
+		}
+	}
+	return nil
+}
+
+// placeholderSQL reports placeholder SQL left in a migration generated by
+// `pgroll convert` or `pgroll diff`.
+type placeholderSQL struct{}
+
+func (r *placeholderSQL) Name() string { return "placeholder-sql" }
+
+func (r *placeholderSQL) Description() string {
+	return "generated migrations must have their placeholder SQL replaced before they are run"
+}
+
+func (r *placeholderSQL) DefaultSeverity() Severity { return SeverityError }
+
+func (r *placeholderSQL) Check(op migrations.Operation) []string {
+	var msgs []string
+	for _, field := range migrations.PlaceholderFields(op) {
+		msgs = append(msgs, fmt.Sprintf("field %q contains placeholder SQL %q", field, migrations.PlaceHolderSQL))
+	}
+	return msgs
+}
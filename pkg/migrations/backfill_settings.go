@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/xataio/pgroll/pkg/backfill"
+)
+
+// operationBackfillKey is the key in an operation object that holds the
+// backfill settings of the operation, for example:
+//
+//	{"backfill": {"batch_size": 100}, "alter_column": {...}}
+const operationBackfillKey = "backfill"
+
+// BackfillOptions returns the backfill options set by the backfill settings
+// of the migration. They apply to every table that the migration backfills.
+func (m *Migration) BackfillOptions() []backfill.OptionFn {
+	return m.Backfill.options()
+}
+
+// OperationBackfillOptions returns the backfill options set by the backfill
+// settings of the operation at index `i`. They apply to the tables that the
+// operation backfills, on top of the options of the migration.
+func (m *Migration) OperationBackfillOptions(i int) []backfill.OptionFn {
+	if i < len(m.OperationBackfills) {
+		return m.OperationBackfills[i].options()
+	}
+	return nil
+}
+
+// ValidateBackfillSettings returns an error if the backfill settings of the
+// migration, or of any of its operations, are invalid.
+func (m *Migration) ValidateBackfillSettings() error {
+	if err := m.Backfill.validate(); err != nil {
+		return InvalidMigrationError{Reason: fmt.Sprintf("invalid backfill settings: %s", err)}
+	}
+
+	for i, s := range m.OperationBackfills {
+		if s == nil {
+			continue
+		}
+		if err := s.validate(); err != nil {
+			return InvalidMigrationError{Reason: fmt.Sprintf("invalid backfill settings for operation %d: %s", i+1, err)}
+		}
+		// Tables are backfilled concurrently across the whole migration
+		if s.Parallelism != nil {
+			return InvalidMigrationError{Reason: fmt.Sprintf("invalid backfill settings for operation %d: parallelism can only be set for the migration", i+1)}
+		}
+	}
+
+	return nil
+}
+
+// validate returns an error if any of the settings are out of range.
+func (s *BackfillSettings) validate() error {
+	if s == nil {
+		return nil
+	}
+	if s.BatchSize != nil && *s.BatchSize < 1 {
+		return fmt.Errorf("batch size must be positive, got %d", *s.BatchSize)
+	}
+	if s.Parallelism != nil && *s.Parallelism < 1 {
+		return fmt.Errorf("parallelism must be positive, got %d", *s.Parallelism)
+	}
+	if _, err := parseBackfillDuration("batch delay", s.BatchDelay); err != nil {
+		return err
+	}
+	if _, err := parseBackfillDuration("max duration", s.MaxDuration); err != nil {
+		return err
+	}
+	return nil
+}
+
+// options returns the backfill options for the settings. Settings that are
+// invalid are ignored; they are rejected by ValidateBackfillSettings before
+// the migration is started.
+func (s *BackfillSettings) options() []backfill.OptionFn {
+	if s == nil {
+		return nil
+	}
+
+	var opts []backfill.OptionFn
+	if s.BatchSize != nil {
+		opts = append(opts, backfill.WithBatchSize(*s.BatchSize))
+	}
+	if d, err := parseBackfillDuration("batch delay", s.BatchDelay); err == nil && d != nil {
+		opts = append(opts, backfill.WithBatchDelay(*d))
+	}
+	if d, err := parseBackfillDuration("max duration", s.MaxDuration); err == nil && d != nil {
+		opts = append(opts, backfill.WithMaxDuration(*d))
+	}
+	if s.Parallelism != nil {
+		opts = append(opts, backfill.WithParallelism(*s.Parallelism))
+	}
+	return opts
+}
+
+// parseBackfillDuration parses the duration `value` of the backfill setting
+// `name`, returning nil if the setting is not set.
+func parseBackfillDuration(name string, value *string) (*time.Duration, error) {
+	if value == nil {
+		return nil, nil
+	}
+	d, err := time.ParseDuration(*value)
+	if err != nil {
+		return nil, fmt.Errorf("%s %q is not a valid duration", name, *value)
+	}
+	if d < 0 {
+		return nil, fmt.Errorf("%s must not be negative, got %s", name, *value)
+	}
+	return &d, nil
+}
+
+// splitOperationBackfills removes the backfill key from each object in the
+// raw operations array, returning the remaining operations and the backfill
+// settings of each of them. The returned settings are nil if no operation has
+// any.
+func splitOperationBackfills(raw json.RawMessage) (json.RawMessage, []*BackfillSettings, error) {
+	var objs []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &objs); err != nil {
+		// Leave it to the operations parser to report malformed operations
+		return raw, nil, nil
+	}
+
+	var settings []*BackfillSettings
+	for i, obj := range objs {
+		value, ok := obj[operationBackfillKey]
+		if !ok {
+			continue
+		}
+
+		var s BackfillSettings
+		dec := json.NewDecoder(bytes.NewReader(value))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&s); err != nil {
+			return nil, nil, fmt.Errorf("backfill settings of operation at index %d: %w", i, err)
+		}
+		if settings == nil {
+			settings = make([]*BackfillSettings, len(objs))
+		}
+		settings[i] = &s
+		delete(obj, operationBackfillKey)
+	}
+
+	if settings == nil {
+		return raw, nil, nil
+	}
+
+	ops, err := json.Marshal(objs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ops, settings, nil
+}
@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// Checksum returns a checksum of the content of the migration when it is run
+// against `defaultSchema`. The name of the migration is not included.
+//
+// The checksum is computed from the parsed migration, so migrations that
+// differ only in formatting, in the order of keys, or in being written as
+// YAML rather than JSON have the same checksum.
+func (m *Migration) Checksum(defaultSchema string) (string, error) {
+	raw, err := json.Marshal(m.WithDefaultSchema(defaultSchema))
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
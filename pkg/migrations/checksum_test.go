@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestMigrationChecksum(t *testing.T) {
+	t.Parallel()
+
+	dir := fstest.MapFS{
+		"01_create_users.json": &fstest.MapFile{Data: []byte(`{
+			"operations": [
+				{"create_table": {"name": "users", "columns": [{"name": "id", "type": "serial", "pk": true}]}}
+			]
+		}`)},
+		"01_create_users.yaml": &fstest.MapFile{Data: []byte(`operations:
+  - create_table:
+      columns:
+        - pk: true
+          type: serial
+          name: id
+      name: users
+`)},
+		"01_create_users_in_public.json": &fstest.MapFile{Data: []byte(`{
+			"operations": [
+				{"schema": "public", "create_table": {"name": "users", "columns": [{"name": "id", "type": "serial", "pk": true}]}}
+			]
+		}`)},
+		"01_create_users_in_other.json": &fstest.MapFile{Data: []byte(`{
+			"operations": [
+				{"schema": "other", "create_table": {"name": "users", "columns": [{"name": "id", "type": "serial", "pk": true}]}}
+			]
+		}`)},
+		"02_create_accounts.json": &fstest.MapFile{Data: []byte(`{
+			"operations": [
+				{"create_table": {"name": "accounts", "columns": [{"name": "id", "type": "serial", "pk": true}]}}
+			]
+		}`)},
+	}
+
+	checksum := func(t *testing.T, file string) string {
+		t.Helper()
+
+		mig, err := migrations.ReadMigration(dir, file)
+		require.NoError(t, err)
+
+		sum, err := mig.Checksum("public")
+		require.NoError(t, err)
+		return sum
+	}
+
+	t.Run("formatting and file format don't affect the checksum", func(t *testing.T) {
+		require.Equal(t, checksum(t, "01_create_users.json"), checksum(t, "01_create_users.yaml"))
+	})
+
+	t.Run("naming the default schema doesn't affect the checksum", func(t *testing.T) {
+		require.Equal(t, checksum(t, "01_create_users.json"), checksum(t, "01_create_users_in_public.json"))
+	})
+
+	t.Run("the schema targeted by an operation affects the checksum", func(t *testing.T) {
+		require.NotEqual(t, checksum(t, "01_create_users.json"), checksum(t, "01_create_users_in_other.json"))
+	})
+
+	t.Run("the operations affect the checksum", func(t *testing.T) {
+		require.NotEqual(t, checksum(t, "01_create_users.json"), checksum(t, "02_create_accounts.json"))
+	})
+}
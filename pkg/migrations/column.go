@@ -88,6 +88,7 @@ func (w ColumnSQLWriter) Write(col Column) (string, error) {
 	if col.References != nil {
 		writer := &ConstraintSQLWriter{Name: col.References.Name}
 		sql += " " + writer.WriteForeignKey(
+			col.References.Schema,
 			col.References.Table,
 			[]string{col.References.Column},
 			col.References.OnDelete,
@@ -90,6 +90,7 @@ func (w *ConstraintSQLWriter) WritePrimaryKey() string {
 }
 
 // WriteForeignKey generates a foreign key constraint on the table level and inline.
+// The referenced table is qualified with `referencedSchema` if it is not empty.
 // Supported options:
 // - includeColumns: additional columns to include in the index.
 // - storageParameters: storage parameters for the index.
@@ -97,7 +98,7 @@ func (w *ConstraintSQLWriter) WritePrimaryKey() string {
 // - deferrable: if true, the constraint is deferrable.
 // - initiallyDeferred: if true, the constraint is initially deferred.
 // - skipValidation: if true, the constraint is not validated.
-func (w *ConstraintSQLWriter) WriteForeignKey(referencedTable string, referencedColumns []string, onDelete, onUpdate ForeignKeyAction, setColumns []string, matchType ForeignKeyMatchType) string {
+func (w *ConstraintSQLWriter) WriteForeignKey(referencedSchema, referencedTable string, referencedColumns []string, onDelete, onUpdate ForeignKeyAction, setColumns []string, matchType ForeignKeyMatchType) string {
 	onDeleteAction := string(ForeignKeyActionNOACTION)
 	if onDelete != "" {
 		onDeleteAction = strings.ToUpper(string(onDelete))
@@ -122,9 +123,13 @@ func (w *ConstraintSQLWriter) WriteForeignKey(referencedTable string, referenced
 	if len(w.Columns) != 0 {
 		constraint += fmt.Sprintf("FOREIGN KEY (%s) ", strings.Join(quoteColumnNames(w.Columns), ", "))
 	}
+	referenced := pq.QuoteIdentifier(referencedTable)
+	if referencedSchema != "" {
+		referenced = pq.QuoteIdentifier(referencedSchema) + "." + referenced
+	}
 	constraint += fmt.Sprintf(
 		"REFERENCES %s (%s) MATCH %s ON DELETE %s ON UPDATE %s",
-		pq.QuoteIdentifier(referencedTable),
+		referenced,
 		strings.Join(quoteColumnNames(referencedColumns), ", "),
 		matchTypeStr,
 		onDeleteAction,
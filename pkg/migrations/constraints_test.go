@@ -234,6 +234,7 @@ func TestConstraintForeign(t *testing.T) {
 	tests := map[string]struct {
 		name               string
 		columns            []string
+		referencedSchema   string
 		referencedTable    string
 		referencedColumns  []string
 		matchType          migrations.ForeignKeyMatchType
@@ -289,6 +290,14 @@ func TestConstraintForeign(t *testing.T) {
 			initiallyDeferred: true,
 			expected:          `CONSTRAINT "test" FOREIGN KEY ("other_id") REFERENCES "other_table" ("id") MATCH SIMPLE ON DELETE NO ACTION ON UPDATE NO ACTION DEFERRABLE INITIALLY DEFERRED`,
 		},
+		"foreign key referencing a table in another schema": {
+			name:              "test",
+			columns:           []string{"other_id"},
+			referencedSchema:  "other_schema",
+			referencedTable:   "other_table",
+			referencedColumns: []string{"id"},
+			expected:          `CONSTRAINT "test" FOREIGN KEY ("other_id") REFERENCES "other_schema"."other_table" ("id") MATCH SIMPLE ON DELETE NO ACTION ON UPDATE NO ACTION`,
+		},
 		"composite foreign key": {
 			name:              "test",
 			columns:           []string{"other_id_1", "other_id_2"},
@@ -326,6 +335,7 @@ func TestConstraintForeign(t *testing.T) {
 			}
 
 			constraint := writer.WriteForeignKey(
+				tc.referencedSchema,
 				tc.referencedTable,
 				tc.referencedColumns,
 				tc.onDelete,
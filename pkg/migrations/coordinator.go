@@ -6,6 +6,8 @@ import (
 	"context"
 	"fmt"
 	"slices"
+
+	"github.com/xataio/pgroll/pkg/telemetry"
 )
 
 // Coordinator is responsible for executing a series of database actions in a specific orderedActions.
@@ -33,6 +35,15 @@ func NewCoordinator(actions []DBAction) *Coordinator {
 	}
 }
 
+// Actions returns the actions in the order in which `Execute` runs them.
+func (c *Coordinator) Actions() []DBAction {
+	actions := make([]DBAction, 0, len(c.orderedActions))
+	for _, id := range c.orderedActions {
+		actions = append(actions, c.actions[id])
+	}
+	return actions
+}
+
 // Execute runs all actions in the order they were added to the coordinator.
 func (c *Coordinator) Execute(ctx context.Context) error {
 	for _, id := range c.orderedActions {
@@ -40,9 +51,16 @@ func (c *Coordinator) Execute(ctx context.Context) error {
 		if !exists {
 			return fmt.Errorf("action %s not found", id)
 		}
-		if err := action.Execute(ctx); err != nil {
+		if err := executeAction(ctx, action); err != nil {
 			return fmt.Errorf("failed to execute action %s: %w", id, err)
 		}
 	}
 	return nil
 }
+
+func executeAction(ctx context.Context, action DBAction) error {
+	ctx, span := telemetry.StartSpan(ctx, "pgroll.action", telemetry.ActionKey.String(action.ID()))
+	err := action.Execute(ctx)
+	telemetry.EndSpan(span, err)
+	return err
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/lib/pq"
 
 	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
 )
 
 // DBAction is an interface for common database actions
@@ -542,6 +543,7 @@ type createTableAction struct {
 	table       string
 	columns     string
 	constraints string
+	partitionBy string
 }
 
 func NewCreateTableAction(conn db.DB, table, columns, constraints string) *createTableAction {
@@ -554,13 +556,109 @@ func NewCreateTableAction(conn db.DB, table, columns, constraints string) *creat
 	}
 }
 
+// NewCreatePartitionedTableAction creates a table that is partitioned by
+// `partitionBy`.
+func NewCreatePartitionedTableAction(conn db.DB, table, columns, constraints string, partitionBy PartitionBy) *createTableAction {
+	a := NewCreateTableAction(conn, table, columns, constraints)
+	a.partitionBy = partitionBySQL(partitionBy)
+	return a
+}
+
 func (a *createTableAction) ID() string { return a.id }
 
 func (a *createTableAction) Execute(ctx context.Context) error {
-	_, err := a.conn.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s %s)",
+	_, err := a.conn.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s %s) %s",
 		pq.QuoteIdentifier(a.table),
 		a.columns,
-		a.constraints))
+		a.constraints,
+		a.partitionBy))
+	return err
+}
+
+// createPartitionAction is a DBAction that creates a partition of a
+// partitioned table.
+type createPartitionAction struct {
+	conn      db.DB
+	id        string
+	table     string
+	partition string
+	bound     string
+}
+
+func NewCreatePartitionAction(conn db.DB, table, partition, bound string) *createPartitionAction {
+	return &createPartitionAction{
+		conn:      conn,
+		id:        fmt.Sprintf("create_partition_%s", partition),
+		table:     table,
+		partition: partition,
+		bound:     bound,
+	}
+}
+
+func (a *createPartitionAction) ID() string { return a.id }
+
+func (a *createPartitionAction) Execute(ctx context.Context) error {
+	_, err := a.conn.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s PARTITION OF %s %s",
+		pq.QuoteIdentifier(a.partition),
+		pq.QuoteIdentifier(a.table),
+		a.bound))
+	return err
+}
+
+// attachPartitionAction is a DBAction that attaches an existing table to a
+// partitioned table as a partition.
+type attachPartitionAction struct {
+	conn      db.DB
+	id        string
+	table     string
+	partition string
+	bound     string
+}
+
+func NewAttachPartitionAction(conn db.DB, table, partition, bound string) *attachPartitionAction {
+	return &attachPartitionAction{
+		conn:      conn,
+		id:        fmt.Sprintf("attach_partition_%s", partition),
+		table:     table,
+		partition: partition,
+		bound:     bound,
+	}
+}
+
+func (a *attachPartitionAction) ID() string { return a.id }
+
+func (a *attachPartitionAction) Execute(ctx context.Context) error {
+	_, err := a.conn.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s %s",
+		pq.QuoteIdentifier(a.table),
+		pq.QuoteIdentifier(a.partition),
+		a.bound))
+	return err
+}
+
+// detachPartitionAction is a DBAction that detaches a partition from a
+// partitioned table, leaving it as a table of its own.
+type detachPartitionAction struct {
+	conn      db.DB
+	id        string
+	table     string
+	partition string
+}
+
+func NewDetachPartitionAction(conn db.DB, table, partition string) *detachPartitionAction {
+	return &detachPartitionAction{
+		conn:      conn,
+		id:        fmt.Sprintf("detach_partition_%s", partition),
+		table:     table,
+		partition: partition,
+	}
+}
+
+func (a *detachPartitionAction) ID() string { return a.id }
+
+func (a *detachPartitionAction) Execute(ctx context.Context) error {
+	_, err := a.conn.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s",
+		pq.QuoteIdentifier(a.table),
+		pq.QuoteIdentifier(a.partition)))
 	return err
 }
 
@@ -726,6 +824,7 @@ func (a *createFKConstraintAction) Execute(ctx context.Context) error {
 		SkipValidation:    a.skipValidation,
 	}
 	sql += writer.WriteForeignKey(
+		a.reference.Schema,
 		a.reference.Table,
 		a.reference.Columns,
 		a.reference.OnDelete,
@@ -779,7 +878,8 @@ func getSequenceNameForColumn(ctx context.Context, conn db.DB, tableName, column
 		SELECT pg_get_serial_sequence('%s', '%s')
 	`, pq.QuoteIdentifier(tableName), columnName)
 	rows, err := conn.QueryContext(ctx, query)
-	if err != nil {
+	if err != nil || rows == nil {
+		// if rows == nil && err == nil, then it means we have queried a fake db.
 		return ""
 	}
 	defer rows.Close()
@@ -949,3 +1049,544 @@ func (a *setReplicaIdentityAction) Execute(ctx context.Context) error {
 		identitySQL))
 	return err
 }
+
+// createEnumAction is a DBAction that creates an enum type.
+type createEnumAction struct {
+	conn   db.DB
+	id     string
+	name   string
+	values []string
+}
+
+func NewCreateEnumAction(conn db.DB, name string, values []string) *createEnumAction {
+	return &createEnumAction{
+		conn:   conn,
+		id:     fmt.Sprintf("create_enum_%s", name),
+		name:   name,
+		values: values,
+	}
+}
+
+func (a *createEnumAction) ID() string { return a.id }
+
+func (a *createEnumAction) Execute(ctx context.Context) error {
+	values := make([]string, len(a.values))
+	for i, v := range a.values {
+		values[i] = pq.QuoteLiteral(v)
+	}
+
+	_, err := a.conn.ExecContext(ctx, fmt.Sprintf("CREATE TYPE %s AS ENUM (%s)",
+		pq.QuoteIdentifier(a.name),
+		strings.Join(values, ", ")))
+	return err
+}
+
+// dropEnumAction is a DBAction that drops an enum type.
+type dropEnumAction struct {
+	conn db.DB
+	id   string
+	name string
+}
+
+func NewDropEnumAction(conn db.DB, name string) *dropEnumAction {
+	return &dropEnumAction{
+		conn: conn,
+		id:   fmt.Sprintf("drop_enum_%s", name),
+		name: name,
+	}
+}
+
+func (a *dropEnumAction) ID() string { return a.id }
+
+func (a *dropEnumAction) Execute(ctx context.Context) error {
+	_, err := a.conn.ExecContext(ctx, fmt.Sprintf("DROP TYPE IF EXISTS %s",
+		pq.QuoteIdentifier(a.name)))
+	return err
+}
+
+// addEnumValueAction is a DBAction that adds a value to an enum type.
+type addEnumValueAction struct {
+	conn   db.DB
+	id     string
+	name   string
+	value  string
+	before string
+	after  string
+}
+
+func NewAddEnumValueAction(conn db.DB, name, value, before, after string) *addEnumValueAction {
+	return &addEnumValueAction{
+		conn:   conn,
+		id:     fmt.Sprintf("add_enum_value_%s_%s", name, value),
+		name:   name,
+		value:  value,
+		before: before,
+		after:  after,
+	}
+}
+
+func (a *addEnumValueAction) ID() string { return a.id }
+
+func (a *addEnumValueAction) Execute(ctx context.Context) error {
+	stmt := fmt.Sprintf("ALTER TYPE %s ADD VALUE IF NOT EXISTS %s",
+		pq.QuoteIdentifier(a.name),
+		pq.QuoteLiteral(a.value))
+
+	switch {
+	case a.before != "":
+		stmt += " BEFORE " + pq.QuoteLiteral(a.before)
+	case a.after != "":
+		stmt += " AFTER " + pq.QuoteLiteral(a.after)
+	}
+
+	_, err := a.conn.ExecContext(ctx, stmt)
+	return err
+}
+
+// renameEnumValueAction is a DBAction that renames a value of an enum type.
+type renameEnumValueAction struct {
+	conn db.DB
+	id   string
+	name string
+	from string
+	to   string
+}
+
+func NewRenameEnumValueAction(conn db.DB, name, from, to string) *renameEnumValueAction {
+	return &renameEnumValueAction{
+		conn: conn,
+		id:   fmt.Sprintf("rename_enum_value_%s_%s_to_%s", name, from, to),
+		name: name,
+		from: from,
+		to:   to,
+	}
+}
+
+func (a *renameEnumValueAction) ID() string { return a.id }
+
+func (a *renameEnumValueAction) Execute(ctx context.Context) error {
+	_, err := a.conn.ExecContext(ctx, fmt.Sprintf("ALTER TYPE %s RENAME VALUE %s TO %s",
+		pq.QuoteIdentifier(a.name),
+		pq.QuoteLiteral(a.from),
+		pq.QuoteLiteral(a.to)))
+	return err
+}
+
+// createViewAction is a DBAction that creates a view.
+type createViewAction struct {
+	conn       db.DB
+	id         string
+	name       string
+	definition string
+}
+
+func NewCreateViewAction(conn db.DB, name, definition string) *createViewAction {
+	return &createViewAction{
+		conn:       conn,
+		id:         fmt.Sprintf("create_view_%s", name),
+		name:       name,
+		definition: definition,
+	}
+}
+
+func (a *createViewAction) ID() string { return a.id }
+
+func (a *createViewAction) Execute(ctx context.Context) error {
+	_, err := a.conn.ExecContext(ctx, fmt.Sprintf("CREATE VIEW %s AS %s",
+		pq.QuoteIdentifier(a.name),
+		a.definition))
+	return err
+}
+
+// dropViewAction is a DBAction that drops a view.
+type dropViewAction struct {
+	conn db.DB
+	id   string
+	name string
+}
+
+func NewDropViewAction(conn db.DB, name string) *dropViewAction {
+	return &dropViewAction{
+		conn: conn,
+		id:   fmt.Sprintf("drop_view_%s", name),
+		name: name,
+	}
+}
+
+func (a *dropViewAction) ID() string { return a.id }
+
+func (a *dropViewAction) Execute(ctx context.Context) error {
+	_, err := a.conn.ExecContext(ctx, fmt.Sprintf("DROP VIEW IF EXISTS %s",
+		pq.QuoteIdentifier(a.name)))
+	return err
+}
+
+// enableRowLevelSecurityAction is a DBAction that enables row level security
+// on a table.
+type enableRowLevelSecurityAction struct {
+	conn  db.DB
+	id    string
+	table string
+	force bool
+}
+
+func NewEnableRowLevelSecurityAction(conn db.DB, table string, force bool) *enableRowLevelSecurityAction {
+	return &enableRowLevelSecurityAction{
+		conn:  conn,
+		id:    fmt.Sprintf("enable_rls_%s", table),
+		table: table,
+		force: force,
+	}
+}
+
+func (a *enableRowLevelSecurityAction) ID() string { return a.id }
+
+func (a *enableRowLevelSecurityAction) Execute(ctx context.Context) error {
+	sql := fmt.Sprintf("ALTER TABLE %s ENABLE ROW LEVEL SECURITY", pq.QuoteIdentifier(a.table))
+	if a.force {
+		sql += fmt.Sprintf("; ALTER TABLE %s FORCE ROW LEVEL SECURITY", pq.QuoteIdentifier(a.table))
+	}
+	_, err := a.conn.ExecContext(ctx, sql)
+	return err
+}
+
+// disableRowLevelSecurityAction is a DBAction that disables row level
+// security on a table.
+type disableRowLevelSecurityAction struct {
+	conn  db.DB
+	id    string
+	table string
+}
+
+func NewDisableRowLevelSecurityAction(conn db.DB, table string) *disableRowLevelSecurityAction {
+	return &disableRowLevelSecurityAction{
+		conn:  conn,
+		id:    fmt.Sprintf("disable_rls_%s", table),
+		table: table,
+	}
+}
+
+func (a *disableRowLevelSecurityAction) ID() string { return a.id }
+
+func (a *disableRowLevelSecurityAction) Execute(ctx context.Context) error {
+	_, err := a.conn.ExecContext(ctx, fmt.Sprintf("ALTER TABLE IF EXISTS %s NO FORCE ROW LEVEL SECURITY, DISABLE ROW LEVEL SECURITY",
+		pq.QuoteIdentifier(a.table)))
+	return err
+}
+
+// createPolicyAction is a DBAction that creates a row level security policy.
+type createPolicyAction struct {
+	conn   db.DB
+	id     string
+	table  string
+	policy *schema.Policy
+}
+
+func NewCreatePolicyAction(conn db.DB, table string, policy *schema.Policy) *createPolicyAction {
+	return &createPolicyAction{
+		conn:   conn,
+		id:     fmt.Sprintf("create_policy_%s_%s", table, policy.Name),
+		table:  table,
+		policy: policy,
+	}
+}
+
+func (a *createPolicyAction) ID() string { return a.id }
+
+func (a *createPolicyAction) Execute(ctx context.Context) error {
+	_, err := a.conn.ExecContext(ctx, createPolicySQL(a.table, a.policy))
+	return err
+}
+
+// alterPolicyAction is a DBAction that changes the roles and expressions of a
+// row level security policy. Empty fields of the policy are left unchanged.
+type alterPolicyAction struct {
+	conn   db.DB
+	id     string
+	table  string
+	policy *schema.Policy
+}
+
+func NewAlterPolicyAction(conn db.DB, table string, policy *schema.Policy) *alterPolicyAction {
+	return &alterPolicyAction{
+		conn:   conn,
+		id:     fmt.Sprintf("alter_policy_%s_%s", table, policy.Name),
+		table:  table,
+		policy: policy,
+	}
+}
+
+func (a *alterPolicyAction) ID() string { return a.id }
+
+func (a *alterPolicyAction) Execute(ctx context.Context) error {
+	sql := fmt.Sprintf("ALTER POLICY %s ON %s", pq.QuoteIdentifier(a.policy.Name), pq.QuoteIdentifier(a.table))
+	if len(a.policy.Roles) > 0 {
+		sql += " TO " + QuoteRoles(a.policy.Roles)
+	}
+	if a.policy.Using != "" {
+		sql += fmt.Sprintf(" USING (%s)", a.policy.Using)
+	}
+	if a.policy.WithCheck != "" {
+		sql += fmt.Sprintf(" WITH CHECK (%s)", a.policy.WithCheck)
+	}
+	_, err := a.conn.ExecContext(ctx, sql)
+	return err
+}
+
+// renamePolicyAction is a DBAction that renames a row level security policy.
+type renamePolicyAction struct {
+	conn  db.DB
+	id    string
+	table string
+	from  string
+	to    string
+}
+
+func NewRenamePolicyAction(conn db.DB, table, from, to string) *renamePolicyAction {
+	return &renamePolicyAction{
+		conn:  conn,
+		id:    fmt.Sprintf("rename_policy_%s_%s_%s", table, from, to),
+		table: table,
+		from:  from,
+		to:    to,
+	}
+}
+
+func (a *renamePolicyAction) ID() string { return a.id }
+
+func (a *renamePolicyAction) Execute(ctx context.Context) error {
+	_, err := a.conn.ExecContext(ctx, fmt.Sprintf("ALTER POLICY %s ON %s RENAME TO %s",
+		pq.QuoteIdentifier(a.from),
+		pq.QuoteIdentifier(a.table),
+		pq.QuoteIdentifier(a.to)))
+	return err
+}
+
+// dropPolicyAction is a DBAction that drops a row level security policy.
+type dropPolicyAction struct {
+	conn  db.DB
+	id    string
+	table string
+	name  string
+}
+
+func NewDropPolicyAction(conn db.DB, table, name string) *dropPolicyAction {
+	return &dropPolicyAction{
+		conn:  conn,
+		id:    fmt.Sprintf("drop_policy_%s_%s", table, name),
+		table: table,
+		name:  name,
+	}
+}
+
+func (a *dropPolicyAction) ID() string { return a.id }
+
+func (a *dropPolicyAction) Execute(ctx context.Context) error {
+	_, err := a.conn.ExecContext(ctx, fmt.Sprintf("DROP POLICY IF EXISTS %s ON %s",
+		pq.QuoteIdentifier(a.name),
+		pq.QuoteIdentifier(a.table)))
+	return err
+}
+
+// grantAction is a DBAction that grants privileges on a table to roles.
+type grantAction struct {
+	conn            db.DB
+	id              string
+	table           string
+	privileges      []TablePrivilege
+	roles           []string
+	withGrantOption bool
+}
+
+func NewGrantAction(conn db.DB, table string, privileges []TablePrivilege, roles []string, withGrantOption bool) *grantAction {
+	return &grantAction{
+		conn:            conn,
+		id:              fmt.Sprintf("grant_%s_%s", table, strings.Join(roles, "_")),
+		table:           table,
+		privileges:      privileges,
+		roles:           roles,
+		withGrantOption: withGrantOption,
+	}
+}
+
+func (a *grantAction) ID() string { return a.id }
+
+func (a *grantAction) Execute(ctx context.Context) error {
+	sql := fmt.Sprintf("GRANT %s ON TABLE %s TO %s",
+		PrivilegesSQL(a.privileges),
+		pq.QuoteIdentifier(a.table),
+		QuoteRoles(a.roles))
+	if a.withGrantOption {
+		sql += " WITH GRANT OPTION"
+	}
+	_, err := a.conn.ExecContext(ctx, sql)
+	return err
+}
+
+// revokeAction is a DBAction that revokes privileges on a table from roles.
+type revokeAction struct {
+	conn       db.DB
+	id         string
+	table      string
+	privileges []TablePrivilege
+	roles      []string
+}
+
+func NewRevokeAction(conn db.DB, table string, privileges []TablePrivilege, roles []string) *revokeAction {
+	return &revokeAction{
+		conn:       conn,
+		id:         fmt.Sprintf("revoke_%s_%s", table, strings.Join(roles, "_")),
+		table:      table,
+		privileges: privileges,
+		roles:      roles,
+	}
+}
+
+func (a *revokeAction) ID() string { return a.id }
+
+func (a *revokeAction) Execute(ctx context.Context) error {
+	_, err := a.conn.ExecContext(ctx, fmt.Sprintf("REVOKE %s ON TABLE %s FROM %s",
+		PrivilegesSQL(a.privileges),
+		pq.QuoteIdentifier(a.table),
+		QuoteRoles(a.roles)))
+	return err
+}
+
+// createSequenceAction is a DBAction that creates a sequence.
+type createSequenceAction struct {
+	conn     db.DB
+	id       string
+	sequence *schema.Sequence
+}
+
+func NewCreateSequenceAction(conn db.DB, sequence *schema.Sequence) *createSequenceAction {
+	return &createSequenceAction{
+		conn:     conn,
+		id:       fmt.Sprintf("create_sequence_%s", sequence.Name),
+		sequence: sequence,
+	}
+}
+
+func (a *createSequenceAction) ID() string { return a.id }
+
+func (a *createSequenceAction) Execute(ctx context.Context) error {
+	_, err := a.conn.ExecContext(ctx, createSequenceSQL(a.sequence))
+	return err
+}
+
+// alterSequenceAction is a DBAction that changes the options of a sequence.
+// Options that are nil are left unchanged.
+type alterSequenceAction struct {
+	conn      db.DB
+	id        string
+	name      string
+	increment *int64
+	cache     *int64
+	restart   *int64
+}
+
+func NewAlterSequenceAction(conn db.DB, name string, increment, cache, restart *int64) *alterSequenceAction {
+	return &alterSequenceAction{
+		conn:      conn,
+		id:        fmt.Sprintf("alter_sequence_%s", name),
+		name:      name,
+		increment: increment,
+		cache:     cache,
+		restart:   restart,
+	}
+}
+
+func (a *alterSequenceAction) ID() string { return a.id }
+
+func (a *alterSequenceAction) Execute(ctx context.Context) error {
+	sql := fmt.Sprintf("ALTER SEQUENCE %s", pq.QuoteIdentifier(a.name))
+	if a.increment != nil {
+		sql += fmt.Sprintf(" INCREMENT BY %d", *a.increment)
+	}
+	if a.cache != nil {
+		sql += fmt.Sprintf(" CACHE %d", *a.cache)
+	}
+	if a.restart != nil {
+		sql += fmt.Sprintf(" RESTART WITH %d", *a.restart)
+	}
+	_, err := a.conn.ExecContext(ctx, sql)
+	return err
+}
+
+// dropSequenceAction is a DBAction that drops a sequence.
+type dropSequenceAction struct {
+	conn db.DB
+	id   string
+	name string
+}
+
+func NewDropSequenceAction(conn db.DB, name string) *dropSequenceAction {
+	return &dropSequenceAction{
+		conn: conn,
+		id:   fmt.Sprintf("drop_sequence_%s", name),
+		name: name,
+	}
+}
+
+func (a *dropSequenceAction) ID() string { return a.id }
+
+func (a *dropSequenceAction) Execute(ctx context.Context) error {
+	_, err := a.conn.ExecContext(ctx, fmt.Sprintf("DROP SEQUENCE IF EXISTS %s", pq.QuoteIdentifier(a.name)))
+	return err
+}
+
+// setIdentityAction is a DBAction that changes a column between a serial
+// column and an identity column, or changes the generation of an identity
+// column.
+type setIdentityAction struct {
+	conn     db.DB
+	id       string
+	table    string
+	column   *schema.Column
+	identity OpSetIdentityIdentity
+}
+
+func NewSetIdentityAction(conn db.DB, table string, column *schema.Column, identity OpSetIdentityIdentity) *setIdentityAction {
+	return &setIdentityAction{
+		conn:     conn,
+		id:       fmt.Sprintf("set_identity_%s_%s", table, column.Name),
+		table:    table,
+		column:   column,
+		identity: identity,
+	}
+}
+
+func (a *setIdentityAction) ID() string { return a.id }
+
+func (a *setIdentityAction) Execute(ctx context.Context) error {
+	_, err := a.conn.ExecContext(ctx, setIdentitySQL(a.table, a.column, a.identity))
+	return err
+}
+
+// alterSequenceTypeAction is a DBAction that changes the data type of a
+// sequence. The maximum value of an ascending sequence that had the maximum
+// value of its old type becomes the maximum value of the new type.
+type alterSequenceTypeAction struct {
+	conn     db.DB
+	id       string
+	name     string
+	dataType string
+}
+
+func NewAlterSequenceTypeAction(conn db.DB, name, dataType string) *alterSequenceTypeAction {
+	return &alterSequenceTypeAction{
+		conn:     conn,
+		id:       fmt.Sprintf("alter_sequence_type_%s", name),
+		name:     name,
+		dataType: dataType,
+	}
+}
+
+func (a *alterSequenceTypeAction) ID() string { return a.id }
+
+func (a *alterSequenceTypeAction) Execute(ctx context.Context) error {
+	_, err := a.conn.ExecContext(ctx, fmt.Sprintf("ALTER SEQUENCE IF EXISTS %s AS %s",
+		pq.QuoteIdentifier(a.name),
+		a.dataType))
+	return err
+}
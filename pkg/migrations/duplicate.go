@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 
@@ -166,9 +167,52 @@ func (d *duplicator) Execute(ctx context.Context) error {
 		}
 	}
 
+	// Generate SQL to duplicate any row level security policies on the columns,
+	// so that the table remains protected once the original columns are
+	// dropped.
+	asNames := make(map[string]string, len(d.columns))
+	for name, c := range d.columns {
+		asNames[name] = c.asName
+	}
+	stmts, err := d.stmtBuilder.duplicatePolicies(asNames)
+	if err != nil {
+		return err
+	}
+	for _, sql := range stmts {
+		if _, err := d.conn.ExecContext(ctx, sql); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// duplicatePolicies returns the statements that duplicate the policies on the
+// columns in `asNames`, a map of column name to duplicated column name. The
+// expressions of the duplicated policies refer to the duplicated columns.
+func (d *duplicatorStmtBuilder) duplicatePolicies(asNames map[string]string) ([]string, error) {
+	columns := slices.Collect(maps.Keys(asNames))
+	stmts := make([]string, 0)
+	for _, policy := range duplicatedPolicies(d.table, columns...) {
+		duplicate := *policy
+		duplicate.Name = DuplicationName(policy.Name)
+
+		var err error
+		if policy.Using != "" {
+			if duplicate.Using, _, err = rewriteColumnReferences(policy.Using, d.table.Name, asNames); err != nil {
+				return nil, fmt.Errorf("failed to duplicate policy %q: %w", policy.Name, err)
+			}
+		}
+		if policy.WithCheck != "" {
+			if duplicate.WithCheck, _, err = rewriteColumnReferences(policy.WithCheck, d.table.Name, asNames); err != nil {
+				return nil, fmt.Errorf("failed to duplicate policy %q: %w", policy.Name, err)
+			}
+		}
+		stmts = append(stmts, createPolicySQL(d.table.Name, &duplicate))
+	}
+	return stmts, nil
+}
+
 func (d *duplicatorStmtBuilder) duplicateCheckConstraints(withoutConstraint []string, colNames ...string) []string {
 	stmts := make([]string, 0, len(d.table.CheckConstraints))
 	for _, cc := range d.table.CheckConstraints {
@@ -198,6 +242,7 @@ func (d *duplicatorStmtBuilder) duplicateForeignKeyConstraints(withoutConstraint
 				Columns: constraintColumns,
 			}
 			sql += writer.WriteForeignKey(
+				fk.ReferencedSchema,
 				fk.ReferencedTable,
 				fk.ReferencedColumns,
 				ForeignKeyAction(fk.OnDelete),
@@ -4,6 +4,7 @@ package migrations
 
 import (
 	"fmt"
+	"strings"
 )
 
 type InvalidMigrationError struct {
@@ -28,6 +29,14 @@ func (e TableAlreadyExistsError) Error() string {
 	return fmt.Sprintf("table %q already exists", e.Name)
 }
 
+type SchemaDoesNotExistError struct {
+	Name string
+}
+
+func (e SchemaDoesNotExistError) Error() string {
+	return fmt.Sprintf("schema %q does not exist", e.Name)
+}
+
 type TableDoesNotExistError struct {
 	Name string
 }
@@ -293,6 +302,16 @@ func (e InvalidGeneratedColumnError) Error() string {
 	return fmt.Sprintf("column %q on table %q is invalid: only one of generated.expression and generated.identity may be set", e.Column, e.Table)
 }
 
+type GeneratedColumnError struct {
+	Table  string
+	Column string
+	Reason string
+}
+
+func (e GeneratedColumnError) Error() string {
+	return fmt.Sprintf("invalid generated column %q on table %q: %s", e.Column, e.Table, e.Reason)
+}
+
 type UpSQLMustBeColumnDefaultError struct {
 	Column string
 }
@@ -300,3 +319,297 @@ type UpSQLMustBeColumnDefaultError struct {
 func (e UpSQLMustBeColumnDefaultError) Error() string {
 	return fmt.Sprintf(`volatile default expression for column %q; "up" must be equal to "default"`, e.Column)
 }
+
+type EnumAlreadyExistsError struct {
+	Name string
+}
+
+func (e EnumAlreadyExistsError) Error() string {
+	return fmt.Sprintf("enum type %q already exists", e.Name)
+}
+
+type EnumDoesNotExistError struct {
+	Name string
+}
+
+func (e EnumDoesNotExistError) Error() string {
+	return fmt.Sprintf("enum type %q does not exist", e.Name)
+}
+
+type EnumValueAlreadyExistsError struct {
+	Enum  string
+	Value string
+}
+
+func (e EnumValueAlreadyExistsError) Error() string {
+	return fmt.Sprintf("value %q already exists in enum type %q", e.Value, e.Enum)
+}
+
+type EnumValueDoesNotExistError struct {
+	Enum  string
+	Value string
+}
+
+func (e EnumValueDoesNotExistError) Error() string {
+	return fmt.Sprintf("value %q does not exist in enum type %q", e.Value, e.Enum)
+}
+
+type RenamedEnumValueReferenceError struct {
+	Enum      string
+	Value     string
+	Operation OpName
+	Field     string
+}
+
+func (e RenamedEnumValueReferenceError) Error() string {
+	return fmt.Sprintf("operation %q refers to value %q of enum type %q in %q, but the value is only renamed when the migration is completed",
+		e.Operation, e.Value, e.Enum, e.Field)
+}
+
+type EnumIsInUseError struct {
+	Name string
+}
+
+func (e EnumIsInUseError) Error() string {
+	return fmt.Sprintf("enum type %q is used by one or more columns", e.Name)
+}
+
+type ViewAlreadyExistsError struct {
+	Name string
+}
+
+func (e ViewAlreadyExistsError) Error() string {
+	return fmt.Sprintf("view %q already exists", e.Name)
+}
+
+type ViewDoesNotExistError struct {
+	Name string
+}
+
+func (e ViewDoesNotExistError) Error() string {
+	return fmt.Sprintf("view %q does not exist", e.Name)
+}
+
+type InvalidViewDefinitionError struct {
+	Name   string
+	Reason string
+}
+
+func (e InvalidViewDefinitionError) Error() string {
+	return fmt.Sprintf("invalid definition for view %q: %s", e.Name, e.Reason)
+}
+
+// ViewDependencyMissingError is returned when a view reads from a table, view
+// or column that does not exist, for example because the migration drops or
+// renames it.
+type ViewDependencyMissingError struct {
+	View     string
+	Relation string
+	Column   string
+}
+
+func (e ViewDependencyMissingError) Error() string {
+	switch {
+	case e.Column == "":
+		return fmt.Sprintf("view %q depends on %q, which does not exist", e.View, e.Relation)
+	case e.Relation == "":
+		return fmt.Sprintf("view %q depends on column %q, which does not exist", e.View, e.Column)
+	default:
+		return fmt.Sprintf("view %q depends on column %q of %q, which does not exist", e.View, e.Column, e.Relation)
+	}
+}
+
+type TableIsNotPartitionedError struct {
+	Name string
+}
+
+func (e TableIsNotPartitionedError) Error() string {
+	return fmt.Sprintf("table %q is not partitioned", e.Name)
+}
+
+type TableIsPartitionedError struct {
+	Name string
+}
+
+func (e TableIsPartitionedError) Error() string {
+	return fmt.Sprintf("table %q is already partitioned", e.Name)
+}
+
+type TableIsPartitionError struct {
+	Name  string
+	Table string
+}
+
+func (e TableIsPartitionError) Error() string {
+	return fmt.Sprintf("table %q is a partition of table %q", e.Name, e.Table)
+}
+
+type PartitionAlreadyExistsError struct {
+	Table string
+	Name  string
+}
+
+func (e PartitionAlreadyExistsError) Error() string {
+	return fmt.Sprintf("partition %q of table %q already exists", e.Name, e.Table)
+}
+
+type PartitionDoesNotExistError struct {
+	Table string
+	Name  string
+}
+
+func (e PartitionDoesNotExistError) Error() string {
+	return fmt.Sprintf("partition %q of table %q does not exist", e.Name, e.Table)
+}
+
+// InvalidPartitioningError is returned when a table can't be partitioned as
+// requested, for example because its primary key does not include the
+// partition key.
+type InvalidPartitioningError struct {
+	Table  string
+	Reason string
+}
+
+func (e InvalidPartitioningError) Error() string {
+	return fmt.Sprintf("table %q can not be partitioned: %s", e.Table, e.Reason)
+}
+
+// InvalidTableRewriteError is returned when a table can't be rewritten, for
+// example because it has no primary key to copy its rows by.
+type InvalidTableRewriteError struct {
+	Table  string
+	Reason string
+}
+
+func (e InvalidTableRewriteError) Error() string {
+	return fmt.Sprintf("table %q can not be rewritten: %s", e.Table, e.Reason)
+}
+
+type RowLevelSecurityAlreadyEnabledError struct {
+	Table string
+}
+
+func (e RowLevelSecurityAlreadyEnabledError) Error() string {
+	return fmt.Sprintf("row level security is already enabled on table %q", e.Table)
+}
+
+type PolicyAlreadyExistsError struct {
+	Table string
+	Name  string
+}
+
+func (e PolicyAlreadyExistsError) Error() string {
+	return fmt.Sprintf("policy %q on table %q already exists", e.Name, e.Table)
+}
+
+type PolicyDoesNotExistError struct {
+	Table string
+	Name  string
+}
+
+func (e PolicyDoesNotExistError) Error() string {
+	return fmt.Sprintf("policy %q on table %q does not exist", e.Name, e.Table)
+}
+
+type AlterPolicyNoChangesError struct {
+	Table string
+	Name  string
+}
+
+func (e AlterPolicyNoChangesError) Error() string {
+	return fmt.Sprintf("alter policy %q on table %q requires at least one change", e.Name, e.Table)
+}
+
+type InvalidPolicyError struct {
+	Table  string
+	Name   string
+	Reason string
+}
+
+func (e InvalidPolicyError) Error() string {
+	return fmt.Sprintf("invalid policy %q on table %q: %s", e.Name, e.Table, e.Reason)
+}
+
+type InvalidPrivilegeError struct {
+	Privilege string
+}
+
+func (e InvalidPrivilegeError) Error() string {
+	return fmt.Sprintf("invalid privilege %q", e.Privilege)
+}
+
+type SequenceAlreadyExistsError struct {
+	Name string
+}
+
+func (e SequenceAlreadyExistsError) Error() string {
+	return fmt.Sprintf("sequence %q already exists", e.Name)
+}
+
+type SequenceDoesNotExistError struct {
+	Name string
+}
+
+func (e SequenceDoesNotExistError) Error() string {
+	return fmt.Sprintf("sequence %q does not exist", e.Name)
+}
+
+type SequenceIsOwnedError struct {
+	Name   string
+	Table  string
+	Column string
+}
+
+func (e SequenceIsOwnedError) Error() string {
+	return fmt.Sprintf("sequence %q is owned by column %q of table %q", e.Name, e.Column, e.Table)
+}
+
+type AlterSequenceNoChangesError struct {
+	Name string
+}
+
+func (e AlterSequenceNoChangesError) Error() string {
+	return fmt.Sprintf("alter sequence %q requires at least one change", e.Name)
+}
+
+type InvalidSequenceError struct {
+	Name   string
+	Reason string
+}
+
+func (e InvalidSequenceError) Error() string {
+	return fmt.Sprintf("invalid sequence %q: %s", e.Name, e.Reason)
+}
+
+type InvalidIdentityChangeError struct {
+	Table  string
+	Column string
+	Reason string
+}
+
+func (e InvalidIdentityChangeError) Error() string {
+	return fmt.Sprintf("invalid identity change for column %q of table %q: %s", e.Column, e.Table, e.Reason)
+}
+
+type InvalidPrimaryKeyTypeChangeError struct {
+	Table  string
+	Column string
+	Reason string
+}
+
+func (e InvalidPrimaryKeyTypeChangeError) Error() string {
+	return fmt.Sprintf("invalid type change for primary key column %q of table %q: %s", e.Column, e.Table, e.Reason)
+}
+
+type UnresolvedPlaceholderError struct {
+	Placeholders []Placeholder
+}
+
+func (e UnresolvedPlaceholderError) Error() string {
+	var b strings.Builder
+	b.WriteString("migration contains placeholder SQL that must be replaced before it can be run:")
+	for _, p := range e.Placeholders {
+		fmt.Fprintf(&b, "\n  operation %d (%s): %s", p.OperationIndex, p.Operation, p.Field)
+	}
+	return b.String()
+}
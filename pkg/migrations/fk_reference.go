@@ -18,7 +18,12 @@ func (f *ForeignKeyReference) Validate(s *schema.Schema) error {
 		return err
 	}
 
-	table := s.GetTable(f.Table)
+	referenced := s.GetSchema(f.Schema)
+	if referenced == nil {
+		return SchemaDoesNotExistError{Name: f.Schema}
+	}
+
+	table := referenced.GetTable(f.Table)
 	if table == nil {
 		return TableDoesNotExistError{Name: f.Table}
 	}
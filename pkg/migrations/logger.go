@@ -7,9 +7,11 @@ import "github.com/pterm/pterm"
 // Logger is responsible for logging all migration steps.
 type Logger interface {
 	LogMigrationStart(*Migration)
+	LogMigrationStartComplete(*Migration)
 	LogMigrationComplete(*Migration)
 	LogMigrationRollback(*Migration)
 	LogMigrationRollbackComplete(*Migration)
+	LogMigrationFailed(*Migration, error)
 
 	LogOperationStart(Operation)
 	LogOperationComplete(Operation)
@@ -44,6 +46,13 @@ func (l *migrationLogger) LogMigrationStart(m *Migration) {
 	))
 }
 
+func (l *migrationLogger) LogMigrationStartComplete(m *Migration) {
+	l.logger.Info("started migration", l.logger.Args(
+		"name", m.Name,
+		"operation_count", len(m.Operations),
+	))
+}
+
 func (l *migrationLogger) LogMigrationComplete(m *Migration) {
 	l.logger.Info("completing migration", l.logger.Args(
 		"name", m.Name,
@@ -65,6 +74,13 @@ func (l *migrationLogger) LogMigrationRollbackComplete(m *Migration) {
 	))
 }
 
+func (l *migrationLogger) LogMigrationFailed(m *Migration, err error) {
+	l.logger.Error("migration failed", l.logger.Args(
+		"name", m.Name,
+		"error", err,
+	))
+}
+
 func (l *migrationLogger) LogBackfillStart(table string) {
 	l.logger.Info("backfilling started", l.logger.Args("table", table))
 }
@@ -82,22 +98,24 @@ func (l *migrationLogger) LogSchemaDeletion(migration, schema string) {
 }
 
 func (l migrationLogger) LogOperationStart(op Operation) {
-	l.logger.Info("starting operation", l.logger.Args(l.extractOpArgs(op)...))
+	l.logger.Info("starting operation", l.logger.Args(operationArgs(op)...))
 }
 
 func (l migrationLogger) LogOperationComplete(op Operation) {
-	l.logger.Info("completing operation", l.logger.Args(l.extractOpArgs(op)...))
+	l.logger.Info("completing operation", l.logger.Args(operationArgs(op)...))
 }
 
 func (l migrationLogger) LogOperationRollback(op Operation) {
-	l.logger.Info("rolling back operation", l.logger.Args(l.extractOpArgs(op)...))
+	l.logger.Info("rolling back operation", l.logger.Args(operationArgs(op)...))
 }
 
 func (l migrationLogger) Info(msg string, args ...any) {
 	l.logger.Info(msg, l.logger.Args(args))
 }
 
-func (l migrationLogger) extractOpArgs(op Operation) []any {
+// operationArgs returns the key-value pairs that describe an operation in log
+// events
+func operationArgs(op Operation) []any {
 	switch o := op.(type) {
 	case *OpAddColumn:
 		return []any{
@@ -155,6 +173,143 @@ func (l migrationLogger) extractOpArgs(op Operation) []any {
 			"constraint", o.Name,
 			"table", o.Table,
 		}
+	case *OpCreateEnum:
+		return []any{
+			"operation", OpNameCreateEnum,
+			"name", o.Name,
+			"values", o.Values,
+		}
+	case *OpAlterEnum:
+		args := []any{
+			"operation", OpNameAlterEnum,
+			"name", o.Name,
+		}
+		if o.AddValue != nil {
+			args = append(args, "add_value", o.AddValue.Value)
+		}
+		if o.RenameValue != nil {
+			args = append(args, "from", o.RenameValue.From, "to", o.RenameValue.To)
+		}
+		return args
+	case *OpDropEnum:
+		return []any{
+			"operation", OpNameDropEnum,
+			"name", o.Name,
+		}
+	case *OpCreateView:
+		return []any{
+			"operation", OpNameCreateView,
+			"name", o.Name,
+		}
+	case *OpReplaceView:
+		return []any{
+			"operation", OpNameReplaceView,
+			"name", o.Name,
+		}
+	case *OpDropView:
+		return []any{
+			"operation", OpNameDropView,
+			"name", o.Name,
+		}
+	case *OpAttachPartition:
+		return []any{
+			"operation", OpNameAttachPartition,
+			"table", o.Table,
+			"partition", o.Partition,
+		}
+	case *OpDetachPartition:
+		return []any{
+			"operation", OpNameDetachPartition,
+			"table", o.Table,
+			"partition", o.Partition,
+		}
+	case *OpPartitionTable:
+		return []any{
+			"operation", OpNamePartitionTable,
+			"table", o.Table,
+			"strategy", o.PartitionBy.Strategy,
+			"columns", o.PartitionBy.Columns,
+		}
+	case *OpEnableRLS:
+		return []any{
+			"operation", OpNameEnableRLS,
+			"table", o.Table,
+			"force", o.Force,
+		}
+	case *OpCreatePolicy:
+		return []any{
+			"operation", OpNameCreatePolicy,
+			"table", o.Table,
+			"name", o.Name,
+		}
+	case *OpAlterPolicy:
+		return []any{
+			"operation", OpNameAlterPolicy,
+			"table", o.Table,
+			"name", o.Name,
+		}
+	case *OpDropPolicy:
+		return []any{
+			"operation", OpNameDropPolicy,
+			"table", o.Table,
+			"name", o.Name,
+		}
+	case *OpGrant:
+		return []any{
+			"operation", OpNameGrant,
+			"table", o.Table,
+			"privileges", o.Privileges,
+			"roles", o.Roles,
+		}
+	case *OpRevoke:
+		return []any{
+			"operation", OpNameRevoke,
+			"table", o.Table,
+			"privileges", o.Privileges,
+			"roles", o.Roles,
+		}
+	case *OpCreateSequence:
+		return []any{
+			"operation", OpNameCreateSequence,
+			"name", o.Name,
+		}
+	case *OpAlterSequence:
+		return []any{
+			"operation", OpNameAlterSequence,
+			"name", o.Name,
+		}
+	case *OpDropSequence:
+		return []any{
+			"operation", OpNameDropSequence,
+			"name", o.Name,
+		}
+	case *OpSetIdentity:
+		return []any{
+			"operation", OpNameSetIdentity,
+			"table", o.Table,
+			"column", o.Column,
+			"identity", o.Identity,
+		}
+	case *OpAlterPrimaryKeyType:
+		return []any{
+			"operation", OpNameAlterPrimaryKeyType,
+			"table", o.Table,
+			"column", o.Column,
+			"type", o.Type,
+		}
+	case *OpRewriteTable:
+		return []any{
+			"operation", OpNameRewriteTable,
+			"table", o.Table,
+			"storage_parameters", o.StorageParameters,
+			"tablespace", o.Tablespace,
+		}
+	case *OpMoveTable:
+		return []any{
+			"operation", OpNameMoveTable,
+			"table", o.Table,
+			"to_schema", o.ToSchema,
+		}
 	case *OpDropIndex:
 		return []any{
 			"operation", OpNameDropIndex,
@@ -226,6 +381,16 @@ func (l migrationLogger) extractOpArgs(op Operation) []any {
 			args = append(args, "default", *o.Default)
 		}
 		return args
+	case *OpSetGenerated:
+		args := []any{
+			"operation", OpNameAlterColumn,
+			"table", o.Table,
+			"column", o.Column,
+		}
+		if o.Expression != nil {
+			args = append(args, "generated", *o.Expression)
+		}
+		return args
 	case *OpSetForeignKey:
 		return []any{
 			"operation", OpNameAlterColumn,
@@ -276,9 +441,11 @@ func getConstraintNames(cons []Constraint) []string {
 }
 
 func (l *noopLogger) LogMigrationStart(m *Migration)             {}
+func (l *noopLogger) LogMigrationStartComplete(m *Migration)     {}
 func (l *noopLogger) LogMigrationComplete(m *Migration)          {}
 func (l *noopLogger) LogMigrationRollback(m *Migration)          {}
 func (l *noopLogger) LogMigrationRollbackComplete(m *Migration)  {}
+func (l *noopLogger) LogMigrationFailed(m *Migration, err error) {}
 func (l *noopLogger) LogBackfillStart(table string)              {}
 func (l *noopLogger) LogBackfillComplete(table string)           {}
 func (l *noopLogger) LogSchemaCreation(migration, schema string) {}
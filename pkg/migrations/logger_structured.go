@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// structuredLogger is a Logger that writes machine-parsable events using
+// log/slog. Events that end a migration phase or a backfill include the
+// duration of the phase.
+type structuredLogger struct {
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	started map[string]time.Time
+}
+
+// NewStructuredLogger returns a Logger that writes events to `logger`
+func NewStructuredLogger(logger *slog.Logger) Logger {
+	return &structuredLogger{
+		logger:  logger,
+		started: make(map[string]time.Time),
+	}
+}
+
+// NewJSONLogger returns a Logger that writes events to `w` as JSON objects,
+// one per line
+func NewJSONLogger(w io.Writer) Logger {
+	return NewStructuredLogger(slog.New(slog.NewJSONHandler(w, nil)))
+}
+
+// NewLogfmtLogger returns a Logger that writes events to `w` as
+// space-separated key=value pairs, one event per line
+func NewLogfmtLogger(w io.Writer) Logger {
+	return NewStructuredLogger(slog.New(slog.NewTextHandler(w, nil)))
+}
+
+func (l *structuredLogger) LogMigrationStart(m *Migration) {
+	l.begin("start", m.Name)
+	l.logger.Info("starting migration", migrationArgs(m)...)
+}
+
+func (l *structuredLogger) LogMigrationStartComplete(m *Migration) {
+	l.logger.Info("started migration", l.end("start", m.Name, migrationArgs(m))...)
+}
+
+// LogMigrationComplete is called both when completing a migration begins and
+// when it has finished, so the second call for a migration logs the duration.
+func (l *structuredLogger) LogMigrationComplete(m *Migration) {
+	if l.running("complete", m.Name) {
+		l.logger.Info("completed migration", l.end("complete", m.Name, migrationArgs(m))...)
+		return
+	}
+	l.begin("complete", m.Name)
+	l.logger.Info("completing migration", migrationArgs(m)...)
+}
+
+func (l *structuredLogger) LogMigrationRollback(m *Migration) {
+	l.begin("rollback", m.Name)
+	l.logger.Info("rolling back migration", migrationArgs(m)...)
+}
+
+func (l *structuredLogger) LogMigrationRollbackComplete(m *Migration) {
+	l.logger.Info("rolled back migration", l.end("rollback", m.Name, migrationArgs(m))...)
+}
+
+func (l *structuredLogger) LogMigrationFailed(m *Migration, err error) {
+	args := append(migrationArgs(m), "error", err.Error())
+
+	// The failure ends whichever phase of the migration was in progress
+	for _, phase := range []string{"start", "complete", "rollback"} {
+		if l.running(phase, m.Name) {
+			args = l.end(phase, m.Name, append(args, "phase", phase))
+			break
+		}
+	}
+	l.logger.Error("migration failed", args...)
+}
+
+func (l *structuredLogger) LogOperationStart(op Operation) {
+	l.logger.Info("starting operation", operationArgs(op)...)
+}
+
+func (l *structuredLogger) LogOperationComplete(op Operation) {
+	l.logger.Info("completing operation", operationArgs(op)...)
+}
+
+func (l *structuredLogger) LogOperationRollback(op Operation) {
+	l.logger.Info("rolling back operation", operationArgs(op)...)
+}
+
+func (l *structuredLogger) LogBackfillStart(table string) {
+	l.begin("backfill", table)
+	l.logger.Info("backfilling started", "table", table)
+}
+
+func (l *structuredLogger) LogBackfillComplete(table string) {
+	l.logger.Info("backfilling completed", l.end("backfill", table, []any{"table", table})...)
+}
+
+func (l *structuredLogger) LogSchemaCreation(migration, schema string) {
+	l.logger.Info("created versioned schema for migration", "migration", migration, "schema_name", schema)
+}
+
+func (l *structuredLogger) LogSchemaDeletion(migration, schema string) {
+	l.logger.Info("dropped versioned schema for migration", "migration", migration, "schema_name", schema)
+}
+
+func (l *structuredLogger) Info(msg string, args ...any) {
+	l.logger.Info(msg, args...)
+}
+
+// begin records the start time of the phase `kind` for `name`
+func (l *structuredLogger) begin(kind, name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.started[kind+"/"+name] = time.Now()
+}
+
+// running returns true if the phase `kind` for `name` has begun but not ended
+func (l *structuredLogger) running(kind, name string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.started[kind+"/"+name]
+	return ok
+}
+
+// end forgets the start time of the phase `kind` for `name`, appending its
+// duration to `args` if it was recorded.
+func (l *structuredLogger) end(kind, name string, args []any) []any {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key := kind + "/" + name
+	start, ok := l.started[key]
+	if !ok {
+		return args
+	}
+	delete(l.started, key)
+	return append(args, slog.Duration("duration", time.Since(start)))
+}
+
+func migrationArgs(m *Migration) []any {
+	return []any{
+		"name", m.Name,
+		"operation_count", len(m.Operations),
+	}
+}
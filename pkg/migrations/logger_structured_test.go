@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestJSONLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := migrations.NewJSONLogger(&buf)
+
+	migration := &migrations.Migration{
+		Name: "01_add_column",
+		Operations: migrations.Operations{
+			&migrations.OpAddColumn{
+				Table:  "users",
+				Column: migrations.Column{Name: "age", Type: "int", Nullable: true},
+			},
+		},
+	}
+
+	logger.LogMigrationStart(migration)
+	logger.LogOperationStart(migration.Operations[0])
+	logger.LogBackfillStart("users")
+	logger.LogBackfillComplete("users")
+	logger.LogMigrationStartComplete(migration)
+	logger.LogMigrationComplete(migration)
+	logger.LogMigrationFailed(migration, errors.New("boom"))
+
+	var events []map[string]any
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var event map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	require.Len(t, events, 7)
+
+	assert.Equal(t, "starting migration", events[0]["msg"])
+	assert.Equal(t, "01_add_column", events[0]["name"])
+	assert.NotContains(t, events[0], "duration")
+
+	assert.Equal(t, "starting operation", events[1]["msg"])
+	assert.Equal(t, "add_column", events[1]["operation"])
+	assert.Equal(t, "users", events[1]["table"])
+	assert.Equal(t, "age", events[1]["name"])
+
+	assert.Equal(t, "backfilling completed", events[3]["msg"])
+	assert.Contains(t, events[3], "duration")
+
+	assert.Equal(t, "started migration", events[4]["msg"])
+	assert.Contains(t, events[4], "duration")
+
+	assert.Equal(t, "completing migration", events[5]["msg"])
+	assert.NotContains(t, events[5], "duration")
+
+	assert.Equal(t, "migration failed", events[6]["msg"])
+	assert.Equal(t, "ERROR", events[6]["level"])
+	assert.Equal(t, "boom", events[6]["error"])
+	assert.Equal(t, "complete", events[6]["phase"])
+	assert.Contains(t, events[6], "duration")
+}
@@ -6,6 +6,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 
 	_ "github.com/lib/pq"
 
@@ -72,11 +74,30 @@ type (
 		Name          string     `json:"-"`
 		VersionSchema string     `json:"version_schema,omitempty"`
 		Operations    Operations `json:"operations"`
+
+		// OperationSchemas holds the schema targeted by each operation, indexed
+		// like Operations. An empty or missing entry targets the schema that the
+		// migration is run against.
+		OperationSchemas []string `json:"-"`
+
+		// Backfill holds the backfill settings of the migration, if any
+		Backfill *BackfillSettings `json:"-"`
+
+		// OperationBackfills holds the backfill settings of each operation,
+		// indexed like Operations. A nil or missing entry means the operation
+		// has no backfill settings of its own.
+		OperationBackfills []*BackfillSettings `json:"-"`
+
+		// Squashes holds the names of the migrations that the migration
+		// replaces, in order, if it was created by squashing them
+		Squashes []string `json:"-"`
 	}
 	RawMigration struct {
-		Name          string          `json:"-"`
-		VersionSchema string          `json:"version_schema,omitempty"`
-		Operations    json.RawMessage `json:"operations"`
+		Name          string            `json:"-"`
+		VersionSchema string            `json:"version_schema,omitempty"`
+		Operations    json.RawMessage   `json:"operations"`
+		Backfill      *BackfillSettings `json:"backfill,omitempty"`
+		Squashes      []string          `json:"squashes,omitempty"`
 	}
 
 	StartResult struct {
@@ -97,6 +118,13 @@ func (m *Migration) VersionSchemaName() string {
 // Validate will check that the migration can be applied to the given schema
 // returns a descriptive error if the migration is invalid
 func (m *Migration) Validate(ctx context.Context, s *schema.Schema) error {
+	if err := m.ValidatePlaceholders(); err != nil {
+		return err
+	}
+	if err := m.ValidateBackfillSettings(); err != nil {
+		return err
+	}
+
 	for _, op := range m.Operations {
 		if isolatedOp, ok := op.(IsolatedOperation); ok {
 			if isolatedOp.IsIsolated() && len(m.Operations) > 1 {
@@ -104,6 +132,11 @@ func (m *Migration) Validate(ctx context.Context, s *schema.Schema) error {
 			}
 		}
 	}
+	if err := validateEnumRenames(m.Operations); err != nil {
+		return err
+	}
+
+	viewDeps := existingViewDependencies(s)
 
 	for _, op := range m.Operations {
 		err := op.Validate(ctx, s)
@@ -112,6 +145,61 @@ func (m *Migration) Validate(ctx context.Context, s *schema.Schema) error {
 		}
 	}
 
+	// Views must not depend on tables or columns that the migration drops or
+	// renames
+	return validateViews(s, viewDeps)
+}
+
+// ValidateSchemas will check that the migration can be applied to `schemas`,
+// keyed by schema name. Each operation is validated against the schema it
+// targets, with operations that don't name a schema targeting
+// `defaultSchema`. Operations reach tables in the other schemas, such as the
+// tables referenced by foreign keys, through the schema they target.
+func (m *Migration) ValidateSchemas(ctx context.Context, schemas map[string]*schema.Schema, defaultSchema string) error {
+	if err := m.ValidatePlaceholders(); err != nil {
+		return err
+	}
+	if err := m.ValidateBackfillSettings(); err != nil {
+		return err
+	}
+	if err := m.ValidateDefaultSchema(defaultSchema); err != nil {
+		return err
+	}
+
+	schema.LinkSchemas(schemas)
+
+	if err := validateEnumRenames(m.Operations); err != nil {
+		return err
+	}
+
+	viewDeps := make(map[string]map[string]*viewDependencies, len(schemas))
+	for name, s := range schemas {
+		viewDeps[name] = existingViewDependencies(s)
+	}
+
+	for i, op := range m.Operations {
+		if isolatedOp, ok := op.(IsolatedOperation); ok {
+			if isolatedOp.IsIsolated() && len(m.Operations) > 1 {
+				return InvalidMigrationError{Reason: fmt.Sprintf("operation %q cannot be executed with other operations", OperationName(op))}
+			}
+		}
+
+		name := m.OperationSchema(i, defaultSchema)
+		s, ok := schemas[name]
+		if !ok {
+			return InvalidMigrationError{Reason: fmt.Sprintf("schema %q targeted by operation %d is unknown", name, i+1)}
+		}
+		if err := op.Validate(ctx, s); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(schemas)) {
+		if err := validateViews(schemas[name], viewDeps[name]); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -129,3 +217,24 @@ func (m *Migration) UpdateVirtualSchema(ctx context.Context, s *schema.Schema) e
 	}
 	return nil
 }
+
+// UpdateVirtualSchemas updates the in-memory representations of `schemas`,
+// keyed by schema name, with the changes made by the migration. Operations
+// that don't name a schema update the schema for `defaultSchema`. No changes
+// are made to the physical database.
+func (m *Migration) UpdateVirtualSchemas(ctx context.Context, schemas map[string]*schema.Schema, defaultSchema string) error {
+	db := &db.FakeDB{}
+
+	schema.LinkSchemas(schemas)
+
+	for i, op := range m.Operations {
+		s, ok := schemas[m.OperationSchema(i, defaultSchema)]
+		if !ok {
+			return fmt.Errorf("schema %q targeted by operation %d is unknown", m.OperationSchema(i, defaultSchema), i+1)
+		}
+		if _, err := op.Start(ctx, NewNoopLogger(), db, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
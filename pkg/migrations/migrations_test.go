@@ -170,3 +170,290 @@ func exampleMigration(t *testing.T) []byte {
 
 	return bytes
 }
+
+func TestMigrationsWithPlaceholderSQLAreInvalid(t *testing.T) {
+	t.Parallel()
+
+	migration := migrations.Migration{
+		Name: "placeholders",
+		Operations: migrations.Operations{
+			&migrations.OpCreateTable{Name: "foo"},
+			&migrations.OpAlterColumn{
+				Table:  "foo",
+				Column: "a",
+				Up:     migrations.PlaceHolderSQL,
+				Down:   migrations.PlaceHolderSQL,
+			},
+			&migrations.OpCreateConstraint{
+				Table:   "foo",
+				Name:    "foo_check",
+				Type:    migrations.OpCreateConstraintTypeCheck,
+				Columns: []string{"b"},
+				Up:      migrations.MultiColumnUpSQL{"b": migrations.PlaceHolderSQL},
+				Down:    migrations.MultiColumnDownSQL{"b": "b"},
+			},
+		},
+	}
+
+	err := migration.Validate(context.TODO(), schema.New())
+	var wantErr migrations.UnresolvedPlaceholderError
+	require.ErrorAs(t, err, &wantErr)
+	assert.Equal(t, []migrations.Placeholder{
+		{OperationIndex: 2, Operation: migrations.OpNameAlterColumn, Field: "down"},
+		{OperationIndex: 2, Operation: migrations.OpNameAlterColumn, Field: "up"},
+		{OperationIndex: 3, Operation: migrations.OpCreateConstraintName, Field: "up.b"},
+	}, wantErr.Placeholders)
+}
+
+func TestResolvePlaceholders(t *testing.T) {
+	t.Parallel()
+
+	op := &migrations.OpAddColumn{
+		Table:  "foo",
+		Column: migrations.Column{Name: "a", Type: "int", Default: ptr("1")},
+		Up:     migrations.PlaceHolderSQL,
+	}
+
+	resolved, err := migrations.ResolvePlaceholders(op, func(field string) (string, error) {
+		return "resolved " + field, nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &migrations.OpAddColumn{
+		Table:  "foo",
+		Column: migrations.Column{Name: "a", Type: "int", Default: ptr("1")},
+		Up:     "resolved up",
+	}, resolved)
+	assert.Empty(t, migrations.PlaceholderFields(resolved))
+	assert.Equal(t, migrations.PlaceHolderSQL, op.Up)
+}
+
+func TestMigrationsTargetingSeveralSchemas(t *testing.T) {
+	t.Parallel()
+
+	fs := fstest.MapFS{
+		"01_move_invoices.yaml": &fstest.MapFile{Data: []byte(`operations:
+  - schema: billing
+    create_table:
+      name: invoices
+      columns:
+        - name: id
+          type: serial
+          pk: true
+  - drop_table:
+      name: invoices
+`)},
+	}
+
+	migration, err := migrations.ReadMigration(fs, "01_move_invoices.yaml")
+	require.NoError(t, err)
+	require.Len(t, migration.Operations, 2)
+
+	assert.Equal(t, "billing", migration.OperationSchema(0, "public"))
+	assert.Equal(t, "public", migration.OperationSchema(1, "public"))
+	assert.Equal(t, []string{"billing", "public"}, migration.Schemas("public"))
+	assert.True(t, migration.IsMultiSchema("public"))
+
+	t.Run("the schema of each operation survives a round trip through JSON", func(t *testing.T) {
+		raw, err := json.Marshal(migration.WithDefaultSchema("public"))
+		require.NoError(t, err)
+
+		var decoded migrations.Migration
+		require.NoError(t, json.Unmarshal(raw, &decoded))
+		assert.Equal(t, []string{"billing", "public"}, decoded.OperationSchemas)
+		assert.Equal(t, migration.Operations, decoded.Operations)
+	})
+
+	t.Run("operations are validated against the schema they target", func(t *testing.T) {
+		withInvoices := func() *schema.Schema {
+			s := schema.New()
+			s.AddTable("invoices", &schema.Table{Name: "invoices"})
+			return s
+		}
+
+		err := migration.ValidateSchemas(context.Background(), map[string]*schema.Schema{
+			"billing": schema.New(),
+			"public":  withInvoices(),
+		}, "public")
+		assert.NoError(t, err)
+
+		err = migration.ValidateSchemas(context.Background(), map[string]*schema.Schema{
+			"billing": withInvoices(),
+			"public":  schema.New(),
+		}, "public")
+		assert.Error(t, err)
+	})
+
+	t.Run("migrations must target the default schema", func(t *testing.T) {
+		assert.NoError(t, migration.ValidateDefaultSchema("public"))
+
+		m := &migrations.Migration{
+			Operations:       migrations.Operations{&migrations.OpDropTable{Name: "invoices"}},
+			OperationSchemas: []string{"billing"},
+		}
+		err := m.ValidateDefaultSchema("public")
+		assert.Equal(t, migrations.InvalidMigrationError{Reason: `at least one operation must target schema "public", the schema the migration is run against`}, err)
+
+		err = m.ValidateSchemas(context.Background(), map[string]*schema.Schema{
+			"billing": schema.New(),
+		}, "public")
+		assert.Equal(t, migrations.InvalidMigrationError{Reason: `at least one operation must target schema "public", the schema the migration is run against`}, err)
+	})
+
+	t.Run("migrations without a schema target only the default schema", func(t *testing.T) {
+		m := &migrations.Migration{Operations: migrations.Operations{&migrations.OpDropTable{Name: "foo"}}}
+		assert.False(t, m.IsMultiSchema("public"))
+		assert.Equal(t, []string{"public"}, m.Schemas("public"))
+
+		raw, err := json.Marshal(m)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"operations":[{"drop_table":{"name":"foo"}}]}`, string(raw))
+	})
+
+	withCustomers := func() *schema.Schema {
+		s := schema.New()
+		s.AddTable("customers", &schema.Table{
+			Name:    "customers",
+			Columns: map[string]*schema.Column{"id": {Name: "id", Type: "integer"}},
+		})
+		return s
+	}
+
+	t.Run("foreign keys can reference tables in other schemas", func(t *testing.T) {
+		m := &migrations.Migration{Operations: migrations.Operations{
+			&migrations.OpCreateTable{
+				Name: "orders",
+				Columns: []migrations.Column{{
+					Name: "customer_id",
+					Type: "integer",
+					References: &migrations.ForeignKeyReference{
+						Name:   "fk_orders_customers",
+						Schema: "billing",
+						Table:  "customers",
+						Column: "id",
+					},
+				}},
+			},
+		}}
+		assert.False(t, m.IsMultiSchema("public"))
+		assert.Equal(t, []string{"billing"}, m.ReferencedSchemas("public"))
+
+		err := m.ValidateSchemas(context.Background(), map[string]*schema.Schema{
+			"billing": withCustomers(),
+			"public":  schema.New(),
+		}, "public")
+		assert.NoError(t, err)
+
+		err = m.ValidateSchemas(context.Background(), map[string]*schema.Schema{
+			"billing": schema.New(),
+			"public":  withCustomers(),
+		}, "public")
+		assert.ErrorAs(t, err, &migrations.TableDoesNotExistError{})
+
+		err = m.ValidateSchemas(context.Background(), map[string]*schema.Schema{
+			"public": withCustomers(),
+		}, "public")
+		assert.ErrorAs(t, err, &migrations.SchemaDoesNotExistError{})
+	})
+
+	t.Run("move_table targets the schema it moves the table to", func(t *testing.T) {
+		m := &migrations.Migration{Operations: migrations.Operations{
+			&migrations.OpMoveTable{Table: "customers", ToSchema: "billing"},
+		}}
+		assert.True(t, m.IsMultiSchema("public"))
+		assert.Equal(t, []string{"public", "billing"}, m.Schemas("public"))
+
+		schemas := map[string]*schema.Schema{
+			"billing": schema.New(),
+			"public":  withCustomers(),
+		}
+		require.NoError(t, m.ValidateSchemas(context.Background(), schemas, "public"))
+		assert.Nil(t, schemas["public"].GetTable("customers"))
+		assert.NotNil(t, schemas["billing"].GetTable("customers"))
+
+		err := m.ValidateSchemas(context.Background(), map[string]*schema.Schema{
+			"billing": withCustomers(),
+			"public":  withCustomers(),
+		}, "public")
+		assert.ErrorAs(t, err, &migrations.TableAlreadyExistsError{})
+	})
+}
+
+func TestMigrationBackfillSettings(t *testing.T) {
+	t.Parallel()
+
+	fs := fstest.MapFS{
+		"01_add_column.yaml": &fstest.MapFile{Data: []byte(`backfill:
+  batch_size: 5000
+  parallelism: 2
+operations:
+  - backfill:
+      batch_size: 100
+      batch_delay: 50ms
+      max_duration: 1h
+    add_column:
+      table: events
+      column:
+        name: kind
+        type: text
+        nullable: true
+  - drop_table:
+      name: lookups
+`)},
+	}
+
+	migration, err := migrations.ReadMigration(fs, "01_add_column.yaml")
+	require.NoError(t, err)
+	require.Len(t, migration.Operations, 2)
+
+	assert.Equal(t, &migrations.BackfillSettings{BatchSize: ptr(5000), Parallelism: ptr(2)}, migration.Backfill)
+	assert.Equal(t, []*migrations.BackfillSettings{
+		{BatchSize: ptr(100), BatchDelay: ptr("50ms"), MaxDuration: ptr("1h")},
+		nil,
+	}, migration.OperationBackfills)
+	assert.NoError(t, migration.ValidateBackfillSettings())
+
+	t.Run("the backfill settings survive a round trip through JSON", func(t *testing.T) {
+		raw, err := json.Marshal(migration)
+		require.NoError(t, err)
+
+		var decoded migrations.Migration
+		require.NoError(t, json.Unmarshal(raw, &decoded))
+		assert.Equal(t, migration.Backfill, decoded.Backfill)
+		assert.Equal(t, migration.OperationBackfills, decoded.OperationBackfills)
+		assert.Equal(t, migration.Operations, decoded.Operations)
+	})
+
+	t.Run("invalid backfill settings are rejected", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			migration migrations.Migration
+			wantErr   error
+		}{
+			{
+				name:      "batch size must be positive",
+				migration: migrations.Migration{Backfill: &migrations.BackfillSettings{BatchSize: ptr(0)}},
+				wantErr:   migrations.InvalidMigrationError{Reason: "invalid backfill settings: batch size must be positive, got 0"},
+			},
+			{
+				name:      "durations must be valid",
+				migration: migrations.Migration{Backfill: &migrations.BackfillSettings{MaxDuration: ptr("an hour")}},
+				wantErr:   migrations.InvalidMigrationError{Reason: `invalid backfill settings: max duration "an hour" is not a valid duration`},
+			},
+			{
+				name: "parallelism can only be set for the migration",
+				migration: migrations.Migration{
+					Operations:         migrations.Operations{&migrations.OpDropTable{Name: "lookups"}},
+					OperationBackfills: []*migrations.BackfillSettings{{Parallelism: ptr(2)}},
+				},
+				wantErr: migrations.InvalidMigrationError{Reason: "invalid backfill settings for operation 1: parallelism can only be set for the migration"},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.wantErr, tt.migration.ValidateBackfillSettings())
+			})
+		}
+	})
+}
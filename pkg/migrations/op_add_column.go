@@ -237,6 +237,15 @@ func (o *OpAddColumn) Validate(ctx context.Context, s *schema.Schema) error {
 		return InvalidGeneratedColumnError{Table: o.Table, Column: o.Column.Name}
 	}
 
+	if o.Column.Generated != nil && o.Column.Generated.Expression != "" {
+		switch {
+		case o.Column.Default != nil:
+			return GeneratedColumnError{Table: o.Table, Column: o.Column.Name, Reason: "generated columns can't have a default"}
+		case o.Up != "":
+			return GeneratedColumnError{Table: o.Table, Column: o.Column.Name, Reason: "generated columns are computed by Postgres and can't have an up expression"}
+		}
+	}
+
 	if !o.Column.IsNullable() && o.Column.Default == nil && o.Up == "" && !o.Column.HasImplicitDefault() && o.Column.Generated == nil {
 		return FieldRequiredError{Name: "up"}
 	}
@@ -266,8 +275,18 @@ func addColumn(conn db.DB, o OpAddColumn, t *schema.Table, fastPathDefault bool)
 		o.Column.Nullable = true
 	}
 
+	// Add generated columns with their generation expression in terms of the
+	// physical columns of the table. Postgres computes the values of the
+	// column for the existing rows as it is added.
 	if o.Column.Generated != nil {
-		return nil, fmt.Errorf("adding generated columns to existing tables is not supported")
+		if o.Column.Generated.Identity != nil {
+			return nil, fmt.Errorf("adding identity columns to existing tables is not supported")
+		}
+		expression, _, err := physicalExpression(t, t.Name, o.Column.Generated.Expression)
+		if err != nil {
+			return nil, GeneratedColumnError{Table: o.Table, Column: o.Column.Name, Reason: err.Error()}
+		}
+		o.Column.Generated = &ColumnGenerated{Expression: expression}
 	}
 
 	// Don't add a column with a CHECK constraint directly.
@@ -1949,6 +1949,28 @@ func TestAddColumnValidation(t *testing.T) {
 			},
 			wantStartErr: migrations.FieldRequiredError{Name: "up"},
 		},
+		{
+			name: "generated columns can't have an up expression",
+			migrations: []migrations.Migration{
+				addTableMigration,
+				{
+					Name: "02_add_column",
+					Operations: migrations.Operations{
+						&migrations.OpAddColumn{
+							Table: "users",
+							Up:    "upper(name)",
+							Column: migrations.Column{
+								Name:      "shouty_name",
+								Type:      "text",
+								Nullable:  true,
+								Generated: &migrations.ColumnGenerated{Expression: "upper(name)"},
+							},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.GeneratedColumnError{Table: "users", Column: "shouty_name", Reason: "generated columns are computed by Postgres and can't have an up expression"},
+		},
 		{
 			name: "table can have multiple primary keys",
 			migrations: []migrations.Migration{
@@ -2180,6 +2202,79 @@ func TestAddColumnWithComment(t *testing.T) {
 	}})
 }
 
+func TestAddGeneratedColumn(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{{
+		name: "add a stored generated column",
+		migrations: []migrations.Migration{
+			{
+				Name: "01_create_table",
+				Operations: migrations.Operations{
+					&migrations.OpCreateTable{
+						Name: "items",
+						Columns: []migrations.Column{
+							{
+								Name: "id",
+								Type: "serial",
+								Pk:   true,
+							},
+							{
+								Name: "price",
+								Type: "integer",
+							},
+							{
+								Name: "quantity",
+								Type: "integer",
+							},
+							{
+								Name:     "total",
+								Type:     "integer",
+								Nullable: true,
+							},
+						},
+					},
+				},
+			},
+			{
+				Name: "02_add_column",
+				Operations: migrations.Operations{
+					&migrations.OpAddColumn{
+						Table: "items",
+						Column: migrations.Column{
+							Name:      "subtotal",
+							Type:      "integer",
+							Nullable:  true,
+							Generated: &migrations.ColumnGenerated{Expression: "price * quantity"},
+						},
+					},
+				},
+			},
+		},
+		afterStart: func(t *testing.T, db *sql.DB, schema string) {
+			// The new column is a generated column
+			ColumnMustBeGenerated(t, db, schema, "items", migrations.TemporaryName("subtotal"))
+
+			// Postgres computes the value of the new column for rows inserted
+			// into either version of the schema
+			MustInsert(t, db, schema, "01_create_table", "items", map[string]string{"price": "2", "quantity": "3"})
+			MustInsert(t, db, schema, "02_add_column", "items", map[string]string{"price": "4", "quantity": "5"})
+
+			rows := MustSelect(t, db, schema, "02_add_column", "items")
+			assert.Equal(t, []map[string]any{
+				{"id": 1, "price": 2, "quantity": 3, "total": nil, "subtotal": 6},
+				{"id": 2, "price": 4, "quantity": 5, "total": nil, "subtotal": 20},
+			}, rows)
+		},
+		afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+			ColumnMustNotExist(t, db, schema, "items", migrations.TemporaryName("subtotal"))
+		},
+		afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+			ColumnMustBeGenerated(t, db, schema, "items", "subtotal")
+		},
+	}})
+}
+
 func TestAddColumnInMultiOperationMigrations(t *testing.T) {
 	t.Parallel()
 
@@ -46,24 +46,23 @@ func (o *OpAlterColumn) Start(ctx context.Context, l Logger, conn db.DB, s *sche
 	}
 
 	// Add a trigger to copy values from the old column to the new, rewriting values using the `up` SQL.
-	triggers := make([]backfill.OperationTrigger, 0, 2)
-	triggers = append(
-		triggers,
-		backfill.OperationTrigger{
+	triggers := []backfill.OperationTrigger{
+		{
 			Name:           backfill.TriggerName(o.Table, o.Column),
 			Direction:      backfill.TriggerDirectionUp,
 			TableName:      table.Name,
 			Columns:        upColumns,
 			PhysicalColumn: TemporaryName(o.Column),
-			SQL:            o.upSQLForOperations(ops),
+			SQL:            o.upSQLForOperations(ops, column),
 		},
-	)
+	}
 
 	// Add the new column to the internal schema representation. This is done
 	// here, before creation of the down trigger, so that the trigger can declare
 	// a variable for the new column. Save the old column name for use as the
 	// physical column name. in the down trigger first.
 	oldPhysicalColumn := column.Name
+	oldGenerated := column.Generated
 	columnType := column.Type
 	if o.Type != nil {
 		columnType = *o.Type
@@ -73,18 +72,22 @@ func (o *OpAlterColumn) Start(ctx context.Context, l Logger, conn db.DB, s *sche
 		Type: columnType,
 	})
 
-	// Add a trigger to copy values from the new column to the old.
-	triggers = append(
-		triggers,
-		backfill.OperationTrigger{
-			Name:           backfill.TriggerName(o.Table, TemporaryName(o.Column)),
-			Direction:      backfill.TriggerDirectionDown,
-			TableName:      table.Name,
-			Columns:        table.Columns,
-			PhysicalColumn: oldPhysicalColumn,
-			SQL:            o.downSQLForOperations(ops),
-		},
-	)
+	// Add a trigger to copy values from the new column to the old, unless the
+	// old column is a generated column. Generated columns are computed by
+	// Postgres and can't be written by a trigger.
+	if oldGenerated == "" {
+		triggers = append(
+			triggers,
+			backfill.OperationTrigger{
+				Name:           backfill.TriggerName(o.Table, TemporaryName(o.Column)),
+				Direction:      backfill.TriggerDirectionDown,
+				TableName:      table.Name,
+				Columns:        table.Columns,
+				PhysicalColumn: oldPhysicalColumn,
+				SQL:            o.downSQLForOperations(ops),
+			},
+		)
+	}
 	task := backfill.NewTask(table, triggers...)
 
 	var dbActions []DBAction
@@ -129,6 +132,7 @@ func (o *OpAlterColumn) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAc
 	// Rename the new column to the old column name
 	return append(dbActions, []DBAction{
 		NewAlterSequenceOwnerAction(conn, table.Name, column.Name, TemporaryName(column.Name)),
+		NewDropReplacedPoliciesAction(conn, table, o.Column),
 		NewDropColumnAction(conn, table.Name, o.Column),
 		NewDropFunctionAction(
 			conn,
@@ -163,6 +167,9 @@ func (o *OpAlterColumn) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAc
 		dbActions = append(dbActions, actions...)
 	}
 
+	// Drop the duplicated policies on the column before the column they refer to
+	dbActions = append(dbActions, dropDuplicatedPoliciesActions(conn, table, o.Column)...)
+
 	dbActions = append(
 		dbActions,
 		NewDropColumnAction(conn, table.Name, column.Name),
@@ -201,6 +208,13 @@ func (o *OpAlterColumn) Validate(ctx context.Context, s *schema.Schema) error {
 		}
 	}
 
+	// The new column can't be added as a stored generated column without
+	// rewriting the table, so a generated column must be made a regular column
+	// to alter it.
+	if table.GetColumn(o.Column).Generated != "" && !o.Generated.IsSpecified() {
+		return GeneratedColumnError{Table: o.Table, Column: o.Column, Reason: "Postgres can't duplicate a stored generated column without rewriting the table, so the column must be made a regular column"}
+	}
+
 	return nil
 }
 
@@ -288,6 +302,20 @@ func (o *OpAlterColumn) subOperations() []Operation {
 			Down:    o.Down,
 		})
 	}
+	if o.Generated.IsSpecified() {
+		var expression *string
+		if e, err := o.Generated.Get(); err == nil {
+			expression = &e
+		}
+
+		ops = append(ops, &OpSetGenerated{
+			Table:      o.Table,
+			Column:     o.Column,
+			Expression: expression,
+			Up:         o.Up,
+			Down:       o.Down,
+		})
+	}
 
 	return ops
 }
@@ -326,14 +354,20 @@ func (o *OpAlterColumn) downSQLForOperations(ops []Operation) string {
 
 // upSQLForOperations returns the `up` SQL for the given operations, applying
 // an appropriate default if no `up` SQL is provided.
-func (o *OpAlterColumn) upSQLForOperations(ops []Operation) string {
+func (o *OpAlterColumn) upSQLForOperations(ops []Operation, column *schema.Column) string {
 	if o.Up != "" {
 		return o.Up
 	}
 
+	// The old generated column is not computed yet when the trigger runs, so
+	// the new column is computed from the old column's generation expression
+	if column.Generated != "" {
+		return column.Generated
+	}
+
 	for _, op := range ops {
 		switch op.(type) {
-		case *OpDropNotNull, *OpSetDefault, *OpSetComment:
+		case *OpDropNotNull, *OpSetDefault, *OpSetComment, *OpSetGenerated:
 			return pq.QuoteIdentifier(o.Column)
 		}
 	}
@@ -612,6 +612,82 @@ func TestAlterColumnMultipleSubOperations(t *testing.T) {
 	})
 }
 
+func TestAlterColumnWithPolicies(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "policies on an altered column are duplicated onto the new column",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_policy",
+					Operations: migrations.Operations{
+						&migrations.OpCreatePolicy{
+							Table: "documents",
+							Name:  "owner_only",
+							Using: ptr("owner = current_user"),
+						},
+					},
+				},
+				{
+					Name: "03_set_not_null",
+					Operations: migrations.Operations{
+						&migrations.OpAlterColumn{
+							Table:    "documents",
+							Column:   "owner",
+							Nullable: ptr(false),
+							Up:       "SELECT CASE WHEN owner IS NULL THEN 'nobody' ELSE owner END",
+							Down:     "owner",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The original policy still protects the old column
+				PolicyMustHaveUsing(t, db, schema, "documents", "owner_only", "(owner = (CURRENT_USER)::text)")
+
+				// A duplicate of the policy protects the new column
+				PolicyMustHaveUsing(t, db, schema, "documents", migrations.DuplicationName("owner_only"),
+					"(_pgroll_new_owner = (CURRENT_USER)::text)")
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				// The duplicated policy has been dropped with the new column
+				PolicyMustNotExist(t, db, schema, "documents", migrations.DuplicationName("owner_only"))
+				PolicyMustHaveUsing(t, db, schema, "documents", "owner_only", "(owner = (CURRENT_USER)::text)")
+
+				TableMustBeCleanedUp(t, db, schema, "documents", "owner")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				// The duplicated policy has replaced the original policy
+				PolicyMustNotExist(t, db, schema, "documents", migrations.DuplicationName("owner_only"))
+				PolicyMustHaveUsing(t, db, schema, "documents", "owner_only", "(owner = (CURRENT_USER)::text)")
+
+				TableMustBeCleanedUp(t, db, schema, "documents", "owner")
+			},
+		},
+	})
+}
+
 func TestAlterPrimaryKeyColumns(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation  = (*OpAlterEnum)(nil)
+	_ Createable = (*OpAlterEnum)(nil)
+)
+
+func (o *OpAlterEnum) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	o.updateSchema(s)
+
+	// New values are added on migration start so that they are available to
+	// the new version of the schema. Adding a value is backwards compatible:
+	// the old version of the schema never writes it.
+	if o.AddValue != nil {
+		return &StartResult{Actions: []DBAction{
+			NewAddEnumValueAction(conn, o.Name, o.AddValue.Value, o.AddValue.Before, o.AddValue.After),
+		}}, nil
+	}
+
+	// Values are renamed on migration completion; they retain their old name
+	// during the active migration period.
+	return nil, nil
+}
+
+func (o *OpAlterEnum) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	if o.RenameValue != nil {
+		return []DBAction{
+			NewRenameEnumValueAction(conn, o.Name, o.RenameValue.From, o.RenameValue.To),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+func (o *OpAlterEnum) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	// Postgres does not support removing a value from an enum type, so an
+	// added value remains in the type after rollback. It is not written by
+	// the old version of the schema.
+	return nil, nil
+}
+
+func (o *OpAlterEnum) Validate(ctx context.Context, s *schema.Schema) error {
+	if o.Name == "" {
+		return FieldRequiredError{Name: "name"}
+	}
+
+	enum := s.GetEnum(o.Name)
+	if enum == nil {
+		return EnumDoesNotExistError{Name: o.Name}
+	}
+
+	switch {
+	case o.AddValue != nil && o.RenameValue != nil:
+		return InvalidMigrationError{Reason: "only one of add_value and rename_value may be set"}
+
+	case o.AddValue != nil:
+		if o.AddValue.Value == "" {
+			return FieldRequiredError{Name: "add_value.value"}
+		}
+		if enum.HasValue(o.AddValue.Value) {
+			return EnumValueAlreadyExistsError{Enum: o.Name, Value: o.AddValue.Value}
+		}
+		if o.AddValue.Before != "" && o.AddValue.After != "" {
+			return InvalidMigrationError{Reason: "only one of add_value.before and add_value.after may be set"}
+		}
+		for _, neighbour := range []string{o.AddValue.Before, o.AddValue.After} {
+			if neighbour != "" && !enum.HasValue(neighbour) {
+				return EnumValueDoesNotExistError{Enum: o.Name, Value: neighbour}
+			}
+		}
+
+	case o.RenameValue != nil:
+		if o.RenameValue.From == "" {
+			return FieldRequiredError{Name: "rename_value.from"}
+		}
+		if o.RenameValue.To == "" {
+			return FieldRequiredError{Name: "rename_value.to"}
+		}
+		if !enum.HasValue(o.RenameValue.From) {
+			return EnumValueDoesNotExistError{Enum: o.Name, Value: o.RenameValue.From}
+		}
+		if enum.HasValue(o.RenameValue.To) {
+			return EnumValueAlreadyExistsError{Enum: o.Name, Value: o.RenameValue.To}
+		}
+
+	default:
+		return InvalidMigrationError{Reason: "one of add_value or rename_value must be set"}
+	}
+
+	o.updateSchema(s)
+	return nil
+}
+
+// updateSchema applies the change to the enum type's values in the in-memory
+// schema
+func (o *OpAlterEnum) updateSchema(s *schema.Schema) {
+	enum := s.GetEnum(o.Name)
+	if enum == nil {
+		return
+	}
+
+	switch {
+	case o.AddValue != nil && !enum.HasValue(o.AddValue.Value):
+		idx := len(enum.Values)
+		if o.AddValue.Before != "" {
+			if i := slices.Index(enum.Values, o.AddValue.Before); i >= 0 {
+				idx = i
+			}
+		} else if o.AddValue.After != "" {
+			if i := slices.Index(enum.Values, o.AddValue.After); i >= 0 {
+				idx = i + 1
+			}
+		}
+		enum.Values = slices.Insert(enum.Values, idx, o.AddValue.Value)
+
+	case o.RenameValue != nil:
+		if i := slices.Index(enum.Values, o.RenameValue.From); i >= 0 {
+			enum.Values[i] = o.RenameValue.To
+		}
+	}
+}
+
+// validateEnumRenames returns an error if an operation in `ops` refers to the
+// new name of an enum value that another operation renames. Values are only
+// renamed on migration completion, so the new name is not a value of the enum
+// type while the operations in the migration are started.
+func validateEnumRenames(ops Operations) error {
+	for _, op := range ops {
+		rename, ok := op.(*OpAlterEnum)
+		if !ok || rename.RenameValue == nil {
+			continue
+		}
+
+		// SQL refers to an enum value with a string literal
+		literal := pq.QuoteLiteral(rename.RenameValue.To)
+		for _, other := range ops {
+			if other == op {
+				continue
+			}
+			var field string
+			walkStrings(other, func(path, value string) (string, bool) {
+				if field == "" && strings.Contains(value, literal) {
+					field = path
+				}
+				return "", false
+			})
+			if field != "" {
+				return RenamedEnumValueReferenceError{
+					Enum:      rename.Name,
+					Value:     rename.RenameValue.To,
+					Operation: OperationName(other),
+					Field:     field,
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestAlterEnum(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "add a value to the end of an enum",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_enum",
+					Operations: migrations.Operations{
+						&migrations.OpCreateEnum{
+							Name:   "mood",
+							Values: []string{"sad", "happy"},
+						},
+					},
+				},
+				{
+					Name: "02_alter_enum",
+					Operations: migrations.Operations{
+						&migrations.OpAlterEnum{
+							Name:     "mood",
+							AddValue: &migrations.OpAlterEnumAddValue{Value: "ecstatic"},
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The value has been added to the enum type
+				EnumMustHaveValues(t, db, schema, "mood", []string{"sad", "happy", "ecstatic"})
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				// Postgres can not remove values from an enum type, so the value
+				// remains after rollback
+				EnumMustHaveValues(t, db, schema, "mood", []string{"sad", "happy", "ecstatic"})
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				EnumMustHaveValues(t, db, schema, "mood", []string{"sad", "happy", "ecstatic"})
+			},
+		},
+		{
+			name: "add a value before an existing value",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_enum",
+					Operations: migrations.Operations{
+						&migrations.OpCreateEnum{
+							Name:   "mood",
+							Values: []string{"sad", "happy"},
+						},
+					},
+				},
+				{
+					Name: "02_alter_enum",
+					Operations: migrations.Operations{
+						&migrations.OpAlterEnum{
+							Name:     "mood",
+							AddValue: &migrations.OpAlterEnumAddValue{Value: "ok", Before: "happy"},
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				EnumMustHaveValues(t, db, schema, "mood", []string{"sad", "ok", "happy"})
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				EnumMustHaveValues(t, db, schema, "mood", []string{"sad", "ok", "happy"})
+			},
+		},
+		{
+			name: "add a value after an existing value",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_enum",
+					Operations: migrations.Operations{
+						&migrations.OpCreateEnum{
+							Name:   "mood",
+							Values: []string{"sad", "happy"},
+						},
+					},
+				},
+				{
+					Name: "02_alter_enum",
+					Operations: migrations.Operations{
+						&migrations.OpAlterEnum{
+							Name:     "mood",
+							AddValue: &migrations.OpAlterEnumAddValue{Value: "miserable", After: "sad"},
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				EnumMustHaveValues(t, db, schema, "mood", []string{"sad", "miserable", "happy"})
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				EnumMustHaveValues(t, db, schema, "mood", []string{"sad", "miserable", "happy"})
+			},
+		},
+		{
+			name: "rename a value",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_enum",
+					Operations: migrations.Operations{
+						&migrations.OpCreateEnum{
+							Name:   "mood",
+							Values: []string{"sad", "happy"},
+						},
+					},
+				},
+				{
+					Name: "02_alter_enum",
+					Operations: migrations.Operations{
+						&migrations.OpAlterEnum{
+							Name: "mood",
+							RenameValue: &migrations.OpAlterEnumRenameValue{
+								From: "sad",
+								To:   "unhappy",
+							},
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The value is not renamed until the migration is completed
+				EnumMustHaveValues(t, db, schema, "mood", []string{"sad", "happy"})
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				EnumMustHaveValues(t, db, schema, "mood", []string{"sad", "happy"})
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				// The value has been renamed
+				EnumMustHaveValues(t, db, schema, "mood", []string{"unhappy", "happy"})
+			},
+		},
+	})
+}
+
+func TestAlterEnumValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "enum type must exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_alter_enum",
+					Operations: migrations.Operations{
+						&migrations.OpAlterEnum{
+							Name:     "mood",
+							AddValue: &migrations.OpAlterEnumAddValue{Value: "ok"},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.EnumDoesNotExistError{Name: "mood"},
+		},
+		{
+			name: "added value must not already exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_enum",
+					Operations: migrations.Operations{
+						&migrations.OpCreateEnum{
+							Name:   "mood",
+							Values: []string{"sad", "happy"},
+						},
+					},
+				},
+				{
+					Name: "02_alter_enum",
+					Operations: migrations.Operations{
+						&migrations.OpAlterEnum{
+							Name:     "mood",
+							AddValue: &migrations.OpAlterEnumAddValue{Value: "happy"},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.EnumValueAlreadyExistsError{Enum: "mood", Value: "happy"},
+		},
+		{
+			name: "neighbouring value must exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_enum",
+					Operations: migrations.Operations{
+						&migrations.OpCreateEnum{
+							Name:   "mood",
+							Values: []string{"sad", "happy"},
+						},
+					},
+				},
+				{
+					Name: "02_alter_enum",
+					Operations: migrations.Operations{
+						&migrations.OpAlterEnum{
+							Name:     "mood",
+							AddValue: &migrations.OpAlterEnumAddValue{Value: "ok", Before: "content"},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.EnumValueDoesNotExistError{Enum: "mood", Value: "content"},
+		},
+		{
+			name: "renamed value must exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_enum",
+					Operations: migrations.Operations{
+						&migrations.OpCreateEnum{
+							Name:   "mood",
+							Values: []string{"sad", "happy"},
+						},
+					},
+				},
+				{
+					Name: "02_alter_enum",
+					Operations: migrations.Operations{
+						&migrations.OpAlterEnum{
+							Name: "mood",
+							RenameValue: &migrations.OpAlterEnumRenameValue{
+								From: "ok",
+								To:   "fine",
+							},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.EnumValueDoesNotExistError{Enum: "mood", Value: "ok"},
+		},
+		{
+			name: "new name of a renamed value must not already exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_enum",
+					Operations: migrations.Operations{
+						&migrations.OpCreateEnum{
+							Name:   "mood",
+							Values: []string{"sad", "happy"},
+						},
+					},
+				},
+				{
+					Name: "02_alter_enum",
+					Operations: migrations.Operations{
+						&migrations.OpAlterEnum{
+							Name: "mood",
+							RenameValue: &migrations.OpAlterEnumRenameValue{
+								From: "sad",
+								To:   "happy",
+							},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.EnumValueAlreadyExistsError{Enum: "mood", Value: "happy"},
+		},
+		{
+			name: "new name of a renamed value can't be used by other operations in the same migration",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_enum",
+					Operations: migrations.Operations{
+						&migrations.OpCreateEnum{
+							Name:   "mood",
+							Values: []string{"sad", "happy"},
+						},
+						&migrations.OpCreateTable{
+							Name: "people",
+							Columns: []migrations.Column{
+								{Name: "id", Type: "serial", Pk: true},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_alter_enum",
+					Operations: migrations.Operations{
+						&migrations.OpAlterEnum{
+							Name: "mood",
+							RenameValue: &migrations.OpAlterEnumRenameValue{
+								From: "sad",
+								To:   "unhappy",
+							},
+						},
+						&migrations.OpAddColumn{
+							Table: "people",
+							Column: migrations.Column{
+								Name:    "mood",
+								Type:    "mood",
+								Default: ptr("'unhappy'"),
+							},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.RenamedEnumValueReferenceError{
+				Enum:      "mood",
+				Value:     "unhappy",
+				Operation: migrations.OpNameAddColumn,
+				Field:     "column.default",
+			},
+		},
+	})
+}
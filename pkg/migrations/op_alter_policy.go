@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation  = (*OpAlterPolicy)(nil)
+	_ Createable = (*OpAlterPolicy)(nil)
+)
+
+func (o *OpAlterPolicy) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+
+	changes, err := o.changes(table)
+	if err != nil {
+		return nil, InvalidPolicyError{Table: o.Table, Name: o.Name, Reason: err.Error()}
+	}
+
+	// Policies are defined on the underlying table, so the change applies to
+	// both versions of the schema as soon as the migration starts
+	return &StartResult{Actions: []DBAction{
+		NewAlterPolicyAction(conn, table.Name, changes),
+	}}, nil
+}
+
+func (o *OpAlterPolicy) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	// No-op
+	return nil, nil
+}
+
+func (o *OpAlterPolicy) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+
+	// The policy in the schema still has its original definition, which is
+	// restored
+	policy := table.GetPolicy(o.Name)
+	if policy == nil {
+		return nil, PolicyDoesNotExistError{Table: o.Table, Name: o.Name}
+	}
+
+	return []DBAction{NewAlterPolicyAction(conn, table.Name, policy)}, nil
+}
+
+func (o *OpAlterPolicy) Validate(ctx context.Context, s *schema.Schema) error {
+	if o.Table == "" {
+		return FieldRequiredError{Name: "table"}
+	}
+	if o.Name == "" {
+		return FieldRequiredError{Name: "name"}
+	}
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return TableDoesNotExistError{Name: o.Table}
+	}
+	policy := table.GetPolicy(o.Name)
+	if policy == nil {
+		return PolicyDoesNotExistError{Table: o.Table, Name: o.Name}
+	}
+
+	if len(o.Roles) == 0 && o.Using == nil && o.WithCheck == nil {
+		return AlterPolicyNoChangesError{Table: o.Table, Name: o.Name}
+	}
+	if o.Using != nil && policy.Command == "INSERT" {
+		return InvalidPolicyError{Table: o.Table, Name: o.Name, Reason: "insert policies can not have a using expression"}
+	}
+	if o.WithCheck != nil && (policy.Command == "SELECT" || policy.Command == "DELETE") {
+		return InvalidPolicyError{Table: o.Table, Name: o.Name, Reason: fmt.Sprintf("%s policies can not have a with_check expression", policy.Command)}
+	}
+
+	if _, err := o.changes(table); err != nil {
+		return InvalidPolicyError{Table: o.Table, Name: o.Name, Reason: err.Error()}
+	}
+
+	// The definition of the policy in the schema is left unchanged so that
+	// the operation can restore it on rollback
+	return nil
+}
+
+// changes returns the changes to the policy on `table`, with the expressions
+// rewritten to refer to the physical columns of the table.
+func (o *OpAlterPolicy) changes(table *schema.Table) (*schema.Policy, error) {
+	changes := &schema.Policy{
+		Name:  o.Name,
+		Roles: o.Roles,
+	}
+
+	var err error
+	if o.Using != nil {
+		if changes.Using, _, err = physicalExpression(table, o.Table, *o.Using); err != nil {
+			return nil, fmt.Errorf("invalid using expression: %w", err)
+		}
+	}
+	if o.WithCheck != nil {
+		if changes.WithCheck, _, err = physicalExpression(table, o.Table, *o.WithCheck); err != nil {
+			return nil, fmt.Errorf("invalid with_check expression: %w", err)
+		}
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestAlterPolicy(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "alter policy expression",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_policy",
+					Operations: migrations.Operations{
+						&migrations.OpCreatePolicy{
+							Table: "documents",
+							Name:  "owner_only",
+							Using: ptr("owner = current_user"),
+						},
+					},
+				},
+				{
+					Name: "03_alter_policy",
+					Operations: migrations.Operations{
+						&migrations.OpAlterPolicy{
+							Table: "documents",
+							Name:  "owner_only",
+							Using: ptr("owner = session_user"),
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The policy is altered on the underlying table
+				PolicyMustHaveUsing(t, db, schema, "documents", "owner_only", "(owner = (SESSION_USER)::text)")
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				// The original expression is restored
+				PolicyMustHaveUsing(t, db, schema, "documents", "owner_only", "(owner = (CURRENT_USER)::text)")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				PolicyMustHaveUsing(t, db, schema, "documents", "owner_only", "(owner = (SESSION_USER)::text)")
+			},
+		},
+	})
+}
+
+func TestAlterPolicyValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "policy must exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_alter_policy",
+					Operations: migrations.Operations{
+						&migrations.OpAlterPolicy{
+							Table: "documents",
+							Name:  "owner_only",
+							Using: ptr("owner = session_user"),
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.PolicyDoesNotExistError{Table: "documents", Name: "owner_only"},
+		},
+		{
+			name: "policy must be changed",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_policy",
+					Operations: migrations.Operations{
+						&migrations.OpCreatePolicy{
+							Table: "documents",
+							Name:  "owner_only",
+							Using: ptr("owner = current_user"),
+						},
+					},
+				},
+				{
+					Name: "03_alter_policy",
+					Operations: migrations.Operations{
+						&migrations.OpAlterPolicy{
+							Table: "documents",
+							Name:  "owner_only",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.AlterPolicyNoChangesError{Table: "documents", Name: "owner_only"},
+		},
+	})
+}
@@ -0,0 +1,339 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation         = (*OpAlterPrimaryKeyType)(nil)
+	_ Createable        = (*OpAlterPrimaryKeyType)(nil)
+	_ IsolatedOperation = (*OpAlterPrimaryKeyType)(nil)
+)
+
+// primaryKeyReference is a single column foreign key that references a
+// primary key column.
+type primaryKeyReference struct {
+	table *schema.Table
+	fk    *schema.ForeignKey
+}
+
+// column returns the referencing column of the foreign key.
+func (r primaryKeyReference) column() string {
+	return r.fk.Columns[0]
+}
+
+func (o *OpAlterPrimaryKeyType) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+	column := table.GetColumn(o.Column)
+	if column == nil {
+		return nil, ColumnDoesNotExistError{Table: o.Table, Name: o.Column}
+	}
+	refs := primaryKeyReferences(s, table.Name, column.Name)
+
+	// Duplicate the primary key column with the new type. The primary key
+	// index is duplicated as a unique index, built concurrently.
+	triggers, err := o.duplicateColumn(ctx, conn, table, column)
+	if err != nil {
+		return nil, err
+	}
+	task := backfill.NewTask(table, triggers...)
+
+	// Duplicate the columns of the foreign keys that reference the primary
+	// key. The foreign keys are not duplicated, as the duplicated primary key
+	// column is not unique until it is backfilled; they are created on the
+	// duplicated columns on migration completion.
+	for _, ref := range refs {
+		refColumn := ref.table.GetColumn(ref.column())
+		triggers, err := o.duplicateColumn(ctx, conn, ref.table, refColumn, ref.fk.Name)
+		if err != nil {
+			return nil, err
+		}
+		task.AddTable(ref.table, triggers...)
+	}
+
+	return &StartResult{BackfillTask: task}, nil
+}
+
+func (o *OpAlterPrimaryKeyType) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+	column := table.GetColumn(o.Column)
+	if column == nil {
+		return nil, ColumnDoesNotExistError{Table: o.Table, Name: o.Column}
+	}
+	refs := primaryKeyReferences(s, table.Name, column.Name)
+
+	// Create the foreign keys on the duplicated columns, referencing the
+	// duplicated primary key column, and validate them. They take the names
+	// of the original foreign keys when the duplicated columns are renamed.
+	dbActions := make([]DBAction, 0)
+	for _, ref := range refs {
+		name := DuplicationName(ref.fk.Name)
+		refColumn := TemporaryName(ref.column())
+
+		setColumns := make([]string, 0, len(ref.fk.OnDeleteSetColumns))
+		for _, c := range ref.fk.OnDeleteSetColumns {
+			if c == ref.column() {
+				c = refColumn
+			}
+			setColumns = append(setColumns, c)
+		}
+
+		dbActions = append(dbActions,
+			NewCreateFKConstraintAction(
+				conn,
+				ref.table.Name,
+				name,
+				[]string{refColumn},
+				&TableForeignKeyReference{
+					Table:              table.Name,
+					Columns:            []string{TemporaryName(column.Name)},
+					MatchType:          ForeignKeyMatchType(ref.fk.MatchType),
+					OnDelete:           ForeignKeyAction(ref.fk.OnDelete),
+					OnDeleteSetColumns: setColumns,
+					OnUpdate:           ForeignKeyAction(ref.fk.OnUpdate),
+				},
+				false,
+				false,
+				true,
+			),
+			NewValidateConstraintAction(conn, ref.table.Name, name),
+		)
+		ref.table.ForeignKeys[name] = &schema.ForeignKey{
+			Name:    name,
+			Columns: []string{refColumn},
+		}
+	}
+
+	dbActions = append(dbActions, NewDropFunctionAction(conn, o.triggerFunctionNames(table.Name, column.Name, refs)...))
+
+	// Replace the referencing columns, dropping the original foreign keys
+	// along with them, before the primary key column they depend on.
+	for _, ref := range refs {
+		dbActions = append(dbActions,
+			NewDropReplacedPoliciesAction(conn, ref.table, ref.column()),
+			NewDropColumnAction(conn, ref.table.Name, ref.column()),
+			NewRenameDuplicatedColumnAction(conn, ref.table, ref.column()),
+		)
+	}
+
+	// Replace the primary key column. Renaming the duplicated column turns the
+	// duplicated unique index into the primary key.
+	dbActions = append(dbActions,
+		NewAlterSequenceOwnerAction(conn, table.Name, column.Name, TemporaryName(column.Name)),
+		NewDropReplacedPoliciesAction(conn, table, column.Name),
+		NewDropColumnAction(conn, table.Name, column.Name),
+		NewRenameDuplicatedColumnAction(conn, table, column.Name),
+	)
+
+	// Widen the sequence of a serial column, so that it can generate values
+	// beyond the range of the old type
+	if column.Sequence != "" {
+		dbActions = append(dbActions, NewAlterSequenceTypeAction(conn, column.Sequence, o.Type))
+	}
+
+	dbActions = append(dbActions, NewDropColumnAction(conn, table.Name, backfill.CNeedsBackfillColumn))
+	for _, ref := range refs {
+		dbActions = append(dbActions, NewDropColumnAction(conn, ref.table.Name, backfill.CNeedsBackfillColumn))
+	}
+
+	return dbActions, nil
+}
+
+func (o *OpAlterPrimaryKeyType) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+	column := table.GetColumn(o.Column)
+	if column == nil {
+		return nil, ColumnDoesNotExistError{Table: o.Table, Name: o.Column}
+	}
+	refs := primaryKeyReferences(s, table.Name, o.Column)
+
+	dbActions := make([]DBAction, 0)
+	for _, ref := range refs {
+		dbActions = append(dbActions, dropDuplicatedPoliciesActions(conn, ref.table, ref.column())...)
+		dbActions = append(dbActions, NewDropColumnAction(conn, ref.table.Name, TemporaryName(ref.column())))
+	}
+	dbActions = append(dbActions, dropDuplicatedPoliciesActions(conn, table, o.Column)...)
+	dbActions = append(dbActions,
+		NewDropColumnAction(conn, table.Name, column.Name),
+		NewDropFunctionAction(conn, o.triggerFunctionNames(table.Name, o.Column, refs)...),
+		NewDropColumnAction(conn, table.Name, backfill.CNeedsBackfillColumn),
+	)
+	for _, ref := range refs {
+		dbActions = append(dbActions, NewDropColumnAction(conn, ref.table.Name, backfill.CNeedsBackfillColumn))
+	}
+
+	return dbActions, nil
+}
+
+func (o *OpAlterPrimaryKeyType) Validate(ctx context.Context, s *schema.Schema) error {
+	if o.Table == "" {
+		return FieldRequiredError{Name: "table"}
+	}
+	if o.Column == "" {
+		return FieldRequiredError{Name: "column"}
+	}
+	if o.Type == "" {
+		return FieldRequiredError{Name: "type"}
+	}
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return TableDoesNotExistError{Name: o.Table}
+	}
+	column := table.GetColumn(o.Column)
+	if column == nil {
+		return ColumnDoesNotExistError{Table: o.Table, Name: o.Column}
+	}
+
+	invalid := func(reason string, args ...any) error {
+		return InvalidPrimaryKeyTypeChangeError{Table: o.Table, Column: o.Column, Reason: fmt.Sprintf(reason, args...)}
+	}
+
+	size, isInteger := integerTypeSizes[column.Type]
+	newSize, ok := integerTypeSizes[strings.ToLower(o.Type)]
+	switch {
+	case !slices.Equal(table.PrimaryKey, []string{column.Name}):
+		return invalid("the column must be the only column of the primary key")
+	case !isInteger:
+		return invalid("the column must have an integer type, not %q", column.Type)
+	case column.Identity != "":
+		return invalid("identity columns are not supported, use set_identity to make the column a serial column first")
+	case !ok:
+		return invalid("the new type must be an integer type, not %q", o.Type)
+	case newSize <= size:
+		return invalid("the new type %q must be wider than the current type %q", o.Type, column.Type)
+	}
+
+	for name, t := range s.Tables {
+		for _, fk := range t.ForeignKeys {
+			if fk.ReferencedSchema != "" || fk.ReferencedTable != table.Name || !slices.Contains(fk.ReferencedColumns, column.Name) {
+				continue
+			}
+			if len(fk.Columns) != 1 {
+				return invalid("foreign key %q of table %q has more than one column", fk.Name, name)
+			}
+			refColumn := t.GetColumn(fk.Columns[0])
+			if refColumn == nil {
+				return ColumnDoesNotExistError{Table: name, Name: fk.Columns[0]}
+			}
+			if size, ok := integerTypeSizes[refColumn.Type]; !ok || size > newSize {
+				return invalid("column %q of table %q referencing it must have an integer type no wider than %q", refColumn.Name, name, o.Type)
+			}
+			if len(primaryKeyReferences(s, t.Name, fk.Columns[0])) > 0 {
+				return invalid("column %q of table %q referencing it is itself referenced by a foreign key", refColumn.Name, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// IsIsolated returns true as the operation changes columns of several tables,
+// which other operations in the migration can't take into account.
+func (o *OpAlterPrimaryKeyType) IsIsolated() bool {
+	return true
+}
+
+// duplicateColumn duplicates `column` of `table` with the new type, without
+// the constraints in `withoutConstraints`, and returns the triggers that keep
+// the column and its duplicate in sync. The duplicated column is added to the
+// virtual schema in place of the column.
+func (o *OpAlterPrimaryKeyType) duplicateColumn(ctx context.Context, conn db.DB, table *schema.Table, column *schema.Column, withoutConstraints ...string) ([]backfill.OperationTrigger, error) {
+	name := column.Name
+	d := NewColumnDuplicator(conn, table, column).WithType(name, o.Type)
+	for _, c := range withoutConstraints {
+		d = d.WithoutConstraint(c)
+	}
+	if err := d.Execute(ctx); err != nil {
+		return nil, fmt.Errorf("failed to duplicate column %q of table %q: %w", name, table.Name, err)
+	}
+
+	// Copy the columns of the table, so that the up trigger declares the
+	// column by its physical name
+	upColumns := maps.Clone(table.Columns)
+
+	table.AddColumn(name, &schema.Column{
+		Name:     TemporaryName(name),
+		Type:     o.Type,
+		Nullable: column.Nullable,
+	})
+
+	return []backfill.OperationTrigger{
+		{
+			Name:           backfill.TriggerName(table.Name, name),
+			Direction:      backfill.TriggerDirectionUp,
+			TableName:      table.Name,
+			Columns:        upColumns,
+			PhysicalColumn: TemporaryName(name),
+			SQL:            pq.QuoteIdentifier(name),
+		},
+		{
+			Name:           backfill.TriggerName(table.Name, TemporaryName(name)),
+			Direction:      backfill.TriggerDirectionDown,
+			TableName:      table.Name,
+			Columns:        table.Columns,
+			PhysicalColumn: name,
+			SQL:            pq.QuoteIdentifier(name),
+		},
+	}, nil
+}
+
+// triggerFunctionNames returns the names of the functions of the triggers
+// that keep the primary key column and the columns referencing it in sync
+// with their duplicates.
+func (o *OpAlterPrimaryKeyType) triggerFunctionNames(table, column string, refs []primaryKeyReference) []string {
+	names := []string{
+		backfill.TriggerFunctionName(table, column),
+		backfill.TriggerFunctionName(table, TemporaryName(column)),
+	}
+	for _, ref := range refs {
+		names = append(names,
+			backfill.TriggerFunctionName(ref.table.Name, ref.column()),
+			backfill.TriggerFunctionName(ref.table.Name, TemporaryName(ref.column())),
+		)
+	}
+	return names
+}
+
+// primaryKeyReferences returns the single column foreign keys that reference
+// `column` of `table`, ordered by the name of their table and their name.
+func primaryKeyReferences(s *schema.Schema, table, column string) []primaryKeyReference {
+	refs := make([]primaryKeyReference, 0)
+	for _, tableName := range slices.Sorted(maps.Keys(s.Tables)) {
+		t := s.Tables[tableName]
+		for _, fkName := range slices.Sorted(maps.Keys(t.ForeignKeys)) {
+			fk := t.ForeignKeys[fkName]
+			if fk.ReferencedSchema == "" && fk.ReferencedTable == table && len(fk.Columns) == 1 && slices.Equal(fk.ReferencedColumns, []string{column}) {
+				refs = append(refs, primaryKeyReference{table: t, fk: fk})
+			}
+		}
+	}
+	return refs
+}
@@ -0,0 +1,399 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/pgroll/internal/testutils"
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestAlterPrimaryKeyType(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "widen a primary key referenced by a foreign key",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_tables",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+						&migrations.OpCreateTable{
+							Name: "orders",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name: "user_id",
+									Type: "integer",
+									References: &migrations.ForeignKeyReference{
+										Name:   "fk_users_id",
+										Table:  "users",
+										Column: "id",
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_alter_primary_key_type",
+					Operations: migrations.Operations{
+						&migrations.OpAlterPrimaryKeyType{
+							Table:  "users",
+							Column: "id",
+							Type:   "bigint",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The columns are duplicated with the new type
+				ColumnMustHaveType(t, db, schema, "users", migrations.TemporaryName("id"), "bigint")
+				ColumnMustHaveType(t, db, schema, "orders", migrations.TemporaryName("user_id"), "bigint")
+
+				// Both versions of the schema can insert rows
+				MustInsert(t, db, schema, "01_create_tables", "users", map[string]string{"name": "alice"})
+				MustInsert(t, db, schema, "02_alter_primary_key_type", "users", map[string]string{"id": "3000000000", "name": "bob"})
+				MustInsert(t, db, schema, "01_create_tables", "orders", map[string]string{"user_id": "1"})
+				MustInsert(t, db, schema, "02_alter_primary_key_type", "orders", map[string]string{"user_id": "1"})
+
+				// The old version of the schema can't insert values beyond the range of the old type
+				MustNotInsert(t, db, schema, "01_create_tables", "users", map[string]string{"id": "3000000001", "name": "carol"}, testutils.NumericValueOutOfRangeErrorCode)
+
+				// The foreign key is still enforced
+				MustNotInsert(t, db, schema, "01_create_tables", "orders", map[string]string{"user_id": "100"}, testutils.FKViolationErrorCode)
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				ColumnMustHaveType(t, db, schema, "users", "id", "integer")
+				ColumnMustHaveType(t, db, schema, "orders", "user_id", "integer")
+
+				TableMustBeCleanedUp(t, db, schema, "users", "id")
+				TableMustBeCleanedUp(t, db, schema, "orders", "user_id")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				ColumnMustHaveType(t, db, schema, "users", "id", "bigint")
+				ColumnMustHaveType(t, db, schema, "orders", "user_id", "bigint")
+
+				TableMustBeCleanedUp(t, db, schema, "users", "id")
+				TableMustBeCleanedUp(t, db, schema, "orders", "user_id")
+
+				// The primary key and the foreign key are swapped to the new columns
+				PrimaryKeyConstraintMustExist(t, db, schema, "users", "users_pkey")
+				ValidatedForeignKeyMustExist(t, db, schema, "orders", "fk_users_id")
+				MustNotInsert(t, db, schema, "02_alter_primary_key_type", "orders", map[string]string{"user_id": "100"}, testutils.FKViolationErrorCode)
+
+				// The sequence of the serial column is widened too
+				MustInsert(t, db, schema, "02_alter_primary_key_type", "users", map[string]string{"name": "carol"})
+				MustInsert(t, db, schema, "02_alter_primary_key_type", "orders", map[string]string{"user_id": "3000000000"})
+
+				rows := MustSelect(t, db, schema, "02_alter_primary_key_type", "orders")
+				assert.Equal(t, []map[string]any{
+					{"id": 1, "user_id": 1},
+					{"id": 2, "user_id": 1},
+					{"id": 5, "user_id": 3000000000},
+				}, rows)
+			},
+		},
+	})
+}
+
+func TestAlterPrimaryKeyTypeValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "type is required",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_tables",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+						&migrations.OpCreateTable{
+							Name: "orders",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name: "user_id",
+									Type: "integer",
+									References: &migrations.ForeignKeyReference{
+										Name:   "fk_users_id",
+										Table:  "users",
+										Column: "id",
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_alter_primary_key_type",
+					Operations: migrations.Operations{
+						&migrations.OpAlterPrimaryKeyType{
+							Table:  "users",
+							Column: "id",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.FieldRequiredError{Name: "type"},
+		},
+		{
+			name: "column must be the primary key",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_tables",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+						&migrations.OpCreateTable{
+							Name: "orders",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name: "user_id",
+									Type: "integer",
+									References: &migrations.ForeignKeyReference{
+										Name:   "fk_users_id",
+										Table:  "users",
+										Column: "id",
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_alter_primary_key_type",
+					Operations: migrations.Operations{
+						&migrations.OpAlterPrimaryKeyType{
+							Table:  "orders",
+							Column: "user_id",
+							Type:   "bigint",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidPrimaryKeyTypeChangeError{Table: "orders", Column: "user_id", Reason: "the column must be the only column of the primary key"},
+		},
+		{
+			name: "new type must be an integer type",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_tables",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+						&migrations.OpCreateTable{
+							Name: "orders",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name: "user_id",
+									Type: "integer",
+									References: &migrations.ForeignKeyReference{
+										Name:   "fk_users_id",
+										Table:  "users",
+										Column: "id",
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_alter_primary_key_type",
+					Operations: migrations.Operations{
+						&migrations.OpAlterPrimaryKeyType{
+							Table:  "users",
+							Column: "id",
+							Type:   "text",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidPrimaryKeyTypeChangeError{Table: "users", Column: "id", Reason: `the new type must be an integer type, not "text"`},
+		},
+		{
+			name: "new type must be wider",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_tables",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+						&migrations.OpCreateTable{
+							Name: "orders",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name: "user_id",
+									Type: "integer",
+									References: &migrations.ForeignKeyReference{
+										Name:   "fk_users_id",
+										Table:  "users",
+										Column: "id",
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_alter_primary_key_type",
+					Operations: migrations.Operations{
+						&migrations.OpAlterPrimaryKeyType{
+							Table:  "users",
+							Column: "id",
+							Type:   "smallint",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidPrimaryKeyTypeChangeError{Table: "users", Column: "id", Reason: `the new type "smallint" must be wider than the current type "integer"`},
+		},
+		{
+			name: "operation must be the only operation in the migration",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_tables",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+						&migrations.OpCreateTable{
+							Name: "orders",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name: "user_id",
+									Type: "integer",
+									References: &migrations.ForeignKeyReference{
+										Name:   "fk_users_id",
+										Table:  "users",
+										Column: "id",
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_alter_primary_key_type",
+					Operations: migrations.Operations{
+						&migrations.OpAlterPrimaryKeyType{Table: "users", Column: "id", Type: "bigint"},
+						&migrations.OpAlterPrimaryKeyType{Table: "orders", Column: "id", Type: "bigint"},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidMigrationError{Reason: `operation "alter_primary_key_type" cannot be executed with other operations`},
+		},
+	})
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation  = (*OpAlterSequence)(nil)
+	_ Createable = (*OpAlterSequence)(nil)
+)
+
+func (o *OpAlterSequence) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	if s.GetSequence(o.Name) == nil {
+		return nil, SequenceDoesNotExistError{Name: o.Name}
+	}
+
+	// Sequences are shared by both versions of the schema, so the sequence is
+	// altered as soon as the migration starts
+	return &StartResult{Actions: []DBAction{
+		NewAlterSequenceAction(conn, o.Name, int64Ptr(o.Increment), int64Ptr(o.Cache), int64Ptr(o.Restart)),
+	}}, nil
+}
+
+func (o *OpAlterSequence) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	// No-op
+	return nil, nil
+}
+
+func (o *OpAlterSequence) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	seq := s.GetSequence(o.Name)
+	if seq == nil {
+		return nil, SequenceDoesNotExistError{Name: o.Name}
+	}
+
+	// Restore the increment and cache of the sequence. Values that the
+	// sequence generated after a restart are not undone.
+	var increment, cache *int64
+	if o.Increment != nil {
+		increment = &seq.Increment
+	}
+	if o.Cache != nil {
+		cache = &seq.Cache
+	}
+	if increment == nil && cache == nil {
+		return nil, nil
+	}
+
+	return []DBAction{NewAlterSequenceAction(conn, o.Name, increment, cache, nil)}, nil
+}
+
+func (o *OpAlterSequence) Validate(ctx context.Context, s *schema.Schema) error {
+	if o.Name == "" {
+		return FieldRequiredError{Name: "name"}
+	}
+
+	seq := s.GetSequence(o.Name)
+	if seq == nil {
+		return SequenceDoesNotExistError{Name: o.Name}
+	}
+
+	if o.Increment == nil && o.Cache == nil && o.Restart == nil {
+		return AlterSequenceNoChangesError{Name: o.Name}
+	}
+
+	altered := *seq
+	if o.Increment != nil {
+		altered.Increment = int64(*o.Increment)
+	}
+	if o.Cache != nil {
+		altered.Cache = int64(*o.Cache)
+	}
+	if o.Restart != nil {
+		altered.Start = int64(*o.Restart)
+	}
+	if err := validateSequence(&altered); err != nil {
+		return err
+	}
+
+	// The definition of the sequence in the schema is left unchanged so that
+	// the operation can restore it on rollback
+	return nil
+}
+
+// int64Ptr converts an optional int to an optional int64
+func int64Ptr(i *int) *int64 {
+	if i == nil {
+		return nil
+	}
+	v := int64(*i)
+	return &v
+}
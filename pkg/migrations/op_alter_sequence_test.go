@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestAlterSequence(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "alter sequence",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_sequence",
+					Operations: migrations.Operations{
+						&migrations.OpCreateSequence{
+							Name:       "invoice_numbers",
+							StartValue: ptr(1000),
+							Increment:  ptr(10),
+						},
+					},
+				},
+				{
+					Name: "02_alter_sequence",
+					Operations: migrations.Operations{
+						&migrations.OpAlterSequence{
+							Name:      "invoice_numbers",
+							Increment: ptr(100),
+							Cache:     ptr(20),
+							Restart:   ptr(5000),
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				SequenceMustHaveIncrement(t, db, schema, "invoice_numbers", 100)
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				// The previous increment is restored
+				SequenceMustHaveIncrement(t, db, schema, "invoice_numbers", 10)
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				SequenceMustHaveIncrement(t, db, schema, "invoice_numbers", 100)
+			},
+		},
+	})
+}
+
+func TestAlterSequenceValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "sequence must exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_alter_sequence",
+					Operations: migrations.Operations{
+						&migrations.OpAlterSequence{
+							Name:      "invoice_numbers",
+							Increment: ptr(100),
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.SequenceDoesNotExistError{Name: "invoice_numbers"},
+		},
+		{
+			name: "at least one change is required",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_sequence",
+					Operations: migrations.Operations{
+						&migrations.OpCreateSequence{
+							Name:       "invoice_numbers",
+							StartValue: ptr(1000),
+							Increment:  ptr(10),
+						},
+					},
+				},
+				{
+					Name: "02_alter_sequence",
+					Operations: migrations.Operations{
+						&migrations.OpAlterSequence{
+							Name: "invoice_numbers",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.AlterSequenceNoChangesError{Name: "invoice_numbers"},
+		},
+		{
+			name: "increment must not be zero",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_sequence",
+					Operations: migrations.Operations{
+						&migrations.OpCreateSequence{
+							Name:       "invoice_numbers",
+							StartValue: ptr(1000),
+							Increment:  ptr(10),
+						},
+					},
+				},
+				{
+					Name: "02_alter_sequence",
+					Operations: migrations.Operations{
+						&migrations.OpAlterSequence{
+							Name:      "invoice_numbers",
+							Increment: ptr(0),
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidSequenceError{Name: "invoice_numbers", Reason: "increment must not be zero"},
+		},
+	})
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation  = (*OpAttachPartition)(nil)
+	_ Createable = (*OpAttachPartition)(nil)
+)
+
+func (o *OpAttachPartition) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+
+	var action DBAction
+	if existing := s.GetTable(o.Partition); existing != nil {
+		// An existing table becomes a partition of the table and remains
+		// visible as a table in the new version of the schema
+		action = NewAttachPartitionAction(conn, table.Name, existing.Name, o.Bound)
+		existing.PartitionOf = table.Name
+	} else {
+		action = NewCreatePartitionAction(conn, table.Name, o.Partition, o.Bound)
+	}
+	table.AddPartition(&schema.Partition{Name: o.Partition, Bound: o.Bound})
+
+	return &StartResult{Actions: []DBAction{action}}, nil
+}
+
+func (o *OpAttachPartition) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	// No-op
+	return nil, nil
+}
+
+func (o *OpAttachPartition) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+	table.RemovePartition(o.Partition)
+
+	// A table that was attached as a partition is detached again rather than
+	// dropped
+	if existing := s.GetTable(o.Partition); existing != nil && existing.PartitionOf == table.Name {
+		existing.PartitionOf = ""
+		return []DBAction{NewDetachPartitionAction(conn, table.Name, existing.Name)}, nil
+	}
+
+	return []DBAction{NewDropTableAction(conn, o.Partition)}, nil
+}
+
+func (o *OpAttachPartition) Validate(ctx context.Context, s *schema.Schema) error {
+	if o.Table == "" {
+		return FieldRequiredError{Name: "table"}
+	}
+	if o.Partition == "" {
+		return FieldRequiredError{Name: "partition"}
+	}
+	if o.Bound == "" {
+		return FieldRequiredError{Name: "bound"}
+	}
+	if err := ValidateIdentifierLength(o.Partition); err != nil {
+		return err
+	}
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return TableDoesNotExistError{Name: o.Table}
+	}
+	if table.Partitioning == nil {
+		return TableIsNotPartitionedError{Name: o.Table}
+	}
+	if table.GetPartition(o.Partition) != nil {
+		return PartitionAlreadyExistsError{Table: o.Table, Name: o.Partition}
+	}
+
+	if existing := s.GetTable(o.Partition); existing != nil {
+		if existing.PartitionOf != "" {
+			return PartitionAlreadyExistsError{Table: existing.PartitionOf, Name: o.Partition}
+		}
+		existing.PartitionOf = table.Name
+	}
+	table.AddPartition(&schema.Partition{Name: o.Partition, Bound: o.Bound})
+
+	return nil
+}
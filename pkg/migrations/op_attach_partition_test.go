@@ -0,0 +1,378 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestAttachPartition(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "attach a new partition",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "events",
+							Columns: []migrations.Column{
+								{Name: "id", Type: "integer"},
+								{Name: "created_at", Type: "date"},
+							},
+							Constraints: []migrations.Constraint{
+								{
+									Name:    "events_pkey",
+									Type:    migrations.ConstraintTypePrimaryKey,
+									Columns: []string{"id", "created_at"},
+								},
+							},
+							PartitionBy: &migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyRange,
+								Columns:  []string{"created_at"},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_attach_partition",
+					Operations: migrations.Operations{
+						&migrations.OpAttachPartition{
+							Table:     "events",
+							Partition: "events_2024",
+							Bound:     "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The partition has been created
+				PartitionMustExist(t, db, schema, "events", "events_2024")
+
+				// Rows in the range of the partition can be inserted through either
+				// version of the schema
+				MustInsert(t, db, schema, "01_create_table", "events", map[string]string{
+					"id":         "1",
+					"created_at": "2024-03-01",
+				})
+				MustInsert(t, db, schema, "02_attach_partition", "events", map[string]string{
+					"id":         "2",
+					"created_at": "2024-04-01",
+				})
+
+				// The partition is not exposed as a table
+				ViewMustNotExist(t, db, schema, "02_attach_partition", "events_2024")
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				// The partition has been dropped
+				PartitionMustNotExist(t, db, schema, "events", "events_2024")
+				TableMustNotExist(t, db, schema, "events_2024")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				PartitionMustExist(t, db, schema, "events", "events_2024")
+
+				MustInsert(t, db, schema, "02_attach_partition", "events", map[string]string{
+					"id":         "3",
+					"created_at": "2024-05-01",
+				})
+				// The partition recreated after the rollback holds only the new row
+				rows := MustSelect(t, db, schema, "02_attach_partition", "events")
+				assert.Len(t, rows, 1)
+			},
+		},
+		{
+			name: "attach an existing table as a partition",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "events",
+							Columns: []migrations.Column{
+								{Name: "id", Type: "integer"},
+								{Name: "created_at", Type: "date"},
+							},
+							Constraints: []migrations.Constraint{
+								{
+									Name:    "events_pkey",
+									Type:    migrations.ConstraintTypePrimaryKey,
+									Columns: []string{"id", "created_at"},
+								},
+							},
+							PartitionBy: &migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyRange,
+								Columns:  []string{"created_at"},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "events_2023",
+							Columns: []migrations.Column{
+								{Name: "id", Type: "integer"},
+								{Name: "created_at", Type: "date"},
+							},
+							Constraints: []migrations.Constraint{
+								{
+									Name:    "events_2023_pkey",
+									Type:    migrations.ConstraintTypePrimaryKey,
+									Columns: []string{"id", "created_at"},
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "03_attach_partition",
+					Operations: migrations.Operations{
+						&migrations.OpAttachPartition{
+							Table:     "events",
+							Partition: "events_2023",
+							Bound:     "FOR VALUES FROM ('2023-01-01') TO ('2024-01-01')",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The table has been attached as a partition
+				PartitionMustExist(t, db, schema, "events", "events_2023")
+
+				// The table remains visible in both versions of the schema
+				ViewMustExist(t, db, schema, "02_create_table", "events_2023")
+				ViewMustExist(t, db, schema, "03_attach_partition", "events_2023")
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				// The table has been detached again
+				PartitionMustNotExist(t, db, schema, "events", "events_2023")
+				TableMustExist(t, db, schema, "events_2023")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				PartitionMustExist(t, db, schema, "events", "events_2023")
+				ViewMustExist(t, db, schema, "03_attach_partition", "events_2023")
+			},
+		},
+	})
+}
+
+func TestAttachPartitionValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "table must exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_attach_partition",
+					Operations: migrations.Operations{
+						&migrations.OpAttachPartition{
+							Table:     "events",
+							Partition: "events_2024",
+							Bound:     "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.TableDoesNotExistError{Name: "events"},
+		},
+		{
+			name: "table must be partitioned",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_attach_partition",
+					Operations: migrations.Operations{
+						&migrations.OpAttachPartition{
+							Table:     "users",
+							Partition: "users_1",
+							Bound:     "FOR VALUES FROM (1) TO (10)",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.TableIsNotPartitionedError{Name: "users"},
+		},
+		{
+			name: "partition must not already exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "events",
+							Columns: []migrations.Column{
+								{Name: "id", Type: "integer"},
+								{Name: "created_at", Type: "date"},
+							},
+							Constraints: []migrations.Constraint{
+								{
+									Name:    "events_pkey",
+									Type:    migrations.ConstraintTypePrimaryKey,
+									Columns: []string{"id", "created_at"},
+								},
+							},
+							PartitionBy: &migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyRange,
+								Columns:  []string{"created_at"},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_attach_partition",
+					Operations: migrations.Operations{
+						&migrations.OpAttachPartition{
+							Table:     "events",
+							Partition: "events_2024",
+							Bound:     "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')",
+						},
+					},
+				},
+				{
+					Name: "03_attach_partition",
+					Operations: migrations.Operations{
+						&migrations.OpAttachPartition{
+							Table:     "events",
+							Partition: "events_2024",
+							Bound:     "FOR VALUES FROM ('2025-01-01') TO ('2026-01-01')",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.PartitionAlreadyExistsError{Table: "events", Name: "events_2024"},
+		},
+		{
+			name: "table must not be a partition of another table",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_tables",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "events",
+							Columns: []migrations.Column{
+								{Name: "id", Type: "integer"},
+								{Name: "created_at", Type: "date"},
+							},
+							Constraints: []migrations.Constraint{
+								{
+									Name:    "events_pkey",
+									Type:    migrations.ConstraintTypePrimaryKey,
+									Columns: []string{"id", "created_at"},
+								},
+							},
+							PartitionBy: &migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyRange,
+								Columns:  []string{"created_at"},
+							},
+						},
+						&migrations.OpCreateTable{
+							Name: "archived_events",
+							Columns: []migrations.Column{
+								{Name: "id", Type: "integer"},
+								{Name: "created_at", Type: "date"},
+							},
+							Constraints: []migrations.Constraint{
+								{
+									Name:    "archived_events_pkey",
+									Type:    migrations.ConstraintTypePrimaryKey,
+									Columns: []string{"id", "created_at"},
+								},
+							},
+							PartitionBy: &migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyRange,
+								Columns:  []string{"created_at"},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_attach_partition",
+					Operations: migrations.Operations{
+						&migrations.OpAttachPartition{
+							Table:     "events",
+							Partition: "events_2024",
+							Bound:     "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')",
+						},
+					},
+				},
+				{
+					Name: "03_attach_partition",
+					Operations: migrations.Operations{
+						&migrations.OpAttachPartition{
+							Table:     "archived_events",
+							Partition: "events_2024",
+							Bound:     "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.PartitionAlreadyExistsError{Table: "events", Name: "events_2024"},
+		},
+		{
+			name: "bound is required",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "events",
+							Columns: []migrations.Column{
+								{Name: "id", Type: "integer"},
+								{Name: "created_at", Type: "date"},
+							},
+							Constraints: []migrations.Constraint{
+								{
+									Name:    "events_pkey",
+									Type:    migrations.ConstraintTypePrimaryKey,
+									Columns: []string{"id", "created_at"},
+								},
+							},
+							PartitionBy: &migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyRange,
+								Columns:  []string{"created_at"},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_attach_partition",
+					Operations: migrations.Operations{
+						&migrations.OpAttachPartition{
+							Table:     "events",
+							Partition: "events_2024",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.FieldRequiredError{Name: "bound"},
+		},
+	})
+}
@@ -34,6 +34,28 @@ const (
 	OpNameDropMultiColumnConstraint OpName = "drop_multicolumn_constraint"
 	OpRawSQLName                    OpName = "sql"
 	OpCreateConstraintName          OpName = "create_constraint"
+	OpNameCreateEnum                OpName = "create_enum"
+	OpNameAlterEnum                 OpName = "alter_enum"
+	OpNameDropEnum                  OpName = "drop_enum"
+	OpNameCreateView                OpName = "create_view"
+	OpNameReplaceView               OpName = "replace_view"
+	OpNameDropView                  OpName = "drop_view"
+	OpNameAttachPartition           OpName = "attach_partition"
+	OpNameDetachPartition           OpName = "detach_partition"
+	OpNamePartitionTable            OpName = "partition_table"
+	OpNameEnableRLS                 OpName = "enable_rls"
+	OpNameCreatePolicy              OpName = "create_policy"
+	OpNameAlterPolicy               OpName = "alter_policy"
+	OpNameDropPolicy                OpName = "drop_policy"
+	OpNameGrant                     OpName = "grant"
+	OpNameRevoke                    OpName = "revoke"
+	OpNameCreateSequence            OpName = "create_sequence"
+	OpNameAlterSequence             OpName = "alter_sequence"
+	OpNameDropSequence              OpName = "drop_sequence"
+	OpNameSetIdentity               OpName = "set_identity"
+	OpNameAlterPrimaryKeyType       OpName = "alter_primary_key_type"
+	OpNameRewriteTable              OpName = "rewrite_table"
+	OpNameMoveTable                 OpName = "move_table"
 )
 
 // AllNonDeprecatedOperations contains the list of operations
@@ -53,6 +75,28 @@ var AllNonDeprecatedOperations = []string{
 	string(OpNameDropMultiColumnConstraint),
 	string(OpRawSQLName),
 	string(OpCreateConstraintName),
+	string(OpNameCreateEnum),
+	string(OpNameAlterEnum),
+	string(OpNameDropEnum),
+	string(OpNameCreateView),
+	string(OpNameReplaceView),
+	string(OpNameDropView),
+	string(OpNameAttachPartition),
+	string(OpNameDetachPartition),
+	string(OpNamePartitionTable),
+	string(OpNameEnableRLS),
+	string(OpNameCreatePolicy),
+	string(OpNameAlterPolicy),
+	string(OpNameDropPolicy),
+	string(OpNameGrant),
+	string(OpNameRevoke),
+	string(OpNameCreateSequence),
+	string(OpNameAlterSequence),
+	string(OpNameDropSequence),
+	string(OpNameSetIdentity),
+	string(OpNameAlterPrimaryKeyType),
+	string(OpNameRewriteTable),
+	string(OpNameMoveTable),
 }
 
 const (
@@ -126,15 +170,28 @@ func ReadRawMigration(dir fs.FS, filename string) (*RawMigration, error) {
 
 // ParseMigration converts a RawMigration to a fully parsed Migration
 func ParseMigration(raw *RawMigration) (*Migration, error) {
+	rawOps, schemas, err := splitOperationSchemas(raw.Operations)
+	if err != nil {
+		return nil, fmt.Errorf("parsing operations: %w", err)
+	}
+	rawOps, backfills, err := splitOperationBackfills(rawOps)
+	if err != nil {
+		return nil, fmt.Errorf("parsing operations: %w", err)
+	}
+
 	var ops Operations
-	if err := json.Unmarshal(raw.Operations, &ops); err != nil {
+	if err := json.Unmarshal(rawOps, &ops); err != nil {
 		return nil, fmt.Errorf("parsing operations: %w", err)
 	}
 
 	return &Migration{
-		Name:          raw.Name,
-		VersionSchema: raw.VersionSchema,
-		Operations:    ops,
+		Name:               raw.Name,
+		VersionSchema:      raw.VersionSchema,
+		Operations:         ops,
+		OperationSchemas:   schemas,
+		Backfill:           raw.Backfill,
+		OperationBackfills: backfills,
+		Squashes:           raw.Squashes,
 	}, nil
 }
 
@@ -266,6 +323,72 @@ func OperationName(op Operation) OpName {
 	case *OpDropMultiColumnConstraint:
 		return OpNameDropMultiColumnConstraint
 
+	case *OpCreateEnum:
+		return OpNameCreateEnum
+
+	case *OpAlterEnum:
+		return OpNameAlterEnum
+
+	case *OpDropEnum:
+		return OpNameDropEnum
+
+	case *OpCreateView:
+		return OpNameCreateView
+
+	case *OpReplaceView:
+		return OpNameReplaceView
+
+	case *OpDropView:
+		return OpNameDropView
+
+	case *OpAttachPartition:
+		return OpNameAttachPartition
+
+	case *OpDetachPartition:
+		return OpNameDetachPartition
+
+	case *OpPartitionTable:
+		return OpNamePartitionTable
+
+	case *OpEnableRLS:
+		return OpNameEnableRLS
+
+	case *OpCreatePolicy:
+		return OpNameCreatePolicy
+
+	case *OpAlterPolicy:
+		return OpNameAlterPolicy
+
+	case *OpDropPolicy:
+		return OpNameDropPolicy
+
+	case *OpGrant:
+		return OpNameGrant
+
+	case *OpRevoke:
+		return OpNameRevoke
+
+	case *OpCreateSequence:
+		return OpNameCreateSequence
+
+	case *OpAlterSequence:
+		return OpNameAlterSequence
+
+	case *OpDropSequence:
+		return OpNameDropSequence
+
+	case *OpSetIdentity:
+		return OpNameSetIdentity
+
+	case *OpAlterPrimaryKeyType:
+		return OpNameAlterPrimaryKeyType
+
+	case *OpRewriteTable:
+		return OpNameRewriteTable
+
+	case *OpMoveTable:
+		return OpNameMoveTable
+
 	}
 
 	panic(fmt.Errorf("unknown operation for %T", op))
@@ -318,6 +441,72 @@ func OperationFromName(name OpName) (Operation, error) {
 	case OpNameDropMultiColumnConstraint:
 		return &OpDropMultiColumnConstraint{}, nil
 
+	case OpNameCreateEnum:
+		return &OpCreateEnum{}, nil
+
+	case OpNameAlterEnum:
+		return &OpAlterEnum{}, nil
+
+	case OpNameDropEnum:
+		return &OpDropEnum{}, nil
+
+	case OpNameCreateView:
+		return &OpCreateView{}, nil
+
+	case OpNameReplaceView:
+		return &OpReplaceView{}, nil
+
+	case OpNameDropView:
+		return &OpDropView{}, nil
+
+	case OpNameAttachPartition:
+		return &OpAttachPartition{}, nil
+
+	case OpNameDetachPartition:
+		return &OpDetachPartition{}, nil
+
+	case OpNamePartitionTable:
+		return &OpPartitionTable{}, nil
+
+	case OpNameEnableRLS:
+		return &OpEnableRLS{}, nil
+
+	case OpNameCreatePolicy:
+		return &OpCreatePolicy{}, nil
+
+	case OpNameAlterPolicy:
+		return &OpAlterPolicy{}, nil
+
+	case OpNameDropPolicy:
+		return &OpDropPolicy{}, nil
+
+	case OpNameGrant:
+		return &OpGrant{}, nil
+
+	case OpNameRevoke:
+		return &OpRevoke{}, nil
+
+	case OpNameCreateSequence:
+		return &OpCreateSequence{}, nil
+
+	case OpNameAlterSequence:
+		return &OpAlterSequence{}, nil
+
+	case OpNameDropSequence:
+		return &OpDropSequence{}, nil
+
+	case OpNameSetIdentity:
+		return &OpSetIdentity{}, nil
+
+	case OpNameAlterPrimaryKeyType:
+		return &OpAlterPrimaryKeyType{}, nil
+
+	case OpNameRewriteTable:
+		return &OpRewriteTable{}, nil
+
+	case OpNameMoveTable:
+		return &OpMoveTable{}, nil
+
 	}
 	return nil, fmt.Errorf("unknown migration type: %v", name)
 }
@@ -141,6 +141,48 @@ func ViewMustNotExist(t *testing.T, db *sql.DB, schema, version, view string) {
 	}
 }
 
+func SchemaViewMustExist(t *testing.T, db *sql.DB, schema, view string) {
+	t.Helper()
+
+	var exists bool
+	err := db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1
+			FROM pg_catalog.pg_views
+			WHERE schemaname = $1
+			AND viewname = $2
+		)`,
+		schema, view).Scan(&exists)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !exists {
+		t.Fatalf("Expected view %q to exist in schema %q", view, schema)
+	}
+}
+
+func SchemaViewMustNotExist(t *testing.T, db *sql.DB, schema, view string) {
+	t.Helper()
+
+	var exists bool
+	err := db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1
+			FROM pg_catalog.pg_views
+			WHERE schemaname = $1
+			AND viewname = $2
+		)`,
+		schema, view).Scan(&exists)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exists {
+		t.Fatalf("Expected view %q to not exist in schema %q", view, schema)
+	}
+}
+
 func TableMustExist(t *testing.T, db *sql.DB, schema, table string) {
 	t.Helper()
 	if !tableExists(t, db, schema, table) {
@@ -351,6 +393,501 @@ func ReplicaIdentityMustBe(t *testing.T, db *sql.DB, schema, table, replicaIdent
 	}
 }
 
+func EnumMustExist(t *testing.T, db *sql.DB, schema, enum string) {
+	t.Helper()
+
+	var exists bool
+	err := db.QueryRow(`
+    SELECT EXISTS (
+      SELECT 1
+      FROM pg_catalog.pg_type
+      WHERE typnamespace = $1::regnamespace
+      AND typname = $2
+      AND typtype = 'e'
+    )`,
+		schema, enum).Scan(&exists)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !exists {
+		t.Fatalf("Expected enum type %q to exist", enum)
+	}
+}
+
+func EnumMustNotExist(t *testing.T, db *sql.DB, schema, enum string) {
+	t.Helper()
+
+	var exists bool
+	err := db.QueryRow(`
+    SELECT EXISTS (
+      SELECT 1
+      FROM pg_catalog.pg_type
+      WHERE typnamespace = $1::regnamespace
+      AND typname = $2
+      AND typtype = 'e'
+    )`,
+		schema, enum).Scan(&exists)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exists {
+		t.Fatalf("Expected enum type %q to not exist", enum)
+	}
+}
+
+func EnumMustHaveValues(t *testing.T, db *sql.DB, schema, enum string, values []string) {
+	t.Helper()
+
+	var actual pq.StringArray
+	err := db.QueryRow(`
+    SELECT array_agg(e.enumlabel ORDER BY e.enumsortorder)
+    FROM pg_catalog.pg_enum e
+    JOIN pg_catalog.pg_type t ON t.oid = e.enumtypid
+    WHERE t.typnamespace = $1::regnamespace
+    AND t.typname = $2`,
+		schema, enum).Scan(&actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(values, actual) {
+		t.Fatalf("Expected enum type %q to have values %q, got %q", enum, values, actual)
+	}
+}
+
+func TableMustBePartitioned(t *testing.T, db *sql.DB, schema, table string) {
+	t.Helper()
+
+	var partitioned bool
+	err := db.QueryRow(`
+    SELECT EXISTS (
+      SELECT 1
+      FROM pg_catalog.pg_partitioned_table
+      WHERE partrelid = $1::regclass
+    )`,
+		fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table))).Scan(&partitioned)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !partitioned {
+		t.Fatalf("Expected table %q to be partitioned", table)
+	}
+}
+
+func TableMustNotBePartitioned(t *testing.T, db *sql.DB, schema, table string) {
+	t.Helper()
+
+	var partitioned bool
+	err := db.QueryRow(`
+    SELECT EXISTS (
+      SELECT 1
+      FROM pg_catalog.pg_partitioned_table
+      WHERE partrelid = $1::regclass
+    )`,
+		fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table))).Scan(&partitioned)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if partitioned {
+		t.Fatalf("Expected table %q to not be partitioned", table)
+	}
+}
+
+func TableMustHaveStorageParameter(t *testing.T, db *sql.DB, schema, table, parameter string) {
+	t.Helper()
+
+	var exists bool
+	err := db.QueryRow(`
+    SELECT $2 = ANY(coalesce(reloptions, '{}'))
+    FROM pg_catalog.pg_class
+    WHERE oid = $1::regclass`,
+		fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table)), parameter).Scan(&exists)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !exists {
+		t.Fatalf("Expected table %q to have storage parameter %q", table, parameter)
+	}
+}
+
+func PartitionMustExist(t *testing.T, db *sql.DB, schema, table, partition string) {
+	t.Helper()
+
+	var exists bool
+	err := db.QueryRow(`
+    SELECT EXISTS (
+      SELECT 1
+      FROM pg_catalog.pg_inherits i
+      JOIN pg_catalog.pg_class c ON c.oid = i.inhrelid
+      WHERE i.inhparent = $1::regclass
+      AND c.relname = $2
+      AND c.relispartition
+    )`,
+		fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table)), partition).Scan(&exists)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !exists {
+		t.Fatalf("Expected table %q to have partition %q", table, partition)
+	}
+}
+
+func PartitionMustNotExist(t *testing.T, db *sql.DB, schema, table, partition string) {
+	t.Helper()
+
+	var exists bool
+	err := db.QueryRow(`
+    SELECT EXISTS (
+      SELECT 1
+      FROM pg_catalog.pg_inherits i
+      JOIN pg_catalog.pg_class c ON c.oid = i.inhrelid
+      WHERE i.inhparent = $1::regclass
+      AND c.relname = $2
+      AND c.relispartition
+    )`,
+		fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table)), partition).Scan(&exists)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exists {
+		t.Fatalf("Expected table %q to not have partition %q", table, partition)
+	}
+}
+
+func RowLevelSecurityMustBeEnabled(t *testing.T, db *sql.DB, schema, table string, force bool) {
+	t.Helper()
+
+	var enabled, forced bool
+	err := db.QueryRow(`
+    SELECT relrowsecurity, relforcerowsecurity
+    FROM pg_catalog.pg_class
+    WHERE oid = $1::regclass`,
+		fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table))).Scan(&enabled, &forced)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !enabled || forced != force {
+		t.Fatalf("Expected row level security to be enabled on table %q with force %t, got enabled %t with force %t", table, force, enabled, forced)
+	}
+}
+
+func RowLevelSecurityMustBeDisabled(t *testing.T, db *sql.DB, schema, table string) {
+	t.Helper()
+
+	var enabled, forced bool
+	err := db.QueryRow(`
+    SELECT relrowsecurity, relforcerowsecurity
+    FROM pg_catalog.pg_class
+    WHERE oid = $1::regclass`,
+		fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table))).Scan(&enabled, &forced)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if enabled || forced {
+		t.Fatalf("Expected row level security to be disabled on table %q", table)
+	}
+}
+
+func PolicyMustExist(t *testing.T, db *sql.DB, schema, table, policy string) {
+	t.Helper()
+
+	var exists bool
+	err := db.QueryRow(`
+    SELECT EXISTS (
+      SELECT 1
+      FROM pg_catalog.pg_policy
+      WHERE polrelid = $1::regclass
+      AND polname = $2
+    )`,
+		fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table)), policy).Scan(&exists)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !exists {
+		t.Fatalf("Expected policy %q on table %q to exist", policy, table)
+	}
+}
+
+func PolicyMustNotExist(t *testing.T, db *sql.DB, schema, table, policy string) {
+	t.Helper()
+
+	var exists bool
+	err := db.QueryRow(`
+    SELECT EXISTS (
+      SELECT 1
+      FROM pg_catalog.pg_policy
+      WHERE polrelid = $1::regclass
+      AND polname = $2
+    )`,
+		fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table)), policy).Scan(&exists)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exists {
+		t.Fatalf("Expected policy %q on table %q to not exist", policy, table)
+	}
+}
+
+func PolicyMustHaveUsing(t *testing.T, db *sql.DB, schema, table, policy, expectedUsing string) {
+	t.Helper()
+
+	var using string
+	err := db.QueryRow(`
+    SELECT COALESCE(pg_get_expr(polqual, polrelid), '')
+    FROM pg_catalog.pg_policy
+    WHERE polrelid = $1::regclass
+    AND polname = $2`,
+		fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table)), policy).Scan(&using)
+	if errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Expected policy %q on table %q to exist", policy, table)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if using != expectedUsing {
+		t.Fatalf("Expected policy %q on table %q to have using expression %q, got %q", policy, table, expectedUsing, using)
+	}
+}
+
+func TablePrivilegeMustBeGranted(t *testing.T, db *sql.DB, schema, table, role, privilege string) {
+	t.Helper()
+
+	var granted bool
+	err := db.QueryRow("SELECT has_table_privilege($1, $2, $3)",
+		role,
+		fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table)),
+		privilege).Scan(&granted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !granted {
+		t.Fatalf("Expected role %q to have privilege %q on table %q", role, privilege, table)
+	}
+}
+
+func TablePrivilegeMustNotBeGranted(t *testing.T, db *sql.DB, schema, table, role, privilege string) {
+	t.Helper()
+
+	var granted bool
+	err := db.QueryRow("SELECT has_table_privilege($1, $2, $3)",
+		role,
+		fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table)),
+		privilege).Scan(&granted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if granted {
+		t.Fatalf("Expected role %q to not have privilege %q on table %q", role, privilege, table)
+	}
+}
+
+func ColumnPrivilegeMustBeGranted(t *testing.T, db *sql.DB, schema, table, column, role, privilege string) {
+	t.Helper()
+
+	var granted bool
+	err := db.QueryRow("SELECT has_column_privilege($1, $2, $3, $4)",
+		role,
+		fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table)),
+		column,
+		privilege).Scan(&granted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !granted {
+		t.Fatalf("Expected role %q to have privilege %q on column %q of table %q", role, privilege, column, table)
+	}
+}
+
+func TableOwnerMustBe(t *testing.T, db *sql.DB, schema, table, owner string) {
+	t.Helper()
+
+	var actualOwner string
+	err := db.QueryRow(`
+    SELECT pg_get_userbyid(c.relowner)
+    FROM pg_class c
+    JOIN pg_namespace n ON n.oid = c.relnamespace
+    WHERE n.nspname = $1
+    AND c.relname = $2;
+  `, schema, table).Scan(&actualOwner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if owner != actualOwner {
+		t.Fatalf("Expected table %q to be owned by %q, got %q", table, owner, actualOwner)
+	}
+}
+
+func TableMustBeEmpty(t *testing.T, db *sql.DB, schema, table string) {
+	t.Helper()
+
+	var count int
+	err := db.QueryRow(fmt.Sprintf("SELECT count(*) FROM %s.%s",
+		pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table))).Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 0 {
+		t.Fatalf("Expected table %q to be empty, got %d rows", table, count)
+	}
+}
+
+func SequenceMustExist(t *testing.T, db *sql.DB, schema, sequence string) {
+	t.Helper()
+
+	var exists bool
+	err := db.QueryRow(`
+    SELECT EXISTS (
+      SELECT 1
+      FROM pg_catalog.pg_sequences
+      WHERE schemaname = $1
+      AND sequencename = $2
+    )`,
+		schema, sequence).Scan(&exists)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !exists {
+		t.Fatalf("Expected sequence %q to exist", sequence)
+	}
+}
+
+func SequenceMustNotExist(t *testing.T, db *sql.DB, schema, sequence string) {
+	t.Helper()
+
+	var exists bool
+	err := db.QueryRow(`
+    SELECT EXISTS (
+      SELECT 1
+      FROM pg_catalog.pg_sequences
+      WHERE schemaname = $1
+      AND sequencename = $2
+    )`,
+		schema, sequence).Scan(&exists)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exists {
+		t.Fatalf("Expected sequence %q to not exist", sequence)
+	}
+}
+
+func SequenceMustHaveIncrement(t *testing.T, db *sql.DB, schema, sequence string, expectedIncrement int64) {
+	t.Helper()
+
+	var increment int64
+	err := db.QueryRow(`
+    SELECT increment_by
+    FROM pg_catalog.pg_sequences
+    WHERE schemaname = $1
+    AND sequencename = $2`,
+		schema, sequence).Scan(&increment)
+	if errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Expected sequence %q to exist", sequence)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if increment != expectedIncrement {
+		t.Fatalf("Expected sequence %q to have increment %d, got %d", sequence, expectedIncrement, increment)
+	}
+}
+
+func ColumnMustBeIdentity(t *testing.T, db *sql.DB, schema, table, column, expectedIdentity string) {
+	t.Helper()
+
+	var identity string
+	err := db.QueryRow(`
+    SELECT CASE attidentity WHEN 'a' THEN 'ALWAYS' WHEN 'd' THEN 'BY DEFAULT' ELSE '' END
+    FROM pg_catalog.pg_attribute
+    WHERE attrelid = $1::regclass
+    AND attname = $2`,
+		fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table)), column).Scan(&identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if identity != expectedIdentity {
+		t.Fatalf("Expected column %q on table %q to be generated %q as identity, got %q", column, table, expectedIdentity, identity)
+	}
+}
+
+func ColumnMustNotBeIdentity(t *testing.T, db *sql.DB, schema, table, column string) {
+	t.Helper()
+
+	var identity string
+	err := db.QueryRow(`
+    SELECT CASE attidentity WHEN 'a' THEN 'ALWAYS' WHEN 'd' THEN 'BY DEFAULT' ELSE '' END
+    FROM pg_catalog.pg_attribute
+    WHERE attrelid = $1::regclass
+    AND attname = $2`,
+		fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table)), column).Scan(&identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if identity != "" {
+		t.Fatalf("Expected column %q on table %q to not be an identity column, got %q", column, table, identity)
+	}
+}
+
+func ColumnMustBeGenerated(t *testing.T, db *sql.DB, schema, table, column string) {
+	t.Helper()
+
+	var generated bool
+	err := db.QueryRow(`
+    SELECT attgenerated = 's'
+    FROM pg_catalog.pg_attribute
+    WHERE attrelid = $1::regclass
+    AND attname = $2`,
+		fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table)), column).Scan(&generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !generated {
+		t.Fatalf("Expected column %q on table %q to be a generated column", column, table)
+	}
+}
+
+func ColumnMustNotBeGenerated(t *testing.T, db *sql.DB, schema, table, column string) {
+	t.Helper()
+
+	var generated bool
+	err := db.QueryRow(`
+    SELECT attgenerated = 's'
+    FROM pg_catalog.pg_attribute
+    WHERE attrelid = $1::regclass
+    AND attname = $2`,
+		fmt.Sprintf("%s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table)), column).Scan(&generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if generated {
+		t.Fatalf("Expected column %q on table %q to not be a generated column", column, table)
+	}
+}
+
 func indexExists(t *testing.T, db *sql.DB, schema, table, index string) bool {
 	t.Helper()
 
@@ -918,6 +1455,15 @@ func delete(t *testing.T, db *sql.DB, schema, version, table string, record map[
 	return err
 }
 
+func MustTruncate(t *testing.T, db *sql.DB, schema, table string) {
+	t.Helper()
+
+	_, err := db.Exec(fmt.Sprintf("TRUNCATE %s.%s", pq.QuoteIdentifier(schema), pq.QuoteIdentifier(table)))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func MustSelect(t *testing.T, db *sql.DB, schema, version, table string) []map[string]any {
 	t.Helper()
 	versionSchema := roll.VersionedSchemaName(schema, version)
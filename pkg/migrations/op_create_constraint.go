@@ -159,14 +159,18 @@ func (o *OpCreateConstraint) Complete(l Logger, conn db.DB, s *schema.Schema) ([
 		dbActions = append(dbActions, NewAlterSequenceOwnerAction(conn, o.Table, col, TemporaryName(col)))
 	}
 
-	dbActions = append(dbActions, NewDropColumnAction(conn, o.Table, o.Columns...))
-
 	// rename new columns to old name
 	table := s.GetTable(o.Table)
 	if table == nil {
 		return nil, TableDoesNotExistError{Name: o.Table}
 	}
 	table.Name = o.Table
+
+	for _, col := range o.Columns {
+		dbActions = append(dbActions, NewDropReplacedPoliciesAction(conn, table, col))
+	}
+	dbActions = append(dbActions, NewDropColumnAction(conn, o.Table, o.Columns...))
+
 	for _, col := range o.Columns {
 		column := table.GetColumn(col)
 		if column == nil {
@@ -191,11 +195,16 @@ func (o *OpCreateConstraint) Rollback(l Logger, conn db.DB, s *schema.Schema) ([
 		return nil, TableDoesNotExistError{Name: o.Table}
 	}
 
-	return []DBAction{
+	dbActions := make([]DBAction, 0)
+	for _, col := range o.Columns {
+		dbActions = append(dbActions, dropDuplicatedPoliciesActions(conn, table, col)...)
+	}
+
+	return append(dbActions,
 		NewDropColumnAction(conn, table.Name, temporaryNames(o.Columns)...),
 		o.removeTriggers(conn),
 		NewDropColumnAction(conn, table.Name, backfill.CNeedsBackfillColumn),
-	}, nil
+	), nil
 }
 
 func (o *OpCreateConstraint) removeTriggers(conn db.DB) DBAction {
@@ -258,7 +267,11 @@ func (o *OpCreateConstraint) Validate(ctx context.Context, s *schema.Schema) err
 		if o.References == nil {
 			return FieldRequiredError{Name: "references"}
 		}
-		table := s.GetTable(o.References.Table)
+		referenced := s.GetSchema(o.References.Schema)
+		if referenced == nil {
+			return SchemaDoesNotExistError{Name: o.References.Schema}
+		}
+		table := referenced.GetTable(o.References.Table)
 		if table == nil {
 			return TableDoesNotExistError{Name: o.References.Table}
 		}
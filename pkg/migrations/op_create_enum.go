@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+	"slices"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation  = (*OpCreateEnum)(nil)
+	_ Createable = (*OpCreateEnum)(nil)
+)
+
+func (o *OpCreateEnum) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	// Add the enum type to the in-memory schema so that subsequent operations
+	// in the same migration can refer to it
+	s.AddEnum(&schema.Enum{Name: o.Name, Values: slices.Clone(o.Values)})
+
+	return &StartResult{Actions: []DBAction{
+		NewCreateEnumAction(conn, o.Name, o.Values),
+	}}, nil
+}
+
+func (o *OpCreateEnum) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	// No-op
+	return nil, nil
+}
+
+func (o *OpCreateEnum) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	s.RemoveEnum(o.Name)
+
+	return []DBAction{NewDropEnumAction(conn, o.Name)}, nil
+}
+
+func (o *OpCreateEnum) Validate(ctx context.Context, s *schema.Schema) error {
+	if o.Name == "" {
+		return FieldRequiredError{Name: "name"}
+	}
+	if err := ValidateIdentifierLength(o.Name); err != nil {
+		return err
+	}
+
+	if s.GetEnum(o.Name) != nil {
+		return EnumAlreadyExistsError{Name: o.Name}
+	}
+
+	if len(o.Values) == 0 {
+		return FieldRequiredError{Name: "values"}
+	}
+	for i, v := range o.Values {
+		if slices.Contains(o.Values[:i], v) {
+			return EnumValueAlreadyExistsError{Enum: o.Name, Value: v}
+		}
+	}
+
+	s.AddEnum(&schema.Enum{Name: o.Name, Values: slices.Clone(o.Values)})
+	return nil
+}
@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/xataio/pgroll/internal/testutils"
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestCreateEnum(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "create enum",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_enum",
+					Operations: migrations.Operations{
+						&migrations.OpCreateEnum{
+							Name:   "mood",
+							Values: []string{"sad", "ok", "happy"},
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The enum type has been created
+				EnumMustHaveValues(t, db, schema, "mood", []string{"sad", "ok", "happy"})
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				// The enum type has been dropped
+				EnumMustNotExist(t, db, schema, "mood")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				// The enum type exists
+				EnumMustHaveValues(t, db, schema, "mood", []string{"sad", "ok", "happy"})
+			},
+		},
+		{
+			name: "create enum and use it in a new table",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_enum_and_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateEnum{
+							Name:   "mood",
+							Values: []string{"sad", "ok", "happy"},
+						},
+						&migrations.OpCreateTable{
+							Name: "people",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name: "mood",
+									Type: "mood",
+								},
+							},
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// Values of the enum type can be inserted into the new table
+				MustInsert(t, db, schema, "01_create_enum_and_table", "people", map[string]string{
+					"mood": "happy",
+				})
+
+				// Values not in the enum type are rejected
+				MustNotInsert(t, db, schema, "01_create_enum_and_table", "people", map[string]string{
+					"mood": "angry",
+				}, testutils.InvalidTextRepresentationErrorCode)
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				// The table and the enum type have been dropped
+				TableMustNotExist(t, db, schema, "people")
+				EnumMustNotExist(t, db, schema, "mood")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				// Values of the enum type can be inserted into the new table
+				MustInsert(t, db, schema, "01_create_enum_and_table", "people", map[string]string{
+					"mood": "sad",
+				})
+			},
+		},
+	})
+}
+
+func TestCreateEnumValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "enum type must not already exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_enum",
+					Operations: migrations.Operations{
+						&migrations.OpCreateEnum{
+							Name:   "mood",
+							Values: []string{"sad", "happy"},
+						},
+					},
+				},
+				{
+					Name: "02_create_enum",
+					Operations: migrations.Operations{
+						&migrations.OpCreateEnum{
+							Name:   "mood",
+							Values: []string{"sad", "happy"},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.EnumAlreadyExistsError{Name: "mood"},
+		},
+		{
+			name: "enum values must be unique",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_enum",
+					Operations: migrations.Operations{
+						&migrations.OpCreateEnum{
+							Name:   "mood",
+							Values: []string{"sad", "happy", "sad"},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.EnumValueAlreadyExistsError{Enum: "mood", Value: "sad"},
+		},
+		{
+			name: "enum must have at least one value",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_enum",
+					Operations: migrations.Operations{
+						&migrations.OpCreateEnum{
+							Name: "mood",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.FieldRequiredError{Name: "values"},
+		},
+	})
+}
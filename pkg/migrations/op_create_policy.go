@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation  = (*OpCreatePolicy)(nil)
+	_ Createable = (*OpCreatePolicy)(nil)
+)
+
+func (o *OpCreatePolicy) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+
+	policy, err := o.policy(table)
+	if err != nil {
+		return nil, InvalidPolicyError{Table: o.Table, Name: o.Name, Reason: err.Error()}
+	}
+	table.AddPolicy(policy)
+
+	// Policies are defined on the underlying table, so the policy applies to
+	// both versions of the schema as soon as the migration starts
+	return &StartResult{Actions: []DBAction{
+		NewCreatePolicyAction(conn, table.Name, policy),
+	}}, nil
+}
+
+func (o *OpCreatePolicy) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	// No-op
+	return nil, nil
+}
+
+func (o *OpCreatePolicy) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+
+	return []DBAction{NewDropPolicyAction(conn, table.Name, o.Name)}, nil
+}
+
+func (o *OpCreatePolicy) Validate(ctx context.Context, s *schema.Schema) error {
+	if o.Table == "" {
+		return FieldRequiredError{Name: "table"}
+	}
+	if o.Name == "" {
+		return FieldRequiredError{Name: "name"}
+	}
+	if err := ValidateIdentifierLength(o.Name); err != nil {
+		return err
+	}
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return TableDoesNotExistError{Name: o.Table}
+	}
+	if table.GetPolicy(o.Name) != nil {
+		return PolicyAlreadyExistsError{Table: o.Table, Name: o.Name}
+	}
+
+	if o.Using == nil && o.WithCheck == nil {
+		return FieldRequiredError{Name: "using"}
+	}
+	switch o.Command {
+	case OpCreatePolicyCommandInsert:
+		if o.Using != nil {
+			return InvalidPolicyError{Table: o.Table, Name: o.Name, Reason: "insert policies can not have a using expression"}
+		}
+	case OpCreatePolicyCommandSelect, OpCreatePolicyCommandDelete:
+		if o.WithCheck != nil {
+			return InvalidPolicyError{Table: o.Table, Name: o.Name, Reason: fmt.Sprintf("%s policies can not have a with_check expression", o.Command)}
+		}
+	}
+
+	policy, err := o.policy(table)
+	if err != nil {
+		return InvalidPolicyError{Table: o.Table, Name: o.Name, Reason: err.Error()}
+	}
+
+	table.AddPolicy(policy)
+	return nil
+}
+
+// policy returns the schema representation of the policy on `table`. The
+// expressions of the policy are rewritten to refer to the physical columns of
+// the table, so that they follow columns renamed in the migration.
+func (o *OpCreatePolicy) policy(table *schema.Table) (*schema.Policy, error) {
+	policy := &schema.Policy{
+		Name:       o.Name,
+		Command:    "ALL",
+		Permissive: o.Type != OpCreatePolicyTypeRestrictive,
+		Roles:      o.Roles,
+	}
+	if o.Command != "" {
+		policy.Command = strings.ToUpper(string(o.Command))
+	}
+	if len(policy.Roles) == 0 {
+		policy.Roles = []string{"public"}
+	}
+
+	var usingColumns, checkColumns []string
+	var err error
+	if o.Using != nil {
+		if policy.Using, usingColumns, err = physicalExpression(table, o.Table, *o.Using); err != nil {
+			return nil, fmt.Errorf("invalid using expression: %w", err)
+		}
+	}
+	if o.WithCheck != nil {
+		if policy.WithCheck, checkColumns, err = physicalExpression(table, o.Table, *o.WithCheck); err != nil {
+			return nil, fmt.Errorf("invalid with_check expression: %w", err)
+		}
+	}
+
+	columns := append(usingColumns, checkColumns...)
+	slices.Sort(columns)
+	policy.Columns = slices.Compact(columns)
+
+	return policy, nil
+}
@@ -0,0 +1,338 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestCreatePolicy(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "create policy",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_policy",
+					Operations: migrations.Operations{
+						&migrations.OpCreatePolicy{
+							Table: "documents",
+							Name:  "owner_only",
+							Using: ptr("owner = current_user"),
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The policy is created on the underlying table
+				PolicyMustHaveUsing(t, db, schema, "documents", "owner_only", "(owner = (CURRENT_USER)::text)")
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				PolicyMustNotExist(t, db, schema, "documents", "owner_only")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				PolicyMustHaveUsing(t, db, schema, "documents", "owner_only", "(owner = (CURRENT_USER)::text)")
+			},
+		},
+		{
+			name: "create restrictive policy for a command and role",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_policy",
+					Operations: migrations.Operations{
+						&migrations.OpCreatePolicy{
+							Table:     "documents",
+							Name:      "owner_inserts",
+							Type:      migrations.OpCreatePolicyTypeRestrictive,
+							Command:   migrations.OpCreatePolicyCommandInsert,
+							Roles:     []string{"pgroll"},
+							WithCheck: ptr("owner = current_user"),
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// Insert policies have no using expression
+				PolicyMustHaveUsing(t, db, schema, "documents", "owner_inserts", "")
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				PolicyMustNotExist(t, db, schema, "documents", "owner_inserts")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				PolicyMustExist(t, db, schema, "documents", "owner_inserts")
+			},
+		},
+		{
+			name: "create policy on a column renamed in the same migration",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_rename_column_and_create_policy",
+					Operations: migrations.Operations{
+						&migrations.OpRenameColumn{
+							Table: "documents",
+							From:  "owner",
+							To:    "author",
+						},
+						&migrations.OpCreatePolicy{
+							Table: "documents",
+							Name:  "author_only",
+							Using: ptr("author = current_user"),
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The policy refers to the column by its physical name
+				PolicyMustHaveUsing(t, db, schema, "documents", "author_only", "(owner = (CURRENT_USER)::text)")
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				PolicyMustNotExist(t, db, schema, "documents", "author_only")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				// The policy follows the column rename
+				PolicyMustHaveUsing(t, db, schema, "documents", "author_only", "(author = (CURRENT_USER)::text)")
+			},
+		},
+	})
+}
+
+func TestCreatePolicyValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "table must exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_policy",
+					Operations: migrations.Operations{
+						&migrations.OpCreatePolicy{
+							Table: "documents",
+							Name:  "owner_only",
+							Using: ptr("owner = current_user"),
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.TableDoesNotExistError{Name: "documents"},
+		},
+		{
+			name: "policy must not already exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_policy",
+					Operations: migrations.Operations{
+						&migrations.OpCreatePolicy{
+							Table: "documents",
+							Name:  "owner_only",
+							Using: ptr("owner = current_user"),
+						},
+					},
+				},
+				{
+					Name: "03_create_policy",
+					Operations: migrations.Operations{
+						&migrations.OpCreatePolicy{
+							Table: "documents",
+							Name:  "owner_only",
+							Using: ptr("owner = session_user"),
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.PolicyAlreadyExistsError{Table: "documents", Name: "owner_only"},
+		},
+		{
+			name: "policy must have an expression",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_policy",
+					Operations: migrations.Operations{
+						&migrations.OpCreatePolicy{
+							Table: "documents",
+							Name:  "owner_only",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.FieldRequiredError{Name: "using"},
+		},
+		{
+			name: "insert policies can not have a using expression",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_policy",
+					Operations: migrations.Operations{
+						&migrations.OpCreatePolicy{
+							Table:   "documents",
+							Name:    "owner_only",
+							Command: migrations.OpCreatePolicyCommandInsert,
+							Using:   ptr("owner = current_user"),
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidPolicyError{Table: "documents", Name: "owner_only", Reason: "insert policies can not have a using expression"},
+		},
+		{
+			name: "expression must be a single expression",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_policy",
+					Operations: migrations.Operations{
+						&migrations.OpCreatePolicy{
+							Table: "documents",
+							Name:  "owner_only",
+							Using: ptr("true; DROP TABLE documents"),
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidPolicyError{Table: "documents", Name: "owner_only", Reason: "invalid using expression: the expression must be a single SQL expression"},
+		},
+	})
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation  = (*OpCreateSequence)(nil)
+	_ Createable = (*OpCreateSequence)(nil)
+)
+
+func (o *OpCreateSequence) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	seq := o.sequence()
+	s.AddSequence(seq)
+
+	return &StartResult{Actions: []DBAction{
+		NewCreateSequenceAction(conn, seq),
+	}}, nil
+}
+
+func (o *OpCreateSequence) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	// No-op
+	return nil, nil
+}
+
+func (o *OpCreateSequence) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	s.RemoveSequence(o.Name)
+
+	return []DBAction{NewDropSequenceAction(conn, o.Name)}, nil
+}
+
+func (o *OpCreateSequence) Validate(ctx context.Context, s *schema.Schema) error {
+	if o.Name == "" {
+		return FieldRequiredError{Name: "name"}
+	}
+	if err := ValidateIdentifierLength(o.Name); err != nil {
+		return err
+	}
+
+	if s.GetSequence(o.Name) != nil {
+		return SequenceAlreadyExistsError{Name: o.Name}
+	}
+
+	seq := o.sequence()
+	if err := validateSequence(seq); err != nil {
+		return err
+	}
+
+	s.AddSequence(seq)
+	return nil
+}
+
+// sequence returns the schema representation of the sequence, applying the
+// same defaults as Postgres to the options that are not set.
+func (o *OpCreateSequence) sequence() *schema.Sequence {
+	seq := &schema.Sequence{
+		Name:      o.Name,
+		Type:      string(o.Type),
+		Increment: 1,
+		Cache:     1,
+		Cycle:     o.Cycle,
+	}
+	if seq.Type == "" {
+		seq.Type = string(OpCreateSequenceTypeBigint)
+	}
+	if o.Increment != nil {
+		seq.Increment = int64(*o.Increment)
+	}
+	if o.Cache != nil {
+		seq.Cache = int64(*o.Cache)
+	}
+
+	bounds := sequenceTypeRanges[seq.Type]
+	if seq.Increment > 0 {
+		seq.MinValue, seq.MaxValue = 1, bounds[1]
+	} else {
+		seq.MinValue, seq.MaxValue = bounds[0], -1
+	}
+	if o.MinValue != nil {
+		seq.MinValue = int64(*o.MinValue)
+	}
+	if o.MaxValue != nil {
+		seq.MaxValue = int64(*o.MaxValue)
+	}
+
+	seq.Start = seq.MinValue
+	if seq.Increment < 0 {
+		seq.Start = seq.MaxValue
+	}
+	if o.StartValue != nil {
+		seq.Start = int64(*o.StartValue)
+	}
+
+	return seq
+}
@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestCreateSequence(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "create sequence",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_sequence",
+					Operations: migrations.Operations{
+						&migrations.OpCreateSequence{
+							Name:       "invoice_numbers",
+							StartValue: ptr(1000),
+							Increment:  ptr(10),
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				SequenceMustHaveIncrement(t, db, schema, "invoice_numbers", 10)
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				SequenceMustNotExist(t, db, schema, "invoice_numbers")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				SequenceMustHaveIncrement(t, db, schema, "invoice_numbers", 10)
+			},
+		},
+		{
+			name: "create sequence used as the default of a new column",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_sequence",
+					Operations: migrations.Operations{
+						&migrations.OpCreateSequence{
+							Name:       "invoice_numbers",
+							StartValue: ptr(1000),
+							Increment:  ptr(10),
+						},
+					},
+				},
+				{
+					Name: "02_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "invoices",
+							Columns: []migrations.Column{
+								{
+									Name:    "number",
+									Type:    "bigint",
+									Pk:      true,
+									Default: ptr("nextval('invoice_numbers')"),
+								},
+								{
+									Name:     "customer",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				MustInsert(t, db, schema, "02_create_table", "invoices", map[string]string{"customer": "alice"})
+				MustInsert(t, db, schema, "02_create_table", "invoices", map[string]string{"customer": "bob"})
+
+				rows := MustSelect(t, db, schema, "02_create_table", "invoices")
+				assert.Equal(t, []map[string]any{
+					{"number": 1000, "customer": "alice"},
+					{"number": 1010, "customer": "bob"},
+				}, rows)
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {},
+		},
+	})
+}
+
+func TestCreateSequenceValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "sequence must not already exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_sequence",
+					Operations: migrations.Operations{
+						&migrations.OpCreateSequence{
+							Name:       "invoice_numbers",
+							StartValue: ptr(1000),
+							Increment:  ptr(10),
+						},
+					},
+				},
+				{
+					Name: "02_create_sequence",
+					Operations: migrations.Operations{
+						&migrations.OpCreateSequence{
+							Name:       "invoice_numbers",
+							StartValue: ptr(1000),
+							Increment:  ptr(10),
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.SequenceAlreadyExistsError{Name: "invoice_numbers"},
+		},
+		{
+			name: "start value must be in range",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_sequence",
+					Operations: migrations.Operations{
+						&migrations.OpCreateSequence{
+							Name:       "invoice_numbers",
+							Type:       migrations.OpCreateSequenceTypeSmallint,
+							StartValue: ptr(100000),
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidSequenceError{Name: "invoice_numbers", Reason: "start value must be between the minimum and maximum values"},
+		},
+	})
+}
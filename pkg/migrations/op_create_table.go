@@ -32,7 +32,11 @@ func (o *OpCreateTable) Start(ctx context.Context, l Logger, conn db.DB, s *sche
 	}
 
 	dbActions := make([]DBAction, 0)
-	dbActions = append(dbActions, NewCreateTableAction(conn, o.Name, columnsSQL, constraintsSQL))
+	if o.PartitionBy != nil {
+		dbActions = append(dbActions, NewCreatePartitionedTableAction(conn, o.Name, columnsSQL, constraintsSQL, *o.PartitionBy))
+	} else {
+		dbActions = append(dbActions, NewCreateTableAction(conn, o.Name, columnsSQL, constraintsSQL))
+	}
 
 	// Add comments to any columns that have them
 	for _, col := range o.Columns {
@@ -190,6 +194,12 @@ func (o *OpCreateTable) Validate(ctx context.Context, s *schema.Schema) error {
 	// subsequent operations.
 	o.updateSchema(s)
 
+	if o.PartitionBy != nil {
+		if err := validatePartitionBy(s.GetTable(o.Name), *o.PartitionBy); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -239,6 +249,7 @@ func (o *OpCreateTable) updateSchema(s *schema.Schema) *schema.Schema {
 				Name:              c.Name,
 				Columns:           c.Columns,
 				ReferencedTable:   c.References.Table,
+				ReferencedSchema:  c.References.Schema,
 				ReferencedColumns: c.References.Columns,
 				OnDelete:          string(c.References.OnDelete),
 				OnUpdate:          string(c.References.OnUpdate),
@@ -253,7 +264,7 @@ func (o *OpCreateTable) updateSchema(s *schema.Schema) *schema.Schema {
 		}
 	}
 
-	s.AddTable(o.Name, &schema.Table{
+	table := &schema.Table{
 		Name:               o.Name,
 		Columns:            columns,
 		UniqueConstraints:  uniqueConstraints,
@@ -261,7 +272,11 @@ func (o *OpCreateTable) updateSchema(s *schema.Schema) *schema.Schema {
 		PrimaryKey:         primaryKeys,
 		ForeignKeys:        foreignKeys,
 		ExcludeConstraints: excludeConstraints,
-	})
+	}
+	if o.PartitionBy != nil {
+		table.Partitioning = partitioning(*o.PartitionBy)
+	}
+	s.AddTable(o.Name, table)
 
 	return s
 }
@@ -321,7 +336,7 @@ func constraintsToSQL(constraints []Constraint) (string, error) {
 		case ConstraintTypePrimaryKey:
 			constraintsSQL[i] = writer.WritePrimaryKey()
 		case ConstraintTypeForeignKey:
-			constraintsSQL[i] = writer.WriteForeignKey(c.References.Table, c.References.Columns, c.References.OnDelete, c.References.OnUpdate, c.References.OnDeleteSetColumns, c.References.MatchType)
+			constraintsSQL[i] = writer.WriteForeignKey(c.References.Schema, c.References.Table, c.References.Columns, c.References.OnDelete, c.References.OnUpdate, c.References.OnDeleteSetColumns, c.References.MatchType)
 		case ConstraintTypeExclude:
 			constraintsSQL[i] = writer.WriteExclude(c.Exclude.IndexMethod, c.Exclude.Elements, c.Exclude.Predicate)
 		}
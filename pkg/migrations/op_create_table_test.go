@@ -1313,6 +1313,62 @@ func TestCreateTable(t *testing.T) {
 				}, rows)
 			},
 		},
+		{
+			name: "create partitioned table",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "events",
+							Columns: []migrations.Column{
+								{Name: "id", Type: "integer"},
+								{Name: "created_at", Type: "date"},
+							},
+							Constraints: []migrations.Constraint{
+								{
+									Name:    "events_pkey",
+									Type:    migrations.ConstraintTypePrimaryKey,
+									Columns: []string{"id", "created_at"},
+								},
+							},
+							PartitionBy: &migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyRange,
+								Columns:  []string{"created_at"},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_attach_partition",
+					Operations: migrations.Operations{
+						&migrations.OpAttachPartition{
+							Table:     "events",
+							Partition: "events_2024",
+							Bound:     "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The table is partitioned
+				TableMustBePartitioned(t, db, schema, "events")
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				// The partition has been dropped
+				PartitionMustNotExist(t, db, schema, "events", "events_2024")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				// Rows can be inserted into the partition through the view of the
+				// partitioned table
+				MustInsert(t, db, schema, "02_attach_partition", "events", map[string]string{
+					"id":         "1",
+					"created_at": "2024-06-01",
+				})
+				rows := MustSelect(t, db, schema, "02_attach_partition", "events")
+				assert.Len(t, rows, 1)
+			},
+		},
 	})
 }
 
@@ -1578,6 +1634,28 @@ func TestCreateTableValidation(t *testing.T) {
 			},
 			wantStartErr: migrations.PrimaryKeysAreAlreadySetError{Table: "table1"},
 		},
+		{
+			name: "primary key of a partitioned table must include the partition columns",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "events",
+							Columns: []migrations.Column{
+								{Name: "id", Type: "serial", Pk: true},
+								{Name: "created_at", Type: "date"},
+							},
+							PartitionBy: &migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyRange,
+								Columns:  []string{"created_at"},
+							},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidPartitioningError{Table: "events", Reason: "the primary key must include all partition columns"},
+		},
 	})
 }
 
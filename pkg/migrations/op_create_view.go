@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation  = (*OpCreateView)(nil)
+	_ Createable = (*OpCreateView)(nil)
+)
+
+func (o *OpCreateView) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	// Add the view to the in-memory schema. The view is created in the new
+	// version schema along with the views for its tables, and in the underlying
+	// schema on migration completion.
+	s.AddView(&schema.View{Name: o.Name, Definition: o.Definition})
+
+	return nil, nil
+}
+
+func (o *OpCreateView) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	// No-op: views in the underlying schema are created once all operations in
+	// the migration are complete
+	return nil, nil
+}
+
+func (o *OpCreateView) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	s.RemoveView(o.Name)
+
+	return nil, nil
+}
+
+func (o *OpCreateView) Validate(ctx context.Context, s *schema.Schema) error {
+	if o.Name == "" {
+		return FieldRequiredError{Name: "name"}
+	}
+	if err := ValidateIdentifierLength(o.Name); err != nil {
+		return err
+	}
+
+	if s.GetView(o.Name) != nil {
+		return ViewAlreadyExistsError{Name: o.Name}
+	}
+	if s.GetTable(o.Name) != nil {
+		return TableAlreadyExistsError{Name: o.Name}
+	}
+
+	if o.Definition == "" {
+		return FieldRequiredError{Name: "definition"}
+	}
+	if err := validateView(s, o.Name, o.Definition); err != nil {
+		return err
+	}
+
+	s.AddView(&schema.View{Name: o.Name, Definition: o.Definition})
+	return nil
+}
@@ -0,0 +1,639 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestCreateView(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "create view",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The view has been created in the new version schema
+				ViewMustExist(t, db, schema, "02_create_view", "user_names")
+
+				// The view reads from the table
+				MustInsert(t, db, schema, "02_create_view", "users", map[string]string{
+					"name": "alice",
+				})
+				rows := MustSelect(t, db, schema, "02_create_view", "user_names")
+				assert.Equal(t, []map[string]any{{"id": 1, "name": "alice"}}, rows)
+
+				// The view is not created in the underlying schema until the
+				// migration is completed
+				SchemaViewMustNotExist(t, db, schema, "user_names")
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				SchemaViewMustNotExist(t, db, schema, "user_names")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				// The view exists in the new version schema and the underlying schema
+				ViewMustExist(t, db, schema, "02_create_view", "user_names")
+				SchemaViewMustExist(t, db, schema, "user_names")
+			},
+		},
+		{
+			name: "views are recreated when a migration refreshes the version schema",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+				{
+					Name: "03_raw_sql",
+					Operations: migrations.Operations{
+						&migrations.OpRawSQL{
+							Up:         "ALTER TABLE users ADD COLUMN age integer",
+							OnComplete: true,
+						},
+					},
+				},
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				// The view and the view of the table it reads from have been
+				// recreated in the new version schema
+				MustInsert(t, db, schema, "03_raw_sql", "users", map[string]string{
+					"name": "alice",
+					"age":  "30",
+				})
+				rows := MustSelect(t, db, schema, "03_raw_sql", "user_names")
+				assert.Equal(t, []map[string]any{{"id": 1, "name": "alice"}}, rows)
+			},
+		},
+		{
+			name: "create view that reads from a column renamed in the same migration",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_rename_column_and_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpRenameColumn{
+							Table: "users",
+							From:  "name",
+							To:    "username",
+						},
+						&migrations.OpCreateView{
+							Name:       "usernames",
+							Definition: "SELECT id, username FROM users",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The view reads from the column by its new name
+				MustInsert(t, db, schema, "02_rename_column_and_create_view", "users", map[string]string{
+					"username": "alice",
+				})
+				rows := MustSelect(t, db, schema, "02_rename_column_and_create_view", "usernames")
+				assert.Equal(t, []map[string]any{{"id": 1, "username": "alice"}}, rows)
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				SchemaViewMustExist(t, db, schema, "usernames")
+			},
+		},
+		{
+			name: "view is kept when the type of a column it reads from is changed",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+				{
+					Name: "03_change_type",
+					Operations: migrations.Operations{
+						&migrations.OpAlterColumn{
+							Table:  "users",
+							Column: "name",
+							Type:   ptr("text"),
+							Up:     "name",
+							Down:   "name",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The view is exposed in both version schemas
+				ViewMustExist(t, db, schema, "02_create_view", "user_names")
+				ViewMustExist(t, db, schema, "03_change_type", "user_names")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				// The view has been recreated over the new column
+				SchemaViewMustExist(t, db, schema, "user_names")
+				ViewMustExist(t, db, schema, "03_change_type", "user_names")
+			},
+		},
+	})
+}
+
+func TestCreateViewValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "view must not already exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+				{
+					Name: "03_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "SELECT id FROM users",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.ViewAlreadyExistsError{Name: "user_names"},
+		},
+		{
+			name: "view must not have the name of a table",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "users",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.TableAlreadyExistsError{Name: "users"},
+		},
+		{
+			name: "definition must be a SELECT statement",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "DELETE FROM users",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidViewDefinitionError{Name: "user_names", Reason: "the definition must be a single SELECT statement"},
+		},
+		{
+			name: "definition must read from existing tables",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.ViewDependencyMissingError{View: "user_names", Relation: "users"},
+		},
+		{
+			name: "definition must read from existing columns",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_emails",
+							Definition: "SELECT id, email FROM users",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.ViewDependencyMissingError{View: "user_emails", Relation: "users", Column: "email"},
+		},
+	})
+}
+
+func TestViewDependencyValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "column read by a view can not be renamed",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+				{
+					Name: "03_rename_column",
+					Operations: migrations.Operations{
+						&migrations.OpRenameColumn{
+							Table: "users",
+							From:  "name",
+							To:    "username",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.ViewDependencyMissingError{View: "user_names", Relation: "users", Column: "name"},
+		},
+		{
+			name: "column read by a view can not be dropped",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+				{
+					Name: "03_drop_column",
+					Operations: migrations.Operations{
+						&migrations.OpDropColumn{
+							Table:  "users",
+							Column: "name",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.ViewDependencyMissingError{View: "user_names", Relation: "users", Column: "name"},
+		},
+		{
+			name: "table read by a view can not be renamed",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+				{
+					Name: "03_rename_table",
+					Operations: migrations.Operations{
+						&migrations.OpRenameTable{
+							From: "users",
+							To:   "customers",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.ViewDependencyMissingError{View: "user_names", Relation: "users"},
+		},
+		{
+			name: "table read by a view can not be dropped",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+				{
+					Name: "03_drop_table",
+					Operations: migrations.Operations{
+						&migrations.OpDropTable{
+							Name: "users",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.ViewDependencyMissingError{View: "user_names", Relation: "users"},
+		},
+		{
+			name: "views that read from relations the schema doesn't model don't block other migrations",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_views",
+					Operations: migrations.Operations{
+						&migrations.OpRawSQL{
+							Up: "CREATE MATERIALIZED VIEW user_counts AS SELECT count(*) AS n FROM users; " +
+								"CREATE VIEW user_count AS SELECT n FROM user_counts; " +
+								"CREATE VIEW sessions AS SELECT pid FROM pg_stat_activity",
+						},
+					},
+				},
+				{
+					Name: "03_add_column",
+					Operations: migrations.Operations{
+						&migrations.OpAddColumn{
+							Table:  "users",
+							Column: migrations.Column{Name: "age", Type: "integer", Nullable: true},
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				ColumnMustExist(t, db, schema, "users", migrations.TemporaryName("age"))
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				ColumnMustExist(t, db, schema, "users", "age")
+			},
+		},
+	})
+}
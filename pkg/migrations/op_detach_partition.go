@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation                      = (*OpDetachPartition)(nil)
+	_ Createable                     = (*OpDetachPartition)(nil)
+	_ RequiresSchemaRefreshOperation = (*OpDetachPartition)(nil)
+)
+
+func (o *OpDetachPartition) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+
+	// The partition is detached on migration completion; during the active
+	// migration period its rows remain visible through the partitioned table
+	// in both versions of the schema
+	table.RemovePartition(o.Partition)
+	if partition := s.GetTable(o.Partition); partition != nil {
+		partition.PartitionOf = ""
+	}
+
+	return nil, nil
+}
+
+func (o *OpDetachPartition) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	return []DBAction{NewDetachPartitionAction(conn, o.Table, o.Partition)}, nil
+}
+
+func (o *OpDetachPartition) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	// No-op
+	return nil, nil
+}
+
+func (o *OpDetachPartition) Validate(ctx context.Context, s *schema.Schema) error {
+	if o.Table == "" {
+		return FieldRequiredError{Name: "table"}
+	}
+	if o.Partition == "" {
+		return FieldRequiredError{Name: "partition"}
+	}
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return TableDoesNotExistError{Name: o.Table}
+	}
+	if table.Partitioning == nil {
+		return TableIsNotPartitionedError{Name: o.Table}
+	}
+	if table.GetPartition(o.Partition) == nil {
+		return PartitionDoesNotExistError{Table: o.Table, Name: o.Partition}
+	}
+
+	table.RemovePartition(o.Partition)
+	if partition := s.GetTable(o.Partition); partition != nil {
+		partition.PartitionOf = ""
+	}
+	return nil
+}
+
+// RequiresSchemaRefresh refreshes the new version of the schema once the
+// partition has been detached from the table.
+func (o *OpDetachPartition) RequiresSchemaRefresh() {}
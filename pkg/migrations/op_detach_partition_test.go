@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestDetachPartition(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "detach a partition",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "events",
+							Columns: []migrations.Column{
+								{Name: "id", Type: "integer"},
+								{Name: "created_at", Type: "date"},
+							},
+							Constraints: []migrations.Constraint{
+								{
+									Name:    "events_pkey",
+									Type:    migrations.ConstraintTypePrimaryKey,
+									Columns: []string{"id", "created_at"},
+								},
+							},
+							PartitionBy: &migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyRange,
+								Columns:  []string{"created_at"},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_attach_partition",
+					Operations: migrations.Operations{
+						&migrations.OpAttachPartition{
+							Table:     "events",
+							Partition: "events_2024",
+							Bound:     "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')",
+						},
+					},
+				},
+				{
+					Name: "03_detach_partition",
+					Operations: migrations.Operations{
+						&migrations.OpDetachPartition{
+							Table:     "events",
+							Partition: "events_2024",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The partition is not detached until the migration completes
+				PartitionMustExist(t, db, schema, "events", "events_2024")
+
+				// Rows in the partition are visible through both versions of the schema
+				MustInsert(t, db, schema, "02_attach_partition", "events", map[string]string{
+					"id":         "1",
+					"created_at": "2024-03-01",
+				})
+				rows := MustSelect(t, db, schema, "03_detach_partition", "events")
+				assert.Len(t, rows, 1)
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				PartitionMustExist(t, db, schema, "events", "events_2024")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				// The partition has been detached and is a table of its own
+				PartitionMustNotExist(t, db, schema, "events", "events_2024")
+				TableMustExist(t, db, schema, "events_2024")
+
+				// The detached table is exposed in the new version of the schema
+				ViewMustExist(t, db, schema, "03_detach_partition", "events_2024")
+				rows := MustSelect(t, db, schema, "03_detach_partition", "events")
+				assert.Empty(t, rows)
+			},
+		},
+	})
+}
+
+func TestDetachPartitionValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "partition must exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "events",
+							Columns: []migrations.Column{
+								{Name: "id", Type: "integer"},
+								{Name: "created_at", Type: "date"},
+							},
+							Constraints: []migrations.Constraint{
+								{
+									Name:    "events_pkey",
+									Type:    migrations.ConstraintTypePrimaryKey,
+									Columns: []string{"id", "created_at"},
+								},
+							},
+							PartitionBy: &migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyRange,
+								Columns:  []string{"created_at"},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_detach_partition",
+					Operations: migrations.Operations{
+						&migrations.OpDetachPartition{
+							Table:     "events",
+							Partition: "events_2024",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.PartitionDoesNotExistError{Table: "events", Name: "events_2024"},
+		},
+		{
+			name: "table must be partitioned",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_detach_partition",
+					Operations: migrations.Operations{
+						&migrations.OpDetachPartition{
+							Table:     "users",
+							Partition: "users_1",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.TableIsNotPartitionedError{Name: "users"},
+		},
+	})
+}
@@ -81,5 +81,7 @@ func (o *OpDropColumn) Validate(ctx context.Context, s *schema.Schema) error {
 	if table.GetColumn(o.Column) == nil {
 		return ColumnDoesNotExistError{Table: o.Table, Name: o.Column}
 	}
+
+	table.RemoveColumn(o.Column)
 	return nil
 }
@@ -90,6 +90,7 @@ func (o *OpDropConstraint) Complete(l Logger, conn db.DB, s *schema.Schema) ([]D
 			backfill.TriggerFunctionName(o.Table, TemporaryName(column.Name))),
 		NewAlterSequenceOwnerAction(conn, o.Table, column.Name, TemporaryName(column.Name)),
 		NewDropColumnAction(conn, table.Name, backfill.CNeedsBackfillColumn),
+		NewDropReplacedPoliciesAction(conn, table, column.Name),
 		NewDropColumnAction(conn, o.Table, column.Name),
 		NewRenameDuplicatedColumnAction(conn, table, column.Name),
 	}, nil
@@ -102,13 +103,13 @@ func (o *OpDropConstraint) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]D
 	table := s.GetTable(o.Table)
 	columnName := table.GetConstraintColumns(o.Name)[0]
 
-	return []DBAction{
+	return append(dropDuplicatedPoliciesActions(conn, table, columnName),
 		NewDropColumnAction(conn, o.Table, TemporaryName(columnName)),
 		NewDropFunctionAction(conn,
 			backfill.TriggerFunctionName(o.Table, columnName),
 			backfill.TriggerFunctionName(o.Table, TemporaryName(columnName))),
 		NewDropColumnAction(conn, table.Name, backfill.CNeedsBackfillColumn),
-	}, nil
+	), nil
 }
 
 func (o *OpDropConstraint) Validate(ctx context.Context, s *schema.Schema) error {
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation  = (*OpDropEnum)(nil)
+	_ Createable = (*OpDropEnum)(nil)
+)
+
+func (o *OpDropEnum) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	// The enum type is dropped on migration completion; during the active
+	// migration period it is only removed from the new version of the schema
+	s.RemoveEnum(o.Name)
+
+	return nil, nil
+}
+
+func (o *OpDropEnum) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	s.RemoveEnum(o.Name)
+
+	return []DBAction{NewDropEnumAction(conn, o.Name)}, nil
+}
+
+func (o *OpDropEnum) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	// No-op
+	return nil, nil
+}
+
+func (o *OpDropEnum) Validate(ctx context.Context, s *schema.Schema) error {
+	if o.Name == "" {
+		return FieldRequiredError{Name: "name"}
+	}
+
+	if s.GetEnum(o.Name) == nil {
+		return EnumDoesNotExistError{Name: o.Name}
+	}
+
+	if s.EnumIsUsed(o.Name) {
+		return EnumIsInUseError{Name: o.Name}
+	}
+
+	s.RemoveEnum(o.Name)
+	return nil
+}
@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestDropEnum(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "drop enum",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_enum",
+					Operations: migrations.Operations{
+						&migrations.OpCreateEnum{
+							Name:   "mood",
+							Values: []string{"sad", "happy"},
+						},
+					},
+				},
+				{
+					Name: "02_drop_enum",
+					Operations: migrations.Operations{
+						&migrations.OpDropEnum{
+							Name: "mood",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The enum type is not dropped until the migration is completed
+				EnumMustExist(t, db, schema, "mood")
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				EnumMustExist(t, db, schema, "mood")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				// The enum type has been dropped
+				EnumMustNotExist(t, db, schema, "mood")
+			},
+		},
+	})
+}
+
+func TestDropEnumValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "enum type must exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_drop_enum",
+					Operations: migrations.Operations{
+						&migrations.OpDropEnum{
+							Name: "mood",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.EnumDoesNotExistError{Name: "mood"},
+		},
+		{
+			name: "enum type must not be used by a column",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_enum",
+					Operations: migrations.Operations{
+						&migrations.OpCreateEnum{
+							Name:   "mood",
+							Values: []string{"sad", "happy"},
+						},
+					},
+				},
+				{
+					Name: "02_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "people",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name: "mood",
+									Type: "mood",
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "03_drop_enum",
+					Operations: migrations.Operations{
+						&migrations.OpDropEnum{
+							Name: "mood",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.EnumIsInUseError{Name: "mood"},
+		},
+	})
+}
@@ -110,6 +110,7 @@ func (o *OpDropMultiColumnConstraint) Complete(l Logger, conn db.DB, s *schema.S
 				backfill.TriggerFunctionName(o.Table, TemporaryName(columnName))),
 			NewAlterSequenceOwnerAction(conn, o.Table, columnName, TemporaryName(columnName)),
 			NewDropColumnAction(conn, o.Table, backfill.CNeedsBackfillColumn),
+			NewDropReplacedPoliciesAction(conn, table, columnName),
 			NewDropColumnAction(conn, o.Table, columnName),
 			NewRenameDuplicatedColumnAction(conn, table, columnName),
 		)
@@ -126,6 +127,7 @@ func (o *OpDropMultiColumnConstraint) Rollback(l Logger, conn db.DB, s *schema.S
 	constraintColumns := table.GetConstraintColumns(o.Name)
 	dbAction := make([]DBAction, 0, 3*len(constraintColumns))
 	for _, columnName := range constraintColumns {
+		dbAction = append(dbAction, dropDuplicatedPoliciesActions(conn, table, columnName)...)
 		dbAction = append(
 			dbAction,
 			NewDropColumnAction(conn, table.Name, TemporaryName(columnName)),
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation  = (*OpDropPolicy)(nil)
+	_ Createable = (*OpDropPolicy)(nil)
+)
+
+func (o *OpDropPolicy) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+
+	// The policy is dropped on migration completion, so that it keeps
+	// protecting the table during the active migration period
+	table.RemovePolicy(o.Name)
+
+	return nil, nil
+}
+
+func (o *OpDropPolicy) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	return []DBAction{NewDropPolicyAction(conn, o.Table, o.Name)}, nil
+}
+
+func (o *OpDropPolicy) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	// No-op
+	return nil, nil
+}
+
+func (o *OpDropPolicy) Validate(ctx context.Context, s *schema.Schema) error {
+	if o.Table == "" {
+		return FieldRequiredError{Name: "table"}
+	}
+	if o.Name == "" {
+		return FieldRequiredError{Name: "name"}
+	}
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return TableDoesNotExistError{Name: o.Table}
+	}
+	if table.GetPolicy(o.Name) == nil {
+		return PolicyDoesNotExistError{Table: o.Table, Name: o.Name}
+	}
+
+	table.RemovePolicy(o.Name)
+	return nil
+}
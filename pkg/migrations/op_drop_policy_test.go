@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestDropPolicy(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "drop policy",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_policy",
+					Operations: migrations.Operations{
+						&migrations.OpCreatePolicy{
+							Table: "documents",
+							Name:  "owner_only",
+							Using: ptr("owner = current_user"),
+						},
+					},
+				},
+				{
+					Name: "03_drop_policy",
+					Operations: migrations.Operations{
+						&migrations.OpDropPolicy{
+							Table: "documents",
+							Name:  "owner_only",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The policy is not dropped until the migration is completed
+				PolicyMustExist(t, db, schema, "documents", "owner_only")
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				PolicyMustExist(t, db, schema, "documents", "owner_only")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				PolicyMustNotExist(t, db, schema, "documents", "owner_only")
+			},
+		},
+	})
+}
+
+func TestDropPolicyValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "policy must exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_drop_policy",
+					Operations: migrations.Operations{
+						&migrations.OpDropPolicy{
+							Table: "documents",
+							Name:  "owner_only",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.PolicyDoesNotExistError{Table: "documents", Name: "owner_only"},
+		},
+	})
+}
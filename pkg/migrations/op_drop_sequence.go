@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation  = (*OpDropSequence)(nil)
+	_ Createable = (*OpDropSequence)(nil)
+)
+
+func (o *OpDropSequence) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	// The sequence is dropped on migration completion, so that the old
+	// version of the schema can keep using it during the active migration
+	// period
+	s.RemoveSequence(o.Name)
+
+	return nil, nil
+}
+
+func (o *OpDropSequence) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	return []DBAction{NewDropSequenceAction(conn, o.Name)}, nil
+}
+
+func (o *OpDropSequence) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	// No-op
+	return nil, nil
+}
+
+func (o *OpDropSequence) Validate(ctx context.Context, s *schema.Schema) error {
+	if o.Name == "" {
+		return FieldRequiredError{Name: "name"}
+	}
+
+	if s.GetSequence(o.Name) == nil {
+		return SequenceDoesNotExistError{Name: o.Name}
+	}
+	if table, column := s.SequenceOwner(o.Name); column != nil {
+		return SequenceIsOwnedError{Name: o.Name, Table: table.Name, Column: column.Name}
+	}
+
+	s.RemoveSequence(o.Name)
+	return nil
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestDropSequence(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "drop sequence",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_sequence",
+					Operations: migrations.Operations{
+						&migrations.OpCreateSequence{
+							Name:       "invoice_numbers",
+							StartValue: ptr(1000),
+							Increment:  ptr(10),
+						},
+					},
+				},
+				{
+					Name: "02_drop_sequence",
+					Operations: migrations.Operations{
+						&migrations.OpDropSequence{
+							Name: "invoice_numbers",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The sequence is not dropped until the migration is completed
+				SequenceMustExist(t, db, schema, "invoice_numbers")
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				SequenceMustExist(t, db, schema, "invoice_numbers")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				SequenceMustNotExist(t, db, schema, "invoice_numbers")
+			},
+		},
+	})
+}
+
+func TestDropSequenceValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "sequence must exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_drop_sequence",
+					Operations: migrations.Operations{
+						&migrations.OpDropSequence{
+							Name: "invoice_numbers",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.SequenceDoesNotExistError{Name: "invoice_numbers"},
+		},
+		{
+			name: "sequence must not be owned by a column",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_drop_sequence",
+					Operations: migrations.Operations{
+						&migrations.OpDropSequence{
+							Name: "documents_id_seq",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.SequenceIsOwnedError{Name: "documents_id_seq", Table: "documents", Column: "id"},
+		},
+	})
+}
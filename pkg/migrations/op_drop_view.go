@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation  = (*OpDropView)(nil)
+	_ Createable = (*OpDropView)(nil)
+)
+
+func (o *OpDropView) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	// The view is dropped from the underlying schema on migration completion;
+	// during the active migration period it is only removed from the new
+	// version of the schema
+	s.RemoveView(o.Name)
+
+	return nil, nil
+}
+
+func (o *OpDropView) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	// No-op: views in the underlying schema are dropped before the other
+	// operations in the migration complete
+	return nil, nil
+}
+
+func (o *OpDropView) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	// No-op
+	return nil, nil
+}
+
+func (o *OpDropView) Validate(ctx context.Context, s *schema.Schema) error {
+	if o.Name == "" {
+		return FieldRequiredError{Name: "name"}
+	}
+
+	if s.GetView(o.Name) == nil {
+		return ViewDoesNotExistError{Name: o.Name}
+	}
+
+	s.RemoveView(o.Name)
+	return nil
+}
@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestDropView(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "drop view",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+				{
+					Name: "03_drop_view",
+					Operations: migrations.Operations{
+						&migrations.OpDropView{
+							Name: "user_names",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The view is only removed from the new version schema
+				ViewMustNotExist(t, db, schema, "03_drop_view", "user_names")
+				ViewMustExist(t, db, schema, "02_create_view", "user_names")
+				SchemaViewMustExist(t, db, schema, "user_names")
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				SchemaViewMustExist(t, db, schema, "user_names")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				// The view has been dropped
+				SchemaViewMustNotExist(t, db, schema, "user_names")
+			},
+		},
+		{
+			name: "drop view and the column it reads from",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+				{
+					Name: "03_drop_view_and_column",
+					Operations: migrations.Operations{
+						&migrations.OpDropColumn{
+							Table:  "users",
+							Column: "name",
+						},
+						&migrations.OpDropView{
+							Name: "user_names",
+						},
+					},
+				},
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				SchemaViewMustNotExist(t, db, schema, "user_names")
+				ColumnMustNotExist(t, db, schema, "users", "name")
+			},
+		},
+	})
+}
+
+func TestDropViewValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "view must exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_drop_view",
+					Operations: migrations.Operations{
+						&migrations.OpDropView{
+							Name: "user_names",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.ViewDoesNotExistError{Name: "user_names"},
+		},
+		{
+			name: "view read by another view can not be dropped",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+				{
+					Name: "03_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "alice",
+							Definition: "SELECT id FROM user_names WHERE name = 'alice'",
+						},
+					},
+				},
+				{
+					Name: "04_drop_view",
+					Operations: migrations.Operations{
+						&migrations.OpDropView{
+							Name: "user_names",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.ViewDependencyMissingError{View: "alice", Relation: "user_names"},
+		},
+	})
+}
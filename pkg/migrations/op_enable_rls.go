@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation  = (*OpEnableRLS)(nil)
+	_ Createable = (*OpEnableRLS)(nil)
+)
+
+func (o *OpEnableRLS) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+	table.RowLevelSecurity = true
+	table.ForceRowLevelSecurity = o.Force
+
+	// Row level security is a property of the underlying table, so it applies
+	// to both versions of the schema as soon as the migration starts
+	return &StartResult{Actions: []DBAction{
+		NewEnableRowLevelSecurityAction(conn, table.Name, o.Force),
+	}}, nil
+}
+
+func (o *OpEnableRLS) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	// No-op
+	return nil, nil
+}
+
+func (o *OpEnableRLS) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+
+	return []DBAction{NewDisableRowLevelSecurityAction(conn, table.Name)}, nil
+}
+
+func (o *OpEnableRLS) Validate(ctx context.Context, s *schema.Schema) error {
+	if o.Table == "" {
+		return FieldRequiredError{Name: "table"}
+	}
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return TableDoesNotExistError{Name: o.Table}
+	}
+	if table.RowLevelSecurity {
+		return RowLevelSecurityAlreadyEnabledError{Table: o.Table}
+	}
+
+	table.RowLevelSecurity = true
+	table.ForceRowLevelSecurity = o.Force
+	return nil
+}
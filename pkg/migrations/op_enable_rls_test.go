@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestEnableRLS(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "enable row level security",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_enable_rls",
+					Operations: migrations.Operations{
+						&migrations.OpEnableRLS{
+							Table: "documents",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// Row level security is enabled on the underlying table
+				RowLevelSecurityMustBeEnabled(t, db, schema, "documents", false)
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				RowLevelSecurityMustBeDisabled(t, db, schema, "documents")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				RowLevelSecurityMustBeEnabled(t, db, schema, "documents", false)
+			},
+		},
+		{
+			name: "enable and force row level security",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_enable_rls",
+					Operations: migrations.Operations{
+						&migrations.OpEnableRLS{
+							Table: "documents",
+							Force: true,
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				RowLevelSecurityMustBeEnabled(t, db, schema, "documents", true)
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				RowLevelSecurityMustBeDisabled(t, db, schema, "documents")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				RowLevelSecurityMustBeEnabled(t, db, schema, "documents", true)
+			},
+		},
+	})
+}
+
+func TestEnableRLSValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "table must exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_enable_rls",
+					Operations: migrations.Operations{
+						&migrations.OpEnableRLS{
+							Table: "documents",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.TableDoesNotExistError{Name: "documents"},
+		},
+		{
+			name: "row level security must not already be enabled",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_enable_rls",
+					Operations: migrations.Operations{
+						&migrations.OpEnableRLS{
+							Table: "documents",
+						},
+					},
+				},
+				{
+					Name: "03_enable_rls",
+					Operations: migrations.Operations{
+						&migrations.OpEnableRLS{
+							Table: "documents",
+							Force: true,
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.RowLevelSecurityAlreadyEnabledError{Table: "documents"},
+		},
+	})
+}
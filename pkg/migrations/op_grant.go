@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation  = (*OpGrant)(nil)
+	_ Createable = (*OpGrant)(nil)
+)
+
+func (o *OpGrant) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+
+	// Privileges are granted on the underlying table, so that the roles can
+	// use the table through the views of both versions of the schema as soon
+	// as the migration starts
+	return &StartResult{Actions: []DBAction{
+		NewGrantAction(conn, table.Name, o.Privileges, o.Roles, o.WithGrantOption),
+	}}, nil
+}
+
+func (o *OpGrant) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	// No-op
+	return nil, nil
+}
+
+func (o *OpGrant) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+
+	// Only revoke the privileges that each role didn't have before the
+	// migration, according to the schema after the previous migration, so
+	// that rolling back doesn't take away privileges granted earlier
+	var actions []DBAction
+	for _, role := range o.Roles {
+		missing := missingPrivileges(table, role, o.Privileges)
+		if len(missing) == 0 {
+			continue
+		}
+		actions = append(actions, NewRevokeAction(conn, table.Name, missing, []string{role}))
+	}
+	return actions, nil
+}
+
+func (o *OpGrant) Validate(ctx context.Context, s *schema.Schema) error {
+	return validatePrivilegeChange(s, o.Table, o.Privileges, o.Roles)
+}
+
+// validatePrivilegeChange returns an error if `privileges` on `table` can't be
+// granted to or revoked from `roles`.
+func validatePrivilegeChange(s *schema.Schema, table string, privileges []TablePrivilege, roles []string) error {
+	if table == "" {
+		return FieldRequiredError{Name: "table"}
+	}
+	if s.GetTable(table) == nil {
+		return TableDoesNotExistError{Name: table}
+	}
+	if err := ValidatePrivileges(privileges); err != nil {
+		return err
+	}
+	if len(roles) == 0 {
+		return FieldRequiredError{Name: "roles"}
+	}
+	return nil
+}
+
+// missingPrivileges returns the privileges in `privileges` that `role` hasn't
+// been granted on `table`, according to the schema. `ALL` is expanded into
+// the individual privileges unless the role has no privileges on the table.
+func missingPrivileges(table *schema.Table, role string, privileges []TablePrivilege) []TablePrivilege {
+	if strings.EqualFold(role, "public") {
+		role = "public"
+	}
+	granted := table.Privileges[role]
+	if len(granted) == 0 {
+		return privileges
+	}
+
+	var missing []TablePrivilege
+	for _, p := range privileges {
+		expanded := []TablePrivilege{TablePrivilege(strings.ToLower(string(p)))}
+		if expanded[0] == TablePrivilegeAll {
+			expanded = slices.DeleteFunc(slices.Clone(tablePrivileges), func(p TablePrivilege) bool {
+				return p == TablePrivilegeAll
+			})
+		}
+		for _, p := range expanded {
+			if !slices.Contains(granted, string(p)) && !slices.Contains(missing, p) {
+				missing = append(missing, p)
+			}
+		}
+	}
+	return missing
+}
@@ -0,0 +1,262 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestGrant(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "grant privileges on a table",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_grant",
+					Operations: migrations.Operations{
+						&migrations.OpGrant{
+							Table: "documents",
+							Privileges: []migrations.TablePrivilege{
+								migrations.TablePrivilegeSelect,
+								migrations.TablePrivilegeInsert,
+							},
+							Roles: []string{"pgroll"},
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The privileges are granted when the migration starts
+				TablePrivilegeMustBeGranted(t, db, schema, "documents", "pgroll", "SELECT")
+				TablePrivilegeMustBeGranted(t, db, schema, "documents", "pgroll", "INSERT")
+				TablePrivilegeMustNotBeGranted(t, db, schema, "documents", "pgroll", "DELETE")
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				TablePrivilegeMustNotBeGranted(t, db, schema, "documents", "pgroll", "SELECT")
+				TablePrivilegeMustNotBeGranted(t, db, schema, "documents", "pgroll", "INSERT")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				TablePrivilegeMustBeGranted(t, db, schema, "documents", "pgroll", "SELECT")
+				TablePrivilegeMustBeGranted(t, db, schema, "documents", "pgroll", "INSERT")
+			},
+		},
+		{
+			name: "rollback keeps privileges that were granted before the migration",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_grant_select",
+					Operations: migrations.Operations{
+						&migrations.OpRawSQL{
+							Up: "GRANT SELECT ON documents TO pgroll",
+						},
+					},
+				},
+				{
+					Name: "03_grant",
+					Operations: migrations.Operations{
+						&migrations.OpGrant{
+							Table: "documents",
+							Privileges: []migrations.TablePrivilege{
+								migrations.TablePrivilegeSelect,
+								migrations.TablePrivilegeInsert,
+							},
+							Roles: []string{"pgroll"},
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				TablePrivilegeMustBeGranted(t, db, schema, "documents", "pgroll", "SELECT")
+				TablePrivilegeMustBeGranted(t, db, schema, "documents", "pgroll", "INSERT")
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				// Only the privilege that was missing when the migration started
+				// is revoked
+				TablePrivilegeMustBeGranted(t, db, schema, "documents", "pgroll", "SELECT")
+				TablePrivilegeMustNotBeGranted(t, db, schema, "documents", "pgroll", "INSERT")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				TablePrivilegeMustBeGranted(t, db, schema, "documents", "pgroll", "SELECT")
+				TablePrivilegeMustBeGranted(t, db, schema, "documents", "pgroll", "INSERT")
+			},
+		},
+		{
+			name: "grant privileges on a table created in the same migration",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+						&migrations.OpGrant{
+							Table:      "documents",
+							Privileges: []migrations.TablePrivilege{migrations.TablePrivilegeAll},
+							Roles:      []string{"pgroll"},
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				TablePrivilegeMustBeGranted(t, db, schema, "documents", "pgroll", "UPDATE")
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				TableMustNotExist(t, db, schema, "documents")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				TablePrivilegeMustBeGranted(t, db, schema, "documents", "pgroll", "UPDATE")
+			},
+		},
+	})
+}
+
+func TestGrantValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "table must exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_grant",
+					Operations: migrations.Operations{
+						&migrations.OpGrant{
+							Table:      "documents",
+							Privileges: []migrations.TablePrivilege{migrations.TablePrivilegeSelect},
+							Roles:      []string{"pgroll"},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.TableDoesNotExistError{Name: "documents"},
+		},
+		{
+			name: "privileges are required",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_grant",
+					Operations: migrations.Operations{
+						&migrations.OpGrant{
+							Table: "documents",
+							Roles: []string{"pgroll"},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.FieldRequiredError{Name: "privileges"},
+		},
+		{
+			name: "privileges must be valid",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_grant",
+					Operations: migrations.Operations{
+						&migrations.OpGrant{
+							Table:      "documents",
+							Privileges: []migrations.TablePrivilege{"usage"},
+							Roles:      []string{"pgroll"},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidPrivilegeError{Privilege: "usage"},
+		},
+	})
+}
@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation         = (*OpMoveTable)(nil)
+	_ Createable        = (*OpMoveTable)(nil)
+	_ IsolatedOperation = (*OpMoveTable)(nil)
+)
+
+func (o *OpMoveTable) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+	target := s.GetSchema(o.ToSchema)
+	if target == nil {
+		return nil, SchemaDoesNotExistError{Name: o.ToSchema}
+	}
+
+	// The table is moved on start. The views of the old version of the schema
+	// keep working, as views refer to the tables they read by OID.
+	sql := fmt.Sprintf("ALTER TABLE %s SET SCHEMA %s",
+		pq.QuoteIdentifier(table.Name),
+		pq.QuoteIdentifier(o.ToSchema))
+
+	o.updateSchema(s, target, table)
+
+	return &StartResult{Actions: []DBAction{NewRawSQLAction(conn, sql)}}, nil
+}
+
+func (o *OpMoveTable) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	return []DBAction{}, nil
+}
+
+func (o *OpMoveTable) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	target := s.GetSchema(o.ToSchema)
+	if target == nil {
+		return nil, SchemaDoesNotExistError{Name: o.ToSchema}
+	}
+	table := target.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+
+	// The table may not have been moved if the start of the migration failed
+	sql := fmt.Sprintf("ALTER TABLE IF EXISTS %s.%s SET SCHEMA %s",
+		pq.QuoteIdentifier(o.ToSchema),
+		pq.QuoteIdentifier(table.Name),
+		pq.QuoteIdentifier(s.Name))
+
+	return []DBAction{NewRawSQLAction(conn, sql)}, nil
+}
+
+func (o *OpMoveTable) Validate(ctx context.Context, s *schema.Schema) error {
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return TableDoesNotExistError{Name: o.Table}
+	}
+	if o.ToSchema == "" {
+		return FieldRequiredError{Name: "to_schema"}
+	}
+	if o.ToSchema == s.Name {
+		return InvalidMigrationError{Reason: fmt.Sprintf("table %q is already in schema %q", o.Table, o.ToSchema)}
+	}
+	target := s.GetSchema(o.ToSchema)
+	if target == nil {
+		return SchemaDoesNotExistError{Name: o.ToSchema}
+	}
+	if target.GetTable(o.Table) != nil {
+		return TableAlreadyExistsError{Name: fmt.Sprintf("%s.%s", o.ToSchema, o.Table)}
+	}
+
+	// Partitions are moved separately from the table they belong to
+	if table.Partitioning != nil {
+		return TableIsPartitionedError{Name: o.Table}
+	}
+	if table.PartitionOf != "" {
+		return TableIsPartitionError{Name: o.Table, Table: table.PartitionOf}
+	}
+
+	o.updateSchema(s, target, table)
+	return nil
+}
+
+// IsIsolated returns true as the table is moved on migration start, after
+// which other operations in the migration would find it in a different
+// schema from the one they target.
+func (o *OpMoveTable) IsIsolated() bool {
+	return true
+}
+
+// updateSchema moves the table from `s` to `target` in the in-memory schemas
+// and updates the foreign keys between the table and the tables in either
+// schema to name the schema of the table they reference.
+func (o *OpMoveTable) updateSchema(s, target *schema.Schema, table *schema.Table) {
+	delete(s.Tables, o.Table)
+	target.AddTable(o.Table, table)
+
+	for _, fk := range table.ForeignKeys {
+		switch {
+		case fk.ReferencedSchema == "" && fk.ReferencedTable != table.Name:
+			fk.ReferencedSchema = s.Name
+		case fk.ReferencedSchema == target.Name:
+			fk.ReferencedSchema = ""
+		}
+	}
+	for _, t := range s.Tables {
+		for _, fk := range t.ForeignKeys {
+			if fk.ReferencedSchema == "" && fk.ReferencedTable == table.Name {
+				fk.ReferencedSchema = target.Name
+			}
+		}
+	}
+	for _, t := range target.Tables {
+		for _, fk := range t.ForeignKeys {
+			if fk.ReferencedSchema == s.Name && fk.ReferencedTable == table.Name {
+				fk.ReferencedSchema = ""
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation         = (*OpPartitionTable)(nil)
+	_ Createable        = (*OpPartitionTable)(nil)
+	_ IsolatedOperation = (*OpPartitionTable)(nil)
+)
+
+func (o *OpPartitionTable) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+
+	// Create a partitioned copy of the table and its partitions. The copy is
+	// kept in sync with the table by a trigger and filled by the backfill,
+	// before it replaces the table on migration completion.
+	copyName := TemporaryName(table.Name)
+	dbActions := []DBAction{
+		NewCreateTableCopyAction(conn, table.Name, copyName, partitionBySQL(o.PartitionBy)),
+	}
+	for _, p := range o.Partitions {
+		dbActions = append(dbActions, NewCreatePartitionAction(conn, copyName, p.Name, p.Bound))
+	}
+
+	task := backfill.NewCopyTask(table, backfill.TableCopy{
+		TableName:  table.Name,
+		CopyName:   copyName,
+		PrimaryKey: table.PrimaryKey,
+	})
+
+	o.updateSchema(table)
+
+	return &StartResult{Actions: dbActions, BackfillTask: task}, nil
+}
+
+func (o *OpPartitionTable) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	return []DBAction{
+		NewReplaceTableWithCopyAction(conn, o.Table, TemporaryName(o.Table)),
+	}, nil
+}
+
+func (o *OpPartitionTable) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+	table.Partitioning = nil
+
+	// Dropping the partitioned copy drops its partitions
+	return []DBAction{
+		NewDropFunctionAction(conn, backfill.CopyTriggerName(table.Name)),
+		NewDropColumnAction(conn, table.Name, backfill.CNeedsBackfillColumn),
+		NewDropTableAction(conn, TemporaryName(table.Name)),
+	}, nil
+}
+
+func (o *OpPartitionTable) Validate(ctx context.Context, s *schema.Schema) error {
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return TableDoesNotExistError{Name: o.Table}
+	}
+	if table.Partitioning != nil {
+		return TableIsPartitionedError{Name: o.Table}
+	}
+	if table.PartitionOf != "" {
+		return TableIsPartitionError{Name: o.Table, Table: table.PartitionOf}
+	}
+
+	if len(o.Partitions) == 0 {
+		return FieldRequiredError{Name: "partitions"}
+	}
+	seen := make(map[string]bool, len(o.Partitions))
+	for _, p := range o.Partitions {
+		if p.Name == "" {
+			return FieldRequiredError{Name: "name"}
+		}
+		if p.Bound == "" {
+			return FieldRequiredError{Name: "bound"}
+		}
+		if err := ValidateIdentifierLength(p.Name); err != nil {
+			return err
+		}
+		if s.GetTable(p.Name) != nil {
+			return TableAlreadyExistsError{Name: p.Name}
+		}
+		if seen[p.Name] {
+			return PartitionAlreadyExistsError{Table: o.Table, Name: p.Name}
+		}
+		seen[p.Name] = true
+	}
+
+	// Rows are copied to the partitioned table by primary key
+	if len(table.PrimaryKey) == 0 {
+		return InvalidPartitioningError{Table: o.Table, Reason: "the table must have a primary key"}
+	}
+	if err := validatePartitionBy(table, o.PartitionBy); err != nil {
+		return err
+	}
+	if reason := tableCopyLoss(table); reason != "" {
+		return InvalidPartitioningError{Table: o.Table, Reason: reason}
+	}
+
+	// Foreign keys that reference the table would continue to reference the
+	// table that the partitioned table replaces
+	for _, name := range slices.Sorted(maps.Keys(s.Tables)) {
+		other := s.GetTable(name)
+		if other == nil {
+			continue
+		}
+		for _, fk := range other.ForeignKeys {
+			if fk.ReferencedSchema == "" && fk.ReferencedTable == table.Name {
+				return InvalidPartitioningError{
+					Table:  o.Table,
+					Reason: fmt.Sprintf("the table is referenced by foreign key %q on table %q", fk.Name, name),
+				}
+			}
+		}
+	}
+
+	o.updateSchema(table)
+	return nil
+}
+
+// IsIsolated returns true as the table is replaced by its partitioned copy on
+// migration completion, which other operations in the migration can't take
+// into account.
+func (o *OpPartitionTable) IsIsolated() bool {
+	return true
+}
+
+// updateSchema marks the table as partitioned in the in-memory schema.
+func (o *OpPartitionTable) updateSchema(table *schema.Table) {
+	table.Partitioning = partitioning(o.PartitionBy)
+	for _, p := range o.Partitions {
+		table.AddPartition(&schema.Partition{Name: p.Name, Bound: p.Bound})
+	}
+}
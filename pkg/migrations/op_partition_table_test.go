@@ -0,0 +1,520 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestPartitionTable(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "partition a table",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_partition_table",
+					Operations: migrations.Operations{
+						&migrations.OpPartitionTable{
+							Table: "users",
+							PartitionBy: migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyHash,
+								Columns:  []string{"id"},
+							},
+							Partitions: []migrations.Partition{
+								{Name: "users_0", Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 0)"},
+								{Name: "users_1", Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 1)"},
+							},
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The partitioned copy of the table and its partitions have been created
+				TableMustBePartitioned(t, db, schema, migrations.TemporaryName("users"))
+				PartitionMustExist(t, db, schema, migrations.TemporaryName("users"), "users_0")
+				PartitionMustExist(t, db, schema, migrations.TemporaryName("users"), "users_1")
+
+				// The table itself is not partitioned until the migration completes
+				TableMustNotBePartitioned(t, db, schema, "users")
+
+				// Rows written through either version of the schema are copied to the
+				// partitioned table
+				MustInsert(t, db, schema, "01_create_table", "users", map[string]string{
+					"name": "alice",
+				})
+				MustInsert(t, db, schema, "02_partition_table", "users", map[string]string{
+					"name": "bob",
+				})
+				rows := MustSelect(t, db, schema, "02_partition_table", "users")
+				assert.Equal(t, []map[string]any{
+					{"id": 1, "name": "alice"},
+					{"id": 2, "name": "bob"},
+				}, rows)
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				// The partitioned copy of the table has been dropped
+				TableMustNotExist(t, db, schema, migrations.TemporaryName("users"))
+				TableMustNotExist(t, db, schema, "users_0")
+				TableMustNotBePartitioned(t, db, schema, "users")
+
+				// The copy trigger and the backfill column have been removed
+				FunctionMustNotExist(t, db, schema, backfill.CopyTriggerName("users"))
+				ColumnMustNotExist(t, db, schema, "users", backfill.CNeedsBackfillColumn)
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				// The table has been replaced by its partitioned copy
+				TableMustBePartitioned(t, db, schema, "users")
+				PartitionMustExist(t, db, schema, "users", "users_0")
+				PartitionMustExist(t, db, schema, "users", "users_1")
+				TableMustNotExist(t, db, schema, migrations.TemporaryName("users"))
+				ColumnMustNotExist(t, db, schema, "users", backfill.CNeedsBackfillColumn)
+
+				// The primary key keeps its name
+				PrimaryKeyConstraintMustExist(t, db, schema, "users", "users_pkey")
+
+				// The existing rows have been copied and the serial column continues
+				// from its previous value
+				MustInsert(t, db, schema, "02_partition_table", "users", map[string]string{
+					"name": "carl",
+				})
+				// Rows are returned partition by partition
+				rows := MustSelect(t, db, schema, "02_partition_table", "users")
+				assert.ElementsMatch(t, []map[string]any{
+					{"id": 1, "name": "alice"},
+					{"id": 2, "name": "bob"},
+					{"id": 3, "name": "carl"},
+				}, rows)
+			},
+		},
+		{
+			name: "the owner, privileges and truncation of a partitioned table are carried over",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_set_table_settings",
+					Operations: migrations.Operations{
+						&migrations.OpRawSQL{
+							Up: "ALTER TABLE users OWNER TO pgroll; GRANT SELECT (name) ON users TO PUBLIC",
+						},
+					},
+				},
+				{
+					Name: "03_partition_table",
+					Operations: migrations.Operations{
+						&migrations.OpPartitionTable{
+							Table: "users",
+							PartitionBy: migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyHash,
+								Columns:  []string{"id"},
+							},
+							Partitions: []migrations.Partition{
+								{Name: "users_0", Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 0)"},
+								{Name: "users_1", Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 1)"},
+							},
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The partitioned copy of the table has the owner of the table
+				TableOwnerMustBe(t, db, schema, migrations.TemporaryName("users"), "pgroll")
+
+				// Truncating the table truncates its partitioned copy
+				MustInsert(t, db, schema, "03_partition_table", "users", map[string]string{
+					"name": "alice",
+				})
+				MustTruncate(t, db, schema, "users")
+				TableMustBeEmpty(t, db, schema, migrations.TemporaryName("users"))
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				TableOwnerMustBe(t, db, schema, "users", "pgroll")
+				ColumnPrivilegeMustBeGranted(t, db, schema, "users", "name", "public", "SELECT")
+				TableMustBeEmpty(t, db, schema, "users")
+			},
+		},
+		{
+			name: "views that read from a partitioned table are recreated",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+				{
+					Name: "03_partition_table",
+					Operations: migrations.Operations{
+						&migrations.OpPartitionTable{
+							Table: "users",
+							PartitionBy: migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyHash,
+								Columns:  []string{"id"},
+							},
+							Partitions: []migrations.Partition{
+								{Name: "users_0", Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 0)"},
+								{Name: "users_1", Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 1)"},
+							},
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				MustInsert(t, db, schema, "03_partition_table", "users", map[string]string{
+					"name": "alice",
+				})
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				SchemaViewMustExist(t, db, schema, "user_names")
+
+				// The view reads from the partitioned table
+				MustInsert(t, db, schema, "03_partition_table", "users", map[string]string{
+					"name": "bob",
+				})
+				rows := MustSelect(t, db, schema, "03_partition_table", "user_names")
+				assert.ElementsMatch(t, []map[string]any{
+					{"id": 1, "name": "alice"},
+					{"id": 2, "name": "bob"},
+				}, rows)
+			},
+		},
+	})
+}
+
+func TestPartitionTableValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "table must exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_partition_table",
+					Operations: migrations.Operations{
+						&migrations.OpPartitionTable{
+							Table: "users",
+							PartitionBy: migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyHash,
+								Columns:  []string{"id"},
+							},
+							Partitions: []migrations.Partition{
+								{Name: "users_0", Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 0)"},
+								{Name: "users_1", Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 1)"},
+							},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.TableDoesNotExistError{Name: "users"},
+		},
+		{
+			name: "table must not already be partitioned",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "events",
+							Columns: []migrations.Column{
+								{Name: "id", Type: "integer"},
+								{Name: "created_at", Type: "date"},
+							},
+							Constraints: []migrations.Constraint{
+								{
+									Name:    "events_pkey",
+									Type:    migrations.ConstraintTypePrimaryKey,
+									Columns: []string{"id", "created_at"},
+								},
+							},
+							PartitionBy: &migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyRange,
+								Columns:  []string{"created_at"},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_partition_table",
+					Operations: migrations.Operations{
+						&migrations.OpPartitionTable{
+							Table: "events",
+							PartitionBy: migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyHash,
+								Columns:  []string{"id"},
+							},
+							Partitions: []migrations.Partition{
+								{Name: "events_0", Bound: "FOR VALUES WITH (MODULUS 1, REMAINDER 0)"},
+							},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.TableIsPartitionedError{Name: "events"},
+		},
+		{
+			name: "partitions are required",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_partition_table",
+					Operations: migrations.Operations{
+						&migrations.OpPartitionTable{
+							Table: "users",
+							PartitionBy: migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyHash,
+								Columns:  []string{"id"},
+							},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.FieldRequiredError{Name: "partitions"},
+		},
+		{
+			name: "partition columns must be in the primary key",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_partition_table",
+					Operations: migrations.Operations{
+						&migrations.OpPartitionTable{
+							Table: "users",
+							PartitionBy: migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyList,
+								Columns:  []string{"name"},
+							},
+							Partitions: []migrations.Partition{
+								{Name: "users_default", Bound: "DEFAULT"},
+							},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidPartitioningError{Table: "users", Reason: "the primary key must include all partition columns"},
+		},
+		{
+			name: "table must not have triggers",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_trigger",
+					Operations: migrations.Operations{
+						&migrations.OpRawSQL{
+							Up: `
+								CREATE FUNCTION audit_users() RETURNS trigger LANGUAGE plpgsql AS $$ BEGIN RETURN NEW; END $$;
+								CREATE TRIGGER users_audit BEFORE INSERT ON users FOR EACH ROW EXECUTE FUNCTION audit_users()`,
+						},
+					},
+				},
+				{
+					Name: "03_partition_table",
+					Operations: migrations.Operations{
+						&migrations.OpPartitionTable{
+							Table: "users",
+							PartitionBy: migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyHash,
+								Columns:  []string{"id"},
+							},
+							Partitions: []migrations.Partition{
+								{Name: "users_0", Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 0)"},
+								{Name: "users_1", Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 1)"},
+							},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidPartitioningError{Table: "users", Reason: `the table has trigger "users_audit"`},
+		},
+		{
+			name: "table must not be referenced by foreign keys",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "orders",
+							Columns: []migrations.Column{
+								{Name: "id", Type: "serial", Pk: true},
+								{
+									Name: "user_id",
+									Type: "integer",
+									References: &migrations.ForeignKeyReference{
+										Name:   "fk_orders_users",
+										Table:  "users",
+										Column: "id",
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "03_partition_table",
+					Operations: migrations.Operations{
+						&migrations.OpPartitionTable{
+							Table: "users",
+							PartitionBy: migrations.PartitionBy{
+								Strategy: migrations.PartitionByStrategyHash,
+								Columns:  []string{"id"},
+							},
+							Partitions: []migrations.Partition{
+								{Name: "users_0", Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 0)"},
+								{Name: "users_1", Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 1)"},
+							},
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidPartitioningError{
+				Table:  "users",
+				Reason: `the table is referenced by foreign key "fk_orders_users" on table "orders"`,
+			},
+		},
+	})
+}
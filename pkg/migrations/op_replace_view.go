@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation  = (*OpReplaceView)(nil)
+	_ Createable = (*OpReplaceView)(nil)
+)
+
+func (o *OpReplaceView) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	// Replace the view's definition in the in-memory schema. The new version
+	// schema exposes the new definition, while the old version schema and the
+	// underlying schema keep the old one until the migration is completed.
+	s.AddView(&schema.View{Name: o.Name, Definition: o.Definition})
+
+	return nil, nil
+}
+
+func (o *OpReplaceView) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	// No-op: views in the underlying schema are recreated once all operations
+	// in the migration are complete
+	return nil, nil
+}
+
+func (o *OpReplaceView) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	// No-op
+	return nil, nil
+}
+
+func (o *OpReplaceView) Validate(ctx context.Context, s *schema.Schema) error {
+	if o.Name == "" {
+		return FieldRequiredError{Name: "name"}
+	}
+
+	if s.GetView(o.Name) == nil {
+		return ViewDoesNotExistError{Name: o.Name}
+	}
+
+	if o.Definition == "" {
+		return FieldRequiredError{Name: "definition"}
+	}
+	if err := validateView(s, o.Name, o.Definition); err != nil {
+		return err
+	}
+
+	s.AddView(&schema.View{Name: o.Name, Definition: o.Definition})
+	return nil
+}
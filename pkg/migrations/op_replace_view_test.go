@@ -0,0 +1,234 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestReplaceView(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "replace view",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "SELECT id FROM users",
+						},
+					},
+				},
+				{
+					Name: "03_replace_view",
+					Operations: migrations.Operations{
+						&migrations.OpReplaceView{
+							Name:       "user_names",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				MustInsert(t, db, schema, "03_replace_view", "users", map[string]string{
+					"name": "alice",
+				})
+
+				// The new version schema exposes the new definition
+				rows := MustSelect(t, db, schema, "03_replace_view", "user_names")
+				assert.Equal(t, []map[string]any{{"id": 1, "name": "alice"}}, rows)
+
+				// The old version schema keeps the old definition
+				rows = MustSelect(t, db, schema, "02_create_view", "user_names")
+				assert.Equal(t, []map[string]any{{"id": 1}}, rows)
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				SchemaViewMustExist(t, db, schema, "user_names")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				SchemaViewMustExist(t, db, schema, "user_names")
+				rows := MustSelect(t, db, schema, "03_replace_view", "user_names")
+				assert.Equal(t, []map[string]any{{"id": 1, "name": "alice"}}, rows)
+			},
+		},
+		{
+			name: "replace view to read from a renamed column",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+				{
+					Name: "03_rename_column",
+					Operations: migrations.Operations{
+						&migrations.OpRenameColumn{
+							Table: "users",
+							From:  "name",
+							To:    "username",
+						},
+						&migrations.OpReplaceView{
+							Name:       "user_names",
+							Definition: "SELECT id, username FROM users",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				MustInsert(t, db, schema, "03_rename_column", "users", map[string]string{
+					"username": "alice",
+				})
+
+				// Each version schema's view reads from the column by the name
+				// it has in that version
+				rows := MustSelect(t, db, schema, "03_rename_column", "user_names")
+				assert.Equal(t, []map[string]any{{"id": 1, "username": "alice"}}, rows)
+				rows = MustSelect(t, db, schema, "02_create_view", "user_names")
+				assert.Equal(t, []map[string]any{{"id": 1, "name": "alice"}}, rows)
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				SchemaViewMustExist(t, db, schema, "user_names")
+				rows := MustSelect(t, db, schema, "03_rename_column", "user_names")
+				assert.Equal(t, []map[string]any{{"id": 1, "username": "alice"}}, rows)
+			},
+		},
+	})
+}
+
+func TestReplaceViewValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "view must exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_replace_view",
+					Operations: migrations.Operations{
+						&migrations.OpReplaceView{
+							Name:       "user_names",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.ViewDoesNotExistError{Name: "user_names"},
+		},
+		{
+			name: "definition must read from existing columns",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+				{
+					Name: "03_replace_view",
+					Operations: migrations.Operations{
+						&migrations.OpReplaceView{
+							Name:       "user_names",
+							Definition: "SELECT id, email FROM users",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.ViewDependencyMissingError{View: "user_names", Relation: "users", Column: "email"},
+		},
+	})
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation  = (*OpRevoke)(nil)
+	_ Createable = (*OpRevoke)(nil)
+)
+
+func (o *OpRevoke) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	// The privileges are revoked on migration completion, so that the roles
+	// can keep using the old version of the schema during the active
+	// migration period
+	return nil, nil
+}
+
+func (o *OpRevoke) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+
+	return []DBAction{NewRevokeAction(conn, table.Name, o.Privileges, o.Roles)}, nil
+}
+
+func (o *OpRevoke) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	// No-op
+	return nil, nil
+}
+
+func (o *OpRevoke) Validate(ctx context.Context, s *schema.Schema) error {
+	return validatePrivilegeChange(s, o.Table, o.Privileges, o.Roles)
+}
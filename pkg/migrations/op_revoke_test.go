@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestRevoke(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "revoke privileges on a table",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_grant",
+					Operations: migrations.Operations{
+						&migrations.OpGrant{
+							Table: "documents",
+							Privileges: []migrations.TablePrivilege{
+								migrations.TablePrivilegeSelect,
+								migrations.TablePrivilegeInsert,
+							},
+							Roles: []string{"pgroll"},
+						},
+					},
+				},
+				{
+					Name: "03_revoke",
+					Operations: migrations.Operations{
+						&migrations.OpRevoke{
+							Table:      "documents",
+							Privileges: []migrations.TablePrivilege{migrations.TablePrivilegeInsert},
+							Roles:      []string{"pgroll"},
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The privileges are not revoked until the migration is completed
+				TablePrivilegeMustBeGranted(t, db, schema, "documents", "pgroll", "INSERT")
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				TablePrivilegeMustBeGranted(t, db, schema, "documents", "pgroll", "INSERT")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				TablePrivilegeMustNotBeGranted(t, db, schema, "documents", "pgroll", "INSERT")
+				TablePrivilegeMustBeGranted(t, db, schema, "documents", "pgroll", "SELECT")
+			},
+		},
+	})
+}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation         = (*OpRewriteTable)(nil)
+	_ Createable        = (*OpRewriteTable)(nil)
+	_ IsolatedOperation = (*OpRewriteTable)(nil)
+)
+
+func (o *OpRewriteTable) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+
+	// Create a copy of the table with the new storage settings. The copy is
+	// kept in sync with the table by a trigger and filled by the backfill,
+	// before it replaces the table on migration completion.
+	copyName := TemporaryName(table.Name)
+	dbActions := []DBAction{
+		NewCreateTableCopyAction(conn, table.Name, copyName, ""),
+		NewCopyTableSettingsAction(conn, table.Name, copyName, o.StorageParameters, o.Tablespace),
+	}
+	if table.Comment != "" {
+		dbActions = append(dbActions, NewCommentTableAction(conn, copyName, &table.Comment))
+	}
+
+	task := backfill.NewCopyTask(table, backfill.TableCopy{
+		TableName:  table.Name,
+		CopyName:   copyName,
+		PrimaryKey: table.PrimaryKey,
+	})
+
+	return &StartResult{Actions: dbActions, BackfillTask: task}, nil
+}
+
+func (o *OpRewriteTable) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	return []DBAction{
+		NewReplaceTableWithCopyAction(conn, o.Table, TemporaryName(o.Table)),
+	}, nil
+}
+
+func (o *OpRewriteTable) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+
+	return []DBAction{
+		NewDropFunctionAction(conn, backfill.CopyTriggerName(table.Name)),
+		NewDropColumnAction(conn, table.Name, backfill.CNeedsBackfillColumn),
+		NewDropTableAction(conn, TemporaryName(table.Name)),
+	}, nil
+}
+
+func (o *OpRewriteTable) Validate(ctx context.Context, s *schema.Schema) error {
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return TableDoesNotExistError{Name: o.Table}
+	}
+	if table.Partitioning != nil {
+		return TableIsPartitionedError{Name: o.Table}
+	}
+	if table.PartitionOf != "" {
+		return TableIsPartitionError{Name: o.Table, Table: table.PartitionOf}
+	}
+
+	// Rows are copied to the new table by primary key
+	if len(table.PrimaryKey) == 0 {
+		return InvalidTableRewriteError{Table: o.Table, Reason: "the table must have a primary key"}
+	}
+
+	if reason := tableCopyLoss(table); reason != "" {
+		return InvalidTableRewriteError{Table: o.Table, Reason: reason}
+	}
+
+	// Foreign keys that reference the table would continue to reference the
+	// table that the new table replaces
+	for _, name := range slices.Sorted(maps.Keys(s.Tables)) {
+		other := s.GetTable(name)
+		if other == nil {
+			continue
+		}
+		for _, fk := range other.ForeignKeys {
+			if fk.ReferencedSchema == "" && fk.ReferencedTable == table.Name {
+				return InvalidTableRewriteError{
+					Table:  o.Table,
+					Reason: fmt.Sprintf("the table is referenced by foreign key %q on table %q", fk.Name, name),
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// IsIsolated returns true as the table is replaced by its copy on migration
+// completion, which other operations in the migration can't take into account.
+func (o *OpRewriteTable) IsIsolated() bool {
+	return true
+}
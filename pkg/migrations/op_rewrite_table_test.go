@@ -0,0 +1,454 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestRewriteTable(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "rewrite a table with new storage parameters",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_set_storage_parameters",
+					Operations: migrations.Operations{
+						&migrations.OpRawSQL{
+							Up: "ALTER TABLE users SET (autovacuum_enabled = false)",
+						},
+					},
+				},
+				{
+					Name: "03_rewrite_table",
+					Operations: migrations.Operations{
+						&migrations.OpRewriteTable{
+							Table:             "users",
+							StorageParameters: "fillfactor=70",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The copy of the table has the new storage parameters, as well as
+				// the storage parameters of the table
+				TableMustHaveStorageParameter(t, db, schema, migrations.TemporaryName("users"), "fillfactor=70")
+				TableMustHaveStorageParameter(t, db, schema, migrations.TemporaryName("users"), "autovacuum_enabled=false")
+
+				// Rows written through either version of the schema are copied to
+				// the new table
+				MustInsert(t, db, schema, "02_set_storage_parameters", "users", map[string]string{
+					"name": "alice",
+				})
+				MustInsert(t, db, schema, "03_rewrite_table", "users", map[string]string{
+					"name": "bob",
+				})
+				rows := MustSelect(t, db, schema, "03_rewrite_table", "users")
+				assert.Equal(t, []map[string]any{
+					{"id": 1, "name": "alice"},
+					{"id": 2, "name": "bob"},
+				}, rows)
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				// The copy of the table has been dropped
+				TableMustNotExist(t, db, schema, migrations.TemporaryName("users"))
+
+				// The copy trigger and the backfill column have been removed
+				FunctionMustNotExist(t, db, schema, backfill.CopyTriggerName("users"))
+				ColumnMustNotExist(t, db, schema, "users", backfill.CNeedsBackfillColumn)
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				// The table has been replaced by its copy
+				TableMustHaveStorageParameter(t, db, schema, "users", "fillfactor=70")
+				TableMustHaveStorageParameter(t, db, schema, "users", "autovacuum_enabled=false")
+				TableMustNotExist(t, db, schema, migrations.TemporaryName("users"))
+				ColumnMustNotExist(t, db, schema, "users", backfill.CNeedsBackfillColumn)
+
+				// The primary key keeps its name
+				PrimaryKeyConstraintMustExist(t, db, schema, "users", "users_pkey")
+
+				// The existing rows have been copied and the serial column continues
+				// from its previous value
+				MustInsert(t, db, schema, "03_rewrite_table", "users", map[string]string{
+					"name": "carl",
+				})
+				rows := MustSelect(t, db, schema, "03_rewrite_table", "users")
+				assert.Equal(t, []map[string]any{
+					{"id": 1, "name": "alice"},
+					{"id": 2, "name": "bob"},
+					{"id": 3, "name": "carl"},
+				}, rows)
+			},
+		},
+		{
+			name: "views that read from a rewritten table are recreated",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_view",
+					Operations: migrations.Operations{
+						&migrations.OpCreateView{
+							Name:       "user_names",
+							Definition: "SELECT id, name FROM users",
+						},
+					},
+				},
+				{
+					Name: "03_rewrite_table",
+					Operations: migrations.Operations{
+						&migrations.OpRewriteTable{
+							Table: "users",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				MustInsert(t, db, schema, "03_rewrite_table", "users", map[string]string{
+					"name": "alice",
+				})
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				SchemaViewMustExist(t, db, schema, "user_names")
+
+				rows := MustSelect(t, db, schema, "03_rewrite_table", "user_names")
+				assert.Equal(t, []map[string]any{
+					{"id": 1, "name": "alice"},
+				}, rows)
+			},
+		},
+		{
+			name: "the owner, privileges, replica identity and truncation of a rewritten table are carried over",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_set_table_settings",
+					Operations: migrations.Operations{
+						&migrations.OpRawSQL{
+							Up: "ALTER TABLE users OWNER TO pgroll; GRANT SELECT (name) ON users TO PUBLIC; ALTER TABLE users REPLICA IDENTITY FULL",
+						},
+					},
+				},
+				{
+					Name: "03_rewrite_table",
+					Operations: migrations.Operations{
+						&migrations.OpRewriteTable{
+							Table: "users",
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The copy of the table has the owner of the table
+				TableOwnerMustBe(t, db, schema, migrations.TemporaryName("users"), "pgroll")
+
+				// Truncating the table truncates its copy
+				MustInsert(t, db, schema, "03_rewrite_table", "users", map[string]string{
+					"name": "alice",
+				})
+				MustTruncate(t, db, schema, "users")
+				TableMustBeEmpty(t, db, schema, migrations.TemporaryName("users"))
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				TableOwnerMustBe(t, db, schema, "users", "pgroll")
+				ColumnPrivilegeMustBeGranted(t, db, schema, "users", "name", "public", "SELECT")
+				ReplicaIdentityMustBe(t, db, schema, "users", "f")
+				TableMustBeEmpty(t, db, schema, "users")
+			},
+		},
+	})
+}
+
+func TestRewriteTableValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "table must exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_rewrite_table",
+					Operations: migrations.Operations{
+						&migrations.OpRewriteTable{
+							Table: "users",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.TableDoesNotExistError{Name: "users"},
+		},
+		{
+			name: "table must have a primary key",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{Name: "name", Type: "text"},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_rewrite_table",
+					Operations: migrations.Operations{
+						&migrations.OpRewriteTable{
+							Table: "users",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidTableRewriteError{Table: "users", Reason: "the table must have a primary key"},
+		},
+		{
+			name: "table must not have row level security enabled",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_enable_rls",
+					Operations: migrations.Operations{
+						&migrations.OpEnableRLS{Table: "users"},
+					},
+				},
+				{
+					Name: "03_rewrite_table",
+					Operations: migrations.Operations{
+						&migrations.OpRewriteTable{
+							Table: "users",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidTableRewriteError{Table: "users", Reason: "row level security is enabled on the table"},
+		},
+		{
+			name: "table must not have triggers",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_trigger",
+					Operations: migrations.Operations{
+						&migrations.OpRawSQL{
+							Up: `
+								CREATE FUNCTION audit_users() RETURNS trigger LANGUAGE plpgsql AS $$ BEGIN RETURN NEW; END $$;
+								CREATE TRIGGER users_audit BEFORE INSERT ON users FOR EACH ROW EXECUTE FUNCTION audit_users()`,
+						},
+					},
+				},
+				{
+					Name: "03_rewrite_table",
+					Operations: migrations.Operations{
+						&migrations.OpRewriteTable{
+							Table: "users",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidTableRewriteError{Table: "users", Reason: `the table has trigger "users_audit"`},
+		},
+		{
+			name: "table must not be in a publication",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_publication",
+					Operations: migrations.Operations{
+						&migrations.OpRawSQL{
+							Up: "CREATE PUBLICATION users_publication FOR TABLE users",
+						},
+					},
+				},
+				{
+					Name: "03_rewrite_table",
+					Operations: migrations.Operations{
+						&migrations.OpRewriteTable{
+							Table: "users",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidTableRewriteError{Table: "users", Reason: `the table is in publication "users_publication"`},
+		},
+		{
+			name: "table must not be referenced by foreign keys",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "users",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "name",
+									Type:     "varchar(255)",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "orders",
+							Columns: []migrations.Column{
+								{Name: "id", Type: "serial", Pk: true},
+								{
+									Name: "user_id",
+									Type: "integer",
+									References: &migrations.ForeignKeyReference{
+										Name:   "fk_orders_users",
+										Table:  "users",
+										Column: "id",
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "03_rewrite_table",
+					Operations: migrations.Operations{
+						&migrations.OpRewriteTable{
+							Table: "users",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidTableRewriteError{
+				Table:  "users",
+				Reason: `the table is referenced by foreign key "fk_orders_users" on table "orders"`,
+			},
+		},
+	})
+}
@@ -31,14 +31,20 @@ func (o *OpSetForeignKey) Start(ctx context.Context, l Logger, conn db.DB, s *sc
 	if column == nil {
 		return nil, ColumnDoesNotExistError{Table: o.Table, Name: o.Column}
 	}
-	referencedTable := s.GetTable(o.References.Table)
-	if referencedTable == nil {
-		return nil, TableDoesNotExistError{Name: o.References.Table}
-	}
 
-	referencedColumn := referencedTable.GetColumn(o.References.Column)
-	if referencedColumn == nil {
-		return nil, ColumnDoesNotExistError{Table: o.References.Table, Name: o.References.Column}
+	// A table in another schema is only known when the schemas read by the
+	// migration are linked, otherwise it is referenced by the names given
+	referencedTable, referencedColumn := o.References.Table, o.References.Column
+	if referenced := s.GetSchema(o.References.Schema); referenced != nil {
+		t := referenced.GetTable(o.References.Table)
+		if t == nil {
+			return nil, TableDoesNotExistError{Name: o.References.Table}
+		}
+		c := t.GetColumn(o.References.Column)
+		if c == nil {
+			return nil, ColumnDoesNotExistError{Table: o.References.Table, Name: o.References.Column}
+		}
+		referencedTable, referencedColumn = t.Name, c.Name
 	}
 
 	dbActions := []DBAction{
@@ -49,8 +55,9 @@ func (o *OpSetForeignKey) Start(ctx context.Context, l Logger, conn db.DB, s *sc
 			o.References.Name,
 			[]string{column.Name},
 			&TableForeignKeyReference{
-				Table:     referencedTable.Name,
-				Columns:   []string{referencedColumn.Name},
+				Schema:    o.References.Schema,
+				Table:     referencedTable,
+				Columns:   []string{referencedColumn},
 				MatchType: o.References.MatchType,
 				OnDelete:  o.References.OnDelete,
 				OnUpdate:  o.References.OnUpdate,
@@ -75,6 +82,7 @@ func (o *OpSetForeignKey) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DB
 		Name:              o.References.Name,
 		Columns:           []string{o.Column},
 		ReferencedTable:   o.References.Table,
+		ReferencedSchema:  o.References.Schema,
 		ReferencedColumns: []string{o.References.Column},
 	}
 
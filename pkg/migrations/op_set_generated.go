@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+// OpSetGenerated is an operation that makes a stored generated column a
+// regular column. Making a column a stored generated column, or changing its
+// generation expression, is rejected because Postgres can't do either without
+// rewriting the table while holding an ACCESS EXCLUSIVE lock on it.
+type OpSetGenerated struct {
+	Table      string  `json:"table"`
+	Column     string  `json:"column"`
+	Expression *string `json:"expression"`
+	Up         string  `json:"up"`
+	Down       string  `json:"down"`
+}
+
+var _ Operation = (*OpSetGenerated)(nil)
+
+func (o *OpSetGenerated) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+
+	// The duplicated column is added as a regular column by the duplicator
+	return &StartResult{BackfillTask: backfill.NewTask(table)}, nil
+}
+
+func (o *OpSetGenerated) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	return nil, nil
+}
+
+func (o *OpSetGenerated) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	return nil, nil
+}
+
+func (o *OpSetGenerated) Validate(ctx context.Context, s *schema.Schema) error {
+	column := s.GetTable(o.Table).GetColumn(o.Column)
+
+	invalid := func(reason string) error {
+		return GeneratedColumnError{Table: o.Table, Column: o.Column, Reason: reason}
+	}
+
+	// Postgres can't add a generation expression to an existing column, and
+	// changing the expression of a generated column with SET EXPRESSION
+	// rewrites the table while holding an ACCESS EXCLUSIVE lock on it. Neither
+	// can be done online, so only a generated column can be made a regular
+	// column.
+	switch {
+	case o.Expression != nil && column.Generated == "":
+		return invalid("Postgres can't make an existing column a stored generated column without rewriting the table")
+	case o.Expression != nil:
+		return invalid("Postgres can't change the expression of a stored generated column without rewriting the table")
+	case column.Generated == "":
+		return invalid("the column is not a generated column")
+	}
+
+	return nil
+}
@@ -0,0 +1,287 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/oapi-codegen/nullable"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestSetGenerated(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "make a generated column a regular column",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "items",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name: "price",
+									Type: "integer",
+								},
+								{
+									Name: "quantity",
+									Type: "integer",
+								},
+								{
+									Name:      "total",
+									Type:      "integer",
+									Nullable:  true,
+									Generated: &migrations.ColumnGenerated{Expression: "price * quantity"},
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_drop_generated",
+					Operations: migrations.Operations{
+						&migrations.OpAlterColumn{
+							Table:     "items",
+							Column:    "total",
+							Generated: nullable.NewNullNullable[string](),
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The new column is a regular column
+				ColumnMustBeGenerated(t, db, schema, "items", "total")
+				ColumnMustNotBeGenerated(t, db, schema, "items", migrations.TemporaryName("total"))
+
+				// Both versions of the schema can insert rows, and the new version
+				// can set the values of the column
+				MustInsert(t, db, schema, "01_create_table", "items", map[string]string{"price": "2", "quantity": "3"})
+				MustInsert(t, db, schema, "02_drop_generated", "items", map[string]string{"price": "4", "quantity": "5", "total": "100"})
+
+				// The old version of the schema keeps seeing the values of the
+				// expression
+				rows := MustSelect(t, db, schema, "01_create_table", "items")
+				assert.Equal(t, []map[string]any{
+					{"id": 1, "price": 2, "quantity": 3, "total": 6},
+					{"id": 2, "price": 4, "quantity": 5, "total": 20},
+				}, rows)
+
+				// The new column is computed from the expression for rows inserted
+				// in the old version
+				rows = MustSelect(t, db, schema, "02_drop_generated", "items")
+				assert.Equal(t, []map[string]any{
+					{"id": 1, "price": 2, "quantity": 3, "total": 6},
+					{"id": 2, "price": 4, "quantity": 5, "total": 100},
+				}, rows)
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				ColumnMustBeGenerated(t, db, schema, "items", "total")
+				TableMustBeCleanedUp(t, db, schema, "items", "total")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				ColumnMustNotBeGenerated(t, db, schema, "items", "total")
+				TableMustBeCleanedUp(t, db, schema, "items", "total")
+
+				MustInsert(t, db, schema, "02_drop_generated", "items", map[string]string{"price": "1", "quantity": "1", "total": "50"})
+			},
+		},
+	})
+}
+
+func TestSetGeneratedValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "column must be a generated column to make it a regular column",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "items",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name: "price",
+									Type: "integer",
+								},
+								{
+									Name: "quantity",
+									Type: "integer",
+								},
+								{
+									Name:     "total",
+									Type:     "integer",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_drop_generated",
+					Operations: migrations.Operations{
+						&migrations.OpAlterColumn{
+							Table:     "items",
+							Column:    "total",
+							Generated: nullable.NewNullNullable[string](),
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.GeneratedColumnError{Table: "items", Column: "total", Reason: "the column is not a generated column"},
+		},
+		{
+			name: "a column can't be made a generated column",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "items",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name: "price",
+									Type: "integer",
+								},
+								{
+									Name: "quantity",
+									Type: "integer",
+								},
+								{
+									Name:     "total",
+									Type:     "integer",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_set_generated",
+					Operations: migrations.Operations{
+						&migrations.OpAlterColumn{
+							Table:     "items",
+							Column:    "total",
+							Generated: nullable.NewNullableWithValue("price * quantity"),
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.GeneratedColumnError{Table: "items", Column: "total", Reason: "Postgres can't make an existing column a stored generated column without rewriting the table"},
+		},
+		{
+			name: "the expression of a generated column can't be changed",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "items",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name: "price",
+									Type: "integer",
+								},
+								{
+									Name: "quantity",
+									Type: "integer",
+								},
+								{
+									Name:      "total",
+									Type:      "integer",
+									Nullable:  true,
+									Generated: &migrations.ColumnGenerated{Expression: "price * quantity"},
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_set_generated",
+					Operations: migrations.Operations{
+						&migrations.OpAlterColumn{
+							Table:     "items",
+							Column:    "total",
+							Generated: nullable.NewNullableWithValue("price * quantity + 10"),
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.GeneratedColumnError{Table: "items", Column: "total", Reason: "Postgres can't change the expression of a stored generated column without rewriting the table"},
+		},
+		{
+			name: "a generated column must be made a regular column to alter it",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "items",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name: "price",
+									Type: "integer",
+								},
+								{
+									Name: "quantity",
+									Type: "integer",
+								},
+								{
+									Name:      "total",
+									Type:      "integer",
+									Nullable:  true,
+									Generated: &migrations.ColumnGenerated{Expression: "price * quantity"},
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_change_type",
+					Operations: migrations.Operations{
+						&migrations.OpAlterColumn{
+							Table:  "items",
+							Column: "total",
+							Type:   ptr("bigint"),
+							Up:     "total",
+							Down:   "total",
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.GeneratedColumnError{Table: "items", Column: "total", Reason: "Postgres can't duplicate a stored generated column without rewriting the table, so the column must be made a regular column"},
+		},
+	})
+}
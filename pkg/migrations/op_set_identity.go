@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+var (
+	_ Operation  = (*OpSetIdentity)(nil)
+	_ Createable = (*OpSetIdentity)(nil)
+)
+
+func (o *OpSetIdentity) Start(ctx context.Context, l Logger, conn db.DB, s *schema.Schema) (*StartResult, error) {
+	l.LogOperationStart(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+	column := table.GetColumn(o.Column)
+	if column == nil {
+		return nil, ColumnDoesNotExistError{Table: o.Table, Name: o.Column}
+	}
+
+	// The column keeps generating its values as before until the migration is
+	// completed, so that both versions of the schema can insert rows during
+	// the active migration period. Only the virtual schema is updated here.
+	o.updateColumn(s, table, column)
+
+	return nil, nil
+}
+
+func (o *OpSetIdentity) Complete(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationComplete(o)
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return nil, TableDoesNotExistError{Name: o.Table}
+	}
+	column := table.GetColumn(o.Column)
+	if column == nil {
+		return nil, ColumnDoesNotExistError{Table: o.Table, Name: o.Column}
+	}
+
+	return []DBAction{NewSetIdentityAction(conn, table.Name, column, o.Identity)}, nil
+}
+
+func (o *OpSetIdentity) Rollback(l Logger, conn db.DB, s *schema.Schema) ([]DBAction, error) {
+	l.LogOperationRollback(o)
+
+	// No-op
+	return nil, nil
+}
+
+func (o *OpSetIdentity) Validate(ctx context.Context, s *schema.Schema) error {
+	if o.Table == "" {
+		return FieldRequiredError{Name: "table"}
+	}
+	if o.Column == "" {
+		return FieldRequiredError{Name: "column"}
+	}
+
+	table := s.GetTable(o.Table)
+	if table == nil {
+		return TableDoesNotExistError{Name: o.Table}
+	}
+	column := table.GetColumn(o.Column)
+	if column == nil {
+		return ColumnDoesNotExistError{Table: o.Table, Name: o.Column}
+	}
+
+	invalid := func(reason string) error {
+		return InvalidIdentityChangeError{Table: o.Table, Column: o.Column, Reason: reason}
+	}
+
+	switch o.Identity {
+	case OpSetIdentityIdentityAlways, OpSetIdentityIdentityByDefault:
+		generation := identityGeneration(o.Identity)
+		switch {
+		case column.Identity == generation:
+			return invalid(fmt.Sprintf("the column is already generated %s as identity", generation))
+		case !isIntegerColumnType(column.Type):
+			return invalid(fmt.Sprintf("identity columns must have an integer type, not %q", column.Type))
+		case column.Nullable:
+			return invalid("identity columns must be NOT NULL")
+		case column.Identity == "" && column.Sequence == "" && column.Default != nil:
+			return invalid("the column has a default that is not generated by a sequence")
+		}
+	case OpSetIdentityIdentityNone:
+		if column.Identity == "" {
+			return invalid("the column is not an identity column")
+		}
+	default:
+		return invalid(fmt.Sprintf("unknown identity %q", o.Identity))
+	}
+
+	o.updateColumn(s, table, column)
+	return nil
+}
+
+// updateColumn updates `column` in the virtual schema to generate its values
+// as it will once the migration is completed.
+func (o *OpSetIdentity) updateColumn(s *schema.Schema, table *schema.Table, column *schema.Column) {
+	// Sequences of serial columns are part of the schema, but the sequences of
+	// identity columns are part of the column
+	if column.Sequence == "" {
+		column.Sequence = fmt.Sprintf("%s_%s_seq", table.Name, column.Name)
+	}
+
+	column.Identity = identityGeneration(o.Identity)
+	if column.Identity != "" {
+		s.RemoveSequence(column.Sequence)
+		column.Default = nil
+		return
+	}
+
+	s.AddSequence(&schema.Sequence{Name: column.Sequence, Type: column.Type})
+	def := fmt.Sprintf("nextval('%s'::regclass)", column.Sequence)
+	column.Default = &def
+}
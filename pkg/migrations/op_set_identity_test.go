@@ -0,0 +1,415 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+func TestSetIdentity(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "serial column to identity column",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_set_identity",
+					Operations: migrations.Operations{
+						&migrations.OpSetIdentity{
+							Table:    "documents",
+							Column:   "id",
+							Identity: migrations.OpSetIdentityIdentityAlways,
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				// The column keeps its sequence until the migration is completed
+				ColumnMustNotBeIdentity(t, db, schema, "documents", "id")
+
+				// Both versions of the schema can insert rows
+				MustInsert(t, db, schema, "01_create_table", "documents", map[string]string{"owner": "alice"})
+				MustInsert(t, db, schema, "02_set_identity", "documents", map[string]string{"owner": "bob"})
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				ColumnMustNotBeIdentity(t, db, schema, "documents", "id")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				ColumnMustBeIdentity(t, db, schema, "documents", "id", "ALWAYS")
+
+				// The identity continues from the sequence of the serial column
+				MustInsert(t, db, schema, "02_set_identity", "documents", map[string]string{"owner": "carol"})
+
+				rows := MustSelect(t, db, schema, "02_set_identity", "documents")
+				assert.Equal(t, []map[string]any{
+					{"id": 1, "owner": "alice"},
+					{"id": 2, "owner": "bob"},
+					{"id": 3, "owner": "carol"},
+				}, rows)
+			},
+		},
+		{
+			name: "change how an identity column is generated",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_set_identity",
+					Operations: migrations.Operations{
+						&migrations.OpSetIdentity{
+							Table:    "documents",
+							Column:   "id",
+							Identity: migrations.OpSetIdentityIdentityAlways,
+						},
+					},
+				},
+				{
+					Name: "03_set_identity",
+					Operations: migrations.Operations{
+						&migrations.OpSetIdentity{
+							Table:    "documents",
+							Column:   "id",
+							Identity: migrations.OpSetIdentityIdentityByDefault,
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				ColumnMustBeIdentity(t, db, schema, "documents", "id", "ALWAYS")
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				ColumnMustBeIdentity(t, db, schema, "documents", "id", "ALWAYS")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				ColumnMustBeIdentity(t, db, schema, "documents", "id", "BY DEFAULT")
+
+				// Values can be given explicitly for columns generated by default
+				MustInsert(t, db, schema, "03_set_identity", "documents", map[string]string{"id": "100", "owner": "alice"})
+			},
+		},
+		{
+			name: "identity column to serial column",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_set_identity",
+					Operations: migrations.Operations{
+						&migrations.OpSetIdentity{
+							Table:    "documents",
+							Column:   "id",
+							Identity: migrations.OpSetIdentityIdentityByDefault,
+						},
+					},
+				},
+				{
+					Name: "03_set_identity",
+					Operations: migrations.Operations{
+						&migrations.OpSetIdentity{
+							Table:    "documents",
+							Column:   "id",
+							Identity: migrations.OpSetIdentityIdentityNone,
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				ColumnMustBeIdentity(t, db, schema, "documents", "id", "BY DEFAULT")
+
+				MustInsert(t, db, schema, "03_set_identity", "documents", map[string]string{"owner": "alice"})
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				ColumnMustBeIdentity(t, db, schema, "documents", "id", "BY DEFAULT")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				ColumnMustNotBeIdentity(t, db, schema, "documents", "id")
+				SequenceMustExist(t, db, schema, "documents_id_seq")
+
+				// The sequence continues from the identity
+				MustInsert(t, db, schema, "03_set_identity", "documents", map[string]string{"owner": "bob"})
+
+				rows := MustSelect(t, db, schema, "03_set_identity", "documents")
+				assert.Equal(t, []map[string]any{
+					{"id": 1, "owner": "alice"},
+					{"id": 2, "owner": "bob"},
+				}, rows)
+			},
+		},
+		{
+			name: "integer column to identity column",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "tickets",
+							Columns: []migrations.Column{
+								{
+									Name: "number",
+									Type: "integer",
+									Pk:   true,
+								},
+								{
+									Name:     "subject",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_set_identity",
+					Operations: migrations.Operations{
+						&migrations.OpSetIdentity{
+							Table:    "tickets",
+							Column:   "number",
+							Identity: migrations.OpSetIdentityIdentityByDefault,
+						},
+					},
+				},
+			},
+			afterStart: func(t *testing.T, db *sql.DB, schema string) {
+				MustInsert(t, db, schema, "02_set_identity", "tickets", map[string]string{"number": "41", "subject": "first"})
+			},
+			afterRollback: func(t *testing.T, db *sql.DB, schema string) {
+				ColumnMustNotBeIdentity(t, db, schema, "tickets", "number")
+			},
+			afterComplete: func(t *testing.T, db *sql.DB, schema string) {
+				ColumnMustBeIdentity(t, db, schema, "tickets", "number", "BY DEFAULT")
+
+				// The identity continues from the maximum value of the column
+				MustInsert(t, db, schema, "02_set_identity", "tickets", map[string]string{"subject": "second"})
+
+				rows := MustSelect(t, db, schema, "02_set_identity", "tickets")
+				assert.Equal(t, []map[string]any{
+					{"number": 41, "subject": "first"},
+					{"number": 42, "subject": "second"},
+				}, rows)
+			},
+		},
+	})
+}
+
+func TestSetIdentityValidation(t *testing.T) {
+	t.Parallel()
+
+	ExecuteTests(t, TestCases{
+		{
+			name: "column must exist",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_set_identity",
+					Operations: migrations.Operations{
+						&migrations.OpSetIdentity{
+							Table:    "documents",
+							Column:   "number",
+							Identity: migrations.OpSetIdentityIdentityAlways,
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.ColumnDoesNotExistError{Table: "documents", Name: "number"},
+		},
+		{
+			name: "column must have an integer type",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_set_identity",
+					Operations: migrations.Operations{
+						&migrations.OpSetIdentity{
+							Table:    "documents",
+							Column:   "owner",
+							Identity: migrations.OpSetIdentityIdentityAlways,
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidIdentityChangeError{Table: "documents", Column: "owner", Reason: `identity columns must have an integer type, not "text"`},
+		},
+		{
+			name: "column must be an identity column to remove its identity",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_set_identity",
+					Operations: migrations.Operations{
+						&migrations.OpSetIdentity{
+							Table:    "documents",
+							Column:   "id",
+							Identity: migrations.OpSetIdentityIdentityNone,
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidIdentityChangeError{Table: "documents", Column: "id", Reason: "the column is not an identity column"},
+		},
+		{
+			name: "identity must change",
+			migrations: []migrations.Migration{
+				{
+					Name: "01_create_table",
+					Operations: migrations.Operations{
+						&migrations.OpCreateTable{
+							Name: "documents",
+							Columns: []migrations.Column{
+								{
+									Name: "id",
+									Type: "serial",
+									Pk:   true,
+								},
+								{
+									Name:     "owner",
+									Type:     "text",
+									Nullable: true,
+								},
+							},
+						},
+					},
+				},
+				{
+					Name: "02_set_identity",
+					Operations: migrations.Operations{
+						&migrations.OpSetIdentity{
+							Table:    "documents",
+							Column:   "id",
+							Identity: migrations.OpSetIdentityIdentityAlways,
+						},
+					},
+				},
+				{
+					Name: "03_set_identity",
+					Operations: migrations.Operations{
+						&migrations.OpSetIdentity{
+							Table:    "documents",
+							Column:   "id",
+							Identity: migrations.OpSetIdentityIdentityAlways,
+						},
+					},
+				},
+			},
+			wantStartErr: migrations.InvalidIdentityChangeError{Table: "documents", Column: "id", Reason: "the column is already generated ALWAYS as identity"},
+		},
+	})
+}
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+// partitionBySQL returns the PARTITION BY clause for `p`.
+func partitionBySQL(p PartitionBy) string {
+	columns := make([]string, 0, len(p.Columns))
+	for _, c := range p.Columns {
+		columns = append(columns, pq.QuoteIdentifier(c))
+	}
+	return fmt.Sprintf("PARTITION BY %s (%s)", strings.ToUpper(string(p.Strategy)), strings.Join(columns, ", "))
+}
+
+// partitioning returns the schema representation of a table partitioned by
+// `p`, without any partitions.
+func partitioning(p PartitionBy) *schema.Partitioning {
+	return &schema.Partitioning{
+		Strategy: string(p.Strategy),
+		Columns:  p.Columns,
+	}
+}
+
+// validatePartitionBy returns an error if `table` can't be partitioned by
+// `p`. Postgres requires the primary key and unique constraints of a
+// partitioned table to include all of its partition columns.
+func validatePartitionBy(table *schema.Table, p PartitionBy) error {
+	switch p.Strategy {
+	case PartitionByStrategyRange, PartitionByStrategyHash:
+	case PartitionByStrategyList:
+		if len(p.Columns) != 1 {
+			return InvalidPartitioningError{Table: table.Name, Reason: "list partitioning requires a single column"}
+		}
+	default:
+		return InvalidPartitioningError{Table: table.Name, Reason: fmt.Sprintf("unknown partitioning strategy %q", p.Strategy)}
+	}
+
+	if len(p.Columns) == 0 {
+		return FieldRequiredError{Name: "columns"}
+	}
+	for _, c := range p.Columns {
+		if table.GetColumn(c) == nil {
+			return ColumnDoesNotExistError{Table: table.Name, Name: c}
+		}
+	}
+
+	includesPartitionColumns := func(columns []string) bool {
+		for _, c := range p.Columns {
+			if !slices.Contains(columns, c) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if len(table.PrimaryKey) > 0 && !includesPartitionColumns(table.PrimaryKey) {
+		return InvalidPartitioningError{Table: table.Name, Reason: "the primary key must include all partition columns"}
+	}
+	for _, name := range slices.Sorted(maps.Keys(table.UniqueConstraints)) {
+		if !includesPartitionColumns(table.UniqueConstraints[name].Columns) {
+			return InvalidPartitioningError{Table: table.Name, Reason: fmt.Sprintf("unique constraint %q must include all partition columns", name)}
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(table.Indexes)) {
+		idx := table.Indexes[name]
+		if idx.Unique && !includesPartitionColumns(idx.Columns) {
+			return InvalidPartitioningError{Table: table.Name, Reason: fmt.Sprintf("unique index %q must include all partition columns", name)}
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(table.Columns)) {
+		if table.Columns[name].Unique && !includesPartitionColumns([]string{name}) {
+			return InvalidPartitioningError{Table: table.Name, Reason: fmt.Sprintf("unique column %q must include all partition columns", name)}
+		}
+	}
+	if len(table.ExcludeConstraints) > 0 {
+		return InvalidPartitioningError{Table: table.Name, Reason: "exclusion constraints are not supported"}
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+func TestValidatePartitionBy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		table       func(t *schema.Table)
+		partitionBy PartitionBy
+		wantErr     error
+	}{
+		{
+			name:        "range partitioning by a primary key column",
+			partitionBy: PartitionBy{Strategy: PartitionByStrategyRange, Columns: []string{"created_at"}},
+		},
+		{
+			name:        "hash partitioning by several columns",
+			partitionBy: PartitionBy{Strategy: PartitionByStrategyHash, Columns: []string{"id", "created_at"}},
+		},
+		{
+			name:        "unknown strategy",
+			partitionBy: PartitionBy{Strategy: "interval", Columns: []string{"created_at"}},
+			wantErr:     InvalidPartitioningError{Table: "events", Reason: `unknown partitioning strategy "interval"`},
+		},
+		{
+			name:        "list partitioning by several columns",
+			partitionBy: PartitionBy{Strategy: PartitionByStrategyList, Columns: []string{"id", "created_at"}},
+			wantErr:     InvalidPartitioningError{Table: "events", Reason: "list partitioning requires a single column"},
+		},
+		{
+			name:        "unknown column",
+			partitionBy: PartitionBy{Strategy: PartitionByStrategyRange, Columns: []string{"updated_at"}},
+			wantErr:     ColumnDoesNotExistError{Table: "events", Name: "updated_at"},
+		},
+		{
+			name:        "column not in the primary key",
+			partitionBy: PartitionBy{Strategy: PartitionByStrategyList, Columns: []string{"kind"}},
+			wantErr:     InvalidPartitioningError{Table: "events", Reason: "the primary key must include all partition columns"},
+		},
+		{
+			name: "column not in a unique constraint",
+			table: func(t *schema.Table) {
+				t.UniqueConstraints = map[string]*schema.UniqueConstraint{
+					"events_id_key": {Name: "events_id_key", Columns: []string{"id"}},
+				}
+			},
+			partitionBy: PartitionBy{Strategy: PartitionByStrategyRange, Columns: []string{"created_at"}},
+			wantErr:     InvalidPartitioningError{Table: "events", Reason: `unique constraint "events_id_key" must include all partition columns`},
+		},
+		{
+			name: "column not in a unique index",
+			table: func(t *schema.Table) {
+				t.Indexes = map[string]*schema.Index{
+					"events_kind_idx": {Name: "events_kind_idx", Unique: true, Columns: []string{"kind"}},
+				}
+			},
+			partitionBy: PartitionBy{Strategy: PartitionByStrategyRange, Columns: []string{"created_at"}},
+			wantErr:     InvalidPartitioningError{Table: "events", Reason: `unique index "events_kind_idx" must include all partition columns`},
+		},
+		{
+			name: "exclusion constraint",
+			table: func(t *schema.Table) {
+				t.ExcludeConstraints = map[string]*schema.ExcludeConstraint{
+					"events_exclude": {Name: "events_exclude"},
+				}
+			},
+			partitionBy: PartitionBy{Strategy: PartitionByStrategyRange, Columns: []string{"created_at"}},
+			wantErr:     InvalidPartitioningError{Table: "events", Reason: "exclusion constraints are not supported"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &schema.Table{
+				Name: "events",
+				Columns: map[string]*schema.Column{
+					"id":         {Name: "id"},
+					"created_at": {Name: "created_at"},
+					"kind":       {Name: "kind"},
+				},
+				PrimaryKey: []string{"id", "created_at"},
+			}
+			if tt.table != nil {
+				tt.table(table)
+			}
+
+			err := validatePartitionBy(table, tt.partitionBy)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestPartitionBySQL(t *testing.T) {
+	t.Parallel()
+
+	sql := partitionBySQL(PartitionBy{Strategy: PartitionByStrategyRange, Columns: []string{"id", "created_at"}})
+	assert.Equal(t, `PARTITION BY RANGE ("id", "created_at")`, sql)
+}
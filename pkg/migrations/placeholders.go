@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// PlaceHolderSQL is the SQL used by generated migrations for `up` and `down`
+// expressions that can not be generated automatically. It must be replaced
+// before the migration is run.
+const PlaceHolderSQL = "TODO: Implement SQL data migration"
+
+// Placeholder identifies an operation field that contains placeholder SQL
+type Placeholder struct {
+	// The 1-based index of the operation in the migration
+	OperationIndex int
+
+	// The name of the operation
+	Operation OpName
+
+	// The path to the field within the operation, for example `up` or
+	// `column.default`
+	Field string
+}
+
+// Placeholders returns every field in the migration's operations that
+// contains placeholder SQL.
+func (m *Migration) Placeholders() []Placeholder {
+	var placeholders []Placeholder
+	for i, op := range m.Operations {
+		for _, field := range PlaceholderFields(op) {
+			placeholders = append(placeholders, Placeholder{
+				OperationIndex: i + 1,
+				Operation:      OperationName(op),
+				Field:          field,
+			})
+		}
+	}
+	return placeholders
+}
+
+// ValidatePlaceholders returns an error listing every field in the
+// migration's operations that contains placeholder SQL.
+func (m *Migration) ValidatePlaceholders() error {
+	if placeholders := m.Placeholders(); len(placeholders) > 0 {
+		return UnresolvedPlaceholderError{Placeholders: placeholders}
+	}
+	return nil
+}
+
+// PlaceholderFields returns the paths of the fields in the operation that
+// contain placeholder SQL, in sorted order.
+func PlaceholderFields(op Operation) []string {
+	var fields []string
+	_ = walkPlaceholders(op, func(path string, _ string) (string, bool) {
+		fields = append(fields, path)
+		return "", false
+	})
+	return fields
+}
+
+// ResolvePlaceholders returns a copy of the operation in which the value of
+// each field that contains placeholder SQL is replaced by the value returned
+// by `resolve` for the field's path.
+func ResolvePlaceholders(op Operation, resolve func(field string) (string, error)) (Operation, error) {
+	var resolveErr error
+	resolved := walkPlaceholders(op, func(path string, _ string) (string, bool) {
+		if resolveErr != nil {
+			return "", false
+		}
+		value, err := resolve(path)
+		if err != nil {
+			resolveErr = err
+			return "", false
+		}
+		return value, true
+	})
+	if resolveErr != nil {
+		return nil, resolveErr
+	}
+
+	raw, err := json.Marshal(resolved)
+	if err != nil {
+		return nil, err
+	}
+	newOp, err := OperationFromName(OperationName(op))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, newOp); err != nil {
+		return nil, fmt.Errorf("unable to decode resolved operation: %w", err)
+	}
+	return newOp, nil
+}
+
+// walkPlaceholders visits every string in the JSON representation of the
+// operation that contains placeholder SQL, in path order. If `visit` returns
+// true the string is replaced by the returned value. The, possibly updated,
+// JSON representation is returned.
+func walkPlaceholders(op Operation, visit func(path, value string) (string, bool)) any {
+	return walkStrings(op, func(path, value string) (string, bool) {
+		if !strings.Contains(value, PlaceHolderSQL) {
+			return "", false
+		}
+		return visit(path, value)
+	})
+}
+
+// walkStrings visits every string in the JSON representation of the
+// operation, in path order. If `visit` returns true the string is replaced by
+// the returned value. The, possibly updated, JSON representation is returned.
+func walkStrings(op Operation, visit func(path, value string) (string, bool)) any {
+	raw, err := json.Marshal(op)
+	if err != nil {
+		return nil
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil
+	}
+
+	var walk func(path string, v any) any
+	walk = func(path string, v any) any {
+		switch v := v.(type) {
+		case map[string]any:
+			for _, k := range slices.Sorted(maps.Keys(v)) {
+				p := k
+				if path != "" {
+					p = path + "." + k
+				}
+				v[k] = walk(p, v[k])
+			}
+		case []any:
+			for i := range v {
+				v[i] = walk(fmt.Sprintf("%s[%d]", path, i), v[i])
+			}
+		case string:
+			if replacement, ok := visit(path, v); ok {
+				return replacement
+			}
+		}
+		return v
+	}
+
+	return walk("", doc)
+}
@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/lib/pq"
+	pgq "github.com/xataio/pg_query_go/v6"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+// createPolicySQL returns the CREATE POLICY statement for `policy` on `table`.
+func createPolicySQL(table string, policy *schema.Policy) string {
+	kind := "PERMISSIVE"
+	if !policy.Permissive {
+		kind = "RESTRICTIVE"
+	}
+	command := policy.Command
+	if command == "" {
+		command = "ALL"
+	}
+
+	sql := fmt.Sprintf("CREATE POLICY %s ON %s AS %s FOR %s",
+		pq.QuoteIdentifier(policy.Name),
+		pq.QuoteIdentifier(table),
+		kind,
+		command)
+	if len(policy.Roles) > 0 {
+		sql += " TO " + QuoteRoles(policy.Roles)
+	}
+	if policy.Using != "" {
+		sql += fmt.Sprintf(" USING (%s)", policy.Using)
+	}
+	if policy.WithCheck != "" {
+		sql += fmt.Sprintf(" WITH CHECK (%s)", policy.WithCheck)
+	}
+	return sql
+}
+
+// physicalExpression rewrites `expr`, a policy or generation expression that
+// refers to the columns of `table` by the names they have in the new version of
+// the schema, so that it refers to the physical columns of the table. It
+// returns the rewritten expression and the physical columns it refers to.
+func physicalExpression(table *schema.Table, tableName, expr string) (string, []string, error) {
+	columns := make(map[string]string, len(table.Columns))
+	for name, column := range table.Columns {
+		if !column.Deleted {
+			columns[name] = column.Name
+		}
+	}
+	return rewriteColumnReferences(expr, tableName, columns)
+}
+
+// rewriteColumnReferences rewrites the references to the columns of `table` in
+// the SQL expression `expr`, replacing each column in `columns` with the name
+// it maps to. Columns are only replaced when they are unqualified or qualified
+// by `table`. It returns the rewritten expression and the sorted replacement
+// names of the columns it refers to.
+func rewriteColumnReferences(expr, table string, columns map[string]string) (string, []string, error) {
+	tree, err := pgq.Parse("SELECT " + expr)
+	if err != nil {
+		return "", nil, err
+	}
+
+	stmts := tree.GetStmts()
+	if len(stmts) != 1 || !isBareExpression(stmts[0].GetStmt().GetSelectStmt()) {
+		return "", nil, errors.New("the expression must be a single SQL expression")
+	}
+	node := stmts[0].GetStmt().GetSelectStmt().GetTargetList()[0].GetResTarget().GetVal()
+
+	var refs []string
+	var walk func(m protoreflect.Message)
+	walk = func(m protoreflect.Message) {
+		if ref, ok := m.Interface().(*pgq.ColumnRef); ok {
+			if name, ok := renameColumnRef(ref, table, columns); ok {
+				refs = append(refs, name)
+			}
+			return
+		}
+		m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+			switch {
+			case fd.Kind() != protoreflect.MessageKind || fd.IsMap():
+			case fd.IsList():
+				for i := range v.List().Len() {
+					walk(v.List().Get(i).Message())
+				}
+			default:
+				walk(v.Message())
+			}
+			return true
+		})
+	}
+	walk(node.ProtoReflect())
+
+	rewritten, err := pgq.DeparseExpr(node)
+	if err != nil {
+		return "", nil, err
+	}
+
+	slices.Sort(refs)
+	return rewritten, slices.Compact(refs), nil
+}
+
+// isBareExpression returns true if `stmt` is a SELECT statement of a single
+// unnamed expression, without any other clauses.
+func isBareExpression(stmt *pgq.SelectStmt) bool {
+	return stmt != nil &&
+		len(stmt.GetTargetList()) == 1 &&
+		stmt.GetTargetList()[0].GetResTarget().GetName() == "" &&
+		stmt.GetOp() == pgq.SetOperation_SETOP_NONE &&
+		stmt.GetFromClause() == nil &&
+		stmt.GetWhereClause() == nil &&
+		stmt.GetGroupClause() == nil &&
+		stmt.GetHavingClause() == nil &&
+		stmt.GetSortClause() == nil &&
+		stmt.GetLimitCount() == nil &&
+		stmt.GetWithClause() == nil
+}
+
+// renameColumnRef replaces the column in `ref` with the name it maps to in
+// `columns`, if it is unqualified or qualified by `table`.
+func renameColumnRef(ref *pgq.ColumnRef, table string, columns map[string]string) (string, bool) {
+	var column *pgq.String
+	switch fields := ref.GetFields(); len(fields) {
+	case 1:
+		column = fields[0].GetString_()
+	case 2:
+		if fields[0].GetString_().GetSval() == table {
+			column = fields[1].GetString_()
+		}
+	}
+	if column == nil {
+		return "", false
+	}
+
+	to, ok := columns[column.GetSval()]
+	if !ok {
+		return "", false
+	}
+	column.Sval = to
+	return to, true
+}
+
+// duplicatedPolicies returns the policies on `table` that refer to any of the
+// physical columns in `columns`, in name order.
+func duplicatedPolicies(table *schema.Table, columns ...string) []*schema.Policy {
+	policies := make([]*schema.Policy, 0)
+	for _, name := range slices.Sorted(maps.Keys(table.Policies)) {
+		policy := table.Policies[name]
+		if IsDuplicatedName(policy.Name) {
+			continue
+		}
+		if slices.ContainsFunc(policy.Columns, func(c string) bool { return slices.Contains(columns, c) }) {
+			policies = append(policies, policy)
+		}
+	}
+	return policies
+}
+
+// dropReplacedPoliciesAction is a DBAction that drops the policies on a
+// column that have been duplicated onto its duplicated column. The original
+// policies must be dropped before the column, which they depend on; their
+// duplicates take their names when the duplicated column is renamed.
+type dropReplacedPoliciesAction struct {
+	conn   db.DB
+	id     string
+	table  *schema.Table
+	column string
+}
+
+func NewDropReplacedPoliciesAction(conn db.DB, table *schema.Table, column string) *dropReplacedPoliciesAction {
+	return &dropReplacedPoliciesAction{
+		conn:   conn,
+		id:     fmt.Sprintf("drop_replaced_policies_%s_%s", table.Name, column),
+		table:  table,
+		column: column,
+	}
+}
+
+func (a *dropReplacedPoliciesAction) ID() string { return a.id }
+
+func (a *dropReplacedPoliciesAction) Execute(ctx context.Context) error {
+	for _, policy := range duplicatedPolicies(a.table, a.column) {
+		if a.table.GetPolicy(DuplicationName(policy.Name)) == nil {
+			continue
+		}
+		if err := NewDropPolicyAction(a.conn, a.table.Name, policy.Name).Execute(ctx); err != nil {
+			return fmt.Errorf("failed to drop policy %q: %w", policy.Name, err)
+		}
+		a.table.RemovePolicy(policy.Name)
+	}
+	return nil
+}
+
+// dropDuplicatedPoliciesActions returns the actions that drop the duplicates
+// of the policies on `column`. The duplicates must be dropped before the
+// duplicated column, which they depend on.
+func dropDuplicatedPoliciesActions(conn db.DB, table *schema.Table, column string) []DBAction {
+	actions := make([]DBAction, 0)
+	for _, policy := range duplicatedPolicies(table, column) {
+		actions = append(actions, NewDropPolicyAction(conn, table.Name, DuplicationName(policy.Name)))
+	}
+	return actions
+}
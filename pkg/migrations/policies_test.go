@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+func TestRewriteColumnReferences(t *testing.T) {
+	t.Parallel()
+
+	columns := map[string]string{
+		"owner":  "_pgroll_new_owner",
+		"tenant": "tenant",
+	}
+
+	tests := []struct {
+		name        string
+		expr        string
+		wantExpr    string
+		wantColumns []string
+		wantErr     bool
+	}{
+		{
+			name:        "unqualified column",
+			expr:        "owner = current_user",
+			wantExpr:    "_pgroll_new_owner = current_user",
+			wantColumns: []string{"_pgroll_new_owner"},
+		},
+		{
+			name:        "column qualified by the table",
+			expr:        "(documents.owner = (CURRENT_USER)::text)",
+			wantExpr:    "documents._pgroll_new_owner = current_user::text",
+			wantColumns: []string{"_pgroll_new_owner"},
+		},
+		{
+			name:        "columns in a subquery",
+			expr:        "EXISTS (SELECT 1 FROM members m WHERE m.owner = documents.owner AND m.tenant = tenant)",
+			wantExpr:    "EXISTS (SELECT 1 FROM members m WHERE m.owner = documents._pgroll_new_owner AND m.tenant = tenant)",
+			wantColumns: []string{"_pgroll_new_owner", "tenant"},
+		},
+		{
+			name:     "unknown columns are left unchanged",
+			expr:     "public",
+			wantExpr: "public",
+		},
+		{
+			name:    "more than an expression",
+			expr:    "true FROM documents",
+			wantErr: true,
+		},
+		{
+			name:    "several statements",
+			expr:    "true; DROP TABLE documents",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, refs, err := rewriteColumnReferences(tt.expr, "documents", columns)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantExpr, expr)
+			assert.Equal(t, tt.wantColumns, refs)
+		})
+	}
+}
+
+func TestCreatePolicySQL(t *testing.T) {
+	t.Parallel()
+
+	sql := createPolicySQL("documents", &schema.Policy{
+		Name:      "owner_only",
+		Command:   "UPDATE",
+		Roles:     []string{"public", "app_user"},
+		Using:     "owner = current_user",
+		WithCheck: "owner = current_user",
+	})
+	assert.Equal(t, `CREATE POLICY "owner_only" ON "documents" AS RESTRICTIVE FOR UPDATE TO PUBLIC, "app_user" USING (owner = current_user) WITH CHECK (owner = current_user)`, sql)
+}
+
+func TestDuplicatePolicies(t *testing.T) {
+	t.Parallel()
+
+	table := &schema.Table{
+		Name: "documents",
+		Policies: map[string]*schema.Policy{
+			"owner_only": {
+				Name:       "owner_only",
+				Command:    "ALL",
+				Permissive: true,
+				Roles:      []string{"public"},
+				Using:      "(owner = CURRENT_USER)",
+				Columns:    []string{"owner"},
+			},
+			"tenant_only": {
+				Name:       "tenant_only",
+				Command:    "SELECT",
+				Permissive: false,
+				Roles:      []string{"public"},
+				Using:      "(tenant = 1)",
+				Columns:    []string{"tenant"},
+			},
+		},
+	}
+
+	stmts, err := (&duplicatorStmtBuilder{table: table}).duplicatePolicies(map[string]string{"owner": "_pgroll_new_owner"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`CREATE POLICY "_pgroll_dup_owner_only" ON "documents" AS PERMISSIVE FOR ALL TO PUBLIC USING (_pgroll_new_owner = current_user)`,
+	}, stmts)
+}
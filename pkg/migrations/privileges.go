@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+var tablePrivileges = []TablePrivilege{
+	TablePrivilegeAll,
+	TablePrivilegeSelect,
+	TablePrivilegeInsert,
+	TablePrivilegeUpdate,
+	TablePrivilegeDelete,
+	TablePrivilegeTruncate,
+	TablePrivilegeReferences,
+	TablePrivilegeTrigger,
+}
+
+// ValidatePrivileges returns an error if any of `privileges` is not a
+// privilege that can be granted on a table or view.
+func ValidatePrivileges(privileges []TablePrivilege) error {
+	if len(privileges) == 0 {
+		return FieldRequiredError{Name: "privileges"}
+	}
+	for _, p := range privileges {
+		if !slices.Contains(tablePrivileges, TablePrivilege(strings.ToLower(string(p)))) {
+			return InvalidPrivilegeError{Privilege: string(p)}
+		}
+	}
+	return nil
+}
+
+// PrivilegesSQL returns a comma separated list of `privileges` for use in a
+// GRANT or REVOKE statement. The privileges must have been validated with
+// ValidatePrivileges.
+func PrivilegesSQL(privileges []TablePrivilege) string {
+	sql := make([]string, 0, len(privileges))
+	for _, p := range privileges {
+		sql = append(sql, strings.ToUpper(string(p)))
+	}
+	return strings.Join(sql, ", ")
+}
+
+// QuoteRoles returns a comma separated list of roles for use in a policy
+// definition or a GRANT or REVOKE statement. The role specifications
+// understood by Postgres, such as `public` and `current_user`, are not
+// quoted.
+func QuoteRoles(roles []string) string {
+	quoted := make([]string, 0, len(roles))
+	for _, role := range roles {
+		switch strings.ToLower(role) {
+		case "public", "current_role", "current_user", "session_user":
+			quoted = append(quoted, strings.ToUpper(role))
+		default:
+			quoted = append(quoted, pq.QuoteIdentifier(role))
+		}
+	}
+	return strings.Join(quoted, ", ")
+}
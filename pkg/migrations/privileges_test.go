@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePrivileges(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, ValidatePrivileges([]TablePrivilege{TablePrivilegeSelect, "INSERT"}))
+	assert.Equal(t, FieldRequiredError{Name: "privileges"}, ValidatePrivileges(nil))
+	assert.Equal(t, InvalidPrivilegeError{Privilege: "usage"}, ValidatePrivileges([]TablePrivilege{TablePrivilegeSelect, "usage"}))
+}
+
+func TestPrivilegesSQL(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "SELECT, INSERT", PrivilegesSQL([]TablePrivilege{TablePrivilegeSelect, TablePrivilegeInsert}))
+	assert.Equal(t, `PUBLIC, "app_user"`, QuoteRoles([]string{"public", "app_user"}))
+}
@@ -61,6 +61,14 @@ func (o *OpCreateTable) Create() {
 	if comment != "" {
 		o.Comment = &comment
 	}
+
+	partition, _ := pterm.DefaultInteractiveConfirm.
+		WithDefaultText("Partition table").
+		Show()
+	if partition {
+		partitionBy := getPartitionByFromCLI()
+		o.PartitionBy = &partitionBy
+	}
 }
 
 func (o *OpCreateIndex) Create() {
@@ -261,6 +269,229 @@ func (o *OpRenameTable) Create() {
 	o.To, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("to").Show()
 }
 
+func (o *OpCreateEnum) Create() {
+	o.Name, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("name").Show()
+	values, _ := pterm.DefaultInteractiveTextInput.WithDefaultText("values").Show()
+	o.Values = strings.Split(values, ",")
+}
+
+func (o *OpAlterEnum) Create() {
+	o.Name, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("name").Show()
+	change, _ := pterm.DefaultInteractiveSelect.
+		WithDefaultText("change").
+		WithOptions([]string{"add_value", "rename_value"}).
+		Show()
+	switch change {
+	case "add_value":
+		var v OpAlterEnumAddValue
+		v.Value, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("value").Show()
+		v.Before, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("before").Show()
+		if v.Before == "" {
+			v.After, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("after").Show()
+		}
+		o.AddValue = &v
+	case "rename_value":
+		var v OpAlterEnumRenameValue
+		v.From, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("from").Show()
+		v.To, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("to").Show()
+		o.RenameValue = &v
+	}
+}
+
+func (o *OpDropEnum) Create() {
+	o.Name, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("name").Show()
+}
+
+func (o *OpCreateView) Create() {
+	o.Name, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("name").Show()
+	o.Definition, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("definition").Show()
+}
+
+func (o *OpReplaceView) Create() {
+	o.Name, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("name").Show()
+	o.Definition, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("definition").Show()
+}
+
+func (o *OpDropView) Create() {
+	o.Name, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("name").Show()
+}
+
+func (o *OpAttachPartition) Create() {
+	o.Table, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("table").Show()
+	o.Partition, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("partition").Show()
+	o.Bound, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("bound").Show()
+}
+
+func (o *OpDetachPartition) Create() {
+	o.Table, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("table").Show()
+	o.Partition, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("partition").Show()
+}
+
+func (o *OpPartitionTable) Create() {
+	o.Table, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("table").Show()
+	o.PartitionBy = getPartitionByFromCLI()
+
+	addPartitions := true
+	for addPartitions {
+		var p Partition
+		p.Name, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("partition.name").Show()
+		p.Bound, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("partition.bound").Show()
+		o.Partitions = append(o.Partitions, p)
+
+		addPartitions, _ = pterm.DefaultInteractiveConfirm.
+			WithDefaultText("Add more partitions").
+			Show()
+	}
+}
+
+func (o *OpEnableRLS) Create() {
+	o.Table, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("table").Show()
+	o.Force, _ = pterm.DefaultInteractiveConfirm.WithDefaultText("force").WithDefaultValue(false).Show()
+}
+
+func (o *OpCreatePolicy) Create() {
+	o.Table, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("table").Show()
+	o.Name, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("name").Show()
+	policyType, _ := pterm.DefaultInteractiveSelect.
+		WithDefaultText("type").
+		WithOptions([]string{"permissive", "restrictive"}).
+		Show()
+	o.Type = OpCreatePolicyType(policyType)
+	command, _ := pterm.DefaultInteractiveSelect.
+		WithDefaultText("command").
+		WithOptions([]string{"all", "select", "insert", "update", "delete"}).
+		Show()
+	o.Command = OpCreatePolicyCommand(command)
+	o.Roles, o.Using, o.WithCheck = getPolicyFromCLI()
+}
+
+func (o *OpAlterPolicy) Create() {
+	o.Table, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("table").Show()
+	o.Name, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("name").Show()
+	o.Roles, o.Using, o.WithCheck = getPolicyFromCLI()
+}
+
+func (o *OpDropPolicy) Create() {
+	o.Table, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("table").Show()
+	o.Name, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("name").Show()
+}
+
+func (o *OpGrant) Create() {
+	o.Table, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("table").Show()
+	o.Privileges, o.Roles = getPrivilegesFromCLI()
+	o.WithGrantOption, _ = pterm.DefaultInteractiveConfirm.WithDefaultText("with_grant_option").WithDefaultValue(false).Show()
+}
+
+func (o *OpRevoke) Create() {
+	o.Table, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("table").Show()
+	o.Privileges, o.Roles = getPrivilegesFromCLI()
+}
+
+func (o *OpCreateSequence) Create() {
+	o.Name, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("name").Show()
+	seqType, _ := pterm.DefaultInteractiveSelect.
+		WithDefaultText("type").
+		WithOptions([]string{"bigint", "integer", "smallint"}).
+		Show()
+	o.Type = OpCreateSequenceType(seqType)
+	o.StartValue = getOptionalIntFromCLI("start_value")
+	o.Increment = getOptionalIntFromCLI("increment")
+	o.MinValue = getOptionalIntFromCLI("min_value")
+	o.MaxValue = getOptionalIntFromCLI("max_value")
+	o.Cache = getOptionalIntFromCLI("cache")
+	o.Cycle = getBooleanOptionForColumnAttr("cycle")
+}
+
+func (o *OpAlterSequence) Create() {
+	o.Name, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("name").Show()
+	o.Restart = getOptionalIntFromCLI("restart")
+	o.Increment = getOptionalIntFromCLI("increment")
+	o.Cache = getOptionalIntFromCLI("cache")
+}
+
+func (o *OpDropSequence) Create() {
+	o.Name, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("name").Show()
+}
+
+func (o *OpSetIdentity) Create() {
+	o.Table, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("table").Show()
+	o.Column, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("column").Show()
+	identity, _ := pterm.DefaultInteractiveSelect.
+		WithDefaultText("identity").
+		WithOptions([]string{"always", "by_default", "none"}).
+		Show()
+	o.Identity = OpSetIdentityIdentity(identity)
+}
+
+func (o *OpAlterPrimaryKeyType) Create() {
+	o.Table, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("table").Show()
+	o.Column, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("column").Show()
+	o.Type, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("type").Show()
+}
+
+func (o *OpRewriteTable) Create() {
+	o.Table, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("table").Show()
+	o.StorageParameters, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("storage_parameters").Show()
+	o.Tablespace, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("tablespace").Show()
+}
+
+func (o *OpMoveTable) Create() {
+	o.Table, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("table").Show()
+	o.ToSchema, _ = pterm.DefaultInteractiveTextInput.WithDefaultText("to_schema").Show()
+}
+
+// getOptionalIntFromCLI prompts for an integer, returning nil if the answer
+// is empty or not an integer
+func getOptionalIntFromCLI(name string) *int {
+	val, _ := pterm.DefaultInteractiveTextInput.WithDefaultText(name).Show()
+	i, err := strconv.Atoi(val)
+	if err != nil {
+		return nil
+	}
+	return &i
+}
+
+func getPrivilegesFromCLI() ([]TablePrivilege, []string) {
+	selected, _ := pterm.DefaultInteractiveMultiselect.
+		WithDefaultText("privileges").
+		WithOptions([]string{"all", "select", "insert", "update", "delete", "truncate", "references", "trigger"}).
+		Show()
+	privileges := make([]TablePrivilege, 0, len(selected))
+	for _, p := range selected {
+		privileges = append(privileges, TablePrivilege(p))
+	}
+	rolesStr, _ := pterm.DefaultInteractiveTextInput.WithDefaultText("roles").Show()
+	return privileges, strings.Split(rolesStr, ",")
+}
+
+func getPolicyFromCLI() (roles []string, using, withCheck *string) {
+	rolesStr, _ := pterm.DefaultInteractiveTextInput.WithDefaultText("roles").Show()
+	if rolesStr != "" {
+		roles = strings.Split(rolesStr, ",")
+	}
+	usingStr, _ := pterm.DefaultInteractiveTextInput.WithDefaultText("using").Show()
+	if usingStr != "" {
+		using = &usingStr
+	}
+	withCheckStr, _ := pterm.DefaultInteractiveTextInput.WithDefaultText("with_check").Show()
+	if withCheckStr != "" {
+		withCheck = &withCheckStr
+	}
+	return roles, using, withCheck
+}
+
+func getPartitionByFromCLI() PartitionBy {
+	strategy, _ := pterm.DefaultInteractiveSelect.
+		WithDefaultText("partition_by.strategy").
+		WithOptions([]string{"range", "list", "hash"}).
+		Show()
+	columns, _ := pterm.DefaultInteractiveTextInput.WithDefaultText("partition_by.columns").Show()
+	return PartitionBy{
+		Strategy: PartitionByStrategy(strategy),
+		Columns:  strings.Split(columns, ","),
+	}
+}
+
 func getFkAction(name string) ForeignKeyAction {
 	action, _ := pterm.DefaultInteractiveSelect.
 		WithDefaultText(name).
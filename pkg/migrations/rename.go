@@ -5,6 +5,7 @@ package migrations
 import (
 	"context"
 	"fmt"
+	"maps"
 	"slices"
 
 	"github.com/lib/pq"
@@ -17,6 +18,7 @@ import (
 // * Renames a duplicated column to its original name
 // * Renames any foreign keys on the duplicated column to their original name.
 // * Validates and renames any temporary `CHECK` constraints on the duplicated column.
+// * Renames any row level security policies on the duplicated column to their original name.
 type renameDuplicatedColumnAction struct {
 	conn  db.DB
 	id    string
@@ -136,6 +138,21 @@ func (a *renameDuplicatedColumnAction) Execute(ctx context.Context) error {
 		}
 	}
 
+	// Rename any row level security policies on the duplicated column from
+	// their temporary name to their original name.
+	for _, name := range slices.Sorted(maps.Keys(a.table.Policies)) {
+		policy := a.table.Policies[name]
+		if !IsDuplicatedName(policy.Name) || !slices.Contains(policy.Columns, a.from) {
+			continue
+		}
+
+		err := NewRenamePolicyAction(a.conn, a.table.Name, policy.Name, StripDuplicationPrefix(policy.Name)).Execute(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to rename policy %q: %w", policy.Name, err)
+		}
+		a.table.RemovePolicy(policy.Name)
+	}
+
 	if slices.Contains(a.table.PrimaryKey, a.to) {
 		err := NewAddPrimaryKeyAction(a.conn, a.table.Name, primaryKeyName(a.table.Name)).Execute(ctx)
 		if err != nil {
@@ -0,0 +1,224 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// operationSchemaKey is the key in an operation object that names the schema
+// targeted by the operation, for example:
+//
+//	{"schema": "billing", "create_table": {...}}
+const operationSchemaKey = "schema"
+
+// OperationSchema returns the schema targeted by the operation at index `i`,
+// or `defaultSchema` if the operation doesn't name one.
+func (m *Migration) OperationSchema(i int, defaultSchema string) string {
+	if i < len(m.OperationSchemas) && m.OperationSchemas[i] != "" {
+		return m.OperationSchemas[i]
+	}
+	return defaultSchema
+}
+
+// Schemas returns the schemas targeted by the migration's operations, in the
+// order in which they are first targeted. Operations that don't name a schema
+// target `defaultSchema`. A `move_table` operation also targets the schema it
+// moves its table to.
+func (m *Migration) Schemas(defaultSchema string) []string {
+	var schemas []string
+	for i, op := range m.Operations {
+		if s := m.OperationSchema(i, defaultSchema); !slices.Contains(schemas, s) {
+			schemas = append(schemas, s)
+		}
+		if mt, ok := op.(*OpMoveTable); ok && mt.ToSchema != "" && !slices.Contains(schemas, mt.ToSchema) {
+			schemas = append(schemas, mt.ToSchema)
+		}
+	}
+	if len(schemas) == 0 {
+		schemas = append(schemas, defaultSchema)
+	}
+	return schemas
+}
+
+// ReferencedSchemas returns the schemas, other than those targeted by the
+// migration, that contain tables referenced by the foreign keys that the
+// migration creates. They are read, but not changed, by the migration.
+func (m *Migration) ReferencedSchemas(defaultSchema string) []string {
+	targeted := m.Schemas(defaultSchema)
+
+	var schemas []string
+	add := func(s string) {
+		if s != "" && !slices.Contains(targeted, s) && !slices.Contains(schemas, s) {
+			schemas = append(schemas, s)
+		}
+	}
+	for _, op := range m.Operations {
+		switch op := op.(type) {
+		case *OpCreateTable:
+			for _, col := range op.Columns {
+				if col.References != nil {
+					add(col.References.Schema)
+				}
+			}
+			for _, c := range op.Constraints {
+				if c.References != nil {
+					add(c.References.Schema)
+				}
+			}
+		case *OpAddColumn:
+			if op.Column.References != nil {
+				add(op.Column.References.Schema)
+			}
+		case *OpAlterColumn:
+			if op.References != nil {
+				add(op.References.Schema)
+			}
+		case *OpCreateConstraint:
+			if op.References != nil {
+				add(op.References.Schema)
+			}
+		}
+	}
+	return schemas
+}
+
+// IsMultiSchema returns true if the migration targets any schema other than
+// `defaultSchema`.
+func (m *Migration) IsMultiSchema(defaultSchema string) bool {
+	schemas := m.Schemas(defaultSchema)
+	return len(schemas) > 1 || schemas[0] != defaultSchema
+}
+
+// ValidateDefaultSchema returns an error if the migration targets schemas
+// other than `defaultSchema` but none of its operations target
+// `defaultSchema`. pgroll reads the history of the schema it is run against,
+// so such a migration would be missing from that history once it is started.
+func (m *Migration) ValidateDefaultSchema(defaultSchema string) error {
+	if slices.Contains(m.Schemas(defaultSchema), defaultSchema) {
+		return nil
+	}
+	return InvalidMigrationError{Reason: fmt.Sprintf("at least one operation must target schema %q, the schema the migration is run against", defaultSchema)}
+}
+
+// WithDefaultSchema returns a copy of the migration in which every operation
+// that doesn't name a schema targets `defaultSchema`. The schemas of the
+// returned migration no longer depend on the schema it is run against.
+func (m *Migration) WithDefaultSchema(defaultSchema string) *Migration {
+	c := *m
+	c.OperationSchemas = make([]string, len(m.Operations))
+	for i := range m.Operations {
+		c.OperationSchemas[i] = m.OperationSchema(i, defaultSchema)
+	}
+	return &c
+}
+
+// MarshalJSON serializes the migration, including the schema targeted by each
+// operation that names one and any backfill settings.
+func (m Migration) MarshalJSON() ([]byte, error) {
+	ops, err := json.Marshal(m.Operations)
+	if err != nil {
+		return nil, err
+	}
+
+	hasSchemas := slices.ContainsFunc(m.OperationSchemas, func(s string) bool { return s != "" })
+	hasBackfills := slices.ContainsFunc(m.OperationBackfills, func(s *BackfillSettings) bool { return s != nil })
+	if hasSchemas || hasBackfills {
+		var objs []map[string]json.RawMessage
+		if err := json.Unmarshal(ops, &objs); err != nil {
+			return nil, err
+		}
+		for i, s := range m.OperationSchemas {
+			if s == "" || i >= len(objs) {
+				continue
+			}
+			objs[i][operationSchemaKey], err = json.Marshal(s)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for i, s := range m.OperationBackfills {
+			if s == nil || i >= len(objs) {
+				continue
+			}
+			objs[i][operationBackfillKey], err = json.Marshal(s)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if ops, err = json.Marshal(objs); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(RawMigration{
+		VersionSchema: m.VersionSchema,
+		Operations:    ops,
+		Backfill:      m.Backfill,
+		Squashes:      m.Squashes,
+	})
+}
+
+// UnmarshalJSON deserializes the migration, including the schema targeted by
+// each operation that names one.
+func (m *Migration) UnmarshalJSON(data []byte) error {
+	var raw RawMigration
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	raw.Name = m.Name
+
+	if len(raw.Operations) == 0 {
+		*m = Migration{Name: raw.Name, VersionSchema: raw.VersionSchema, Backfill: raw.Backfill, Squashes: raw.Squashes}
+		return nil
+	}
+
+	parsed, err := ParseMigration(&raw)
+	if err != nil {
+		return err
+	}
+
+	*m = *parsed
+	return nil
+}
+
+// splitOperationSchemas removes the schema key from each object in the raw
+// operations array, returning the remaining operations and the schema named
+// by each of them. The returned schemas are nil if no operation names one.
+func splitOperationSchemas(raw json.RawMessage) (json.RawMessage, []string, error) {
+	var objs []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &objs); err != nil {
+		// Leave it to the operations parser to report malformed operations
+		return raw, nil, nil
+	}
+
+	var schemas []string
+	for i, obj := range objs {
+		value, ok := obj[operationSchemaKey]
+		if !ok {
+			continue
+		}
+
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil || s == "" {
+			return nil, nil, fmt.Errorf("schema of operation at index %d must be a non-empty string", i)
+		}
+		if schemas == nil {
+			schemas = make([]string, len(objs))
+		}
+		schemas[i] = s
+		delete(obj, operationSchemaKey)
+	}
+
+	if schemas == nil {
+		return raw, nil, nil
+	}
+
+	ops, err := json.Marshal(objs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ops, schemas, nil
+}
@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/lib/pq"
+
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+// sequenceTypeRanges are the ranges of the data types a sequence can have
+var sequenceTypeRanges = map[string][2]int64{
+	"smallint": {math.MinInt16, math.MaxInt16},
+	"integer":  {math.MinInt32, math.MaxInt32},
+	"bigint":   {math.MinInt64, math.MaxInt64},
+}
+
+// integerColumnTypes are the column types that can be identity columns, or
+// that are backed by a sequence owned by the column
+var integerColumnTypes = []string{
+	"smallint", "integer", "bigint",
+	"int2", "int4", "int8", "int",
+	"smallserial", "serial", "bigserial",
+	"serial2", "serial4", "serial8",
+}
+
+// integerTypeSizes are the storage sizes, in bytes, of the integer types
+var integerTypeSizes = map[string]int{
+	"smallint": 2, "int2": 2,
+	"integer": 4, "int": 4, "int4": 4,
+	"bigint": 8, "int8": 8,
+}
+
+// createSequenceSQL returns the CREATE SEQUENCE statement for `seq`, with all
+// of its options set explicitly.
+func createSequenceSQL(seq *schema.Sequence) string {
+	cycle := "NO CYCLE"
+	if seq.Cycle {
+		cycle = "CYCLE"
+	}
+	return fmt.Sprintf("CREATE SEQUENCE %s AS %s INCREMENT BY %d MINVALUE %d MAXVALUE %d START WITH %d CACHE %d %s",
+		pq.QuoteIdentifier(seq.Name),
+		seq.Type,
+		seq.Increment,
+		seq.MinValue,
+		seq.MaxValue,
+		seq.Start,
+		seq.Cache,
+		cycle)
+}
+
+// validateSequence returns an error if the options of `seq` are inconsistent.
+func validateSequence(seq *schema.Sequence) error {
+	invalid := func(reason string, args ...any) error {
+		return InvalidSequenceError{Name: seq.Name, Reason: fmt.Sprintf(reason, args...)}
+	}
+
+	bounds, ok := sequenceTypeRanges[seq.Type]
+	switch {
+	case !ok:
+		return invalid("unknown data type %q", seq.Type)
+	case seq.Increment == 0:
+		return invalid("increment must not be zero")
+	case seq.MinValue < bounds[0] || seq.MaxValue > bounds[1]:
+		return invalid("minimum and maximum values must be in the range of type %s", seq.Type)
+	case seq.MinValue >= seq.MaxValue:
+		return invalid("minimum value must be less than the maximum value")
+	case seq.Start < seq.MinValue || seq.Start > seq.MaxValue:
+		return invalid("start value must be between the minimum and maximum values")
+	case seq.Cache < 1:
+		return invalid("cache must be at least 1")
+	}
+	return nil
+}
+
+// identityGeneration returns the generation of an identity column for
+// `identity`, as it is represented in the schema.
+func identityGeneration(identity OpSetIdentityIdentity) string {
+	switch identity {
+	case OpSetIdentityIdentityAlways:
+		return "ALWAYS"
+	case OpSetIdentityIdentityByDefault:
+		return "BY DEFAULT"
+	default:
+		return ""
+	}
+}
+
+// setIdentitySQL returns the statement that changes how `column` of `table`
+// generates its values to `identity`. The statement only changes the
+// column's metadata, without rewriting the table, and takes an ACCESS
+// EXCLUSIVE lock on the table for as long as it runs.
+//
+// The new identity or sequence continues from the next value of the column's
+// current sequence, or from the maximum value of the column if it has none,
+// so that generated values never repeat.
+func setIdentitySQL(table string, column *schema.Column, identity OpSetIdentityIdentity) string {
+	qTable := pq.QuoteIdentifier(table)
+	qColumn := pq.QuoteIdentifier(column.Name)
+
+	generation := identityGeneration(identity)
+	switch {
+	case column.Identity != "" && generation != "":
+		// Change the generation of an identity column
+		return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET GENERATED %s", qTable, qColumn, generation)
+
+	case column.Identity != "":
+		// Replace the identity with a sequence owned by the column that
+		// continues from the identity's sequence
+		seq := pq.QuoteIdentifier(column.Sequence)
+		return fmt.Sprintf(`DO $pgroll$
+DECLARE
+  next bigint;
+  options text;
+BEGIN
+  LOCK TABLE %[1]s IN ACCESS EXCLUSIVE MODE;
+  SELECT format(' AS %%s INCREMENT BY %%s CACHE %%s', format_type(seqtypid, NULL), seqincrement, seqcache)
+    FROM pg_sequence WHERE seqrelid = %[3]s::regclass INTO options;
+  next := nextval(%[3]s::regclass);
+  ALTER TABLE %[1]s ALTER COLUMN %[2]s DROP IDENTITY;
+  EXECUTE format('CREATE SEQUENCE %%s%%s START WITH %%s OWNED BY %%s.%%s', %[4]s, options, next, %[5]s, %[6]s);
+  EXECUTE format('ALTER TABLE %%s ALTER COLUMN %%s SET DEFAULT nextval(%%L::regclass)', %[5]s, %[6]s, %[4]s);
+END $pgroll$`,
+			qTable, qColumn, pq.QuoteLiteral(seq), pq.QuoteLiteral(seq), pq.QuoteLiteral(qTable), pq.QuoteLiteral(qColumn))
+
+	case column.Sequence != "":
+		// Replace the sequence of a serial column with an identity that
+		// continues from it
+		seq := pq.QuoteIdentifier(column.Sequence)
+		return fmt.Sprintf(`DO $pgroll$
+DECLARE
+  next bigint;
+  options text;
+BEGIN
+  LOCK TABLE %[1]s IN ACCESS EXCLUSIVE MODE;
+  SELECT format(' INCREMENT BY %%s CACHE %%s', seqincrement, seqcache)
+    FROM pg_sequence WHERE seqrelid = %[4]s::regclass INTO options;
+  next := nextval(%[4]s::regclass);
+  ALTER TABLE %[1]s ALTER COLUMN %[2]s DROP DEFAULT;
+  DROP SEQUENCE %[3]s;
+  EXECUTE format('ALTER TABLE %%s ALTER COLUMN %%s ADD GENERATED %[5]s AS IDENTITY (START WITH %%s%%s)', %[6]s, %[7]s, next, options);
+END $pgroll$`,
+			qTable, qColumn, seq, pq.QuoteLiteral(seq), generation, pq.QuoteLiteral(qTable), pq.QuoteLiteral(qColumn))
+
+	default:
+		// Make a column without a sequence an identity column that continues
+		// from the column's maximum value
+		return fmt.Sprintf(`DO $pgroll$
+DECLARE
+  next bigint;
+BEGIN
+  LOCK TABLE %[1]s IN ACCESS EXCLUSIVE MODE;
+  SELECT COALESCE(max(%[2]s), 0) + 1 FROM %[1]s INTO next;
+  EXECUTE format('ALTER TABLE %%s ALTER COLUMN %%s ADD GENERATED %[3]s AS IDENTITY (START WITH %%s)', %[4]s, %[5]s, next);
+END $pgroll$`,
+			qTable, qColumn, generation, pq.QuoteLiteral(qTable), pq.QuoteLiteral(qColumn))
+	}
+}
+
+// isIntegerColumnType returns true if a column of type `t` can be an identity
+// column.
+func isIntegerColumnType(t string) bool {
+	return slices.Contains(integerColumnTypes, t)
+}
@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+func TestCreateSequenceSQL(t *testing.T) {
+	t.Parallel()
+
+	sql := createSequenceSQL(&schema.Sequence{
+		Name:      "invoice_numbers",
+		Type:      "integer",
+		Start:     1000,
+		Increment: 10,
+		MinValue:  1,
+		MaxValue:  100000,
+		Cache:     5,
+		Cycle:     true,
+	})
+	assert.Equal(t, `CREATE SEQUENCE "invoice_numbers" AS integer INCREMENT BY 10 MINVALUE 1 MAXVALUE 100000 START WITH 1000 CACHE 5 CYCLE`, sql)
+}
+
+func TestCreateSequenceDefaults(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		op   OpCreateSequence
+		want *schema.Sequence
+	}{
+		{
+			name: "no options",
+			op:   OpCreateSequence{Name: "seq"},
+			want: &schema.Sequence{Name: "seq", Type: "bigint", Start: 1, Increment: 1, MinValue: 1, MaxValue: 9223372036854775807, Cache: 1},
+		},
+		{
+			name: "ascending smallint sequence",
+			op:   OpCreateSequence{Name: "seq", Type: OpCreateSequenceTypeSmallint, Increment: ptr(5)},
+			want: &schema.Sequence{Name: "seq", Type: "smallint", Start: 1, Increment: 5, MinValue: 1, MaxValue: 32767, Cache: 1},
+		},
+		{
+			name: "descending integer sequence",
+			op:   OpCreateSequence{Name: "seq", Type: OpCreateSequenceTypeInteger, Increment: ptr(-1)},
+			want: &schema.Sequence{Name: "seq", Type: "integer", Start: -1, Increment: -1, MinValue: -2147483648, MaxValue: -1, Cache: 1},
+		},
+		{
+			name: "start defaults to the minimum value",
+			op:   OpCreateSequence{Name: "seq", MinValue: ptr(100), Cache: ptr(20), Cycle: true},
+			want: &schema.Sequence{Name: "seq", Type: "bigint", Start: 100, Increment: 1, MinValue: 100, MaxValue: 9223372036854775807, Cache: 20, Cycle: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.op.sequence())
+		})
+	}
+}
+
+func TestValidateSequence(t *testing.T) {
+	t.Parallel()
+
+	valid := func() *schema.Sequence {
+		return &schema.Sequence{Name: "seq", Type: "integer", Start: 1, Increment: 1, MinValue: 1, MaxValue: 100, Cache: 1}
+	}
+
+	tests := []struct {
+		name    string
+		update  func(seq *schema.Sequence)
+		wantErr error
+	}{
+		{
+			name:   "valid sequence",
+			update: func(seq *schema.Sequence) {},
+		},
+		{
+			name:    "unknown type",
+			update:  func(seq *schema.Sequence) { seq.Type = "numeric" },
+			wantErr: InvalidSequenceError{Name: "seq", Reason: `unknown data type "numeric"`},
+		},
+		{
+			name:    "zero increment",
+			update:  func(seq *schema.Sequence) { seq.Increment = 0 },
+			wantErr: InvalidSequenceError{Name: "seq", Reason: "increment must not be zero"},
+		},
+		{
+			name:    "maximum value out of the range of the type",
+			update:  func(seq *schema.Sequence) { seq.MaxValue = 3000000000 },
+			wantErr: InvalidSequenceError{Name: "seq", Reason: "minimum and maximum values must be in the range of type integer"},
+		},
+		{
+			name:    "minimum value greater than the maximum value",
+			update:  func(seq *schema.Sequence) { seq.MinValue = 200 },
+			wantErr: InvalidSequenceError{Name: "seq", Reason: "minimum value must be less than the maximum value"},
+		},
+		{
+			name:    "start value out of range",
+			update:  func(seq *schema.Sequence) { seq.Start = 101 },
+			wantErr: InvalidSequenceError{Name: "seq", Reason: "start value must be between the minimum and maximum values"},
+		},
+		{
+			name:    "zero cache",
+			update:  func(seq *schema.Sequence) { seq.Cache = 0 },
+			wantErr: InvalidSequenceError{Name: "seq", Reason: "cache must be at least 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seq := valid()
+			tt.update(seq)
+			assert.Equal(t, tt.wantErr, validateSequence(seq))
+		})
+	}
+}
+
+func TestSetIdentitySQL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("change the generation of an identity column", func(t *testing.T) {
+		column := &schema.Column{Name: "id", Type: "bigint", Identity: "BY DEFAULT", Sequence: "orders_id_seq"}
+
+		sql := setIdentitySQL("orders", column, OpSetIdentityIdentityAlways)
+		assert.Equal(t, `ALTER TABLE "orders" ALTER COLUMN "id" SET GENERATED ALWAYS`, sql)
+	})
+
+	t.Run("identity columns continue from their sequence", func(t *testing.T) {
+		column := &schema.Column{Name: "id", Type: "bigint", Identity: "ALWAYS", Sequence: "orders_id_seq"}
+
+		sql := setIdentitySQL("orders", column, OpSetIdentityIdentityNone)
+		assert.Contains(t, sql, `next := nextval('"orders_id_seq"'::regclass);`)
+		assert.Contains(t, sql, `ALTER TABLE "orders" ALTER COLUMN "id" DROP IDENTITY;`)
+	})
+
+	t.Run("serial columns continue from their sequence", func(t *testing.T) {
+		def := "nextval('orders_id_seq'::regclass)"
+		column := &schema.Column{Name: "id", Type: "integer", Default: &def, Sequence: "orders_id_seq"}
+
+		sql := setIdentitySQL("orders", column, OpSetIdentityIdentityByDefault)
+		assert.Contains(t, sql, `next := nextval('"orders_id_seq"'::regclass);`)
+		assert.Contains(t, sql, `DROP SEQUENCE "orders_id_seq";`)
+		assert.Contains(t, sql, `ADD GENERATED BY DEFAULT AS IDENTITY`)
+	})
+
+	t.Run("columns without a sequence continue from their maximum value", func(t *testing.T) {
+		column := &schema.Column{Name: "id", Type: "integer"}
+
+		sql := setIdentitySQL("orders", column, OpSetIdentityIdentityAlways)
+		assert.Contains(t, sql, `SELECT COALESCE(max("id"), 0) + 1 FROM "orders" INTO next;`)
+		assert.Contains(t, sql, `ADD GENERATED ALWAYS AS IDENTITY`)
+	})
+}
@@ -0,0 +1,517 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+// createTableCopyAction is a DBAction that creates an empty copy of a table,
+// with the table's columns, defaults, constraints, indexes, foreign keys,
+// owner and privileges.
+type createTableCopyAction struct {
+	conn    db.DB
+	id      string
+	table   string
+	copy    string
+	options string
+}
+
+// NewCreateTableCopyAction creates an empty copy of `table` named `copy`.
+// `options` are appended to the CREATE TABLE statement of the copy, for
+// example to partition the copy or to set its storage parameters.
+func NewCreateTableCopyAction(conn db.DB, table, copy, options string) *createTableCopyAction {
+	return &createTableCopyAction{
+		conn:    conn,
+		id:      fmt.Sprintf("create_table_copy_%s", copy),
+		table:   table,
+		copy:    copy,
+		options: options,
+	}
+}
+
+func (a *createTableCopyAction) ID() string { return a.id }
+
+func (a *createTableCopyAction) Execute(ctx context.Context) error {
+	return a.conn.WithRetryableTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING ALL) %s",
+			pq.QuoteIdentifier(a.copy),
+			pq.QuoteIdentifier(a.table),
+			a.options))
+		if err != nil {
+			return err
+		}
+
+		// Foreign keys are not copied by LIKE
+		stmts, err := queryStrings(ctx, tx, `
+		  SELECT format('ALTER TABLE %I ADD CONSTRAINT %I %s', $2::text, conname, pg_get_constraintdef(oid))
+		  FROM pg_constraint
+		  WHERE conrelid = $1::regclass
+		    AND contype = 'f'
+		  ORDER BY conname`,
+			pq.QuoteIdentifier(a.table), a.copy)
+		if err != nil {
+			return fmt.Errorf("getting foreign keys of %q: %w", a.table, err)
+		}
+		// The privileges on the table and its columns are granted on the copy, so
+		// that roles keep them once the copy replaces the table
+		grants, err := queryStrings(ctx, tx, `
+		  SELECT * FROM (
+		    SELECT format('GRANT %s ON %I TO %s%s', a.privilege_type, $2::text,
+		      CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE quote_ident(pg_get_userbyid(a.grantee)) END,
+		      CASE WHEN a.is_grantable THEN ' WITH GRANT OPTION' ELSE '' END)
+		    FROM pg_class c, aclexplode(c.relacl) a
+		    WHERE c.oid = $1::regclass
+		      AND a.grantee <> c.relowner
+		    ORDER BY a.grantee, a.privilege_type
+		  ) table_grants
+		  UNION ALL
+		  SELECT * FROM (
+		    SELECT format('GRANT %s (%I) ON %I TO %s%s', a.privilege_type, att.attname, $2::text,
+		      CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE quote_ident(pg_get_userbyid(a.grantee)) END,
+		      CASE WHEN a.is_grantable THEN ' WITH GRANT OPTION' ELSE '' END)
+		    FROM pg_attribute att, aclexplode(att.attacl) a
+		    WHERE att.attrelid = $1::regclass
+		      AND att.attnum > 0
+		      AND NOT att.attisdropped
+		    ORDER BY att.attnum, a.grantee, a.privilege_type
+		  ) column_grants`,
+			pq.QuoteIdentifier(a.table), a.copy)
+		if err != nil {
+			return fmt.Errorf("getting privileges of %q: %w", a.table, err)
+		}
+		stmts = append(stmts, grants...)
+
+		// The copy is owned by the owner of the table rather than by the role
+		// that created it
+		owner, err := queryStrings(ctx, tx, `
+		  SELECT format('ALTER TABLE %I OWNER TO %I', $2::text, pg_get_userbyid(c.relowner))
+		  FROM pg_class c
+		  WHERE c.oid = $1::regclass
+		    AND pg_get_userbyid(c.relowner) <> current_user`,
+			pq.QuoteIdentifier(a.table), a.copy)
+		if err != nil {
+			return fmt.Errorf("getting owner of %q: %w", a.table, err)
+		}
+		stmts = append(stmts, owner...)
+
+		for _, stmt := range stmts {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// copyTableSettingsAction is a DBAction that gives a copy of a table, created
+// by NewCreateTableCopyAction, the storage parameters and tablespace of the
+// table.
+type copyTableSettingsAction struct {
+	conn              db.DB
+	id                string
+	table             string
+	copy              string
+	storageParameters string
+	tablespace        string
+}
+
+// NewCopyTableSettingsAction gives `copy` the storage parameters and
+// tablespace of `table`. `storageParameters` are set on the copy on top of
+// the storage parameters of the table and a non-empty `tablespace` replaces
+// the tablespace of the table. The copy must be empty, so that moving it to
+// another tablespace is cheap.
+func NewCopyTableSettingsAction(conn db.DB, table, copy, storageParameters, tablespace string) *copyTableSettingsAction {
+	return &copyTableSettingsAction{
+		conn:              conn,
+		id:                fmt.Sprintf("copy_table_settings_%s", copy),
+		table:             table,
+		copy:              copy,
+		storageParameters: storageParameters,
+		tablespace:        tablespace,
+	}
+}
+
+func (a *copyTableSettingsAction) ID() string { return a.id }
+
+func (a *copyTableSettingsAction) Execute(ctx context.Context) error {
+	return a.conn.WithRetryableTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		copy := pq.QuoteIdentifier(a.copy)
+
+		options, tablespace, err := storageSettings(ctx, tx, a.table)
+		if err != nil {
+			return fmt.Errorf("getting storage settings of %q: %w", a.table, err)
+		}
+		if a.tablespace != "" {
+			tablespace = a.tablespace
+		}
+
+		var stmts []string
+		if options != "" {
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s SET (%s)", copy, options))
+		}
+		if a.storageParameters != "" {
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s SET (%s)", copy, a.storageParameters))
+		}
+		if tablespace != "" {
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s SET TABLESPACE %s", copy, pq.QuoteIdentifier(tablespace)))
+		}
+
+		for _, stmt := range stmts {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// storageSettings returns the storage parameters and the tablespace of
+// `table`. Both are empty if the table has none, or if the query returns no
+// rows because it was run against a fake database.
+func storageSettings(ctx context.Context, tx *sql.Tx, table string) (string, string, error) {
+	rows, err := tx.QueryContext(ctx, `
+	  SELECT coalesce(array_to_string(c.reloptions, ', '), ''), coalesce(t.spcname, '')
+	  FROM pg_class c
+	  LEFT JOIN pg_tablespace t ON t.oid = c.reltablespace
+	  WHERE c.oid = $1::regclass`,
+		pq.QuoteIdentifier(table))
+	if err != nil {
+		return "", "", err
+	}
+	defer rows.Close()
+
+	var options, tablespace string
+	if rows.Next() {
+		if err := rows.Scan(&options, &tablespace); err != nil {
+			return "", "", err
+		}
+	}
+	return options, tablespace, rows.Err()
+}
+
+// replaceTableWithCopyAction is a DBAction that replaces a table with a copy
+// of it created by NewCreateTableCopyAction.
+type replaceTableWithCopyAction struct {
+	conn  db.DB
+	id    string
+	table string
+	copy  string
+}
+
+// NewReplaceTableWithCopyAction replaces `table` with `copy` in a single
+// transaction. The trigger that copies rows from the table to the copy is
+// dropped, the table is dropped and the copy takes its name. The indexes of
+// the copy are given the names of the matching indexes of the table, its
+// sequences and replica identity are carried over, and views that read from
+// the table are recreated to read from the copy.
+func NewReplaceTableWithCopyAction(conn db.DB, table, copy string) *replaceTableWithCopyAction {
+	return &replaceTableWithCopyAction{
+		conn:  conn,
+		id:    fmt.Sprintf("replace_table_%s", table),
+		table: table,
+		copy:  copy,
+	}
+}
+
+func (a *replaceTableWithCopyAction) ID() string { return a.id }
+
+func (a *replaceTableWithCopyAction) Execute(ctx context.Context) error {
+	return a.conn.WithRetryableTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		table := pq.QuoteIdentifier(a.table)
+		copy := pq.QuoteIdentifier(a.copy)
+
+		_, err := tx.ExecContext(ctx, fmt.Sprintf("LOCK TABLE %s, %s IN ACCESS EXCLUSIVE MODE", table, copy))
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("DROP FUNCTION IF EXISTS %s CASCADE",
+			pq.QuoteIdentifier(backfill.CopyTriggerName(a.table))))
+		if err != nil {
+			return err
+		}
+
+		// Views that read from the table are bound to it rather than to its name,
+		// so they are dropped and recreated once the copy has replaced the table
+		views, err := dependentViews(ctx, tx, a.table)
+		if err != nil {
+			return err
+		}
+		for i := len(views) - 1; i >= 0; i-- {
+			if _, err := tx.ExecContext(ctx, "DROP VIEW IF EXISTS "+views[i].name); err != nil {
+				return err
+			}
+		}
+
+		// The identity columns of the copy have their own sequences, which
+		// continue from the sequences of the table
+		identities, err := queryStrings(ctx, tx, `
+		  SELECT format('SELECT setval(pg_get_serial_sequence(%L, %L), last_value, is_called) FROM %s',
+		    $2::text, attname, pg_get_serial_sequence($1::text, attname))
+		  FROM pg_attribute
+		  WHERE attrelid = $1::text::regclass
+		    AND attnum > 0
+		    AND NOT attisdropped
+		    AND attidentity <> ''
+		  ORDER BY attnum`,
+			table, copy)
+		if err != nil {
+			return fmt.Errorf("getting identity columns of %q: %w", a.table, err)
+		}
+
+		// The defaults of the copy's serial columns use the sequences owned by
+		// the table, which must be owned by the copy before the table is dropped
+		serials, err := queryStrings(ctx, tx, `
+		  SELECT format('ALTER SEQUENCE %s OWNED BY %I.%I', pg_get_serial_sequence($1::text, attname), $2::text, attname)
+		  FROM pg_attribute
+		  WHERE attrelid = $1::text::regclass
+		    AND attnum > 0
+		    AND NOT attisdropped
+		    AND attidentity = ''
+		    AND pg_get_serial_sequence($1::text, attname) IS NOT NULL
+		  ORDER BY attnum`,
+			table, a.table)
+		if err != nil {
+			return fmt.Errorf("getting sequences of %q: %w", a.table, err)
+		}
+
+		renameIndexes, err := matchingIndexRenames(ctx, tx, a.table, a.copy)
+		if err != nil {
+			return err
+		}
+
+		// The replica identity of the table is set on the copy once its indexes
+		// have the names of the indexes of the table
+		replicaIdentity, err := queryStrings(ctx, tx, `
+		  SELECT format('ALTER TABLE %I REPLICA IDENTITY %s', $2::text, CASE c.relreplident
+		    WHEN 'f' THEN 'FULL'
+		    WHEN 'n' THEN 'NOTHING'
+		    ELSE (
+		      SELECT format('USING INDEX %I', ic.relname)
+		      FROM pg_index i
+		      JOIN pg_class ic ON ic.oid = i.indexrelid
+		      WHERE i.indrelid = c.oid
+		        AND i.indisreplident
+		    )
+		  END)
+		  FROM pg_class c
+		  WHERE c.oid = $1::regclass
+		    AND c.relreplident <> 'd'`,
+			table, a.table)
+		if err != nil {
+			return fmt.Errorf("getting replica identity of %q: %w", a.table, err)
+		}
+
+		for _, stmt := range identities {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s RENAME TO %s",
+			table, pq.QuoteIdentifier(DeletionName(a.table))))
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s RENAME TO %s", copy, table))
+		if err != nil {
+			return err
+		}
+
+		for _, stmt := range serials {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("DROP TABLE %s", pq.QuoteIdentifier(DeletionName(a.table))))
+		if err != nil {
+			return err
+		}
+
+		for _, stmt := range append(renameIndexes, replicaIdentity...) {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+
+		for _, v := range views {
+			for _, stmt := range v.stmts {
+				if _, err := tx.ExecContext(ctx, stmt); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// tableCopyLoss returns a description of what would be lost if `table` were
+// replaced by a copy of it, or "" if the copy can replace the table.
+func tableCopyLoss(table *schema.Table) string {
+	// Row level security and the policies of the table are not carried over
+	// to the copy
+	if table.RowLevelSecurity {
+		return "row level security is enabled on the table"
+	}
+	// The triggers of the table are not carried over to the copy
+	if len(table.Triggers) > 0 {
+		return fmt.Sprintf("the table has trigger %q", table.Triggers[0])
+	}
+	// The copy is not a member of the publications that the table is in
+	if len(table.Publications) > 0 {
+		return fmt.Sprintf("the table is in publication %q", table.Publications[0])
+	}
+	return ""
+}
+
+// dependentView is a view that reads from a table, directly or through other
+// views, together with the statements that recreate it.
+type dependentView struct {
+	name  string
+	stmts []string
+}
+
+// dependentViews returns the views that read from `table`, in an order in
+// which they can be created, along with the statements to recreate each view
+// with its options, column defaults, comments and privileges.
+func dependentViews(ctx context.Context, tx *sql.Tx, table string) ([]dependentView, error) {
+	rows, err := tx.QueryContext(ctx, `
+	  WITH RECURSIVE deps(oid, depth) AS (
+	    SELECT r.ev_class, 1
+	    FROM pg_depend d
+	    JOIN pg_rewrite r ON r.oid = d.objid
+	    WHERE d.classid = 'pg_rewrite'::regclass
+	      AND d.refobjid = $1::regclass
+	      AND r.ev_class <> $1::regclass
+	    UNION
+	    SELECT r.ev_class, deps.depth + 1
+	    FROM deps
+	    JOIN pg_depend d ON d.refobjid = deps.oid
+	    JOIN pg_rewrite r ON r.oid = d.objid
+	    WHERE d.classid = 'pg_rewrite'::regclass
+	      AND r.ev_class <> deps.oid
+	  )
+	  SELECT c.oid, format('%I.%I', n.nspname, c.relname), c.relkind = 'm'
+	  FROM deps
+	  JOIN pg_class c ON c.oid = deps.oid
+	  JOIN pg_namespace n ON n.oid = c.relnamespace
+	  GROUP BY c.oid, n.nspname, c.relname, c.relkind
+	  ORDER BY max(deps.depth), n.nspname, c.relname`,
+		pq.QuoteIdentifier(table))
+	if err != nil {
+		return nil, fmt.Errorf("getting views that read from %q: %w", table, err)
+	}
+
+	type view struct {
+		oid          int64
+		name         string
+		materialized bool
+	}
+	var found []view
+	for rows.Next() {
+		var v view
+		if err := rows.Scan(&v.oid, &v.name, &v.materialized); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning views that read from %q: %w", table, err)
+		}
+		found = append(found, v)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	views := make([]dependentView, 0, len(found))
+	for _, v := range found {
+		if v.materialized {
+			return nil, fmt.Errorf("materialized view %s reads from table %q", v.name, table)
+		}
+
+		stmts, err := queryStrings(ctx, tx, `
+		  SELECT format('CREATE VIEW %s %s AS %s', $2::text,
+		    CASE WHEN c.reloptions IS NULL THEN '' ELSE 'WITH (' || array_to_string(c.reloptions, ', ') || ')' END,
+		    rtrim(pg_get_viewdef(c.oid), ';'))
+		  FROM pg_class c
+		  WHERE c.oid = $1
+		  UNION ALL
+		  SELECT * FROM (
+		    SELECT format('ALTER VIEW %s ALTER COLUMN %I SET DEFAULT %s', $2::text, a.attname, pg_get_expr(d.adbin, d.adrelid))
+		    FROM pg_attrdef d
+		    JOIN pg_attribute a ON a.attrelid = d.adrelid AND a.attnum = d.adnum
+		    WHERE d.adrelid = $1
+		    ORDER BY a.attnum
+		  ) defaults
+		  UNION ALL
+		  SELECT format('COMMENT ON VIEW %s IS %L', $2::text, obj_description($1, 'pg_class'))
+		  WHERE obj_description($1, 'pg_class') IS NOT NULL
+		  UNION ALL
+		  SELECT * FROM (
+		    SELECT format('GRANT %s ON %s TO %s', a.privilege_type, $2::text,
+		      CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE quote_ident(pg_get_userbyid(a.grantee)) END)
+		    FROM pg_class c, aclexplode(c.relacl) a
+		    WHERE c.oid = $1
+		      AND a.grantee <> c.relowner
+		    ORDER BY a.grantee, a.privilege_type
+		  ) grants`,
+			v.oid, v.name)
+		if err != nil {
+			return nil, fmt.Errorf("getting definition of view %s: %w", v.name, err)
+		}
+		views = append(views, dependentView{name: v.name, stmts: stmts})
+	}
+	return views, nil
+}
+
+// matchingIndexRenames returns the statements that give each index of `copy`
+// the name of the matching index of `table`. The primary keys of the tables
+// match, as do any other indexes with the same definition.
+func matchingIndexRenames(ctx context.Context, tx *sql.Tx, table, copy string) ([]string, error) {
+	return queryStrings(ctx, tx, `
+	  WITH indexes AS (
+	    SELECT i.indrelid, c.relname, i.indisprimary,
+	      CASE WHEN i.indisprimary THEN 'pk'
+	        ELSE i.indisunique || substring(pg_get_indexdef(i.indexrelid) FROM ' USING .*$')
+	      END AS key
+	    FROM pg_index i
+	    JOIN pg_class c ON c.oid = i.indexrelid
+	    WHERE i.indrelid IN ($1::regclass, $2::regclass)
+	  )
+	  SELECT DISTINCT ON (old.relname) format('ALTER INDEX %I RENAME TO %I', new.relname, old.relname)
+	  FROM indexes old
+	  JOIN indexes new ON new.key = old.key
+	  WHERE old.indrelid = $1::regclass
+	    AND new.indrelid = $2::regclass
+	    AND old.relname <> new.relname
+	  ORDER BY old.relname, new.relname`,
+		pq.QuoteIdentifier(table), pq.QuoteIdentifier(copy))
+}
+
+// queryStrings runs a query that returns a single text column and returns its
+// values.
+func queryStrings(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
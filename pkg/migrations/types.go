@@ -5,6 +5,25 @@ package migrations
 
 import "github.com/oapi-codegen/nullable"
 
+// Backfill settings. Settings of an operation apply to the tables that it
+// backfills and take precedence over the settings of the migration, which take
+// precedence over the settings pgroll is run with
+type BackfillSettings struct {
+	// Delay between batches, as a duration such as '100ms' or '1s'
+	BatchDelay *string `json:"batch_delay,omitempty"`
+
+	// Number of rows backfilled in each batch
+	BatchSize *int `json:"batch_size,omitempty"`
+
+	// Longest time the backfill of a table may take, as a duration such as '30m' or
+	// '2h'. The backfill fails if it takes longer
+	MaxDuration *string `json:"max_duration,omitempty"`
+
+	// Maximum number of tables backfilled concurrently. Can only be set for the
+	// migration
+	Parallelism *int `json:"parallelism,omitempty"`
+}
+
 // Check constraint definition
 type CheckConstraint struct {
 	// Constraint expression
@@ -177,6 +196,10 @@ type ForeignKeyReference struct {
 	// On update behavior of the foreign key constraint
 	OnUpdate ForeignKeyAction `json:"on_update,omitempty"`
 
+	// Schema of the referenced table. Defaults to the schema of the table with the
+	// foreign key
+	Schema string `json:"schema,omitempty"`
+
 	// Name of the referenced table
 	Table string `json:"table"`
 }
@@ -254,6 +277,11 @@ type OpAlterColumn struct {
 	// SQL expression for down migration
 	Down string `json:"down"`
 
+	// Setting to null makes a stored generated column a regular column. Generation
+	// expressions can't be set, as Postgres can't make an existing column a stored
+	// generated column, or change the expression of one, without rewriting the table.
+	Generated nullable.Nullable[string] `json:"generated,omitempty"`
+
 	// Indicates if the column is nullable (for add/remove not null constraint
 	// operation)
 	Nullable *bool `json:"nullable,omitempty"`
@@ -274,6 +302,102 @@ type OpAlterColumn struct {
 	Up string `json:"up"`
 }
 
+// Alter enum type operation
+type OpAlterEnum struct {
+	// Add a value to the enum type
+	AddValue *OpAlterEnumAddValue `json:"add_value,omitempty"`
+
+	// Name of the enum type
+	Name string `json:"name"`
+
+	// Rename a value of the enum type. The value is renamed when the migration is
+	// completed
+	RenameValue *OpAlterEnumRenameValue `json:"rename_value,omitempty"`
+}
+
+// Add a value to the enum type
+type OpAlterEnumAddValue struct {
+	// Existing value after which to place the new value
+	After string `json:"after,omitempty"`
+
+	// Existing value before which to place the new value
+	Before string `json:"before,omitempty"`
+
+	// Value to add
+	Value string `json:"value"`
+}
+
+// Rename a value of the enum type. The value is renamed when the migration is
+// completed
+type OpAlterEnumRenameValue struct {
+	// Old name of the value
+	From string `json:"from"`
+
+	// New name of the value
+	To string `json:"to"`
+}
+
+// Alter row level security policy operation
+type OpAlterPolicy struct {
+	// Name of the policy
+	Name string `json:"name"`
+
+	// New roles the policy applies to
+	Roles []string `json:"roles,omitempty"`
+
+	// Name of the table
+	Table string `json:"table"`
+
+	// New expression that existing rows must satisfy to be visible, referring to
+	// columns by the names they have in the new version of the schema
+	Using *string `json:"using,omitempty"`
+
+	// New expression that new and updated rows must satisfy, referring to columns by
+	// the names they have in the new version of the schema
+	WithCheck *string `json:"with_check,omitempty"`
+}
+
+// Alter primary key type operation
+type OpAlterPrimaryKeyType struct {
+	// Name of the primary key column
+	Column string `json:"column"`
+
+	// Name of the table
+	Table string `json:"table"`
+
+	// New type of the primary key column and of the columns of the foreign keys that
+	// reference it. Must be an integer type wider than the current type of the column
+	Type string `json:"type"`
+}
+
+// Alter sequence operation
+type OpAlterSequence struct {
+	// New number of values of the sequence to preallocate
+	Cache *int `json:"cache,omitempty"`
+
+	// New increment of the sequence
+	Increment *int `json:"increment,omitempty"`
+
+	// Name of the sequence
+	Name string `json:"name"`
+
+	// Value to restart the sequence with
+	Restart *int `json:"restart,omitempty"`
+}
+
+// Attach partition operation
+type OpAttachPartition struct {
+	// Partition bound, for example `FOR VALUES FROM (1) TO (10)` or `DEFAULT`
+	Bound string `json:"bound"`
+
+	// Name of the partition. A new partition is created unless a table with this name
+	// exists, in which case the table is attached as a partition
+	Partition string `json:"partition"`
+
+	// Name of the partitioned table
+	Table string `json:"table"`
+}
+
 // Add constraint to table operation
 type OpCreateConstraint struct {
 	// Check constraint expression
@@ -325,6 +449,15 @@ const OpCreateConstraintTypeForeignKey OpCreateConstraintType = "foreign_key"
 const OpCreateConstraintTypePrimaryKey OpCreateConstraintType = "primary_key"
 const OpCreateConstraintTypeUnique OpCreateConstraintType = "unique"
 
+// Create enum type operation
+type OpCreateEnum struct {
+	// Name of the enum type
+	Name string `json:"name"`
+
+	// Values of the enum type, in sort order
+	Values []string `json:"values"`
+}
+
 // Create index operation
 type OpCreateIndex struct {
 	// Names and settings of columns on which to define the index
@@ -358,6 +491,79 @@ const OpCreateIndexMethodGist OpCreateIndexMethod = "gist"
 const OpCreateIndexMethodHash OpCreateIndexMethod = "hash"
 const OpCreateIndexMethodSpgist OpCreateIndexMethod = "spgist"
 
+// Create row level security policy operation
+type OpCreatePolicy struct {
+	// Command the policy applies to
+	Command OpCreatePolicyCommand `json:"command,omitempty"`
+
+	// Name of the policy
+	Name string `json:"name"`
+
+	// Roles the policy applies to. Defaults to all roles
+	Roles []string `json:"roles,omitempty"`
+
+	// Name of the table
+	Table string `json:"table"`
+
+	// Whether the policy is combined with other policies using OR (permissive) or AND
+	// (restrictive)
+	Type OpCreatePolicyType `json:"type,omitempty"`
+
+	// Expression that existing rows must satisfy to be visible, referring to columns
+	// by the names they have in the new version of the schema
+	Using *string `json:"using,omitempty"`
+
+	// Expression that new and updated rows must satisfy, referring to columns by the
+	// names they have in the new version of the schema
+	WithCheck *string `json:"with_check,omitempty"`
+}
+
+type OpCreatePolicyCommand string
+
+const OpCreatePolicyCommandAll OpCreatePolicyCommand = "all"
+const OpCreatePolicyCommandDelete OpCreatePolicyCommand = "delete"
+const OpCreatePolicyCommandInsert OpCreatePolicyCommand = "insert"
+const OpCreatePolicyCommandSelect OpCreatePolicyCommand = "select"
+const OpCreatePolicyCommandUpdate OpCreatePolicyCommand = "update"
+
+type OpCreatePolicyType string
+
+const OpCreatePolicyTypePermissive OpCreatePolicyType = "permissive"
+const OpCreatePolicyTypeRestrictive OpCreatePolicyType = "restrictive"
+
+// Create sequence operation
+type OpCreateSequence struct {
+	// Number of values of the sequence to preallocate
+	Cache *int `json:"cache,omitempty"`
+
+	// Whether the sequence wraps around when it reaches its maximum or minimum value
+	Cycle bool `json:"cycle,omitempty"`
+
+	// Value added to the current value of the sequence to create a new value
+	Increment *int `json:"increment,omitempty"`
+
+	// Maximum value of the sequence
+	MaxValue *int `json:"max_value,omitempty"`
+
+	// Minimum value of the sequence
+	MinValue *int `json:"min_value,omitempty"`
+
+	// Name of the sequence
+	Name string `json:"name"`
+
+	// First value of the sequence
+	StartValue *int `json:"start_value,omitempty"`
+
+	// Data type of the sequence
+	Type OpCreateSequenceType `json:"type,omitempty"`
+}
+
+type OpCreateSequenceType string
+
+const OpCreateSequenceTypeBigint OpCreateSequenceType = "bigint"
+const OpCreateSequenceTypeInteger OpCreateSequenceType = "integer"
+const OpCreateSequenceTypeSmallint OpCreateSequenceType = "smallint"
+
 // Create table operation
 type OpCreateTable struct {
 	// Columns corresponds to the JSON schema field "columns".
@@ -371,6 +577,28 @@ type OpCreateTable struct {
 
 	// Name of the table
 	Name string `json:"name"`
+
+	// Partition the table by the given strategy and columns
+	PartitionBy *PartitionBy `json:"partition_by,omitempty"`
+}
+
+// Create view operation
+type OpCreateView struct {
+	// SELECT statement that defines the view, referring to tables and columns by the
+	// names they have in the new version of the schema
+	Definition string `json:"definition"`
+
+	// Name of the view
+	Name string `json:"name"`
+}
+
+// Detach partition operation
+type OpDetachPartition struct {
+	// Name of the partition
+	Partition string `json:"partition"`
+
+	// Name of the partitioned table
+	Table string `json:"table"`
 }
 
 // Drop column operation
@@ -400,6 +628,12 @@ type OpDropConstraint struct {
 	Up string `json:"up"`
 }
 
+// Drop enum type operation
+type OpDropEnum struct {
+	// Name of the enum type
+	Name string `json:"name"`
+}
+
 // Drop index operation
 type OpDropIndex struct {
 	// Index name
@@ -421,12 +655,78 @@ type OpDropMultiColumnConstraint struct {
 	Up MultiColumnUpSQL `json:"up,omitempty"`
 }
 
+// Drop row level security policy operation
+type OpDropPolicy struct {
+	// Name of the policy
+	Name string `json:"name"`
+
+	// Name of the table
+	Table string `json:"table"`
+}
+
+// Drop sequence operation
+type OpDropSequence struct {
+	// Name of the sequence
+	Name string `json:"name"`
+}
+
 // Drop table operation
 type OpDropTable struct {
 	// Name of the table
 	Name string `json:"name"`
 }
 
+// Drop view operation
+type OpDropView struct {
+	// Name of the view
+	Name string `json:"name"`
+}
+
+// Enable row level security operation
+type OpEnableRLS struct {
+	// Apply row level security to the table owner as well
+	Force bool `json:"force,omitempty"`
+
+	// Name of the table
+	Table string `json:"table"`
+}
+
+// Grant privileges operation
+type OpGrant struct {
+	// Privileges to grant on the table
+	Privileges []TablePrivilege `json:"privileges"`
+
+	// Roles to grant the privileges to
+	Roles []string `json:"roles"`
+
+	// Name of the table
+	Table string `json:"table"`
+
+	// Whether the roles can grant the privileges to other roles
+	WithGrantOption bool `json:"with_grant_option,omitempty"`
+}
+
+// Move table operation
+type OpMoveTable struct {
+	// Name of the table
+	Table string `json:"table"`
+
+	// Schema to move the table to
+	ToSchema string `json:"to_schema"`
+}
+
+// Partition table operation
+type OpPartitionTable struct {
+	// Partitioning of the table
+	PartitionBy PartitionBy `json:"partition_by"`
+
+	// Partitions to create for the partitioned table
+	Partitions []Partition `json:"partitions"`
+
+	// Name of the table
+	Table string `json:"table"`
+}
+
 // Raw SQL operation
 type OpRawSQL struct {
 	// SQL expression for down migration
@@ -472,6 +772,62 @@ type OpRenameTable struct {
 	To string `json:"to"`
 }
 
+// Replace view operation
+type OpReplaceView struct {
+	// SELECT statement that defines the view, referring to tables and columns by the
+	// names they have in the new version of the schema
+	Definition string `json:"definition"`
+
+	// Name of the view
+	Name string `json:"name"`
+}
+
+// Revoke privileges operation
+type OpRevoke struct {
+	// Privileges to revoke on the table
+	Privileges []TablePrivilege `json:"privileges"`
+
+	// Roles to revoke the privileges from
+	Roles []string `json:"roles"`
+
+	// Name of the table
+	Table string `json:"table"`
+}
+
+// Rewrite table operation
+type OpRewriteTable struct {
+	// Storage parameters to set on the rewritten table, for example 'fillfactor=70'.
+	// Storage parameters that are not set keep their current values
+	StorageParameters string `json:"storage_parameters,omitempty"`
+
+	// Name of the table
+	Table string `json:"table"`
+
+	// Tablespace to move the table to. Defaults to the current tablespace of the
+	// table
+	Tablespace string `json:"tablespace,omitempty"`
+}
+
+// Set identity operation
+type OpSetIdentity struct {
+	// Name of the column
+	Column string `json:"column"`
+
+	// How the column generates its values: as an identity column whose values are
+	// always generated, as an identity column whose values are generated by default,
+	// or from a sequence owned by the column, like a serial column
+	Identity OpSetIdentityIdentity `json:"identity"`
+
+	// Name of the table
+	Table string `json:"table"`
+}
+
+type OpSetIdentityIdentity string
+
+const OpSetIdentityIdentityAlways OpSetIdentityIdentity = "always"
+const OpSetIdentityIdentityByDefault OpSetIdentityIdentity = "by_default"
+const OpSetIdentityIdentityNone OpSetIdentityIdentity = "none"
+
 // Set replica identity operation
 type OpSetReplicaIdentity struct {
 	// Replica identity to set
@@ -481,14 +837,49 @@ type OpSetReplicaIdentity struct {
 	Table string `json:"table"`
 }
 
+// Schema targeted by the operation. Defaults to the schema that the migration is
+// run against
+type OperationSchema string
+
+// Partition of a partitioned table
+type Partition struct {
+	// Partition bound, for example `FOR VALUES FROM (1) TO (10)` or `DEFAULT`
+	Bound string `json:"bound"`
+
+	// Name of the partition
+	Name string `json:"name"`
+}
+
+// Partitioning of a table
+type PartitionBy struct {
+	// Columns that make up the partition key
+	Columns []string `json:"columns"`
+
+	// Partitioning strategy
+	Strategy PartitionByStrategy `json:"strategy"`
+}
+
+type PartitionByStrategy string
+
+const PartitionByStrategyHash PartitionByStrategy = "hash"
+const PartitionByStrategyList PartitionByStrategy = "list"
+const PartitionByStrategyRange PartitionByStrategy = "range"
+
 // PgRoll migration definition
 type PgRollMigration struct {
+	// Backfill corresponds to the JSON schema field "backfill".
+	Backfill *BackfillSettings `json:"backfill,omitempty"`
+
 	// Name of the migration
 	Name *string `json:"name,omitempty"`
 
 	// Operations corresponds to the JSON schema field "operations".
 	Operations PgRollOperations `json:"operations"`
 
+	// Names of the migrations that this migration replaces, in order. Set by `pgroll
+	// squash`
+	Squashes []string `json:"squashes,omitempty"`
+
 	// Name of the version schema to use for this migration
 	VersionSchema *string `json:"version_schema,omitempty"`
 }
@@ -523,10 +914,25 @@ type TableForeignKeyReference struct {
 	// On update behavior of the foreign key constraint
 	OnUpdate ForeignKeyAction `json:"on_update,omitempty"`
 
+	// Schema of the referenced table. Defaults to the schema of the table with the
+	// foreign key
+	Schema string `json:"schema,omitempty"`
+
 	// Name of the table
 	Table string `json:"table"`
 }
 
+type TablePrivilege string
+
+const TablePrivilegeAll TablePrivilege = "all"
+const TablePrivilegeDelete TablePrivilege = "delete"
+const TablePrivilegeInsert TablePrivilege = "insert"
+const TablePrivilegeReferences TablePrivilege = "references"
+const TablePrivilegeSelect TablePrivilege = "select"
+const TablePrivilegeTrigger TablePrivilege = "trigger"
+const TablePrivilegeTruncate TablePrivilege = "truncate"
+const TablePrivilegeUpdate TablePrivilege = "update"
+
 // Unique constraint definition
 type UniqueConstraint struct {
 	// Name of unique constraint
@@ -0,0 +1,443 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+
+	pgq "github.com/xataio/pg_query_go/v6"
+
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+// viewRefs are the relations and columns that a view definition refers to
+type viewRefs struct {
+	// The tables and views in the view's schema that the definition reads from
+	relations []string
+
+	// The relation that each name or alias in the definition refers to, or ""
+	// if the columns of the relation it refers to are not known
+	aliases map[string]string
+
+	// Column references qualified by a relation name or alias
+	qualified map[string][]string
+
+	// Column references that are not qualified
+	unqualified []string
+
+	// The names given to output columns in the definition
+	outputNames []string
+
+	// Whether the definition reads from subqueries, functions, common table
+	// expressions or relations in other schemas, whose columns are not known
+	opaque bool
+}
+
+// parseViewDefinition parses the SELECT statement that defines a view in
+// `schemaName` and returns the relations and columns it refers to.
+func parseViewDefinition(definition, schemaName string) (*viewRefs, error) {
+	tree, err := pgq.ParseToJSON(definition)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed struct {
+		Stmts []struct {
+			Stmt map[string]any `json:"stmt"`
+		} `json:"stmts"`
+	}
+	if err := json.Unmarshal([]byte(tree), &parsed); err != nil {
+		return nil, err
+	}
+	if len(parsed.Stmts) != 1 || parsed.Stmts[0].Stmt["SelectStmt"] == nil {
+		return nil, errors.New("the definition must be a single SELECT statement")
+	}
+
+	refs := &viewRefs{
+		aliases:   make(map[string]string),
+		qualified: make(map[string][]string),
+	}
+	ctes := make(map[string]bool)
+	refs.walk(parsed.Stmts[0].Stmt, schemaName, ctes)
+
+	// Common table expressions are not relations in the schema
+	refs.relations = slices.DeleteFunc(refs.relations, func(r string) bool { return ctes[r] })
+	for alias, relation := range refs.aliases {
+		if ctes[relation] {
+			refs.aliases[alias] = ""
+		}
+	}
+
+	return refs, nil
+}
+
+// walk records the relations and columns referred to by `node`, a node of the
+// JSON representation of a parse tree, and its children.
+func (r *viewRefs) walk(node any, schemaName string, ctes map[string]bool) {
+	switch n := node.(type) {
+	case []any:
+		for _, child := range n {
+			r.walk(child, schemaName, ctes)
+		}
+	case map[string]any:
+		for key, value := range n {
+			child, _ := value.(map[string]any)
+			switch key {
+			case "RangeVar":
+				r.addRangeVar(child, schemaName)
+			case "RangeSubselect", "RangeFunction", "RangeTableFunc", "JsonTable":
+				r.opaque = true
+				if alias := aliasName(child["alias"]); alias != "" {
+					r.aliases[alias] = ""
+				}
+			case "JoinExpr":
+				for _, field := range []string{"alias", "join_using_alias"} {
+					if alias := aliasName(child[field]); alias != "" {
+						r.aliases[alias] = ""
+					}
+				}
+			case "CommonTableExpr":
+				r.opaque = true
+				if name, ok := child["ctename"].(string); ok {
+					ctes[name] = true
+				}
+			case "ColumnRef":
+				r.addColumnRef(child)
+			case "ResTarget":
+				if name, ok := child["name"].(string); ok {
+					r.outputNames = append(r.outputNames, name)
+				}
+			}
+			r.walk(value, schemaName, ctes)
+		}
+	}
+}
+
+func (r *viewRefs) addRangeVar(rv map[string]any, schemaName string) {
+	relation, _ := rv["relname"].(string)
+	alias := aliasName(rv["alias"])
+	if alias == "" {
+		alias = relation
+	}
+
+	if s, _ := rv["schemaname"].(string); s != "" && s != schemaName {
+		r.opaque = true
+		r.aliases[alias] = ""
+		return
+	}
+
+	if !slices.Contains(r.relations, relation) {
+		r.relations = append(r.relations, relation)
+	}
+	// An alias that is used for different relations in different parts of the
+	// definition is ambiguous
+	if existing, ok := r.aliases[alias]; ok && existing != relation {
+		r.aliases[alias] = ""
+	} else {
+		r.aliases[alias] = relation
+	}
+}
+
+func (r *viewRefs) addColumnRef(ref map[string]any) {
+	fields, _ := ref["fields"].([]any)
+
+	var names []string
+	for _, field := range fields {
+		f, _ := field.(map[string]any)
+		if _, ok := f["A_Star"]; ok {
+			return
+		}
+		s, _ := f["String"].(map[string]any)
+		name, _ := s["sval"].(string)
+		names = append(names, name)
+	}
+
+	switch len(names) {
+	case 0:
+	case 1:
+		r.unqualified = append(r.unqualified, names[0])
+	default:
+		qualifier := names[len(names)-2]
+		r.qualified[qualifier] = append(r.qualified[qualifier], names[len(names)-1])
+	}
+}
+
+func aliasName(alias any) string {
+	a, _ := alias.(map[string]any)
+	name, _ := a["aliasname"].(string)
+	return name
+}
+
+// validateView returns an error if the definition of the view `name` can't be
+// parsed or reads from a table, view or column that does not exist in `s`.
+//
+// Column references are only checked where the relation they refer to is
+// known, so a definition that passes validation may still fail to create.
+func validateView(s *schema.Schema, name, definition string) error {
+	refs, err := parseViewDefinition(definition, s.Name)
+	if err != nil {
+		return InvalidViewDefinitionError{Name: name, Reason: err.Error()}
+	}
+
+	for _, relation := range refs.relations {
+		if s.GetTable(relation) == nil && s.GetView(relation) == nil {
+			return ViewDependencyMissingError{View: name, Relation: relation}
+		}
+	}
+
+	for _, qualifier := range slices.Sorted(maps.Keys(refs.qualified)) {
+		relation := refs.aliases[qualifier]
+		table := s.GetTable(relation)
+		if table == nil {
+			continue
+		}
+		for _, column := range refs.qualified[qualifier] {
+			if table.GetColumn(column) == nil {
+				return ViewDependencyMissingError{View: name, Relation: relation, Column: column}
+			}
+		}
+	}
+
+	// Unqualified column references can only be checked if the definition
+	// reads only from tables
+	if refs.opaque {
+		return nil
+	}
+	var tables []*schema.Table
+	for _, relation := range refs.relations {
+		table := s.GetTable(relation)
+		if table == nil {
+			return nil
+		}
+		tables = append(tables, table)
+	}
+	for _, column := range refs.unqualified {
+		if slices.Contains(refs.outputNames, column) {
+			continue
+		}
+		if _, ok := refs.aliases[column]; ok {
+			continue
+		}
+		found := slices.ContainsFunc(tables, func(t *schema.Table) bool { return t.GetColumn(column) != nil })
+		if !found {
+			err := ViewDependencyMissingError{View: name, Column: column}
+			if len(refs.relations) == 1 {
+				err.Relation = refs.relations[0]
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// viewDependencies are the tables, views and columns in a schema that a view
+// reads from
+type viewDependencies struct {
+	// The definition of the view
+	definition string
+
+	// The tables and views that the view reads from
+	relations []string
+
+	// The columns that the view refers to, keyed by table
+	columns map[string][]string
+}
+
+// existingViewDependencies returns the dependencies of each view in `s`.
+//
+// Only references that resolve in `s` are included. References to relations
+// that the schema doesn't model, such as materialized views, foreign tables,
+// partitions or catalog views, are left out, as are the references of views
+// whose definitions can't be parsed.
+func existingViewDependencies(s *schema.Schema) map[string]*viewDependencies {
+	deps := make(map[string]*viewDependencies, len(s.Views))
+	for name, v := range s.Views {
+		refs, err := parseViewDefinition(v.Definition, s.Name)
+		if err != nil {
+			continue
+		}
+
+		d := &viewDependencies{definition: v.Definition, columns: make(map[string][]string)}
+		addColumn := func(relation, column string) {
+			if !slices.Contains(d.columns[relation], column) {
+				d.columns[relation] = append(d.columns[relation], column)
+			}
+		}
+
+		for _, relation := range refs.relations {
+			if s.GetTable(relation) != nil || s.GetView(relation) != nil {
+				d.relations = append(d.relations, relation)
+			}
+		}
+		for qualifier, columns := range refs.qualified {
+			relation := refs.aliases[qualifier]
+			table := s.GetTable(relation)
+			if table == nil {
+				continue
+			}
+			for _, column := range columns {
+				if table.GetColumn(column) != nil {
+					addColumn(relation, column)
+				}
+			}
+		}
+		if !refs.opaque {
+			for _, column := range refs.unqualified {
+				for _, relation := range refs.relations {
+					if table := s.GetTable(relation); table != nil && table.GetColumn(column) != nil {
+						addColumn(relation, column)
+						break
+					}
+				}
+			}
+		}
+
+		deps[name] = d
+	}
+	return deps
+}
+
+// validateViews returns an error if a view in `s` no longer has one of the
+// dependencies in `deps`, which were found in the schema before it was
+// changed by a migration. Views that the migration drops or replaces are not
+// checked, as the operations that drop or replace them validate them.
+func validateViews(s *schema.Schema, deps map[string]*viewDependencies) error {
+	for _, name := range slices.Sorted(maps.Keys(deps)) {
+		v := s.GetView(name)
+		d := deps[name]
+		if v == nil || v.Definition != d.definition {
+			continue
+		}
+
+		for _, relation := range d.relations {
+			if s.GetTable(relation) == nil && s.GetView(relation) == nil {
+				return ViewDependencyMissingError{View: name, Relation: relation}
+			}
+		}
+		for _, relation := range slices.Sorted(maps.Keys(d.columns)) {
+			table := s.GetTable(relation)
+			if table == nil {
+				continue
+			}
+			for _, column := range d.columns[relation] {
+				if table.GetColumn(column) == nil {
+					return ViewDependencyMissingError{View: name, Relation: relation, Column: column}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// viewRelations returns the tables and views in `s` that the view `v` reads
+// from
+func viewRelations(s *schema.Schema, v *schema.View) []string {
+	refs, err := parseViewDefinition(v.Definition, s.Name)
+	if err != nil {
+		return nil
+	}
+	return refs.relations
+}
+
+// SortedViews returns the names of the views in `s` in an order in which they
+// can be created, with each view after the views that it reads from.
+func SortedViews(s *schema.Schema) []string {
+	sorted := make([]string, 0, len(s.Views))
+	visited := make(map[string]bool, len(s.Views))
+
+	var visit func(name string)
+	visit = func(name string) {
+		v := s.GetView(name)
+		if v == nil || visited[name] {
+			return
+		}
+		visited[name] = true
+		for _, relation := range viewRelations(s, v) {
+			visit(relation)
+		}
+		sorted = append(sorted, name)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(s.Views)) {
+		visit(name)
+	}
+	return sorted
+}
+
+// ViewsToRebuild compares the views in `current`, the schema as it is in the
+// database before a migration completes, with the views in `virtual`, the
+// virtual schema once the migration has started. It returns the views to
+// drop from the database before the migration's operations complete, in the
+// order in which to drop them, and the views to create once they have
+// completed, in the order in which to create them.
+//
+// Views are rebuilt if the migration creates, replaces or drops them, or if
+// they read from a table whose columns the migration changes, as completing
+// those changes may drop or rename the columns that the views depend on.
+func ViewsToRebuild(current, virtual *schema.Schema) (drop, create []string) {
+	rebuild := make(map[string]bool)
+	for name, v := range current.Views {
+		want := virtual.GetView(name)
+		if want == nil || want.Definition != v.Definition {
+			rebuild[name] = true
+			continue
+		}
+		for _, relation := range viewRelations(current, v) {
+			if current.GetTable(relation) != nil && tableChanged(virtual, relation) {
+				rebuild[name] = true
+				break
+			}
+		}
+	}
+
+	// Views that read from a rebuilt view must be rebuilt with it
+	for changed := true; changed; {
+		changed = false
+		for name, v := range current.Views {
+			if rebuild[name] {
+				continue
+			}
+			if slices.ContainsFunc(viewRelations(current, v), func(r string) bool { return rebuild[r] }) {
+				rebuild[name] = true
+				changed = true
+			}
+		}
+	}
+
+	for _, name := range slices.Backward(SortedViews(current)) {
+		if rebuild[name] {
+			drop = append(drop, name)
+		}
+	}
+	for _, name := range SortedViews(virtual) {
+		if rebuild[name] || current.GetView(name) == nil {
+			create = append(create, name)
+		}
+	}
+	return drop, create
+}
+
+// tableChanged returns true if the table whose physical name is `name` is
+// dropped or renamed in the virtual schema `s`, or has columns that are
+// dropped, renamed or replaced.
+func tableChanged(s *schema.Schema, name string) bool {
+	for key, table := range s.Tables {
+		if table.Name != name {
+			continue
+		}
+		if table.Deleted || key != table.Name {
+			return true
+		}
+		for columnName, column := range table.Columns {
+			if column.Deleted || columnName != column.Name {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
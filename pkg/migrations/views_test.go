@@ -0,0 +1,224 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+func viewTestSchema() *schema.Schema {
+	return &schema.Schema{
+		Name: "public",
+		Tables: map[string]*schema.Table{
+			"users": {
+				Name: "users",
+				Columns: map[string]*schema.Column{
+					"id":   {Name: "id"},
+					"name": {Name: "name"},
+				},
+			},
+			"orders": {
+				Name: "orders",
+				Columns: map[string]*schema.Column{
+					"id":      {Name: "id"},
+					"user_id": {Name: "user_id"},
+					"total":   {Name: "total"},
+				},
+			},
+		},
+		Views: map[string]*schema.View{
+			"user_totals": {
+				Name:       "user_totals",
+				Definition: "SELECT u.name, sum(o.total) AS total FROM users u JOIN orders o ON o.user_id = u.id GROUP BY u.name",
+			},
+			"big_spenders": {
+				Name:       "big_spenders",
+				Definition: "SELECT name FROM user_totals WHERE total > 100",
+			},
+		},
+	}
+}
+
+func TestValidateView(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		definition string
+		wantErr    error
+	}{
+		{
+			name:       "qualified columns",
+			definition: "SELECT u.id, users.name FROM users u, users",
+		},
+		{
+			name:       "unqualified columns and output names",
+			definition: "SELECT name AS n, total FROM users JOIN orders ON orders.user_id = users.id ORDER BY n",
+		},
+		{
+			name:       "reads from a view",
+			definition: "SELECT name, anything FROM big_spenders",
+		},
+		{
+			name:       "reads from a common table expression",
+			definition: "WITH t AS (SELECT id AS x FROM users) SELECT x FROM t",
+		},
+		{
+			name:       "reads from another schema",
+			definition: "SELECT anything FROM other.users",
+		},
+		{
+			name:       "unknown table",
+			definition: "SELECT id FROM accounts",
+			wantErr:    ViewDependencyMissingError{View: "v", Relation: "accounts"},
+		},
+		{
+			name:       "unknown qualified column",
+			definition: "SELECT u.email FROM users u",
+			wantErr:    ViewDependencyMissingError{View: "v", Relation: "users", Column: "email"},
+		},
+		{
+			name:       "unknown unqualified column",
+			definition: "SELECT email FROM users",
+			wantErr:    ViewDependencyMissingError{View: "v", Relation: "users", Column: "email"},
+		},
+		{
+			name:       "not a SELECT statement",
+			definition: "DELETE FROM users",
+			wantErr:    InvalidViewDefinitionError{Name: "v", Reason: "the definition must be a single SELECT statement"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateView(viewTestSchema(), "v", tt.definition)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestValidateViews(t *testing.T) {
+	t.Parallel()
+
+	t.Run("dropped column", func(t *testing.T) {
+		s := viewTestSchema()
+		deps := existingViewDependencies(s)
+		s.Tables["orders"].RemoveColumn("total")
+
+		err := validateViews(s, deps)
+		assert.Equal(t, ViewDependencyMissingError{View: "user_totals", Relation: "orders", Column: "total"}, err)
+	})
+
+	t.Run("renamed table", func(t *testing.T) {
+		s := viewTestSchema()
+		deps := existingViewDependencies(s)
+		assert.NoError(t, s.RenameTable("users", "customers"))
+
+		err := validateViews(s, deps)
+		assert.Equal(t, ViewDependencyMissingError{View: "user_totals", Relation: "users"}, err)
+	})
+
+	t.Run("dropped view", func(t *testing.T) {
+		s := viewTestSchema()
+		deps := existingViewDependencies(s)
+		s.RemoveView("user_totals")
+
+		err := validateViews(s, deps)
+		assert.Equal(t, ViewDependencyMissingError{View: "big_spenders", Relation: "user_totals"}, err)
+	})
+
+	t.Run("relations the schema doesn't model are not checked", func(t *testing.T) {
+		s := viewTestSchema()
+		s.AddView(&schema.View{Name: "sessions", Definition: "SELECT pid, usename FROM pg_stat_activity"})
+		s.AddView(&schema.View{Name: "report", Definition: "SELECT r.day, u.name FROM daily_totals r JOIN users u ON u.id = r.user_id"})
+		deps := existingViewDependencies(s)
+		s.AddTable("accounts", &schema.Table{Name: "accounts"})
+
+		assert.NoError(t, validateViews(s, deps))
+	})
+
+	t.Run("replaced views are not checked", func(t *testing.T) {
+		s := viewTestSchema()
+		deps := existingViewDependencies(s)
+		s.Tables["orders"].RemoveColumn("total")
+		s.AddView(&schema.View{Name: "user_totals", Definition: "SELECT users.id FROM users"})
+
+		assert.NoError(t, validateViews(s, deps))
+	})
+}
+
+func TestSortedViews(t *testing.T) {
+	t.Parallel()
+
+	s := viewTestSchema()
+	s.AddView(&schema.View{Name: "a_view", Definition: "SELECT * FROM big_spenders"})
+
+	assert.Equal(t, []string{"user_totals", "big_spenders", "a_view"}, SortedViews(s))
+}
+
+func TestViewsToRebuild(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		change     func(s *schema.Schema)
+		wantDrop   []string
+		wantCreate []string
+	}{
+		{
+			name:   "no changes",
+			change: func(s *schema.Schema) {},
+		},
+		{
+			name: "new view",
+			change: func(s *schema.Schema) {
+				s.AddView(&schema.View{Name: "names", Definition: "SELECT name FROM users"})
+			},
+			wantCreate: []string{"names"},
+		},
+		{
+			name: "dropped view",
+			change: func(s *schema.Schema) {
+				s.RemoveView("big_spenders")
+			},
+			wantDrop: []string{"big_spenders"},
+		},
+		{
+			name: "replaced view is rebuilt with its dependent views",
+			change: func(s *schema.Schema) {
+				s.AddView(&schema.View{Name: "user_totals", Definition: "SELECT u.name, 0 AS total FROM users u"})
+			},
+			wantDrop:   []string{"big_spenders", "user_totals"},
+			wantCreate: []string{"user_totals", "big_spenders"},
+		},
+		{
+			name: "changed column in a table that a view reads from",
+			change: func(s *schema.Schema) {
+				s.Tables["orders"].Columns["total"].Name = TemporaryName("total")
+			},
+			wantDrop:   []string{"big_spenders", "user_totals"},
+			wantCreate: []string{"user_totals", "big_spenders"},
+		},
+		{
+			name: "new table",
+			change: func(s *schema.Schema) {
+				s.AddTable("products", &schema.Table{Name: "products"})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			virtual := viewTestSchema()
+			tt.change(virtual)
+
+			drop, create := ViewsToRebuild(viewTestSchema(), virtual)
+			assert.Equal(t, tt.wantDrop, drop)
+			assert.Equal(t, tt.wantCreate, create)
+		})
+	}
+}
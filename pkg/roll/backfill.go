@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package roll
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/schema"
+	"github.com/xataio/pgroll/pkg/telemetry"
+)
+
+// Backfill backfills all tables modified by the active migration, starting
+// from the beginning of each table.
+func (m *Roll) Backfill(ctx context.Context, cfg *backfill.Config) error {
+	migration, err := m.state.GetActiveMigration(ctx, m.schema)
+	if err != nil {
+		return fmt.Errorf("unable to get active migration: %w", err)
+	}
+
+	for _, schemaName := range migration.Schemas(m.schema) {
+		if err := m.state.ClearBackfillProgress(ctx, schemaName, migration.Name); err != nil {
+			return fmt.Errorf("unable to clear backfill progress: %w", err)
+		}
+	}
+
+	return m.ResumeBackfill(ctx, cfg)
+}
+
+// ResumeBackfill continues the backfill of the active migration from the last
+// batch committed for each table. Tables that have already been fully
+// backfilled are skipped.
+func (m *Roll) ResumeBackfill(ctx context.Context, cfg *backfill.Config) error {
+	migration, err := m.state.GetActiveMigration(ctx, m.schema)
+	if err != nil {
+		return fmt.Errorf("unable to get active migration: %w", err)
+	}
+
+	// A migration that targets several schemas is backfilled one schema at a
+	// time
+	rolls, err := m.rollsForMigration(ctx, migration)
+	if err != nil {
+		return err
+	}
+	for _, schemaName := range migration.Schemas(m.schema) {
+		r := rolls[schemaName]
+
+		job, err := r.replayBackfillJob(ctx, migration)
+		if err != nil {
+			return err
+		}
+		if err := r.performBackfills(ctx, migration, job, cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replayBackfillJob rebuilds the backfill job for the operations in
+// `migration` that target the Roll's schema.
+func (m *Roll) replayBackfillJob(ctx context.Context, migration *migrations.Migration) (*backfill.Job, error) {
+	// get the schema after the previous migration was applied
+	previousMigration, err := m.state.PreviousMigration(ctx, m.schema)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get name of previous version: %w", err)
+	}
+	s := schema.New()
+	if previousMigration != nil {
+		s, err = m.state.SchemaAfterMigration(ctx, m.schema, *previousMigration)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read schema: %w", err)
+		}
+	}
+
+	// Rebuild the backfill job by replaying the migration's operations against
+	// the in-memory schema. No changes are made to the physical database.
+	versionSchemaName := VersionedSchemaName(m.schema, migration.VersionSchemaName())
+	job := backfill.NewJob(m.schema, versionSchemaName)
+	for i, op := range migration.Operations {
+		if migration.OperationSchema(i, m.schema) != m.schema {
+			continue
+		}
+		startOp, err := op.Start(ctx, m.logger, &db.FakeDB{}, s)
+		if err != nil {
+			return nil, fmt.Errorf("unable to replay operation: %w", err)
+		}
+		if startOp != nil && startOp.BackfillTask != nil {
+			job.AddTask(startOp.BackfillTask, migration.OperationBackfillOptions(i)...)
+		}
+	}
+
+	return job, nil
+}
+
+// backfillTable backfills all rows in `table` using `bf`.
+func (m *Roll) backfillTable(ctx context.Context, bf *backfill.Backfill, table *schema.Table) error {
+	m.logger.LogBackfillStart(table.Name)
+
+	ctx, span := telemetry.StartSpan(ctx, "pgroll.backfill", telemetry.TableKey.String(table.Name))
+	err := bf.Start(ctx, table)
+	telemetry.EndSpan(span, err)
+	if err != nil {
+		return fmt.Errorf("unable to backfill table %q: %w", table.Name, err)
+	}
+
+	m.logger.LogBackfillComplete(table.Name)
+
+	return nil
+}
+
+// backfillTablesInParallel backfills `tables` using up to
+// `cfg.Parallelism()` concurrent workers, each using its own connection. The
+// first error encountered by any worker cancels the remaining workers and is
+// returned.
+func (m *Roll) backfillTablesInParallel(ctx context.Context, tables []*schema.Table, cfg *backfill.Config, checkpointer backfill.Checkpointer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	queue := make(chan *schema.Table)
+	for range min(cfg.Parallelism(), len(tables)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := m.backfillWorker(ctx, queue, cfg, checkpointer); err != nil {
+				fail(err)
+			}
+		}()
+	}
+
+dispatch:
+	for _, table := range tables {
+		select {
+		case queue <- table:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if firstErr == nil {
+		// The parent context may have been cancelled while dispatching
+		return ctx.Err()
+	}
+	return firstErr
+}
+
+// backfillWorker backfills the tables received on `queue` using a dedicated
+// connection to the database.
+func (m *Roll) backfillWorker(ctx context.Context, queue <-chan *schema.Table, cfg *backfill.Config, checkpointer backfill.Checkpointer) error {
+	conn, err := m.backfillConn(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to open backfill connection: %w", err)
+	}
+	defer conn.Close()
+
+	bf := backfill.New(conn, cfg)
+	bf.SetCheckpointer(checkpointer)
+
+	for table := range queue {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := m.backfillTable(ctx, bf, table); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backfillConn opens a new connection to the database, configured in the same
+// way as the Roll's own connection. The connection pool is limited to a single
+// connection so that session settings such as the lock timeout and role apply
+// to every statement.
+func (m *Roll) backfillConn(ctx context.Context) (db.DB, error) {
+	conn, err := openConn(m.pgURL, m.schema, m.connOpts)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetMaxOpenConns(1)
+
+	if err := configureConn(ctx, conn, m.connOpts); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &db.RDB{DB: conn}, nil
+}
@@ -0,0 +1,286 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package roll_test
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xataio/pgroll/internal/testutils"
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/roll"
+)
+
+func TestResumeBackfill(t *testing.T) {
+	t.Parallel()
+
+	testutils.WithMigratorAndConnectionToContainer(t, func(mig *roll.Roll, db *sql.DB) {
+		ctx := context.Background()
+
+		// Create a table and insert some rows
+		err := mig.Start(ctx, &migrations.Migration{
+			Name:       "01_create_table",
+			Operations: migrations.Operations{createTableOp("table1")},
+		}, backfill.NewConfig())
+		require.NoError(t, err)
+		err = mig.Complete(ctx)
+		require.NoError(t, err)
+
+		_, err = db.ExecContext(ctx, "INSERT INTO table1 (id, name) VALUES (1, 'alice'), (2, 'bob'), (3, 'carol')")
+		require.NoError(t, err)
+
+		// Start a migration that requires a backfill
+		err = mig.Start(ctx, &migrations.Migration{
+			Name: "02_add_column",
+			Operations: migrations.Operations{
+				&migrations.OpAddColumn{
+					Table:  "table1",
+					Column: migrations.Column{Name: "age", Type: "integer", Nullable: true},
+					Up:     "18",
+				},
+			},
+		}, backfill.NewConfig(backfill.WithBatchSize(1)))
+		require.NoError(t, err)
+
+		// The backfill of the table is recorded as done
+		assert.True(t, backfillDone(t, db, "table1"))
+
+		// Simulate an interrupted backfill: reset the new column for the rows
+		// after the first one and rewind the checkpoint to the first row
+		_, err = db.ExecContext(ctx, `UPDATE table1 SET "_pgroll_new_age" = NULL WHERE id > 1`)
+		require.NoError(t, err)
+		_, err = db.ExecContext(ctx, `UPDATE pgroll.backfill_progress SET last_value = '{1}', done = false WHERE table_name = 'table1'`)
+		require.NoError(t, err)
+
+		// Resuming the backfill updates only the rows after the checkpoint
+		err = mig.ResumeBackfill(ctx, backfill.NewConfig(backfill.WithBatchSize(1)))
+		require.NoError(t, err)
+
+		var backfilled int
+		err = db.QueryRowContext(ctx, `SELECT count(*) FROM table1 WHERE "_pgroll_new_age" = 18`).Scan(&backfilled)
+		require.NoError(t, err)
+		assert.Equal(t, 3, backfilled)
+		assert.True(t, backfillDone(t, db, "table1"))
+
+		// Resuming a finished backfill is a no-op
+		err = mig.ResumeBackfill(ctx, backfill.NewConfig())
+		require.NoError(t, err)
+
+		// Rolling back the migration removes its recorded progress
+		err = mig.Rollback(ctx)
+		require.NoError(t, err)
+
+		var rows int
+		err = db.QueryRowContext(ctx, "SELECT count(*) FROM pgroll.backfill_progress").Scan(&rows)
+		require.NoError(t, err)
+		assert.Equal(t, 0, rows)
+	})
+}
+
+func backfillDone(t *testing.T, db *sql.DB, table string) bool {
+	t.Helper()
+
+	var done bool
+	err := db.QueryRowContext(context.Background(),
+		"SELECT done FROM pgroll.backfill_progress WHERE table_name = $1", table).Scan(&done)
+	require.NoError(t, err)
+	return done
+}
+
+func TestParallelBackfill(t *testing.T) {
+	t.Parallel()
+
+	testutils.WithMigratorAndConnectionToContainer(t, func(mig *roll.Roll, db *sql.DB) {
+		ctx := context.Background()
+
+		// Create two tables and insert some rows into each
+		err := mig.Start(ctx, &migrations.Migration{
+			Name:       "01_create_tables",
+			Operations: migrations.Operations{createTableOp("table1"), createTableOp("table2")},
+		}, backfill.NewConfig())
+		require.NoError(t, err)
+		err = mig.Complete(ctx)
+		require.NoError(t, err)
+
+		for _, table := range []string{"table1", "table2"} {
+			_, err = db.ExecContext(ctx, "INSERT INTO "+table+" (id, name) VALUES (1, 'alice'), (2, 'bob'), (3, 'carol')")
+			require.NoError(t, err)
+		}
+
+		// Record the tables reported by the progress callbacks
+		var mu sync.Mutex
+		reported := make(map[string]bool)
+		cfg := backfill.NewConfig(backfill.WithBatchSize(1), backfill.WithParallelism(2))
+		cfg.AddCallback(func(table string, n, total int64) {
+			mu.Lock()
+			defer mu.Unlock()
+			reported[table] = true
+		})
+
+		// Start a migration that requires a backfill of both tables
+		addColumn := func(table string) *migrations.OpAddColumn {
+			return &migrations.OpAddColumn{
+				Table:  table,
+				Column: migrations.Column{Name: "age", Type: "integer", Nullable: true},
+				Up:     "18",
+			}
+		}
+		err = mig.Start(ctx, &migrations.Migration{
+			Name:       "02_add_columns",
+			Operations: migrations.Operations{addColumn("table1"), addColumn("table2")},
+		}, cfg)
+		require.NoError(t, err)
+
+		// Both tables are backfilled
+		for _, table := range []string{"table1", "table2"} {
+			var backfilled int
+			err = db.QueryRowContext(ctx, `SELECT count(*) FROM `+table+` WHERE "_pgroll_new_age" = 18`).Scan(&backfilled)
+			require.NoError(t, err)
+			assert.Equal(t, 3, backfilled)
+			assert.True(t, backfillDone(t, db, table))
+		}
+
+		// Progress was reported for both tables
+		assert.Equal(t, map[string]bool{"table1": true, "table2": true}, reported)
+	})
+}
+
+func TestParallelBackfillOfTableChangedTwice(t *testing.T) {
+	t.Parallel()
+
+	testutils.WithMigratorAndConnectionToContainer(t, func(mig *roll.Roll, db *sql.DB) {
+		ctx := context.Background()
+
+		// Create a table and insert some rows into it
+		err := mig.Start(ctx, &migrations.Migration{
+			Name:       "01_create_table",
+			Operations: migrations.Operations{createTableOp("table1")},
+		}, backfill.NewConfig())
+		require.NoError(t, err)
+		err = mig.Complete(ctx)
+		require.NoError(t, err)
+
+		_, err = db.ExecContext(ctx, "INSERT INTO table1 (id, name) VALUES (1, 'alice'), (2, 'bob'), (3, 'carol')")
+		require.NoError(t, err)
+
+		// Count the backfills of each table reported by the progress callbacks
+		var mu sync.Mutex
+		started := make(map[string]int)
+		cfg := backfill.NewConfig(backfill.WithBatchSize(1), backfill.WithParallelism(2))
+		cfg.AddCallback(func(table string, n, total int64) {
+			mu.Lock()
+			defer mu.Unlock()
+			if n == 0 {
+				started[table]++
+			}
+		})
+
+		// Start a migration with two operations that require a backfill of
+		// the same table
+		err = mig.Start(ctx, &migrations.Migration{
+			Name: "02_add_columns",
+			Operations: migrations.Operations{
+				&migrations.OpAddColumn{
+					Table:  "table1",
+					Column: migrations.Column{Name: "age", Type: "integer", Nullable: true},
+					Up:     "18",
+				},
+				&migrations.OpAddColumn{
+					Table:  "table1",
+					Column: migrations.Column{Name: "score", Type: "integer", Nullable: true},
+					Up:     "100",
+				},
+			},
+		}, cfg)
+		require.NoError(t, err)
+
+		// Both columns are backfilled
+		var backfilled int
+		err = db.QueryRowContext(ctx, `SELECT count(*) FROM table1 WHERE "_pgroll_new_age" = 18 AND "_pgroll_new_score" = 100`).Scan(&backfilled)
+		require.NoError(t, err)
+		assert.Equal(t, 3, backfilled)
+
+		// The table was backfilled once
+		assert.Equal(t, map[string]int{"table1": 1}, started)
+	})
+}
+
+func TestBackfillSettingsInMigration(t *testing.T) {
+	t.Parallel()
+
+	testutils.WithMigratorAndConnectionToContainer(t, func(mig *roll.Roll, db *sql.DB) {
+		ctx := context.Background()
+
+		// Create two tables and insert some rows into each
+		err := mig.Start(ctx, &migrations.Migration{
+			Name:       "01_create_tables",
+			Operations: migrations.Operations{createTableOp("table1"), createTableOp("table2")},
+		}, backfill.NewConfig())
+		require.NoError(t, err)
+		err = mig.Complete(ctx)
+		require.NoError(t, err)
+
+		for _, table := range []string{"table1", "table2"} {
+			_, err = db.ExecContext(ctx, "INSERT INTO "+table+" (id, name) VALUES (1, 'alice'), (2, 'bob'), (3, 'carol')")
+			require.NoError(t, err)
+		}
+
+		addColumn := func(table string) *migrations.OpAddColumn {
+			return &migrations.OpAddColumn{
+				Table:  table,
+				Column: migrations.Column{Name: "age", Type: "integer", Nullable: true},
+				Up:     "18",
+			}
+		}
+
+		t.Run("the settings of an operation apply to the tables it backfills", func(t *testing.T) {
+			// Record the progress reported before each batch of each table
+			var mu sync.Mutex
+			reported := make(map[string][]int64)
+			cfg := backfill.NewConfig()
+			cfg.AddCallback(func(table string, n, total int64) {
+				mu.Lock()
+				defer mu.Unlock()
+				reported[table] = append(reported[table], n)
+			})
+
+			err = mig.Start(ctx, &migrations.Migration{
+				Name:       "02_add_columns",
+				Operations: migrations.Operations{addColumn("table1"), addColumn("table2")},
+				Backfill:   &migrations.BackfillSettings{BatchSize: ptr(2)},
+				OperationBackfills: []*migrations.BackfillSettings{
+					{BatchSize: ptr(1)},
+					nil,
+				},
+			}, cfg)
+			require.NoError(t, err)
+
+			// table1 is backfilled one row at a time, table2 two rows at a time
+			assert.Equal(t, map[string][]int64{
+				"table1": {0, 1, 2, 3},
+				"table2": {0, 2, 4},
+			}, reported)
+
+			err = mig.Rollback(ctx)
+			require.NoError(t, err)
+		})
+
+		t.Run("a backfill that exceeds its maximum duration fails", func(t *testing.T) {
+			err = mig.Start(ctx, &migrations.Migration{
+				Name:       "02_add_columns",
+				Operations: migrations.Operations{addColumn("table1")},
+				Backfill:   &migrations.BackfillSettings{MaxDuration: ptr("1ns")},
+			}, backfill.NewConfig())
+
+			var wantErr backfill.MaxDurationExceededError
+			require.ErrorAs(t, err, &wantErr)
+			assert.Equal(t, "table1", wantErr.Table)
+		})
+	})
+}
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package roll
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/xataio/pgroll/pkg/diff"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+// Drift is the difference between the schema recorded after the latest
+// migration and the live schema
+type Drift struct {
+	// The latest migration, after which the recorded schema was read
+	Migration string `json:"migration"`
+
+	// The database objects that differ between the recorded and live schemas
+	Changes []diff.Change `json:"changes"`
+}
+
+// Drift compares the schema recorded in the migration history after the
+// latest migration with the live schema, to detect changes made to the
+// schema outside of pgroll. It returns an error if there is no migration
+// history or a migration is active, as the resulting schema of an active
+// migration is only recorded once it completes.
+func (m *Roll) Drift(ctx context.Context) (*Drift, error) {
+	active, err := m.state.IsActiveMigrationPeriod(ctx, m.schema)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine active migration period: %w", err)
+	}
+	if active {
+		return nil, fmt.Errorf("a migration for schema %q is in progress: complete or roll it back before checking for drift", m.schema)
+	}
+
+	latest, err := m.state.LatestMigration(ctx, m.schema)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine latest migration: %w", err)
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("schema %q has no migration history", m.schema)
+	}
+
+	rawRecorded, err := m.state.RawSchemaAfterMigration(ctx, m.schema, *latest)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read schema after migration %q: %w", *latest, err)
+	}
+	var recorded schema.Schema
+	if err := json.Unmarshal(rawRecorded, &recorded); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal schema after migration %q: %w", *latest, err)
+	}
+
+	live, err := m.state.ReadSchema(ctx, m.schema)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read schema: %w", err)
+	}
+
+	// The recorded schema may have been read by an older version of pgroll
+	if err := withoutUnrecordedAttributes(live, rawRecorded); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal schema after migration %q: %w", *latest, err)
+	}
+
+	return &Drift{
+		Migration: *latest,
+		Changes:   diff.Changes(&recorded, live),
+	}, nil
+}
+
+// withoutUnrecordedAttributes removes from the `live` schema the attributes
+// that the recorded schema `rawRecorded` has no record of, because they
+// weren't read by the version of pgroll that recorded it, so that they are not
+// reported as drift. Support for an attribute is detected from a key that
+// pgroll always writes once it reads the attribute.
+func withoutUnrecordedAttributes(live *schema.Schema, rawRecorded []byte) error {
+	var recorded map[string]json.RawMessage
+	if err := json.Unmarshal(rawRecorded, &recorded); err != nil {
+		return err
+	}
+	var recordedTables map[string]map[string]json.RawMessage
+	if rawTables, ok := recorded["tables"]; ok {
+		if err := json.Unmarshal(rawTables, &recordedTables); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := recorded["enums"]; !ok {
+		live.Enums = nil
+	}
+	if _, ok := recorded["views"]; !ok {
+		live.Views = nil
+	}
+	// Schemas recorded before sequences were read don't record identity
+	// columns, the sequences owned by columns or generated columns either
+	if _, ok := recorded["sequences"]; !ok {
+		live.Sequences = nil
+		for _, table := range live.Tables {
+			for _, column := range table.Columns {
+				column.Identity, column.Sequence, column.Generated = "", "", ""
+			}
+		}
+	}
+
+	for name, table := range live.Tables {
+		recordedTable, ok := recordedTables[name]
+		if !ok {
+			continue
+		}
+		if _, ok := recordedTable["rowLevelSecurity"]; !ok {
+			table.RowLevelSecurity = false
+			table.ForceRowLevelSecurity = false
+			table.Policies = nil
+		}
+	}
+
+	return nil
+}
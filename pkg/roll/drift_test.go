@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package roll_test
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/xataio/pgroll/internal/testutils"
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/diff"
+	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/roll"
+)
+
+func TestDrift(t *testing.T) {
+	t.Parallel()
+
+	createTable := &migrations.Migration{
+		Name: "01_create_table",
+		Operations: migrations.Operations{
+			&migrations.OpCreateTable{
+				Name: "users",
+				Columns: []migrations.Column{
+					{Name: "id", Type: "serial", Pk: true},
+					{Name: "name", Type: "text", Nullable: true},
+				},
+			},
+		},
+	}
+
+	t.Run("a schema with no changes outside of pgroll has not drifted", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(m *roll.Roll, _ *sql.DB) {
+			ctx := context.Background()
+
+			require.NoError(t, m.Start(ctx, createTable, backfill.NewConfig()))
+			require.NoError(t, m.Complete(ctx))
+
+			drift, err := m.Drift(ctx)
+			require.NoError(t, err)
+			require.Equal(t, "01_create_table", drift.Migration)
+			require.Empty(t, drift.Changes)
+		})
+	})
+
+	t.Run("changes that are not captured as inferred migrations are reported", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(m *roll.Roll, db *sql.DB) {
+			ctx := context.Background()
+
+			require.NoError(t, m.Start(ctx, createTable, backfill.NewConfig()))
+			require.NoError(t, m.Complete(ctx))
+
+			// Change the schema without recording an inferred migration
+			conn, err := db.Conn(ctx)
+			require.NoError(t, err)
+			defer conn.Close()
+			_, err = conn.ExecContext(ctx, "SET pgroll.no_inferred_migrations = TRUE")
+			require.NoError(t, err)
+			_, err = conn.ExecContext(ctx, "ALTER TABLE users ALTER COLUMN name SET NOT NULL")
+			require.NoError(t, err)
+			_, err = conn.ExecContext(ctx, "CREATE INDEX idx_users_name ON users (name)")
+			require.NoError(t, err)
+
+			drift, err := m.Drift(ctx)
+			require.NoError(t, err)
+			require.Equal(t, []diff.Change{
+				{Kind: diff.ChangeChanged, Object: diff.ObjectColumn, Table: "users", Name: "name", Details: []string{"nullable: true -> false"}},
+				{Kind: diff.ChangeAdded, Object: diff.ObjectIndex, Table: "users", Name: "idx_users_name"},
+			}, drift.Changes)
+		})
+	})
+
+	t.Run("attributes that an older version of pgroll didn't record are not reported", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(m *roll.Roll, db *sql.DB) {
+			ctx := context.Background()
+
+			require.NoError(t, m.Start(ctx, &migrations.Migration{
+				Name: "01_create_table",
+				Operations: append(slices.Clone(createTable.Operations), &migrations.OpRawSQL{
+					Up: "CREATE VIEW user_names AS SELECT name FROM users",
+				}),
+			}, backfill.NewConfig()))
+			require.NoError(t, m.Complete(ctx))
+
+			// Remove the attributes that older versions of pgroll didn't read
+			// from the recorded schema
+			_, err := db.ExecContext(ctx, `UPDATE pgroll.migrations
+				SET resulting_schema = resulting_schema - 'enums' - 'sequences' - 'views'
+					#- '{tables,users,columns,id,sequence}'
+					#- '{tables,users,rowLevelSecurity}'
+					#- '{tables,users,forceRowLevelSecurity}'
+				WHERE name = '01_create_table'`)
+			require.NoError(t, err)
+
+			drift, err := m.Drift(ctx)
+			require.NoError(t, err)
+			require.Empty(t, drift.Changes)
+		})
+	})
+
+	t.Run("drift can't be checked while a migration is active", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(m *roll.Roll, _ *sql.DB) {
+			ctx := context.Background()
+
+			require.NoError(t, m.Start(ctx, createTable, backfill.NewConfig()))
+
+			_, err := m.Drift(ctx)
+			require.Error(t, err)
+		})
+	})
+}
@@ -6,19 +6,37 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/db"
 	"github.com/xataio/pgroll/pkg/migrations"
 	"github.com/xataio/pgroll/pkg/schema"
+	"github.com/xataio/pgroll/pkg/telemetry"
 )
 
 func (m *Roll) Validate(ctx context.Context, migration *migrations.Migration) error {
+	// Placeholder SQL is never runnable, so it is rejected even when
+	// validation is skipped
+	if err := migration.ValidatePlaceholders(); err != nil {
+		return fmt.Errorf("migration '%s' is invalid: %w", migration.Name, err)
+	}
+	// A migration that doesn't target the schema it is run against can't be
+	// recorded in that schema's history
+	if err := migration.ValidateDefaultSchema(m.schema); err != nil {
+		return fmt.Errorf("migration '%s' is invalid: %w", migration.Name, err)
+	}
 	if m.skipValidation {
 		return nil
 	}
+	if migration.IsMultiSchema(m.schema) || len(migration.ReferencedSchemas(m.schema)) > 0 {
+		return m.validateSchemas(ctx, migration)
+	}
 	lastSchema, err := m.state.ReadSchema(ctx, m.schema)
 	if err != nil {
 		return err
@@ -31,34 +49,54 @@ func (m *Roll) Validate(ctx context.Context, migration *migrations.Migration) er
 }
 
 // Start will apply the required changes to enable supporting the new schema version
-func (m *Roll) Start(ctx context.Context, migration *migrations.Migration, cfg *backfill.Config) error {
+func (m *Roll) Start(ctx context.Context, migration *migrations.Migration, cfg *backfill.Config) (err error) {
+	ctx, span := telemetry.StartSpan(ctx, "pgroll.start", telemetry.MigrationKey.String(migration.Name))
+	defer endPhase(ctx, span, telemetry.PhaseStart, time.Now(), &err)
+
 	// Fail early if we have existing schema without migration history
-	hasExistingSchema, err := m.state.HasExistingSchemaWithoutHistory(ctx, m.schema)
-	if err != nil {
-		return fmt.Errorf("failed to check for existing schema: %w", err)
-	}
-	if hasExistingSchema {
-		return ErrExistingSchemaWithoutHistory
+	for _, schemaName := range migration.Schemas(m.schema) {
+		hasExistingSchema, err := m.state.HasExistingSchemaWithoutHistory(ctx, schemaName)
+		if err != nil {
+			return fmt.Errorf("failed to check for existing schema: %w", err)
+		}
+		if hasExistingSchema {
+			return ErrExistingSchemaWithoutHistory
+		}
 	}
 
 	m.logger.LogMigrationStart(migration)
+	defer func() {
+		if err != nil {
+			m.logger.LogMigrationFailed(migration, err)
+		} else {
+			m.logger.LogMigrationStartComplete(migration)
+		}
+	}()
 
 	if err := m.Validate(ctx, migration); err != nil {
 		return err
 	}
 
+	if migration.IsMultiSchema(m.schema) {
+		return m.startSchemas(ctx, migration, cfg)
+	}
+
 	job, err := m.StartDDLOperations(ctx, migration)
 	if err != nil {
 		return err
 	}
 
 	// perform backfills for the tables that require it
-	return m.performBackfills(ctx, job, cfg)
+	return m.performBackfills(ctx, migration, job, cfg)
 }
 
 // StartDDLOperations performs the DDL operations for the migration. This does
 // not include running backfills for any modified tables.
 func (m *Roll) StartDDLOperations(ctx context.Context, migration *migrations.Migration) (*backfill.Job, error) {
+	if migration.IsMultiSchema(m.schema) {
+		return nil, fmt.Errorf("migration %q targets schemas other than %q and must be run with Start", migration.Name, m.schema)
+	}
+
 	// check if there is an active migration, create one otherwise
 	active, err := m.state.IsActiveMigrationPeriod(ctx, m.schema)
 	if err != nil {
@@ -85,11 +123,12 @@ func (m *Roll) StartDDLOperations(ctx context.Context, migration *migrations.Mig
 		defer m.migrationHooks.AfterStartDDL(m)
 	}
 
-	// Construct the full name of the version schema that will be created by this
-	// migration. The version schema is created after operations have completed
-	// but ops need to know the name in advance in order to construct backfill
-	// triggers.
-	versionSchemaName := VersionedSchemaName(m.schema, migration.VersionSchemaName())
+	// Record the progress of the start if it can be resumed
+	if m.resumableStart {
+		if err := m.state.RecordStartProgress(ctx, m.schema, migration.Name, 0); err != nil {
+			return nil, fmt.Errorf("unable to record migration progress: %w", err)
+		}
+	}
 
 	// Reread the latest schema as validation may have updated the schema object
 	// in memory.
@@ -98,28 +137,51 @@ func (m *Roll) StartDDLOperations(ctx context.Context, migration *migrations.Mig
 		return nil, fmt.Errorf("unable to read schema: %w", err)
 	}
 
+	return m.startOperations(ctx, migration, newSchema, 0)
+}
+
+// startOperations runs the Start phase of the operations in `migration`
+// against `newSchema`, executing the actions of the operations from index
+// `from` onwards, and creates the views for the new version of the schema.
+// The operations before `from` have already been started, so they are only
+// replayed against `newSchema`.
+func (m *Roll) startOperations(ctx context.Context, migration *migrations.Migration, newSchema *schema.Schema, from int) (*backfill.Job, error) {
+	// Construct the full name of the version schema that will be created by this
+	// migration. The version schema is created after operations have completed
+	// but ops need to know the name in advance in order to construct backfill
+	// triggers.
+	versionSchemaName := VersionedSchemaName(m.schema, migration.VersionSchemaName())
+
 	// execute operations
 	job := backfill.NewJob(m.schema, versionSchemaName)
-	for _, op := range migration.Operations {
-		startOp, err := op.Start(ctx, m.logger, m.pgConn, newSchema)
+	for i, op := range migration.Operations {
+		var startOp *migrations.StartResult
+		var err error
+		if i < from {
+			startOp, err = op.Start(ctx, m.logger, &db.FakeDB{}, newSchema)
+		} else {
+			startOp, err = m.startOperation(ctx, i, op, newSchema)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("unable to collect actions for start %q migration: %w", migration.Name, err)
 		}
+
+		if i >= from {
+			if startOp != nil {
+				if err := executeOperationActions(ctx, i, op, startOp.Actions); err != nil {
+					return nil, m.startFailed(ctx, migration, i, newSchema, err)
+				}
+			}
+			if m.resumableStart {
+				if err := m.state.RecordStartProgress(ctx, m.schema, migration.Name, i+1); err != nil {
+					return nil, fmt.Errorf("unable to record migration progress: %w", err)
+				}
+			}
+		}
 		if startOp == nil {
 			continue
 		}
 
-		coordinator := migrations.NewCoordinator(startOp.Actions)
-		if err := coordinator.Execute(ctx); err != nil {
-			errRollback := m.Rollback(ctx)
-			if errRollback != nil {
-				return nil, errors.Join(
-					fmt.Errorf("unable to execute start operation of %q: %w", migration.Name, err),
-					fmt.Errorf("unable to roll back failed operation: %w", errRollback),
-				)
-			}
-			return nil, fmt.Errorf("failed to start %q migration, changes rolled back: %w", migration.Name, err)
-		}
 		// refresh schema when the op is isolated and requires a refresh (for example raw sql)
 		// we don't want to refresh the schema if the operation is not isolated as it would
 		// override changes made by other operations
@@ -132,52 +194,224 @@ func (m *Roll) StartDDLOperations(ctx context.Context, migration *migrations.Mig
 			}
 		}
 		if startOp.BackfillTask != nil {
-			job.AddTask(startOp.BackfillTask)
+			job.AddTask(startOp.BackfillTask, migration.OperationBackfillOptions(i)...)
 		}
 	}
 
 	// create views for the new version
 	if !m.disableVersionSchemas {
-		if err := m.ensureViews(ctx, newSchema, migration); err != nil {
+		if err := m.ensureViews(ctx, m.pgConn, newSchema, migration); err != nil {
 			return nil, err
 		}
+		m.logger.LogSchemaCreation(migration.VersionSchemaName(), versionSchemaName)
 	}
 
 	return job, nil
 }
 
-func (m *Roll) ensureViews(ctx context.Context, schema *schema.Schema, mig *migrations.Migration) error {
+// startFailed handles the failure of the operation at index `idx` of
+// `migration` to start. The whole migration is rolled back unless the Roll is
+// in resumable mode, in which case only the changes made by the failed
+// operation are rolled back and the failure is recorded so that the start can
+// be resumed from the failed operation.
+func (m *Roll) startFailed(ctx context.Context, migration *migrations.Migration, idx int, s *schema.Schema, err error) error {
+	if !m.resumableStart {
+		errRollback := m.Rollback(ctx)
+		if errRollback != nil {
+			return errors.Join(
+				fmt.Errorf("unable to execute start operation of %q: %w", migration.Name, err),
+				fmt.Errorf("unable to roll back failed operation: %w", errRollback),
+			)
+		}
+		return fmt.Errorf("failed to start %q migration, changes rolled back: %w", migration.Name, err)
+	}
+
+	op := migration.Operations[idx]
+	errs := []error{
+		fmt.Errorf("failed to start %q migration at operation %d, resume the start once the cause is fixed or roll the migration back: %w",
+			migration.Name, idx, err),
+	}
+
+	actions, errRollback := op.Rollback(m.logger, m.pgConn, s)
+	if errRollback == nil {
+		errRollback = executeOperationActions(ctx, idx, op, actions)
+	}
+	if errRollback != nil {
+		errs = append(errs, fmt.Errorf("unable to roll back failed operation: %w", errRollback))
+	}
+
+	if errRecord := m.state.RecordStartFailure(ctx, m.schema, migration.Name, idx, err); errRecord != nil {
+		errs = append(errs, fmt.Errorf("unable to record migration progress: %w", errRecord))
+	}
+
+	return errors.Join(errs...)
+}
+
+// startOperation runs the Start phase of an operation, tracing it as a child
+// of the migration's span.
+func (m *Roll) startOperation(ctx context.Context, idx int, op migrations.Operation, s *schema.Schema) (*migrations.StartResult, error) {
+	ctx, span := operationSpan(ctx, idx, op)
+	result, err := op.Start(ctx, m.logger, m.pgConn, s)
+	telemetry.EndSpan(span, err)
+	return result, err
+}
+
+// executeOperationActions executes the actions for an operation, tracing them
+// as a child of the migration's span.
+func executeOperationActions(ctx context.Context, idx int, op migrations.Operation, actions []migrations.DBAction) error {
+	ctx, span := operationSpan(ctx, idx, op)
+	err := migrations.NewCoordinator(actions).Execute(ctx)
+	telemetry.EndSpan(span, err)
+	return err
+}
+
+// executeCompleteActions executes the actions collected from the Complete
+// phase of each operation of `migration` with a single coordinator, so that
+// an action added by several operations runs once. `owners` maps the ID of
+// each action to the index of the operation that added it last, in whose span
+// the action is traced. Actions without an owner are not traced.
+func executeCompleteActions(ctx context.Context, migration *migrations.Migration, actions []migrations.DBAction, owners map[string]int) error {
+	ordered := migrations.NewCoordinator(actions).Actions()
+	for len(ordered) > 0 {
+		// Execute the next run of actions that belong to the same operation
+		idx, owned := owners[ordered[0].ID()]
+		n := 1
+		for n < len(ordered) {
+			i, ok := owners[ordered[n].ID()]
+			if ok != owned || i != idx {
+				break
+			}
+			n++
+		}
+
+		var err error
+		if owned {
+			err = executeOperationActions(ctx, idx, migration.Operations[idx], ordered[:n])
+		} else {
+			err = migrations.NewCoordinator(ordered[:n]).Execute(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		ordered = ordered[n:]
+	}
+	return nil
+}
+
+func operationSpan(ctx context.Context, idx int, op migrations.Operation) (context.Context, trace.Span) {
+	return telemetry.StartSpan(ctx, "pgroll.operation",
+		telemetry.OperationKey.String(string(migrations.OperationName(op))),
+		telemetry.OperationIndexKey.Int(idx))
+}
+
+// logFailure logs that the migration failed if `err` is set
+func (m *Roll) logFailure(migration *migrations.Migration, err *error) {
+	if *err != nil {
+		m.logger.LogMigrationFailed(migration, *err)
+	}
+}
+
+// endPhase ends the span for a phase of a migration and records how long the
+// phase took.
+func endPhase(ctx context.Context, span trace.Span, phase string, start time.Time, err *error) {
+	telemetry.RecordMigrationDuration(ctx, phase, time.Since(start), *err)
+	telemetry.EndSpan(span, *err)
+}
+
+func (m *Roll) ensureViews(ctx context.Context, conn db.DB, schema *schema.Schema, mig *migrations.Migration) error {
 	versionSchema := VersionedSchemaName(m.schema, mig.VersionSchemaName())
-	_, err := m.pgConn.ExecContext(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", pq.QuoteIdentifier(versionSchema)))
+	_, err := conn.ExecContext(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", pq.QuoteIdentifier(versionSchema)))
 	if err != nil {
 		return err
 	}
 
+	// The schema's own views read from the views for the new version's tables,
+	// so they are dropped before those views are replaced. They are dropped one
+	// by one, dependents first, rather than with CASCADE so that views outside
+	// pgroll that read from the version schema are never dropped silently.
+	for _, name := range slices.Backward(migrations.SortedViews(schema)) {
+		_, err := conn.ExecContext(ctx, fmt.Sprintf("DROP VIEW IF EXISTS %s.%s",
+			pq.QuoteIdentifier(versionSchema),
+			pq.QuoteIdentifier(name)))
+		if err != nil {
+			return fmt.Errorf("unable to drop view %q: %w", name, err)
+		}
+	}
+
 	// create views in the new schema
 	for name, table := range schema.Tables {
 		if table.Deleted {
 			continue
 		}
-		err = m.ensureView(ctx, mig.VersionSchemaName(), name, table)
+		err = m.ensureView(ctx, conn, mig.VersionSchemaName(), name, table)
 		if err != nil {
 			return fmt.Errorf("unable to create view: %w", err)
 		}
 	}
 
-	m.logger.LogSchemaCreation(mig.VersionSchemaName(), versionSchema)
+	// re-expose the schema's own views, which read from the views for the
+	// new version's tables
+	for _, name := range migrations.SortedViews(schema) {
+		err = m.ensureSchemaView(ctx, conn, mig.VersionSchemaName(), schema.Views[name])
+		if err != nil {
+			return fmt.Errorf("unable to create view %q: %w", name, err)
+		}
+	}
+
+	if err := m.grantVersionSchemaPrivileges(ctx, conn, versionSchema); err != nil {
+		return fmt.Errorf("unable to grant privileges on version schema: %w", err)
+	}
 
 	return nil
 }
 
+// grantVersionSchemaPrivileges grants the privileges configured with
+// WithVersionSchemaGrant on `versionSchema` and all of its views. Views are
+// re-created each time the version schema is ensured, so the privileges are
+// granted again each time.
+func (m *Roll) grantVersionSchemaPrivileges(ctx context.Context, conn db.DB, versionSchema string) error {
+	grant := m.versionSchemaGrant
+	if len(grant.Roles) == 0 {
+		return nil
+	}
+
+	roles := migrations.QuoteRoles(grant.Roles)
+	_, err := conn.ExecContext(ctx, fmt.Sprintf("GRANT USAGE ON SCHEMA %s TO %s; GRANT %s ON ALL TABLES IN SCHEMA %s TO %s",
+		pq.QuoteIdentifier(versionSchema),
+		roles,
+		migrations.PrivilegesSQL(grant.Privileges),
+		pq.QuoteIdentifier(versionSchema),
+		roles))
+	return err
+}
+
 // Complete will update the database schema to match the current version
-func (m *Roll) Complete(ctx context.Context) error {
+func (m *Roll) Complete(ctx context.Context) (err error) {
+	ctx, span := telemetry.StartSpan(ctx, "pgroll.complete")
+	defer endPhase(ctx, span, telemetry.PhaseComplete, time.Now(), &err)
+
 	// get current ongoing migration
 	migration, err := m.state.GetActiveMigration(ctx, m.schema)
 	if err != nil {
 		return fmt.Errorf("unable to get active migration: %w", err)
 	}
+	span.SetAttributes(telemetry.MigrationKey.String(migration.Name))
 
 	m.logger.LogMigrationComplete(migration)
+	defer m.logFailure(migration, &err)
+
+	if migration.IsMultiSchema(m.schema) {
+		return m.completeSchemas(ctx, migration)
+	}
+
+	// A migration whose start failed is only partially started
+	progress, err := m.state.StartProgress(ctx, m.schema, migration.Name)
+	if err != nil {
+		return fmt.Errorf("unable to read migration progress: %w", err)
+	}
+	if progress != nil && progress.Failed() {
+		return fmt.Errorf("the start of migration %q failed: resume the start or roll the migration back", migration.Name)
+	}
 
 	// Drop the old version schema if there is one
 	prevVersion, err := m.state.PreviousVersion(ctx, m.schema)
@@ -210,23 +444,38 @@ func (m *Roll) Complete(ctx context.Context) error {
 		defer m.migrationHooks.AfterCompleteDDL(m)
 	}
 
+	// the views in the underlying schema that the migration changes are
+	// dropped before its operations complete and recreated afterwards
+	virtualSchema, err := m.previousSchema(ctx)
+	if err != nil {
+		return err
+	}
+	if err := migration.UpdateVirtualSchema(ctx, virtualSchema); err != nil {
+		return fmt.Errorf("unable to replay changes to in-memory schema: %w", err)
+	}
+	dropViews, createViews := viewActions(m.pgConn, currentSchema, virtualSchema)
+
 	// execute operations
 	refreshViews := false
-	var actions []migrations.DBAction
-	for _, op := range migration.Operations {
+	actions := dropViews
+	owners := make(map[string]int)
+	for i, op := range migration.Operations {
 		opActions, err := op.Complete(m.logger, m.pgConn, currentSchema)
 		if err != nil {
 			return fmt.Errorf("unable to collect actions for complete operation: %w", err)
 		}
+		for _, action := range opActions {
+			owners[action.ID()] = i
+		}
 		actions = append(actions, opActions...)
 
 		if _, ok := op.(migrations.RequiresSchemaRefreshOperation); ok {
 			refreshViews = true
 		}
 	}
+	actions = append(actions, createViews...)
 
-	coordinator := migrations.NewCoordinator(actions)
-	if err := coordinator.Execute(ctx); err != nil {
+	if err := executeCompleteActions(ctx, migration, actions, owners); err != nil {
 		return fmt.Errorf("unable to execute complete operation: %w", err)
 	}
 
@@ -237,10 +486,11 @@ func (m *Roll) Complete(ctx context.Context) error {
 			return fmt.Errorf("unable to read schema: %w", err)
 		}
 
-		err = m.ensureViews(ctx, currentSchema, migration)
+		err = m.ensureViews(ctx, m.pgConn, currentSchema, migration)
 		if err != nil {
 			return err
 		}
+		m.logger.LogSchemaCreation(migration.VersionSchemaName(), VersionedSchemaName(m.schema, migration.VersionSchemaName()))
 	}
 
 	// mark as completed
@@ -255,14 +505,23 @@ func (m *Roll) Complete(ctx context.Context) error {
 }
 
 // Rollback will revert the changes made by the migration
-func (m *Roll) Rollback(ctx context.Context) error {
+func (m *Roll) Rollback(ctx context.Context) (err error) {
+	ctx, span := telemetry.StartSpan(ctx, "pgroll.rollback")
+	defer endPhase(ctx, span, telemetry.PhaseRollback, time.Now(), &err)
+
 	// get current ongoing migration
 	migration, err := m.state.GetActiveMigration(ctx, m.schema)
 	if err != nil {
 		return fmt.Errorf("unable to get active migration: %w", err)
 	}
+	span.SetAttributes(telemetry.MigrationKey.String(migration.Name))
 
 	m.logger.LogMigrationRollback(migration)
+	defer m.logFailure(migration, &err)
+
+	if migration.IsMultiSchema(m.schema) {
+		return m.rollbackSchemas(ctx, migration)
+	}
 
 	// delete the schema and views for the new version
 	versionSchema := VersionedSchemaName(m.schema, migration.VersionSchemaName())
@@ -299,8 +558,7 @@ func (m *Roll) Rollback(ctx context.Context) error {
 		if err != nil {
 			return fmt.Errorf("unable to collect actions for rollback operation: %w", err)
 		}
-		coordinator := migrations.NewCoordinator(actions)
-		if err := coordinator.Execute(ctx); err != nil {
+		if err := executeOperationActions(ctx, i, migration.Operations[i], actions); err != nil {
 			return fmt.Errorf("unable to execute rollback operation: %w", err)
 		}
 	}
@@ -317,7 +575,7 @@ func (m *Roll) Rollback(ctx context.Context) error {
 }
 
 // create view creates a view for the new version of the schema
-func (m *Roll) ensureView(ctx context.Context, version, name string, table *schema.Table) error {
+func (m *Roll) ensureView(ctx context.Context, conn db.DB, version, name string, table *schema.Table) error {
 	columns := make([]string, 0, len(table.Columns))
 	defaults := make(map[string]string, len(table.Columns))
 	for k, v := range table.Columns {
@@ -349,7 +607,7 @@ func (m *Roll) ensureView(ctx context.Context, version, name string, table *sche
 			pq.QuoteIdentifier(column),
 			defaultVal)
 	}
-	_, err := m.pgConn.ExecContext(ctx,
+	_, err := conn.ExecContext(ctx,
 		fmt.Sprintf("BEGIN; DROP VIEW IF EXISTS %s.%s; CREATE VIEW %s.%s %s AS SELECT %s FROM %s; %s COMMIT",
 			pq.QuoteIdentifier(VersionedSchemaName(m.schema, version)),
 			pq.QuoteIdentifier(name),
@@ -365,29 +623,95 @@ func (m *Roll) ensureView(ctx context.Context, version, name string, table *sche
 	return nil
 }
 
-func (m *Roll) performBackfills(ctx context.Context, job *backfill.Job, cfg *backfill.Config) error {
-	bf := backfill.New(m.pgConn, cfg)
+// ensureSchemaView creates a view from the underlying schema in the version
+// schema for `version`. The view's definition is evaluated with the version
+// schema first on the search path, so that it reads from the version's views
+// of its tables.
+func (m *Roll) ensureSchemaView(ctx context.Context, conn db.DB, version string, view *schema.View) error {
+	versionSchema := pq.QuoteIdentifier(VersionedSchemaName(m.schema, version))
 
-	bf.CreateTriggers(ctx, job)
+	withOptions := ""
+	if m.PGVersion() >= PGVersion15 {
+		withOptions = "WITH (security_invoker = true)"
+	}
 
-	for _, table := range job.Tables {
-		m.logger.LogBackfillStart(table.Name)
+	_, err := conn.ExecContext(ctx,
+		fmt.Sprintf("BEGIN; SET LOCAL search_path TO %s, %s; DROP VIEW IF EXISTS %s.%s; CREATE VIEW %s.%s %s AS %s; COMMIT",
+			versionSchema,
+			pq.QuoteIdentifier(m.schema),
+			versionSchema,
+			pq.QuoteIdentifier(view.Name),
+			versionSchema,
+			pq.QuoteIdentifier(view.Name),
+			withOptions,
+			view.Definition))
+	return err
+}
 
-		if err := bf.Start(ctx, table); err != nil {
-			errRollback := m.Rollback(ctx)
+// viewActions returns the actions that drop the views in the underlying
+// schema `current` that completing a migration changes, and the actions that
+// create them, and any new views, as they are in the virtual schema `virtual`
+// once the migration is complete.
+func viewActions(conn db.DB, current, virtual *schema.Schema) (drop, create []migrations.DBAction) {
+	dropViews, createViews := migrations.ViewsToRebuild(current, virtual)
+	for _, name := range dropViews {
+		drop = append(drop, migrations.NewDropViewAction(conn, name))
+	}
+	for _, name := range createViews {
+		create = append(create, migrations.NewCreateViewAction(conn, name, virtual.Views[name].Definition))
+	}
+	return drop, create
+}
 
-			return errors.Join(
-				fmt.Errorf("unable to backfill table %q: %w", table.Name, err),
-				errRollback,
-			)
+// performBackfills runs the backfills in `job`. If any of them fail, the
+// migration is rolled back or, in resumable mode, the failure is recorded.
+func (m *Roll) performBackfills(ctx context.Context, migration *migrations.Migration, job *backfill.Job, cfg *backfill.Config) error {
+	if err := m.runBackfills(ctx, migration, job, cfg); err != nil {
+		// In resumable mode the backfill is resumed along with the start
+		if m.resumableStart {
+			errRecord := m.state.RecordStartFailure(ctx, m.schema, migration.Name, len(migration.Operations), err)
+			return errors.Join(err, errRecord)
 		}
 
-		m.logger.LogBackfillComplete(table.Name)
+		errRollback := m.Rollback(ctx)
+
+		return errors.Join(err, errRollback)
 	}
 
 	return nil
 }
 
+// runBackfills creates the backfill triggers for `job` and backfills its
+// tables.
+func (m *Roll) runBackfills(ctx context.Context, migration *migrations.Migration, job *backfill.Job, cfg *backfill.Config) error {
+	// The backfill settings of the migration and of its operations take
+	// precedence over `cfg`
+	cfg = cfg.With(migration.BackfillOptions()...).With(job.Options()...)
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid backfill configuration: %w", err)
+	}
+
+	checkpointer := m.state.BackfillCheckpointer(m.schema, migration.Name)
+
+	bf := backfill.New(m.pgConn, cfg)
+	bf.SetCheckpointer(checkpointer)
+
+	if err := bf.CreateTriggers(ctx, job); err != nil {
+		return fmt.Errorf("unable to create backfill triggers: %w", err)
+	}
+
+	if cfg.Parallelism() <= 1 || len(job.Tables) <= 1 {
+		for _, table := range job.Tables {
+			if err := m.backfillTable(ctx, bf, table); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return m.backfillTablesInParallel(ctx, job.Tables, cfg, checkpointer)
+}
+
 func VersionedSchemaName(schema string, version string) string {
 	return schema + "_" + version
 }
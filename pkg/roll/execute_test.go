@@ -636,6 +636,50 @@ func TestRoleIsRespected(t *testing.T) {
 	})
 }
 
+func TestVersionSchemaGrantIsApplied(t *testing.T) {
+	t.Parallel()
+
+	opts := []roll.Option{roll.WithVersionSchemaGrant(roll.VersionSchemaGrant{
+		Roles:      []string{"pgroll"},
+		Privileges: []migrations.TablePrivilege{migrations.TablePrivilegeSelect},
+	})}
+
+	testutils.WithMigratorInSchemaAndConnectionToContainerWithOptions(t, "public", opts, func(mig *roll.Roll, db *sql.DB) {
+		ctx := context.Background()
+
+		// Start a create table migration
+		err := mig.Start(ctx, &migrations.Migration{
+			Name:       "01_create_table",
+			Operations: migrations.Operations{createTableOp("table1")},
+		}, backfill.NewConfig())
+		require.NoError(t, err)
+
+		// The role can use the version schema and select from its views
+		versionSchema := roll.VersionedSchemaName("public", "01_create_table")
+		var usage, selectable, insertable bool
+		err = db.QueryRow(`
+			SELECT has_schema_privilege($1, $2, 'USAGE'),
+				has_table_privilege($1, format('%I.%I', $2::text, $3::text), 'SELECT'),
+				has_table_privilege($1, format('%I.%I', $2::text, $3::text), 'INSERT')`,
+			"pgroll", versionSchema, "table1").Scan(&usage, &selectable, &insertable)
+		require.NoError(t, err)
+		assert.True(t, usage)
+		assert.True(t, selectable)
+		assert.False(t, insertable)
+	})
+}
+
+func TestInvalidVersionSchemaGrantIsRejected(t *testing.T) {
+	t.Parallel()
+
+	_, err := roll.New(context.Background(), "postgres://localhost", "public", nil,
+		roll.WithVersionSchemaGrant(roll.VersionSchemaGrant{
+			Roles:      []string{"pgroll"},
+			Privileges: []migrations.TablePrivilege{"usage"},
+		}))
+	require.ErrorIs(t, err, migrations.InvalidPrivilegeError{Privilege: "usage"})
+}
+
 func TestMigrationHooksAreInvoked(t *testing.T) {
 	t.Parallel()
 
@@ -699,7 +743,7 @@ func TestCallbacksAreInvokedOnMigrationStart(t *testing.T) {
 
 		// Define a mock callback
 		invoked := false
-		cb := func(n, total int64) { invoked = true }
+		cb := func(table string, n, total int64) { invoked = true }
 
 		backfillConfig := backfill.NewConfig()
 		backfillConfig.AddCallback(cb)
@@ -1032,6 +1076,339 @@ func MustSelect(t *testing.T, db *sql.DB, schema, version, table string) []map[s
 	return res
 }
 
+func TestMigrationsSpanningSeveralSchemas(t *testing.T) {
+	t.Parallel()
+
+	const billing = "billing"
+
+	multiSchemaMigration := func() *migrations.Migration {
+		return &migrations.Migration{
+			Name:             "1_create_tables",
+			Operations:       migrations.Operations{createTableOp("customers"), createTableOp("invoices")},
+			OperationSchemas: []string{"", billing},
+		}
+	}
+
+	t.Run("start creates a version schema in every targeted schema", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(mig *roll.Roll, db *sql.DB) {
+			ctx := context.Background()
+			_, err := db.ExecContext(ctx, "CREATE SCHEMA "+billing)
+			require.NoError(t, err)
+
+			err = mig.Start(ctx, multiSchemaMigration(), backfill.NewConfig())
+			require.NoError(t, err)
+
+			assert.True(t, tableExists(t, db, cSchema, "customers"))
+			assert.True(t, tableExists(t, db, billing, "invoices"))
+			assert.False(t, tableExists(t, db, cSchema, "invoices"))
+			assert.True(t, schemaExists(t, db, roll.VersionedSchemaName(cSchema, "1_create_tables")))
+			assert.True(t, schemaExists(t, db, roll.VersionedSchemaName(billing, "1_create_tables")))
+
+			// The migration is active in both schemas
+			for _, schema := range []string{cSchema, billing} {
+				active, err := mig.State().IsActiveMigrationPeriod(ctx, schema)
+				require.NoError(t, err)
+				assert.True(t, active)
+			}
+		})
+	})
+
+	t.Run("complete completes the migration in every targeted schema", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(mig *roll.Roll, db *sql.DB) {
+			ctx := context.Background()
+			_, err := db.ExecContext(ctx, "CREATE SCHEMA "+billing)
+			require.NoError(t, err)
+
+			err = mig.Start(ctx, multiSchemaMigration(), backfill.NewConfig())
+			require.NoError(t, err)
+			err = mig.Complete(ctx)
+			require.NoError(t, err)
+
+			for _, schema := range []string{cSchema, billing} {
+				active, err := mig.State().IsActiveMigrationPeriod(ctx, schema)
+				require.NoError(t, err)
+				assert.False(t, active)
+
+				history, err := mig.State().SchemaHistory(ctx, schema)
+				require.NoError(t, err)
+				require.Len(t, history, 1)
+				assert.Equal(t, "1_create_tables", history[0].Migration.Name)
+				assert.Equal(t, []string{cSchema, billing}, history[0].Schemas)
+			}
+		})
+	})
+
+	t.Run("rollback reverts the migration in every targeted schema", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(mig *roll.Roll, db *sql.DB) {
+			ctx := context.Background()
+			_, err := db.ExecContext(ctx, "CREATE SCHEMA "+billing)
+			require.NoError(t, err)
+
+			err = mig.Start(ctx, multiSchemaMigration(), backfill.NewConfig())
+			require.NoError(t, err)
+			err = mig.Rollback(ctx)
+			require.NoError(t, err)
+
+			assert.False(t, tableExists(t, db, cSchema, "customers"))
+			assert.False(t, tableExists(t, db, billing, "invoices"))
+			assert.False(t, schemaExists(t, db, roll.VersionedSchemaName(cSchema, "1_create_tables")))
+			assert.False(t, schemaExists(t, db, roll.VersionedSchemaName(billing, "1_create_tables")))
+
+			for _, schema := range []string{cSchema, billing} {
+				active, err := mig.State().IsActiveMigrationPeriod(ctx, schema)
+				require.NoError(t, err)
+				assert.False(t, active)
+			}
+		})
+	})
+
+	t.Run("a failed operation rolls back the migration in every targeted schema", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(mig *roll.Roll, db *sql.DB) {
+			ctx := context.Background()
+			_, err := db.ExecContext(ctx, "CREATE SCHEMA "+billing)
+			require.NoError(t, err)
+
+			err = mig.Start(ctx, &migrations.Migration{
+				Name: "1_create_tables",
+				Operations: migrations.Operations{
+					createTableOp("customers"),
+					&migrations.OpCreateTable{
+						Name:    "broken",
+						Columns: []migrations.Column{{Name: "id", Type: "no_such_type"}},
+					},
+				},
+				OperationSchemas: []string{billing, ""},
+			}, backfill.NewConfig())
+			require.Error(t, err)
+
+			assert.False(t, tableExists(t, db, billing, "customers"))
+			for _, schema := range []string{cSchema, billing} {
+				active, err := mig.State().IsActiveMigrationPeriod(ctx, schema)
+				require.NoError(t, err)
+				assert.False(t, active)
+			}
+		})
+	})
+
+	t.Run("a migration must target the schema it is run against", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(mig *roll.Roll, db *sql.DB) {
+			ctx := context.Background()
+			_, err := db.ExecContext(ctx, "CREATE SCHEMA "+billing)
+			require.NoError(t, err)
+
+			err = mig.Start(ctx, &migrations.Migration{
+				Name:             "1_create_tables",
+				Operations:       migrations.Operations{createTableOp("invoices")},
+				OperationSchemas: []string{billing},
+			}, backfill.NewConfig())
+			require.ErrorAs(t, err, &migrations.InvalidMigrationError{})
+
+			assert.False(t, tableExists(t, db, billing, "invoices"))
+			active, err := mig.State().IsActiveMigrationPeriod(ctx, billing)
+			require.NoError(t, err)
+			assert.False(t, active)
+		})
+	})
+
+	// ordersOp creates an orders table with a foreign key to the customers
+	// table in the billing schema
+	ordersOp := func(customerColumn string) *migrations.OpCreateTable {
+		return &migrations.OpCreateTable{
+			Name: "orders",
+			Columns: []migrations.Column{
+				{Name: "id", Type: "integer", Pk: true},
+				{
+					Name: "customer_id",
+					Type: "integer",
+					References: &migrations.ForeignKeyReference{
+						Name:   "fk_orders_customers",
+						Schema: billing,
+						Table:  "customers",
+						Column: customerColumn,
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("a foreign key can reference a table created in another schema by the same migration", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(mig *roll.Roll, db *sql.DB) {
+			ctx := context.Background()
+			_, err := db.ExecContext(ctx, "CREATE SCHEMA "+billing)
+			require.NoError(t, err)
+
+			err = mig.Start(ctx, &migrations.Migration{
+				Name:             "1_create_tables",
+				Operations:       migrations.Operations{createTableOp("customers"), ordersOp("id")},
+				OperationSchemas: []string{billing, ""},
+			}, backfill.NewConfig())
+			require.NoError(t, err)
+			err = mig.Complete(ctx)
+			require.NoError(t, err)
+
+			_, err = db.ExecContext(ctx, "INSERT INTO billing.customers (id, name) VALUES (1, 'alice')")
+			require.NoError(t, err)
+			_, err = db.ExecContext(ctx, "INSERT INTO public.orders (id, customer_id) VALUES (1, 1)")
+			require.NoError(t, err)
+
+			// The foreign key references the customers table in the billing schema
+			_, err = db.ExecContext(ctx, "INSERT INTO public.orders (id, customer_id) VALUES (2, 2)")
+			require.Error(t, err)
+
+			s, err := mig.State().ReadSchema(ctx, cSchema)
+			require.NoError(t, err)
+			fk := s.GetTable("orders").ForeignKeys["fk_orders_customers"]
+			require.NotNil(t, fk)
+			assert.Equal(t, billing, fk.ReferencedSchema)
+			assert.Equal(t, "customers", fk.ReferencedTable)
+		})
+	})
+
+	t.Run("a foreign key can reference a table in a schema the migration doesn't target", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(mig *roll.Roll, db *sql.DB) {
+			ctx := context.Background()
+			_, err := db.ExecContext(ctx, "CREATE SCHEMA "+billing)
+			require.NoError(t, err)
+
+			err = mig.Start(ctx, &migrations.Migration{
+				Name:             "1_create_customers",
+				Operations:       migrations.Operations{createTableOp("customers"), createTableOp("products")},
+				OperationSchemas: []string{billing, ""},
+			}, backfill.NewConfig())
+			require.NoError(t, err)
+			err = mig.Complete(ctx)
+			require.NoError(t, err)
+
+			// The referenced column is validated against the billing schema
+			err = mig.Start(ctx, &migrations.Migration{
+				Name:       "2_create_orders",
+				Operations: migrations.Operations{ordersOp("no_such_column")},
+			}, backfill.NewConfig())
+			require.ErrorAs(t, err, &migrations.ColumnDoesNotExistError{})
+
+			err = mig.Start(ctx, &migrations.Migration{
+				Name:       "2_create_orders",
+				Operations: migrations.Operations{ordersOp("id")},
+			}, backfill.NewConfig())
+			require.NoError(t, err)
+			err = mig.Complete(ctx)
+			require.NoError(t, err)
+
+			// The migration is only recorded in the schema it targets
+			history, err := mig.State().SchemaHistory(ctx, billing)
+			require.NoError(t, err)
+			require.Len(t, history, 1)
+
+			_, err = db.ExecContext(ctx, "INSERT INTO public.orders (id, customer_id) VALUES (1, 1)")
+			require.Error(t, err)
+		})
+	})
+
+	moveTableMigration := func() *migrations.Migration {
+		return &migrations.Migration{
+			Name:       "2_move_customers",
+			Operations: migrations.Operations{&migrations.OpMoveTable{Table: "customers", ToSchema: billing}},
+		}
+	}
+
+	// startMoveTable creates the customers table in the public schema and
+	// starts a migration that moves it to the billing schema
+	startMoveTable := func(t *testing.T, mig *roll.Roll, db *sql.DB) {
+		t.Helper()
+		ctx := context.Background()
+		_, err := db.ExecContext(ctx, "CREATE SCHEMA "+billing)
+		require.NoError(t, err)
+
+		err = mig.Start(ctx, &migrations.Migration{
+			Name:       "1_create_customers",
+			Operations: migrations.Operations{createTableOp("customers")},
+		}, backfill.NewConfig())
+		require.NoError(t, err)
+		err = mig.Complete(ctx)
+		require.NoError(t, err)
+		_, err = db.ExecContext(ctx, "INSERT INTO public.customers (id, name) VALUES (1, 'alice')")
+		require.NoError(t, err)
+
+		err = mig.Start(ctx, moveTableMigration(), backfill.NewConfig())
+		require.NoError(t, err)
+	}
+
+	t.Run("move_table moves a table to another schema on start", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(mig *roll.Roll, db *sql.DB) {
+			startMoveTable(t, mig, db)
+
+			assert.False(t, tableExists(t, db, cSchema, "customers"))
+			assert.True(t, tableExists(t, db, billing, "customers"))
+
+			// The table is visible through both the old and the new version of
+			// the schemas
+			expected := []map[string]any{{"id": 1, "name": "alice"}}
+			assert.Equal(t, expected, MustSelect(t, db, cSchema, "1_create_customers", "customers"))
+			assert.Equal(t, expected, MustSelect(t, db, billing, "2_move_customers", "customers"))
+		})
+	})
+
+	t.Run("move_table is recorded in both schemas on completion", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(mig *roll.Roll, db *sql.DB) {
+			ctx := context.Background()
+			startMoveTable(t, mig, db)
+
+			err := mig.Complete(ctx)
+			require.NoError(t, err)
+
+			assert.True(t, tableExists(t, db, billing, "customers"))
+			assert.False(t, schemaExists(t, db, roll.VersionedSchemaName(cSchema, "1_create_customers")))
+
+			for _, schema := range []string{cSchema, billing} {
+				history, err := mig.State().SchemaHistory(ctx, schema)
+				require.NoError(t, err)
+				assert.Equal(t, "2_move_customers", history[len(history)-1].Migration.Name)
+			}
+
+			s, err := mig.State().ReadSchema(ctx, billing)
+			require.NoError(t, err)
+			assert.NotNil(t, s.GetTable("customers"))
+		})
+	})
+
+	t.Run("move_table moves the table back on rollback", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(mig *roll.Roll, db *sql.DB) {
+			ctx := context.Background()
+			startMoveTable(t, mig, db)
+
+			err := mig.Rollback(ctx)
+			require.NoError(t, err)
+
+			assert.True(t, tableExists(t, db, cSchema, "customers"))
+			assert.False(t, tableExists(t, db, billing, "customers"))
+			assert.Equal(t,
+				[]map[string]any{{"id": 1, "name": "alice"}},
+				MustSelect(t, db, cSchema, "1_create_customers", "customers"))
+		})
+	})
+
+	t.Run("move_table can't move a table to a schema with a table of the same name", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(mig *roll.Roll, db *sql.DB) {
+			ctx := context.Background()
+			_, err := db.ExecContext(ctx, "CREATE SCHEMA "+billing)
+			require.NoError(t, err)
+
+			err = mig.Start(ctx, &migrations.Migration{
+				Name:             "1_create_customers",
+				Operations:       migrations.Operations{createTableOp("customers"), createTableOp("customers")},
+				OperationSchemas: []string{"", billing},
+			}, backfill.NewConfig())
+			require.NoError(t, err)
+			err = mig.Complete(ctx)
+			require.NoError(t, err)
+
+			err = mig.Start(ctx, moveTableMigration(), backfill.NewConfig())
+			require.ErrorAs(t, err, &migrations.TableAlreadyExistsError{})
+			assert.True(t, tableExists(t, db, cSchema, "customers"))
+		})
+	})
+}
+
 func schemaExists(t *testing.T, db *sql.DB, schema string) bool {
 	t.Helper()
 	var exists bool
@@ -2,6 +2,8 @@
 
 package roll
 
+import "github.com/xataio/pgroll/pkg/migrations"
+
 type options struct {
 	// lock timeout in milliseconds for pgroll DDL operations
 	lockTimeoutMs int
@@ -21,6 +23,24 @@ type options struct {
 	migrationHooks MigrationHooks
 
 	verbose bool
+
+	// logger to use instead of the logger selected by `verbose`
+	logger migrations.Logger
+
+	// keep a migration whose start fails active so that it can be resumed
+	resumableStart bool
+
+	// privileges granted on each new version schema and its views
+	versionSchemaGrant VersionSchemaGrant
+}
+
+// VersionSchemaGrant is a set of privileges granted to roles on each version
+// schema and its views
+type VersionSchemaGrant struct {
+	// Roles to grant the privileges to
+	Roles []string
+	// Privileges to grant on the views in the version schema
+	Privileges []migrations.TablePrivilege
 }
 
 // MigrationHooks defines hooks that can be set to be called at various points
@@ -94,3 +114,36 @@ func WithLogging(enabled bool) Option {
 		}
 	}
 }
+
+// WithLogger sets the logger used to report the progress of migrations. It
+// takes precedence over WithLogging.
+func WithLogger(logger migrations.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithResumableStart controls what happens when the start of a migration
+// fails. By default the whole migration is rolled back. If enabled, only the
+// changes made by the failed operation are rolled back and the migration
+// remains active, recording which of its operations have been started, so
+// that the start can be resumed from the failed operation with ResumeStart
+// once the cause of the failure is fixed.
+//
+// Migrations that target several schemas are always rolled back.
+func WithResumableStart(enabled bool) Option {
+	return func(o *options) {
+		o.resumableStart = enabled
+	}
+}
+
+// WithVersionSchemaGrant sets the privileges granted to roles on each version
+// schema and its views when the version schema is created. The roles are also
+// granted USAGE on the version schema. The privileges of the roles on the
+// underlying tables are unchanged; with `security_invoker` views, the roles
+// also need privileges on the tables themselves.
+func WithVersionSchemaGrant(grant VersionSchemaGrant) Option {
+	return func(o *options) {
+		o.versionSchemaGrant = grant
+	}
+}
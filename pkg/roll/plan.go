@@ -0,0 +1,324 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package roll
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+// PlanPhase is a phase of a migration's lifecycle
+type PlanPhase string
+
+const (
+	PlanPhaseStart    PlanPhase = "start"
+	PlanPhaseBackfill PlanPhase = "backfill"
+	PlanPhaseViews    PlanPhase = "views"
+	PlanPhaseComplete PlanPhase = "complete"
+	PlanPhaseRollback PlanPhase = "rollback"
+)
+
+// Plan describes the SQL statements that pgroll would execute in each phase
+// of a migration.
+type Plan struct {
+	// The name of the migration
+	Migration string `json:"migration"`
+
+	// The phases of the migration, in the order in which they run
+	Phases []PhasePlan `json:"phases"`
+}
+
+// PhasePlan describes the SQL statements executed in a single phase of a
+// migration.
+type PhasePlan struct {
+	Phase PlanPhase  `json:"phase"`
+	Steps []PlanStep `json:"steps"`
+}
+
+// PlanStep is an ordered group of SQL statements issued either by one of the
+// migration's operations or by pgroll itself.
+type PlanStep struct {
+	// The 1-based index of the operation in the migration that issues the
+	// statements. Zero for statements issued by pgroll itself.
+	OperationIndex int `json:"operation_index,omitempty"`
+
+	// The name of the operation that issues the statements
+	Operation migrations.OpName `json:"operation,omitempty"`
+
+	// Description of statements issued by pgroll itself
+	Description string `json:"description,omitempty"`
+
+	// The SQL statements, in execution order
+	Statements []string `json:"statements"`
+}
+
+// Plan returns the SQL statements that starting, completing and rolling back
+// `migration` would execute, without making any changes to the database.
+//
+// Operations are run against a `db.RecordingDB` rather than the database
+// connection. Statements that depend on the contents of the database at
+// execution time, such as backfill batches or schema changes made by raw SQL
+// operations, can not be known in advance and are not included.
+func (m *Roll) Plan(ctx context.Context, migration *migrations.Migration) (*Plan, error) {
+	if err := m.Validate(ctx, migration); err != nil {
+		return nil, err
+	}
+	if migration.IsMultiSchema(m.schema) {
+		return nil, fmt.Errorf("unable to plan migration %q: planning migrations that target schemas other than %q is not supported", migration.Name, m.schema)
+	}
+
+	// Read the schema twice: once to be updated in-memory by the operations'
+	// Start methods and once as the base of the physical schema seen by the
+	// operations' Complete methods.
+	virtualSchema, err := m.state.ReadSchema(ctx, m.schema)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read schema: %w", err)
+	}
+	initialSchema, err := m.state.ReadSchema(ctx, m.schema)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read schema: %w", err)
+	}
+
+	// The latest version schema is dropped when the migration is completed
+	latestVersion, err := m.state.LatestVersion(ctx, m.schema)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get name of latest version: %w", err)
+	}
+
+	p := &planner{
+		conn:      &db.RecordingDB{},
+		logger:    migrations.NewNoopLogger(),
+		migration: migration,
+	}
+	versionSchemaName := VersionedSchemaName(m.schema, migration.VersionSchemaName())
+
+	//
+	// Start
+	//
+	job := backfill.NewJob(m.schema, versionSchemaName)
+	for i, op := range migration.Operations {
+		startOp, err := op.Start(ctx, p.logger, p.conn, virtualSchema)
+		if err != nil {
+			return nil, fmt.Errorf("unable to collect actions for start %q migration: %w", migration.Name, err)
+		}
+		if startOp != nil {
+			if err := migrations.NewCoordinator(startOp.Actions).Execute(ctx); err != nil {
+				return nil, fmt.Errorf("unable to plan start operation: %w", err)
+			}
+			if startOp.BackfillTask != nil {
+				job.AddTask(startOp.BackfillTask)
+			}
+		}
+		p.addOperationStep(PlanPhaseStart, i)
+	}
+
+	//
+	// Backfill
+	//
+	bf := backfill.New(p.conn, backfill.NewConfig())
+	if err := bf.CreateTriggers(ctx, job); err != nil {
+		return nil, fmt.Errorf("unable to plan backfill triggers: %w", err)
+	}
+	p.addStep(PlanPhaseBackfill, "create backfill triggers")
+	for _, table := range job.Tables {
+		p.addStep(PlanPhaseBackfill, fmt.Sprintf("backfill table %q in batches", table.Name))
+	}
+
+	//
+	// Views
+	//
+	if !m.disableVersionSchemas {
+		if err := m.ensureViews(ctx, p.conn, virtualSchema, migration); err != nil {
+			return nil, err
+		}
+		p.addStep(PlanPhaseViews, fmt.Sprintf("create version schema %q", versionSchemaName))
+	}
+
+	//
+	// Complete
+	//
+	if latestVersion != nil {
+		p.dropSchema(ctx, VersionedSchemaName(m.schema, *latestVersion))
+		p.addStep(PlanPhaseComplete, "drop previous version schema")
+	}
+
+	physicalSchema, err := physicalSchemaAfterStart(initialSchema, virtualSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	dropViews, createViews := viewActions(p.conn, initialSchema, virtualSchema)
+	if len(dropViews) > 0 {
+		if err := migrations.NewCoordinator(dropViews).Execute(ctx); err != nil {
+			return nil, fmt.Errorf("unable to plan complete operation: %w", err)
+		}
+		p.addStep(PlanPhaseComplete, "drop views changed by the migration")
+	}
+
+	// Complete actions are deduplicated across all operations, so attribute
+	// each action to the last operation that returned it.
+	var actions []migrations.DBAction
+	owners := make(map[string]int)
+	refreshViews := false
+	for i, op := range migration.Operations {
+		opActions, err := op.Complete(p.logger, p.conn, physicalSchema)
+		if err != nil {
+			return nil, fmt.Errorf("unable to collect actions for complete operation: %w", err)
+		}
+		for _, action := range opActions {
+			owners[action.ID()] = i
+		}
+		actions = append(actions, opActions...)
+
+		if _, ok := op.(migrations.RequiresSchemaRefreshOperation); ok {
+			refreshViews = true
+		}
+	}
+	owner := -1
+	for _, action := range migrations.NewCoordinator(actions).Actions() {
+		if owners[action.ID()] != owner && owner != -1 {
+			p.addOperationStep(PlanPhaseComplete, owner)
+		}
+		owner = owners[action.ID()]
+		if err := action.Execute(ctx); err != nil {
+			return nil, fmt.Errorf("unable to plan complete operation: %w", err)
+		}
+	}
+	if owner != -1 {
+		p.addOperationStep(PlanPhaseComplete, owner)
+	}
+
+	if len(createViews) > 0 {
+		if err := migrations.NewCoordinator(createViews).Execute(ctx); err != nil {
+			return nil, fmt.Errorf("unable to plan complete operation: %w", err)
+		}
+		p.addStep(PlanPhaseComplete, "create views changed by the migration")
+	}
+
+	if refreshViews && !m.disableVersionSchemas {
+		if err := m.ensureViews(ctx, p.conn, virtualSchema, migration); err != nil {
+			return nil, err
+		}
+		p.addStep(PlanPhaseComplete, fmt.Sprintf("recreate views in version schema %q", versionSchemaName))
+	}
+
+	//
+	// Rollback
+	//
+	p.dropSchema(ctx, versionSchemaName)
+	p.addStep(PlanPhaseRollback, "drop version schema")
+
+	for i := len(migration.Operations) - 1; i >= 0; i-- {
+		actions, err := migration.Operations[i].Rollback(p.logger, p.conn, virtualSchema)
+		if err != nil {
+			return nil, fmt.Errorf("unable to collect actions for rollback operation: %w", err)
+		}
+		if err := migrations.NewCoordinator(actions).Execute(ctx); err != nil {
+			return nil, fmt.Errorf("unable to plan rollback operation: %w", err)
+		}
+		p.addOperationStep(PlanPhaseRollback, i)
+	}
+
+	return p.plan(), nil
+}
+
+// planner accumulates the statements recorded for each phase of a migration
+type planner struct {
+	conn      *db.RecordingDB
+	logger    migrations.Logger
+	migration *migrations.Migration
+	phases    []PhasePlan
+}
+
+// addOperationStep adds the statements recorded since the last step to
+// `phase`, attributed to the operation at index `i` in the migration.
+func (p *planner) addOperationStep(phase PlanPhase, i int) {
+	p.add(phase, PlanStep{
+		OperationIndex: i + 1,
+		Operation:      migrations.OperationName(p.migration.Operations[i]),
+	})
+}
+
+// addStep adds the statements recorded since the last step to `phase`,
+// described by `description`.
+func (p *planner) addStep(phase PlanPhase, description string) {
+	p.add(phase, PlanStep{Description: description})
+}
+
+func (p *planner) add(phase PlanPhase, step PlanStep) {
+	step.Statements = append([]string{}, p.conn.Statements()...)
+	p.conn.Reset()
+
+	if len(p.phases) == 0 || p.phases[len(p.phases)-1].Phase != phase {
+		p.phases = append(p.phases, PhasePlan{Phase: phase, Steps: []PlanStep{}})
+	}
+	last := &p.phases[len(p.phases)-1]
+	last.Steps = append(last.Steps, step)
+}
+
+func (p *planner) dropSchema(ctx context.Context, name string) {
+	p.conn.ExecContext(ctx, fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", pq.QuoteIdentifier(name)))
+}
+
+func (p *planner) plan() *Plan {
+	return &Plan{
+		Migration: p.migration.Name,
+		Phases:    p.phases,
+	}
+}
+
+// physicalSchemaAfterStart approximates the schema that `Complete` reads from
+// the database once a migration has started: the schema before the migration
+// plus any tables and columns that the migration's operations created, keyed
+// by their physical names.
+func physicalSchemaAfterStart(before, after *schema.Schema) (*schema.Schema, error) {
+	physical, err := copySchema(before)
+	if err != nil {
+		return nil, err
+	}
+	created, err := copySchema(after)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, table := range created.Tables {
+		existing, ok := physical.Tables[table.Name]
+		if !ok {
+			columns := make(map[string]*schema.Column, len(table.Columns))
+			for _, column := range table.Columns {
+				columns[column.Name] = column
+			}
+			table.Columns = columns
+			physical.AddTable(table.Name, table)
+			continue
+		}
+		for _, column := range table.Columns {
+			if existing.GetColumn(column.Name) == nil {
+				existing.AddColumn(column.Name, column)
+			}
+		}
+	}
+
+	return physical, nil
+}
+
+func copySchema(s *schema.Schema) (*schema.Schema, error) {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal schema: %w", err)
+	}
+
+	c := schema.New()
+	if err := json.Unmarshal(raw, c); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal schema: %w", err)
+	}
+	return c, nil
+}
@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package roll_test
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xataio/pgroll/internal/testutils"
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/roll"
+)
+
+func TestPlan(t *testing.T) {
+	t.Parallel()
+
+	testutils.WithMigratorAndConnectionToContainer(t, func(mig *roll.Roll, db *sql.DB) {
+		ctx := context.Background()
+
+		// Create a table
+		err := mig.Start(ctx, &migrations.Migration{
+			Name:       "01_create_table",
+			Operations: migrations.Operations{createTableOp("table1")},
+		}, backfill.NewConfig())
+		require.NoError(t, err)
+		err = mig.Complete(ctx)
+		require.NoError(t, err)
+
+		// Plan a migration that adds a column to the table
+		plan, err := mig.Plan(ctx, &migrations.Migration{
+			Name: "02_add_column",
+			Operations: migrations.Operations{
+				&migrations.OpAddColumn{
+					Table: "table1",
+					Column: migrations.Column{
+						Name:     "age",
+						Type:     "integer",
+						Nullable: true,
+					},
+					Up: "18",
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		// The plan covers every phase of the migration
+		phases := make(map[roll.PlanPhase][]roll.PlanStep, len(plan.Phases))
+		for _, phase := range plan.Phases {
+			phases[phase.Phase] = phase.Steps
+		}
+		assert.Equal(t, "02_add_column", plan.Migration)
+		require.Contains(t, phases, roll.PlanPhaseStart)
+		require.Contains(t, phases, roll.PlanPhaseBackfill)
+		require.Contains(t, phases, roll.PlanPhaseViews)
+		require.Contains(t, phases, roll.PlanPhaseComplete)
+		require.Contains(t, phases, roll.PlanPhaseRollback)
+
+		// The start phase adds the column under its temporary name
+		start := phases[roll.PlanPhaseStart]
+		require.Len(t, start, 1)
+		assert.Equal(t, migrations.OpNameAddColumn, start[0].Operation)
+		assert.Equal(t, 1, start[0].OperationIndex)
+		require.NotEmpty(t, start[0].Statements)
+		assert.Contains(t, start[0].Statements[0], `ALTER TABLE "table1" ADD COLUMN "_pgroll_new_age"`)
+
+		// The complete phase drops the previous version schema and renames the
+		// column
+		complete := phases[roll.PlanPhaseComplete]
+		require.NotEmpty(t, complete)
+		assert.Contains(t, complete[0].Statements[0], `DROP SCHEMA IF EXISTS "public_01_create_table" CASCADE`)
+		assert.Contains(t, complete[1].Statements,
+			`ALTER TABLE IF EXISTS "table1" RENAME COLUMN "_pgroll_new_age" TO "age"`)
+
+		// Planning the migration does not start it or create its version schema
+		active, err := mig.State().IsActiveMigrationPeriod(ctx, cSchema)
+		require.NoError(t, err)
+		assert.False(t, active)
+		assert.False(t, schemaExists(t, db, roll.VersionedSchemaName(cSchema, "02_add_column")))
+	})
+}
+
+func TestPlanPartitionTable(t *testing.T) {
+	t.Parallel()
+
+	testutils.WithMigratorAndConnectionToContainer(t, func(mig *roll.Roll, db *sql.DB) {
+		ctx := context.Background()
+
+		// Create a table
+		err := mig.Start(ctx, &migrations.Migration{
+			Name: "01_create_table",
+			Operations: migrations.Operations{
+				&migrations.OpCreateTable{
+					Name: "users",
+					Columns: []migrations.Column{
+						{Name: "id", Type: "integer", Pk: true},
+						{Name: "name", Type: "text", Nullable: true},
+					},
+				},
+			},
+		}, backfill.NewConfig())
+		require.NoError(t, err)
+		err = mig.Complete(ctx)
+		require.NoError(t, err)
+
+		// Plan a migration that partitions the table
+		plan, err := mig.Plan(ctx, &migrations.Migration{
+			Name: "02_partition_table",
+			Operations: migrations.Operations{
+				&migrations.OpPartitionTable{
+					Table: "users",
+					PartitionBy: migrations.PartitionBy{
+						Strategy: migrations.PartitionByStrategyHash,
+						Columns:  []string{"id"},
+					},
+					Partitions: []migrations.Partition{
+						{Name: "users_0", Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 0)"},
+						{Name: "users_1", Bound: "FOR VALUES WITH (MODULUS 2, REMAINDER 1)"},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		phases := make(map[roll.PlanPhase][]roll.PlanStep, len(plan.Phases))
+		for _, phase := range plan.Phases {
+			phases[phase.Phase] = phase.Steps
+		}
+
+		// The start phase creates the partitioned copy of the table
+		start := phases[roll.PlanPhaseStart]
+		require.Len(t, start, 1)
+		assert.Equal(t, migrations.OpNamePartitionTable, start[0].Operation)
+		require.NotEmpty(t, start[0].Statements)
+		assert.Contains(t, start[0].Statements[0], `CREATE TABLE "_pgroll_new_users" (LIKE "users" INCLUDING ALL) PARTITION BY HASH`)
+
+		// The backfill phase creates the trigger that copies rows to the copy
+		assert.Contains(t, strings.Join(phases[roll.PlanPhaseBackfill][0].Statements, "\n"),
+			`CREATE OR REPLACE TRIGGER "_pgroll_copy_users"`)
+
+		// The complete phase replaces the table with its partitioned copy
+		var complete []string
+		for _, step := range phases[roll.PlanPhaseComplete] {
+			complete = append(complete, step.Statements...)
+		}
+		assert.Contains(t, complete, `ALTER TABLE "_pgroll_new_users" RENAME TO "users"`)
+
+		// Planning the migration does not create the copy of the table
+		active, err := mig.State().IsActiveMigrationPeriod(ctx, cSchema)
+		require.NoError(t, err)
+		assert.False(t, active)
+	})
+}
+
+func TestPlanRewriteTable(t *testing.T) {
+	t.Parallel()
+
+	testutils.WithMigratorAndConnectionToContainer(t, func(mig *roll.Roll, db *sql.DB) {
+		ctx := context.Background()
+
+		// Create a table
+		err := mig.Start(ctx, &migrations.Migration{
+			Name:       "01_create_table",
+			Operations: migrations.Operations{createTableOp("table1")},
+		}, backfill.NewConfig())
+		require.NoError(t, err)
+		err = mig.Complete(ctx)
+		require.NoError(t, err)
+
+		// Plan a migration that rewrites the table with new storage parameters
+		plan, err := mig.Plan(ctx, &migrations.Migration{
+			Name: "02_rewrite_table",
+			Operations: migrations.Operations{
+				&migrations.OpRewriteTable{
+					Table:             "table1",
+					StorageParameters: "fillfactor=70",
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		phases := make(map[roll.PlanPhase][]roll.PlanStep, len(plan.Phases))
+		for _, phase := range plan.Phases {
+			phases[phase.Phase] = phase.Steps
+		}
+
+		// The start phase creates the copy of the table with the new storage
+		// parameters
+		start := phases[roll.PlanPhaseStart]
+		require.Len(t, start, 1)
+		assert.Equal(t, migrations.OpNameRewriteTable, start[0].Operation)
+		assert.Contains(t, start[0].Statements, `CREATE TABLE "_pgroll_new_table1" (LIKE "table1" INCLUDING ALL) `)
+		assert.Contains(t, start[0].Statements, `ALTER TABLE "_pgroll_new_table1" SET (fillfactor=70)`)
+
+		// The complete phase replaces the table with its copy
+		var complete []string
+		for _, step := range phases[roll.PlanPhaseComplete] {
+			complete = append(complete, step.Statements...)
+		}
+		assert.Contains(t, complete, `ALTER TABLE "_pgroll_new_table1" RENAME TO "table1"`)
+
+		// Planning the migration does not start it
+		active, err := mig.State().IsActiveMigrationPeriod(ctx, cSchema)
+		require.NoError(t, err)
+		assert.False(t, active)
+	})
+}
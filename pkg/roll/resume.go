@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package roll
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/schema"
+	"github.com/xataio/pgroll/pkg/telemetry"
+)
+
+// ResumeStart retries the start of the active migration, which must have been
+// started in resumable mode (see WithResumableStart) and failed. The start
+// resumes from the operation that failed; the operations started before it
+// are not run again. The tables modified by the migration are then backfilled,
+// continuing any backfill that was interrupted.
+//
+// If the resumed start fails, the migration remains active so that its start
+// can be resumed again.
+func (m *Roll) ResumeStart(ctx context.Context, cfg *backfill.Config) (err error) {
+	ctx, span := telemetry.StartSpan(ctx, "pgroll.start")
+	defer endPhase(ctx, span, telemetry.PhaseStart, time.Now(), &err)
+
+	migration, err := m.state.GetActiveMigration(ctx, m.schema)
+	if err != nil {
+		return fmt.Errorf("unable to get active migration: %w", err)
+	}
+	span.SetAttributes(telemetry.MigrationKey.String(migration.Name))
+
+	progress, err := m.state.StartProgress(ctx, m.schema, migration.Name)
+	if err != nil {
+		return fmt.Errorf("unable to read migration progress: %w", err)
+	}
+	if progress == nil {
+		return fmt.Errorf("migration %q was not started in resumable mode", migration.Name)
+	}
+	if !progress.Failed() {
+		return fmt.Errorf("the start of migration %q has not failed", migration.Name)
+	}
+
+	m.logger.LogMigrationStart(migration)
+	defer func() {
+		if err != nil {
+			m.logger.LogMigrationFailed(migration, err)
+		} else {
+			m.logger.LogMigrationStartComplete(migration)
+		}
+	}()
+
+	// A resumed start remains resumable, whatever the Roll's options
+	r := *m
+	r.resumableStart = true
+
+	// run any BeforeStartDDL hooks
+	if m.migrationHooks.BeforeStartDDL != nil {
+		if err := m.migrationHooks.BeforeStartDDL(m); err != nil {
+			return fmt.Errorf("failed to execute BeforeStartDDL hook: %w", err)
+		}
+	}
+
+	// The operations that have already started are replayed against the
+	// schema as it was before the migration started
+	s, err := m.previousSchema(ctx)
+	if err != nil {
+		return err
+	}
+
+	job, err := r.startOperations(ctx, migration, s, progress.CompletedOperations)
+
+	// run any AfterStartDDL hooks
+	if m.migrationHooks.AfterStartDDL != nil {
+		m.migrationHooks.AfterStartDDL(m)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := r.performBackfills(ctx, migration, job, cfg); err != nil {
+		return err
+	}
+
+	if err := m.state.RecordStartProgress(ctx, m.schema, migration.Name, len(migration.Operations)); err != nil {
+		return fmt.Errorf("unable to record migration progress: %w", err)
+	}
+
+	return nil
+}
+
+// previousSchema returns the schema recorded after the migration before the
+// active migration, or an empty schema if there is none.
+func (m *Roll) previousSchema(ctx context.Context) (*schema.Schema, error) {
+	previousMigration, err := m.state.PreviousMigration(ctx, m.schema)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get name of previous version: %w", err)
+	}
+	if previousMigration == nil {
+		return schema.New(), nil
+	}
+
+	s, err := m.state.SchemaAfterMigration(ctx, m.schema, *previousMigration)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read schema: %w", err)
+	}
+	return s, nil
+}
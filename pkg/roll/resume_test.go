@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package roll_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/xataio/pgroll/internal/testutils"
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/roll"
+)
+
+func TestResumableStart(t *testing.T) {
+	t.Parallel()
+
+	createTable := &migrations.Migration{
+		Name: "01_create_table",
+		Operations: migrations.Operations{
+			&migrations.OpCreateTable{
+				Name: "users",
+				Columns: []migrations.Column{
+					{Name: "id", Type: "integer", Pk: true},
+					{Name: "email", Type: "text", Nullable: true},
+				},
+			},
+		},
+	}
+
+	// The second operation fails while there are duplicate emails
+	createIndexes := &migrations.Migration{
+		Name: "02_create_indexes",
+		Operations: migrations.Operations{
+			&migrations.OpCreateIndex{
+				Name:    "idx_users_id_email",
+				Table:   "users",
+				Columns: []migrations.IndexField{{Column: "id"}, {Column: "email"}},
+			},
+			&migrations.OpCreateIndex{
+				Name:    "idx_users_email",
+				Table:   "users",
+				Columns: []migrations.IndexField{{Column: "email"}},
+				Unique:  true,
+			},
+		},
+	}
+
+	t.Run("a failed start is resumed from the failed operation", func(t *testing.T) {
+		opts := []roll.Option{roll.WithResumableStart(true)}
+
+		testutils.WithMigratorInSchemaAndConnectionToContainerWithOptions(t, "public", opts, func(m *roll.Roll, db *sql.DB) {
+			ctx := context.Background()
+
+			require.NoError(t, m.Start(ctx, createTable, backfill.NewConfig()))
+			require.NoError(t, m.Complete(ctx))
+
+			_, err := db.ExecContext(ctx, "INSERT INTO users (id, email) VALUES (1, 'a@example.com'), (2, 'a@example.com')")
+			require.NoError(t, err)
+
+			// The start fails on the unique index
+			err = m.Start(ctx, createIndexes, backfill.NewConfig())
+			require.Error(t, err)
+
+			// The migration remains active, with the first index built and the
+			// failed index rolled back
+			status, err := m.Status(ctx, "public")
+			require.NoError(t, err)
+			require.Equal(t, roll.StartFailedMigrationStatus, status.Status)
+
+			progress, err := m.State().StartProgress(ctx, "public", "02_create_indexes")
+			require.NoError(t, err)
+			require.Equal(t, 1, progress.CompletedOperations)
+			require.True(t, progress.Failed())
+
+			require.True(t, indexExists(t, db, "idx_users_id_email"))
+			require.False(t, indexExists(t, db, "idx_users_email"))
+
+			// The migration can't be completed until the start is resumed
+			require.Error(t, m.Complete(ctx))
+
+			// Fix the cause of the failure and resume the start
+			_, err = db.ExecContext(ctx, "UPDATE users SET email = 'b@example.com' WHERE id = 2")
+			require.NoError(t, err)
+
+			require.NoError(t, m.ResumeStart(ctx, backfill.NewConfig()))
+
+			status, err = m.Status(ctx, "public")
+			require.NoError(t, err)
+			require.Equal(t, roll.InProgressMigrationStatus, status.Status)
+			require.True(t, indexExists(t, db, "idx_users_email"))
+
+			require.NoError(t, m.Complete(ctx))
+			require.True(t, indexExists(t, db, "idx_users_id_email"))
+			require.True(t, indexExists(t, db, "idx_users_email"))
+		})
+	})
+
+	t.Run("a failed start can be rolled back", func(t *testing.T) {
+		opts := []roll.Option{roll.WithResumableStart(true)}
+
+		testutils.WithMigratorInSchemaAndConnectionToContainerWithOptions(t, "public", opts, func(m *roll.Roll, db *sql.DB) {
+			ctx := context.Background()
+
+			require.NoError(t, m.Start(ctx, createTable, backfill.NewConfig()))
+			require.NoError(t, m.Complete(ctx))
+
+			_, err := db.ExecContext(ctx, "INSERT INTO users (id, email) VALUES (1, 'a@example.com'), (2, 'a@example.com')")
+			require.NoError(t, err)
+
+			require.Error(t, m.Start(ctx, createIndexes, backfill.NewConfig()))
+			require.NoError(t, m.Rollback(ctx))
+
+			status, err := m.Status(ctx, "public")
+			require.NoError(t, err)
+			require.Equal(t, roll.CompleteMigrationStatus, status.Status)
+			require.False(t, indexExists(t, db, "idx_users_id_email"))
+		})
+	})
+
+	t.Run("a start that has not failed can't be resumed", func(t *testing.T) {
+		opts := []roll.Option{roll.WithResumableStart(true)}
+
+		testutils.WithMigratorInSchemaAndConnectionToContainerWithOptions(t, "public", opts, func(m *roll.Roll, _ *sql.DB) {
+			ctx := context.Background()
+
+			require.NoError(t, m.Start(ctx, createTable, backfill.NewConfig()))
+			require.Error(t, m.ResumeStart(ctx, backfill.NewConfig()))
+		})
+	})
+}
+
+func indexExists(t *testing.T, db *sql.DB, index string) bool {
+	t.Helper()
+
+	var exists bool
+	err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_catalog.pg_indexes WHERE indexname = $1)", index).Scan(&exists)
+	require.NoError(t, err)
+
+	return exists
+}
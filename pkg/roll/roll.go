@@ -33,6 +33,11 @@ var (
 type Roll struct {
 	pgConn db.DB
 
+	// the connection URL and options used to open additional connections,
+	// such as those used by parallel backfill workers
+	pgURL    string
+	connOpts options
+
 	logger migrations.Logger
 
 	// schema we are acting on
@@ -45,6 +50,14 @@ type Roll struct {
 	state          *state.State
 	pgVersion      PGVersion
 	skipValidation bool
+	resumableStart bool
+
+	// privileges granted on each new version schema and its views
+	versionSchemaGrant VersionSchemaGrant
+
+	// Rolls for each schema targeted by the migrations run with this Roll,
+	// keyed by schema name. Shared by all of them.
+	schemaRolls map[string]*Roll
 }
 
 // New creates a new Roll instance
@@ -54,13 +67,22 @@ func New(ctx context.Context, pgURL, schema string, state *state.State, opts ...
 		o(rollOpts)
 	}
 
+	if len(rollOpts.versionSchemaGrant.Roles) > 0 {
+		if err := migrations.ValidatePrivileges(rollOpts.versionSchemaGrant.Privileges); err != nil {
+			return nil, fmt.Errorf("invalid version schema grant: %w", err)
+		}
+	}
+
 	conn, err := setupConn(ctx, pgURL, schema, *rollOpts)
 	if err != nil {
 		return nil, err
 	}
 
 	logger := migrations.NewNoopLogger()
-	if rollOpts.verbose {
+	switch {
+	case rollOpts.logger != nil:
+		logger = rollOpts.logger
+	case rollOpts.verbose:
 		logger = migrations.NewLogger()
 	}
 
@@ -70,8 +92,10 @@ func New(ctx context.Context, pgURL, schema string, state *state.State, opts ...
 		return nil, fmt.Errorf("unable to retrieve postgres version: %w", err)
 	}
 
-	return &Roll{
+	roll := &Roll{
 		pgConn:                &db.RDB{DB: conn},
+		pgURL:                 pgURL,
+		connOpts:              *rollOpts,
 		logger:                logger,
 		schema:                schema,
 		state:                 state,
@@ -79,10 +103,31 @@ func New(ctx context.Context, pgURL, schema string, state *state.State, opts ...
 		disableVersionSchemas: rollOpts.disableVersionSchemas,
 		migrationHooks:        rollOpts.migrationHooks,
 		skipValidation:        rollOpts.skipValidation,
-	}, nil
+		resumableStart:        rollOpts.resumableStart,
+		versionSchemaGrant:    rollOpts.versionSchemaGrant,
+	}
+	roll.schemaRolls = map[string]*Roll{schema: roll}
+
+	return roll, nil
 }
 
 func setupConn(ctx context.Context, pgURL, schema string, options options) (*sql.DB, error) {
+	conn, err := openConn(pgURL, schema, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := configureConn(ctx, conn, options); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// openConn opens a connection pool to the database at `pgURL` with the
+// search_path and application_name for `schema` and `options`.
+func openConn(pgURL, schema string, options options) (*sql.DB, error) {
 	dsn, err := pq.ParseURL(pgURL)
 	if err != nil {
 		dsn = pgURL
@@ -92,35 +137,35 @@ func setupConn(ctx context.Context, pgURL, schema string, options options) (*sql
 	dsn += fmt.Sprintf(" search_path=%s application_name=%s",
 		strings.Join(searchPath, ","), applicationName)
 
-	conn, err := sql.Open("postgres", dsn)
-	if err != nil {
-		return nil, err
-	}
+	return sql.Open("postgres", dsn)
+}
 
+// configureConn applies the session settings for `options` to `conn`.
+func configureConn(ctx context.Context, conn *sql.DB, options options) error {
 	if err := conn.PingContext(ctx); err != nil {
-		return nil, err
+		return err
 	}
 
-	_, err = conn.ExecContext(ctx, "SET pgroll.no_inferred_migrations TO 'TRUE'")
+	_, err := conn.ExecContext(ctx, "SET pgroll.no_inferred_migrations TO 'TRUE'")
 	if err != nil {
-		return nil, fmt.Errorf("unable to set pgroll.no_inferred_migrations to true: %w", err)
+		return fmt.Errorf("unable to set pgroll.no_inferred_migrations to true: %w", err)
 	}
 
 	if options.lockTimeoutMs > 0 {
 		_, err = conn.ExecContext(ctx, fmt.Sprintf("SET lock_timeout to '%dms'", options.lockTimeoutMs))
 		if err != nil {
-			return nil, fmt.Errorf("unable to set lock_timeout: %w", err)
+			return fmt.Errorf("unable to set lock_timeout: %w", err)
 		}
 	}
 
 	if options.role != "" {
 		_, err = conn.ExecContext(ctx, fmt.Sprintf("SET ROLE %s", options.role))
 		if err != nil {
-			return nil, fmt.Errorf("unable to set role to '%s': %w", options.role, err)
+			return fmt.Errorf("unable to set role to '%s': %w", options.role, err)
 		}
 	}
 
-	return conn, nil
+	return nil
 }
 
 // Init initializes the Roll instance
@@ -153,6 +198,15 @@ func (m *Roll) UseVersionSchema() bool {
 }
 
 func (m *Roll) Close() error {
+	for schema, r := range m.schemaRolls {
+		if schema == m.schema {
+			continue
+		}
+		if err := r.pgConn.Close(); err != nil {
+			return err
+		}
+	}
+
 	err := m.state.Close()
 	if err != nil {
 		return err
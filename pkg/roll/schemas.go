@@ -0,0 +1,394 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package roll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/db"
+	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+// A migration whose operations target schemas other than the Roll's own is
+// run as a single unit across all of the schemas that it targets. Each
+// targeted schema gets its own version schema and its own row for the
+// migration in the state history, and operations run on a connection whose
+// search_path is set to the schema they target.
+
+// forSchema returns a Roll acting on `schemaName` that shares the state,
+// logger and options of `m`. The Roll's connection is opened on first use
+// and is closed when `m` is closed.
+func (m *Roll) forSchema(ctx context.Context, schemaName string) (*Roll, error) {
+	if r, ok := m.schemaRolls[schemaName]; ok {
+		return r, nil
+	}
+
+	conn, err := setupConn(ctx, m.pgURL, schemaName, m.connOpts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect for schema %q: %w", schemaName, err)
+	}
+
+	r := *m
+	r.schema = schemaName
+	r.pgConn = &db.RDB{DB: conn}
+	// A migration that targets several schemas is started as a single unit,
+	// so is always rolled back if its start fails
+	r.resumableStart = false
+	m.schemaRolls[schemaName] = &r
+
+	return &r, nil
+}
+
+// rollsForMigration returns a Roll for each of the schemas targeted by
+// `migration`, keyed by schema name.
+func (m *Roll) rollsForMigration(ctx context.Context, migration *migrations.Migration) (map[string]*Roll, error) {
+	rolls := make(map[string]*Roll)
+	for _, schemaName := range migration.Schemas(m.schema) {
+		r, err := m.forSchema(ctx, schemaName)
+		if err != nil {
+			return nil, err
+		}
+		rolls[schemaName] = r
+	}
+	return rolls, nil
+}
+
+// readSchemas reads the current schema of each of `schemas`, keyed by schema
+// name.
+func (m *Roll) readSchemas(ctx context.Context, schemas []string) (map[string]*schema.Schema, error) {
+	result := make(map[string]*schema.Schema, len(schemas))
+	for _, schemaName := range schemas {
+		s, err := m.state.ReadSchema(ctx, schemaName)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read schema %q: %w", schemaName, err)
+		}
+		result[schemaName] = s
+	}
+	return result, nil
+}
+
+// validateSchemas validates each operation in `migration` against the schema
+// that it targets. The schemas that the migration's foreign keys reference
+// are read too, so that the tables they reference can be validated.
+func (m *Roll) validateSchemas(ctx context.Context, migration *migrations.Migration) error {
+	schemas, err := m.readSchemas(ctx, append(migration.Schemas(m.schema), migration.ReferencedSchemas(m.schema)...))
+	if err != nil {
+		return err
+	}
+	if err := migration.ValidateSchemas(ctx, schemas, m.schema); err != nil {
+		return fmt.Errorf("migration '%s' is invalid: %w", migration.Name, err)
+	}
+	return nil
+}
+
+// startSchemas starts a migration that targets several schemas and backfills
+// the tables that require it in each schema.
+func (m *Roll) startSchemas(ctx context.Context, migration *migrations.Migration, cfg *backfill.Config) error {
+	// Name the schema of every operation so that the migration recorded in the
+	// state can be completed or rolled back from any of the schemas it targets
+	migration = migration.WithDefaultSchema(m.schema)
+
+	jobs, err := m.startSchemasDDLOperations(ctx, migration)
+	if err != nil {
+		return err
+	}
+
+	rolls, err := m.rollsForMigration(ctx, migration)
+	if err != nil {
+		return errors.Join(err, m.rollbackSchemas(ctx, migration))
+	}
+
+	for _, schemaName := range migration.Schemas(m.schema) {
+		if err := rolls[schemaName].runBackfills(ctx, migration, jobs[schemaName], cfg); err != nil {
+			return errors.Join(err, m.rollbackSchemas(ctx, migration))
+		}
+	}
+
+	return nil
+}
+
+// startSchemasDDLOperations performs the DDL operations for a migration that
+// targets several schemas, returning the backfill job for each schema.
+func (m *Roll) startSchemasDDLOperations(ctx context.Context, migration *migrations.Migration) (map[string]*backfill.Job, error) {
+	schemas := migration.Schemas(m.schema)
+
+	for _, schemaName := range schemas {
+		active, err := m.state.IsActiveMigrationPeriod(ctx, schemaName)
+		if err != nil {
+			return nil, err
+		}
+		if active {
+			return nil, fmt.Errorf("a migration for schema %q is already in progress", schemaName)
+		}
+	}
+
+	rolls, err := m.rollsForMigration(ctx, migration)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a new active migration in every schema
+	if err := m.state.StartSchemas(ctx, schemas, migration); err != nil {
+		return nil, fmt.Errorf("unable to start migration: %w", err)
+	}
+
+	// run any BeforeStartDDL hooks
+	if m.migrationHooks.BeforeStartDDL != nil {
+		if err := m.migrationHooks.BeforeStartDDL(m); err != nil {
+			return nil, fmt.Errorf("failed to execute BeforeStartDDL hook: %w", err)
+		}
+	}
+
+	// defer execution of any AfterStartDDL hooks
+	if m.migrationHooks.AfterStartDDL != nil {
+		defer m.migrationHooks.AfterStartDDL(m)
+	}
+
+	newSchemas, err := m.readSchemas(ctx, schemas)
+	if err != nil {
+		return nil, err
+	}
+	schema.LinkSchemas(newSchemas)
+
+	jobs := make(map[string]*backfill.Job, len(schemas))
+	for _, schemaName := range schemas {
+		jobs[schemaName] = backfill.NewJob(schemaName, VersionedSchemaName(schemaName, migration.VersionSchemaName()))
+	}
+
+	// execute operations, each on the connection for the schema it targets
+	for i, op := range migration.Operations {
+		schemaName := migration.OperationSchema(i, m.schema)
+
+		startOp, err := rolls[schemaName].startOperation(ctx, i, op, newSchemas[schemaName])
+		if err != nil {
+			return nil, fmt.Errorf("unable to collect actions for start %q migration: %w", migration.Name, err)
+		}
+		if startOp == nil {
+			continue
+		}
+
+		if err := executeOperationActions(ctx, i, op, startOp.Actions); err != nil {
+			errRollback := m.rollbackSchemas(ctx, migration)
+			if errRollback != nil {
+				return nil, errors.Join(
+					fmt.Errorf("unable to execute start operation of %q: %w", migration.Name, err),
+					fmt.Errorf("unable to roll back failed operation: %w", errRollback),
+				)
+			}
+			return nil, fmt.Errorf("failed to start %q migration, changes rolled back: %w", migration.Name, err)
+		}
+		// refresh schema when the op is isolated and requires a refresh (for example raw sql)
+		if _, ok := op.(migrations.RequiresSchemaRefreshOperation); ok {
+			if isolatedOp, ok := op.(migrations.IsolatedOperation); ok && isolatedOp.IsIsolated() {
+				newSchemas[schemaName], err = m.state.ReadSchema(ctx, schemaName)
+				if err != nil {
+					return nil, fmt.Errorf("unable to refresh schema: %w", err)
+				}
+				schema.LinkSchemas(newSchemas)
+			}
+		}
+		if startOp.BackfillTask != nil {
+			jobs[schemaName].AddTask(startOp.BackfillTask, migration.OperationBackfillOptions(i)...)
+		}
+	}
+
+	// create views for the new version in every schema
+	if !m.disableVersionSchemas {
+		for _, schemaName := range schemas {
+			r := rolls[schemaName]
+			if err := r.ensureViews(ctx, r.pgConn, newSchemas[schemaName], migration); err != nil {
+				return nil, err
+			}
+			m.logger.LogSchemaCreation(migration.VersionSchemaName(), VersionedSchemaName(schemaName, migration.VersionSchemaName()))
+		}
+	}
+
+	return jobs, nil
+}
+
+// completeSchemas completes a migration that targets several schemas
+func (m *Roll) completeSchemas(ctx context.Context, migration *migrations.Migration) error {
+	schemas := migration.Schemas(m.schema)
+
+	rolls, err := m.rollsForMigration(ctx, migration)
+	if err != nil {
+		return err
+	}
+
+	// Drop the old version schema of each schema if there is one
+	for _, schemaName := range schemas {
+		prevVersion, err := m.state.PreviousVersion(ctx, schemaName)
+		if err != nil {
+			return fmt.Errorf("unable to get name of previous version: %w", err)
+		}
+		if prevVersion != nil {
+			versionSchema := VersionedSchemaName(schemaName, *prevVersion)
+			_, err = rolls[schemaName].pgConn.ExecContext(ctx, fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", pq.QuoteIdentifier(versionSchema)))
+			if err != nil {
+				return fmt.Errorf("unable to drop previous version: %w", err)
+			}
+		}
+	}
+
+	currentSchemas, err := m.readSchemas(ctx, schemas)
+	if err != nil {
+		return err
+	}
+
+	// run any BeforeCompleteDDL hooks
+	if m.migrationHooks.BeforeCompleteDDL != nil {
+		if err := m.migrationHooks.BeforeCompleteDDL(m); err != nil {
+			return fmt.Errorf("failed to execute BeforeCompleteDDL hook: %w", err)
+		}
+	}
+
+	// defer execution of any AfterCompleteDDL hooks
+	if m.migrationHooks.AfterCompleteDDL != nil {
+		defer m.migrationHooks.AfterCompleteDDL(m)
+	}
+
+	// the views in each underlying schema that the migration changes are
+	// dropped before its operations complete and recreated afterwards
+	virtualSchemas := make(map[string]*schema.Schema, len(schemas))
+	for _, schemaName := range schemas {
+		virtualSchemas[schemaName], err = rolls[schemaName].previousSchema(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	if err := migration.UpdateVirtualSchemas(ctx, virtualSchemas, m.schema); err != nil {
+		return fmt.Errorf("unable to replay changes to in-memory schemas: %w", err)
+	}
+
+	// collect the actions for each schema separately, as actions are
+	// deduplicated by ID and tables in different schemas may share a name
+	refreshViews := make(map[string]bool)
+	actions := make(map[string][]migrations.DBAction)
+	createViews := make(map[string][]migrations.DBAction)
+	owners := make(map[string]map[string]int)
+	for _, schemaName := range schemas {
+		actions[schemaName], createViews[schemaName] = viewActions(rolls[schemaName].pgConn, currentSchemas[schemaName], virtualSchemas[schemaName])
+		owners[schemaName] = make(map[string]int)
+	}
+	for i, op := range migration.Operations {
+		schemaName := migration.OperationSchema(i, m.schema)
+
+		opActions, err := op.Complete(m.logger, rolls[schemaName].pgConn, currentSchemas[schemaName])
+		if err != nil {
+			return fmt.Errorf("unable to collect actions for complete operation: %w", err)
+		}
+		for _, action := range opActions {
+			owners[schemaName][action.ID()] = i
+		}
+		actions[schemaName] = append(actions[schemaName], opActions...)
+
+		if _, ok := op.(migrations.RequiresSchemaRefreshOperation); ok {
+			refreshViews[schemaName] = true
+		}
+	}
+
+	for _, schemaName := range schemas {
+		actions[schemaName] = append(actions[schemaName], createViews[schemaName]...)
+		if err := executeCompleteActions(ctx, migration, actions[schemaName], owners[schemaName]); err != nil {
+			return fmt.Errorf("unable to execute complete operation in schema %q: %w", schemaName, err)
+		}
+	}
+
+	// recreate views for the new version (if some operations require it, ie SQL)
+	if !m.disableVersionSchemas {
+		for _, schemaName := range schemas {
+			if !refreshViews[schemaName] {
+				continue
+			}
+
+			currentSchema, err := m.state.ReadSchema(ctx, schemaName)
+			if err != nil {
+				return fmt.Errorf("unable to read schema: %w", err)
+			}
+
+			r := rolls[schemaName]
+			if err := r.ensureViews(ctx, r.pgConn, currentSchema, migration); err != nil {
+				return err
+			}
+			m.logger.LogSchemaCreation(migration.VersionSchemaName(), VersionedSchemaName(schemaName, migration.VersionSchemaName()))
+		}
+	}
+
+	// mark as completed in every schema
+	if err := m.state.CompleteSchemas(ctx, schemas, migration.Name); err != nil {
+		return fmt.Errorf("unable to complete migration: %w", err)
+	}
+
+	m.logger.LogMigrationComplete(migration)
+
+	return nil
+}
+
+// rollbackSchemas rolls back a migration that targets several schemas
+func (m *Roll) rollbackSchemas(ctx context.Context, migration *migrations.Migration) error {
+	schemas := migration.Schemas(m.schema)
+
+	rolls, err := m.rollsForMigration(ctx, migration)
+	if err != nil {
+		return err
+	}
+
+	virtualSchemas := make(map[string]*schema.Schema, len(schemas))
+	for _, schemaName := range schemas {
+		// delete the schema and views for the new version
+		versionSchema := VersionedSchemaName(schemaName, migration.VersionSchemaName())
+		_, err = rolls[schemaName].pgConn.ExecContext(ctx, fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", pq.QuoteIdentifier(versionSchema)))
+		if err != nil {
+			return err
+		}
+
+		m.logger.LogSchemaDeletion(migration.Name, versionSchema)
+
+		// get the schema after the previous migration was applied
+		previousMigration, err := m.state.PreviousMigration(ctx, schemaName)
+		if err != nil {
+			return fmt.Errorf("unable to get name of previous version: %w", err)
+		}
+
+		virtualSchemas[schemaName] = schema.New()
+		if previousMigration != nil {
+			virtualSchemas[schemaName], err = m.state.SchemaAfterMigration(ctx, schemaName, *previousMigration)
+			if err != nil {
+				return fmt.Errorf("unable to read schema: %w", err)
+			}
+		}
+	}
+
+	// update the in-memory schemas with the results of applying the migration
+	if err := migration.UpdateVirtualSchemas(ctx, virtualSchemas, m.schema); err != nil {
+		return fmt.Errorf("unable to replay changes to in-memory schema: %w", err)
+	}
+
+	// roll back operations in reverse order
+	for i := len(migration.Operations) - 1; i >= 0; i-- {
+		schemaName := migration.OperationSchema(i, m.schema)
+
+		actions, err := migration.Operations[i].Rollback(m.logger, rolls[schemaName].pgConn, virtualSchemas[schemaName])
+		if err != nil {
+			return fmt.Errorf("unable to collect actions for rollback operation: %w", err)
+		}
+		if err := executeOperationActions(ctx, i, migration.Operations[i], actions); err != nil {
+			return fmt.Errorf("unable to execute rollback operation: %w", err)
+		}
+	}
+
+	// roll back the migration in every schema
+	if err := m.state.RollbackSchemas(ctx, schemas, migration.Name); err != nil {
+		return fmt.Errorf("unable to rollback migration: %w", err)
+	}
+
+	m.logger.LogMigrationRollbackComplete(migration)
+
+	return nil
+}
@@ -0,0 +1,275 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package roll
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+
+	"github.com/xataio/pgroll/pkg/diff"
+	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/schema"
+)
+
+// Squash is a single migration that replaces a range of consecutive
+// migrations
+type Squash struct {
+	// The migration equivalent to the squashed migrations. It takes the name of
+	// the last of them.
+	Migration *migrations.Migration
+
+	// The names of the squashed migrations, in order
+	Squashed []string
+
+	// The files in which the squashed migrations are defined, in order
+	Files []string
+
+	// Differences between the schemas before and after the squashed migrations
+	// that can not be expressed as pgroll operations
+	Unsupported []string
+}
+
+// SquashMigrations builds a single migration that is equivalent to the
+// migrations in `dir` from `from` to `to` inclusive. The migrations are
+// replayed against an in-memory schema and the squashed migration is the
+// difference between the schemas before and after them.
+//
+// If `snapshot` is set, the squashed migration instead creates the whole
+// schema as it is after `to`, in the manner of a baseline. `from` must then
+// be the first migration in `dir`.
+//
+// The schema before `from` is read from the migration history if the
+// database has applied the preceding migration, and is otherwise replayed
+// from the preceding migrations in `dir`. Migrations that run raw SQL can't
+// be replayed and so can't be squashed.
+func (m *Roll) SquashMigrations(ctx context.Context, dir fs.FS, from, to string, snapshot bool) (*Squash, error) {
+	active, err := m.state.IsActiveMigrationPeriod(ctx, m.schema)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, fmt.Errorf("a migration for schema %q is in progress: complete or roll it back before squashing", m.schema)
+	}
+
+	files, err := migrations.CollectFilesFromDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading migration files: %w", err)
+	}
+
+	var migs []*migrations.Migration
+	for _, file := range files {
+		mig, err := migrations.ReadMigration(dir, file)
+		if err != nil {
+			return nil, fmt.Errorf("reading migration file %q: %w", file, err)
+		}
+		migs = append(migs, mig)
+	}
+
+	fromIdx := slices.IndexFunc(migs, func(mig *migrations.Migration) bool { return mig.Name == from })
+	toIdx := slices.IndexFunc(migs, func(mig *migrations.Migration) bool { return mig.Name == to })
+	switch {
+	case fromIdx < 0:
+		return nil, fmt.Errorf("migration %q not found", from)
+	case toIdx < 0:
+		return nil, fmt.Errorf("migration %q not found", to)
+	case fromIdx >= toIdx:
+		return nil, fmt.Errorf("migration %q must come before migration %q", from, to)
+	case snapshot && fromIdx != 0:
+		return nil, fmt.Errorf("a snapshot must start from the first migration, %q", migs[0].Name)
+	}
+
+	squashed := migs[fromIdx : toIdx+1]
+	names := make([]string, 0, len(squashed))
+	for _, mig := range squashed {
+		if err := checkReplayable(mig, m.schema); err != nil {
+			return nil, err
+		}
+		names = append(names, mig.Name)
+	}
+
+	// Refuse to squash migrations that the database has applied only some
+	// of, before any migration files are replaced
+	if err := m.state.CheckSquashable(ctx, m.schema, names); err != nil {
+		return nil, err
+	}
+
+	before, err := m.schemaBefore(ctx, migs, fromIdx)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := cloneSchema(before)
+	if err != nil {
+		return nil, err
+	}
+	for _, mig := range squashed {
+		if err := mig.UpdateVirtualSchema(ctx, after); err != nil {
+			return nil, fmt.Errorf("unable to replay migration %q: %w", mig.Name, err)
+		}
+		completeVirtualSchema(after)
+	}
+
+	if snapshot {
+		before = schema.New()
+	}
+	result := diff.Schemas(before, after)
+
+	sq := &Squash{
+		Migration: &migrations.Migration{
+			Name:       to,
+			Operations: result.Operations,
+		},
+		Files:       files[fromIdx : toIdx+1],
+		Squashed:    names,
+		Unsupported: result.Unsupported,
+	}
+	sq.Migration.Squashes = sq.Squashed
+
+	return sq, nil
+}
+
+// ApplySquash replaces the squashed migrations in the migration history with
+// the squashed migration. It returns false without changing the history if
+// the database has not applied the squashed migrations.
+//
+// Databases whose history is not rewritten still see the squashed migration
+// as applied, as it records the names of the migrations it replaces.
+func (m *Roll) ApplySquash(ctx context.Context, sq *Squash) (bool, error) {
+	return m.state.SquashMigrations(ctx, m.schema, sq.Squashed, sq.Migration)
+}
+
+// schemaBefore returns the schema before the migration at index `idx` of
+// `migs` is applied.
+func (m *Roll) schemaBefore(ctx context.Context, migs []*migrations.Migration, idx int) (*schema.Schema, error) {
+	if idx == 0 {
+		return schema.New(), nil
+	}
+
+	// Prefer the schema recorded in the migration history, which includes
+	// any changes made by raw SQL
+	s, err := m.state.SchemaAfterMigration(ctx, m.schema, migs[idx-1].Name)
+	if err == nil {
+		return s, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("unable to read schema: %w", err)
+	}
+
+	s = schema.New()
+	for _, mig := range migs[:idx] {
+		if err := checkReplayable(mig, m.schema); err != nil {
+			return nil, err
+		}
+		if err := mig.UpdateVirtualSchema(ctx, s); err != nil {
+			return nil, fmt.Errorf("unable to replay migration %q: %w", mig.Name, err)
+		}
+		completeVirtualSchema(s)
+	}
+	return s, nil
+}
+
+// checkReplayable returns an error if the effect of `mig` on the schema can't
+// be replayed in memory.
+func checkReplayable(mig *migrations.Migration, schemaName string) error {
+	if mig.IsMultiSchema(schemaName) {
+		return fmt.Errorf("migration %q targets schemas other than %q and can't be squashed", mig.Name, schemaName)
+	}
+	for _, op := range mig.Operations {
+		if _, ok := op.(*migrations.OpRawSQL); ok {
+			return fmt.Errorf("migration %q runs raw SQL and can't be replayed", mig.Name)
+		}
+	}
+	return nil
+}
+
+// completeVirtualSchema updates a schema that has been updated in memory by
+// the Start phase of migrations to match the schema once the migrations are
+// complete. Deleted tables and columns are removed, and tables and columns
+// take the names they have in the latest version of the schema.
+func completeVirtualSchema(s *schema.Schema) {
+	tableNames := make(map[string]string)
+	columnNames := make(map[string]map[string]string)
+
+	for name, table := range s.Tables {
+		if table.Deleted {
+			delete(s.Tables, name)
+			continue
+		}
+
+		renames := make(map[string]string)
+		for colName, col := range table.Columns {
+			if col.Deleted {
+				delete(table.Columns, colName)
+				continue
+			}
+			renames[col.Name] = colName
+			col.Name = colName
+		}
+
+		tableNames[table.Name] = name
+		columnNames[name] = renames
+		table.Name = name
+	}
+
+	for name, table := range s.Tables {
+		renames := columnNames[name]
+
+		table.PrimaryKey = renameAll(table.PrimaryKey, renames)
+		for _, idx := range table.Indexes {
+			idx.Columns = renameAll(idx.Columns, renames)
+		}
+		for _, uc := range table.UniqueConstraints {
+			uc.Columns = renameAll(uc.Columns, renames)
+		}
+		for _, cc := range table.CheckConstraints {
+			cc.Columns = renameAll(cc.Columns, renames)
+		}
+		for _, ec := range table.ExcludeConstraints {
+			ec.Columns = renameAll(ec.Columns, renames)
+		}
+		for _, fk := range table.ForeignKeys {
+			fk.Columns = renameAll(fk.Columns, renames)
+			if fk.ReferencedSchema != "" {
+				continue
+			}
+			if referenced, ok := tableNames[fk.ReferencedTable]; ok {
+				fk.ReferencedTable = referenced
+				fk.ReferencedColumns = renameAll(fk.ReferencedColumns, columnNames[referenced])
+			}
+		}
+	}
+}
+
+// renameAll returns `names` with each name renamed according to `renames`
+func renameAll(names []string, renames map[string]string) []string {
+	if names == nil {
+		return nil
+	}
+	renamed := make([]string, len(names))
+	for i, name := range names {
+		if to, ok := renames[name]; ok {
+			name = to
+		}
+		renamed[i] = name
+	}
+	return renamed
+}
+
+// cloneSchema returns a deep copy of `s`
+func cloneSchema(s *schema.Schema) (*schema.Schema, error) {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var c schema.Schema
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
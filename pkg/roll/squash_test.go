@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package roll_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/xataio/pgroll/internal/testutils"
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/roll"
+)
+
+func TestSquash(t *testing.T) {
+	t.Parallel()
+
+	fs := fstest.MapFS{
+		"01_create_users.json": &fstest.MapFile{Data: squashTestMigration(t, "01_create_users",
+			&migrations.OpCreateTable{
+				Name: "users",
+				Columns: []migrations.Column{
+					{Name: "id", Type: "serial", Pk: true},
+					{Name: "name", Type: "text"},
+				},
+			})},
+		"02_add_email.json": &fstest.MapFile{Data: squashTestMigration(t, "02_add_email",
+			&migrations.OpAddColumn{
+				Table:  "users",
+				Column: migrations.Column{Name: "email", Type: "text", Nullable: true},
+			})},
+		"03_create_posts.json": &fstest.MapFile{Data: squashTestMigration(t, "03_create_posts",
+			&migrations.OpCreateTable{
+				Name: "posts",
+				Columns: []migrations.Column{
+					{Name: "id", Type: "serial", Pk: true},
+				},
+			})},
+	}
+
+	t.Run("squashed migrations are replaced in the history of a database that applied them", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(m *roll.Roll, _ *sql.DB) {
+			ctx := context.Background()
+
+			// Apply all the migrations
+			for _, file := range []string{"01_create_users.json", "02_add_email.json", "03_create_posts.json"} {
+				mig, err := migrations.ReadMigration(fs, file)
+				require.NoError(t, err)
+				require.NoError(t, m.Start(ctx, mig, backfill.NewConfig()))
+				require.NoError(t, m.Complete(ctx))
+			}
+
+			// Squash the second and third migrations
+			sq, err := m.SquashMigrations(ctx, fs, "02_add_email", "03_create_posts", false)
+			require.NoError(t, err)
+			require.Equal(t, "03_create_posts", sq.Migration.Name)
+			require.Equal(t, []string{"02_add_email", "03_create_posts"}, sq.Squashed)
+			require.Equal(t, []string{"02_add_email.json", "03_create_posts.json"}, sq.Files)
+			require.Empty(t, sq.Unsupported)
+			require.Len(t, sq.Migration.Operations, 2)
+
+			applied, err := m.ApplySquash(ctx, sq)
+			require.NoError(t, err)
+			require.True(t, applied)
+
+			// The directory with the squashed migration is fully applied
+			squashedFS := fstest.MapFS{
+				"01_create_users.json": fs["01_create_users.json"],
+				"03_create_posts.json": &fstest.MapFile{Data: squashTestMigration(t, sq.Migration.Name, sq.Migration.Operations...)},
+			}
+			unapplied, err := m.UnappliedMigrations(ctx, squashedFS)
+			require.NoError(t, err)
+			require.Empty(t, unapplied)
+
+			latest, err := m.State().LatestMigration(ctx, "public")
+			require.NoError(t, err)
+			require.Equal(t, "03_create_posts", *latest)
+		})
+	})
+
+	t.Run("the history of a database that has not applied the squashed migrations is unchanged", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(m *roll.Roll, _ *sql.DB) {
+			ctx := context.Background()
+
+			sq, err := m.SquashMigrations(ctx, fs, "01_create_users", "03_create_posts", true)
+			require.NoError(t, err)
+			require.Len(t, sq.Migration.Operations, 2)
+
+			applied, err := m.ApplySquash(ctx, sq)
+			require.NoError(t, err)
+			require.False(t, applied)
+		})
+	})
+
+	t.Run("a database that applied only some of the squashed migrations can't be squashed", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(m *roll.Roll, _ *sql.DB) {
+			ctx := context.Background()
+
+			for _, file := range []string{"01_create_users.json", "02_add_email.json"} {
+				mig, err := migrations.ReadMigration(fs, file)
+				require.NoError(t, err)
+				require.NoError(t, m.Start(ctx, mig, backfill.NewConfig()))
+				require.NoError(t, m.Complete(ctx))
+			}
+
+			// The squash is refused before any migration files are replaced
+			_, err := m.SquashMigrations(ctx, fs, "02_add_email", "03_create_posts", false)
+			require.ErrorContains(t, err, "only 1 of the 2 squashed migrations have been applied")
+		})
+	})
+
+	t.Run("a database whose history was not rewritten sees the squashed migration as applied", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(m *roll.Roll, _ *sql.DB) {
+			ctx := context.Background()
+
+			for _, file := range []string{"01_create_users.json", "02_add_email.json", "03_create_posts.json"} {
+				mig, err := migrations.ReadMigration(fs, file)
+				require.NoError(t, err)
+				require.NoError(t, m.Start(ctx, mig, backfill.NewConfig()))
+				require.NoError(t, m.Complete(ctx))
+			}
+
+			// Squash the first two migrations without rewriting the history
+			sq, err := m.SquashMigrations(ctx, fs, "01_create_users", "02_add_email", false)
+			require.NoError(t, err)
+			require.Equal(t, []string{"01_create_users", "02_add_email"}, sq.Migration.Squashes)
+
+			squashed, err := json.Marshal(sq.Migration)
+			require.NoError(t, err)
+			squashedFS := fstest.MapFS{
+				"02_add_email.json":    &fstest.MapFile{Data: squashed},
+				"03_create_posts.json": fs["03_create_posts.json"],
+				"04_create_tags.json": &fstest.MapFile{Data: squashTestMigration(t, "04_create_tags",
+					&migrations.OpCreateTable{
+						Name:    "tags",
+						Columns: []migrations.Column{{Name: "id", Type: "serial", Pk: true}},
+					})},
+			}
+
+			// Only the migration after the squashed migrations is unapplied
+			unapplied, err := m.UnappliedMigrations(ctx, squashedFS)
+			require.NoError(t, err)
+			require.Len(t, unapplied, 1)
+			require.Equal(t, "04_create_tags", unapplied[0].Name)
+
+			mismatches, err := m.VerifyMigrations(ctx, squashedFS)
+			require.NoError(t, err)
+			require.Empty(t, mismatches)
+		})
+	})
+
+	t.Run("a database that applied only some of the squashed migrations can't be migrated", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(m *roll.Roll, _ *sql.DB) {
+			ctx := context.Background()
+
+			mig, err := migrations.ReadMigration(fs, "01_create_users.json")
+			require.NoError(t, err)
+			require.NoError(t, m.Start(ctx, mig, backfill.NewConfig()))
+			require.NoError(t, m.Complete(ctx))
+
+			squashed, err := json.Marshal(&migrations.Migration{
+				Name:       "02_add_email",
+				Operations: migrations.Operations{&migrations.OpRawSQL{Up: "SELECT 1"}},
+				Squashes:   []string{"01_create_users", "02_add_email"},
+			})
+			require.NoError(t, err)
+
+			_, err = m.UnappliedMigrations(ctx, fstest.MapFS{
+				"02_add_email.json": &fstest.MapFile{Data: squashed},
+			})
+			require.Error(t, err)
+		})
+	})
+
+	t.Run("a snapshot must start from the first migration", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(m *roll.Roll, _ *sql.DB) {
+			_, err := m.SquashMigrations(context.Background(), fs, "02_add_email", "03_create_posts", true)
+			require.Error(t, err)
+		})
+	})
+}
+
+func squashTestMigration(t *testing.T, name string, ops ...migrations.Operation) []byte {
+	t.Helper()
+
+	bytes, err := json.Marshal(&migrations.Migration{Name: name, Operations: ops})
+	require.NoError(t, err)
+
+	return bytes
+}
@@ -7,9 +7,10 @@ import "context"
 type MigrationStatus string
 
 const (
-	NoneMigrationStatus       MigrationStatus = "No migrations"
-	InProgressMigrationStatus MigrationStatus = "In progress"
-	CompleteMigrationStatus   MigrationStatus = "Complete"
+	NoneMigrationStatus        MigrationStatus = "No migrations"
+	InProgressMigrationStatus  MigrationStatus = "In progress"
+	StartFailedMigrationStatus MigrationStatus = "Start failed"
+	CompleteMigrationStatus    MigrationStatus = "Complete"
 )
 
 // Status describes the current migration status of a database schema.
@@ -44,6 +45,14 @@ func (m *Roll) Status(ctx context.Context, schema string) (*Status, error) {
 		status = NoneMigrationStatus
 	} else if isActive {
 		status = InProgressMigrationStatus
+
+		failed, err := m.hasFailedStart(ctx, schema)
+		if err != nil {
+			return nil, err
+		}
+		if failed {
+			status = StartFailedMigrationStatus
+		}
 	} else {
 		status = CompleteMigrationStatus
 	}
@@ -54,3 +63,19 @@ func (m *Roll) Status(ctx context.Context, schema string) (*Status, error) {
 		Status:  status,
 	}, nil
 }
+
+// hasFailedStart returns true if the start of the active migration in `schema`
+// failed and can be resumed
+func (m *Roll) hasFailedStart(ctx context.Context, schema string) (bool, error) {
+	migration, err := m.State().GetActiveMigration(ctx, schema)
+	if err != nil {
+		return false, err
+	}
+
+	progress, err := m.State().StartProgress(ctx, schema, migration.Name)
+	if err != nil {
+		return false, err
+	}
+
+	return progress != nil && progress.Failed(), nil
+}
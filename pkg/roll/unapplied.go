@@ -9,6 +9,7 @@ import (
 	"sort"
 
 	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/state"
 )
 
 // UnappliedMigrations returns the slice of unapplied migrations from `dir`
@@ -16,22 +17,95 @@ import (
 // returned migrations in order will bring the database up to date with `dir`.
 //
 // If the local order of migrations does not match the order of migrations in
-// the schema history, an `ErrMismatchedMigration` error is returned.
+// the schema history, an `ErrMismatchedMigration` error is returned. A
+// squashed migration matches either its own name or the migrations it
+// replaces, so databases that applied those migrations before they were
+// squashed see the squashed migration as applied.
 func (m *Roll) UnappliedMigrations(ctx context.Context, dir fs.FS) ([]*migrations.RawMigration, error) {
 	history, err := m.State().SchemaHistory(ctx, m.Schema())
 	if err != nil {
 		return nil, fmt.Errorf("reading schema history: %w", err)
 	}
 
+	migsAfterBaseline, files, err := m.migrationsAfterBaseline(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the index of the first local migration that has not been applied to
+	// the database and ensure that the order of migrations in the database
+	// matches the order of migrations in the local directory.
+	var appliedCount, historyCount int
+	for i, m := range migsAfterBaseline {
+		// Stop when we've checked all the migrations in history
+		if historyCount >= len(history) {
+			break
+		}
+
+		squashed, err := squashedInHistory(history[historyCount:], m, files[i])
+		if err != nil {
+			return nil, err
+		}
+		if squashed > 0 {
+			historyCount += squashed
+			appliedCount++
+			continue
+		}
+
+		remoteMigration := history[historyCount].Migration
+		if remoteMigration.Name != m.Name {
+			return nil, &MismatchedMigrationError{
+				Remote: remoteMigration.Name,
+				Local:  m.Name,
+				File:   files[i],
+			}
+		}
+		historyCount++
+		appliedCount++
+	}
+
+	// Return only the migrations that haven't been applied yet
+	return migsAfterBaseline[appliedCount:], nil
+}
+
+// squashedInHistory returns the number of migrations at the start of
+// `history` that are replaced by the squashed migration `local`, defined in
+// `file`. It returns zero if `local` is not a squashed migration or if
+// `history` doesn't start with the first of the migrations it replaces.
+func squashedInHistory(history []state.HistoryEntry, local *migrations.RawMigration, file string) (int, error) {
+	if len(local.Squashes) == 0 || len(history) == 0 || history[0].Migration.Name != local.Squashes[0] {
+		return 0, nil
+	}
+
+	for i, name := range local.Squashes {
+		if i >= len(history) {
+			return 0, fmt.Errorf("migration %q in %q replaces %d migrations, of which only %d have been applied: apply the remaining squashed migrations before migrating past it",
+				local.Name, file, len(local.Squashes), i)
+		}
+		if history[i].Migration.Name != name {
+			return 0, &MismatchedMigrationError{
+				Remote: history[i].Migration.Name,
+				Local:  name,
+				File:   file,
+			}
+		}
+	}
+	return len(local.Squashes), nil
+}
+
+// migrationsAfterBaseline reads the migrations in `dir` that come after the
+// most recent baseline, returning them along with the files in which they
+// are defined.
+func (m *Roll) migrationsAfterBaseline(ctx context.Context, dir fs.FS) ([]*migrations.RawMigration, []string, error) {
 	baseline, err := m.State().LatestBaseline(ctx, m.Schema())
 	if err != nil {
-		return nil, fmt.Errorf("reading baseline: %w", err)
+		return nil, nil, fmt.Errorf("reading baseline: %w", err)
 	}
 
 	// Get all local migration files
 	files, err := migrations.CollectFilesFromDir(dir)
 	if err != nil {
-		return nil, fmt.Errorf("reading migration files: %w", err)
+		return nil, nil, fmt.Errorf("reading migration files: %w", err)
 	}
 
 	baselineName := ""
@@ -49,7 +123,7 @@ func (m *Roll) UnappliedMigrations(ctx context.Context, dir fs.FS) ([]*migration
 		return migration.Name > baselineName
 	})
 	if err != nil {
-		return nil, fmt.Errorf("finding migration after baseline: %w", err)
+		return nil, nil, fmt.Errorf("finding migration after baseline: %w", err)
 	}
 
 	// Read all migrations that come after the baseline
@@ -57,30 +131,10 @@ func (m *Roll) UnappliedMigrations(ctx context.Context, dir fs.FS) ([]*migration
 	for _, file := range files[filesStartIdx:] {
 		migration, err := migrations.ReadRawMigration(dir, file)
 		if err != nil {
-			return nil, fmt.Errorf("reading migration file %q: %w", file, err)
+			return nil, nil, fmt.Errorf("reading migration file %q: %w", file, err)
 		}
 		migsAfterBaseline = append(migsAfterBaseline, migration)
 	}
 
-	// Find the index of the first local migration that has not been applied to
-	// the database and ensure that the order of migrations in the database
-	// matches the order of migrations in the local directory.
-	var appliedCount int
-	for _, m := range migsAfterBaseline {
-		// Stop when we've checked all the migrations in history
-		if appliedCount >= len(history) {
-			break
-		}
-
-		remoteMigration := history[appliedCount].Migration
-		if remoteMigration.Name != m.Name {
-			return nil, fmt.Errorf("%w: remote=%q, local=%q",
-				ErrMismatchedMigration, remoteMigration.Name, m.Name)
-		}
-
-		appliedCount++
-	}
-
-	// Return only the migrations that haven't been applied yet
-	return migsAfterBaseline[appliedCount:], nil
+	return migsAfterBaseline, files[filesStartIdx:], nil
 }
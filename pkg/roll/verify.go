@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package roll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+// MismatchedMigrationError describes a local migration that does not match
+// the migration applied at the same position in the schema history. It wraps
+// `ErrMismatchedMigration`.
+type MismatchedMigrationError struct {
+	// The names of the migration in the schema history and of the local
+	// migration
+	Remote string
+	Local  string
+
+	// The file in which the local migration is defined, if known
+	File string
+
+	// The checksums of the migration recorded in the schema history and of the
+	// local migration, if the migrations have the same name but their contents
+	// differ. LocalChecksum is empty if the local migration can't be parsed.
+	RemoteChecksum string
+	LocalChecksum  string
+}
+
+func (e *MismatchedMigrationError) Error() string {
+	switch {
+	case e.Remote != e.Local:
+		return fmt.Sprintf("%s: remote=%q, local=%q", ErrMismatchedMigration, e.Remote, e.Local)
+	case e.LocalChecksum == "":
+		return fmt.Sprintf("%s: migration %q in %q can't be parsed and was modified after being applied",
+			ErrMismatchedMigration, e.Local, e.File)
+	default:
+		return fmt.Sprintf("%s: migration %q in %q was modified after being applied: checksum=%s, recorded=%s",
+			ErrMismatchedMigration, e.Local, e.File, e.LocalChecksum, e.RemoteChecksum)
+	}
+}
+
+func (e *MismatchedMigrationError) Unwrap() error {
+	return ErrMismatchedMigration
+}
+
+// VerifyMigrations compares the migrations in `dir` with the migrations in the
+// schema history since the most recent baseline, returning an error for each
+// local migration that does not match the applied migration at the same
+// position. A migration matches if it has the same name and, if a checksum was
+// recorded when it was applied, the same checksum.
+//
+// A squashed migration also matches the migrations that it replaces, without
+// comparing checksums. Comparison stops at the first migration whose name does
+// not match, as the migrations after it can't be paired up.
+func (m *Roll) VerifyMigrations(ctx context.Context, dir fs.FS) ([]*MismatchedMigrationError, error) {
+	history, err := m.State().SchemaHistory(ctx, m.Schema())
+	if err != nil {
+		return nil, fmt.Errorf("reading schema history: %w", err)
+	}
+
+	local, files, err := m.migrationsAfterBaseline(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []*MismatchedMigrationError
+	for i, j := 0, 0; j < len(history) && i < len(local); i, j = i+1, j+1 {
+		h := history[j]
+
+		squashed, err := squashedInHistory(history[j:], local[i], files[i])
+		var mismatch *MismatchedMigrationError
+		switch {
+		case errors.As(err, &mismatch):
+			return append(mismatches, mismatch), nil
+		case err != nil:
+			return nil, err
+		case squashed > 0:
+			j += squashed - 1
+			continue
+		}
+
+		if h.Migration.Name != local[i].Name {
+			mismatches = append(mismatches, &MismatchedMigrationError{
+				Remote: h.Migration.Name,
+				Local:  local[i].Name,
+				File:   files[i],
+			})
+			break
+		}
+
+		if h.Checksum == "" {
+			continue
+		}
+
+		var checksum string
+		if mig, err := migrations.ParseMigration(local[i]); err == nil {
+			checksum, err = mig.Checksum(m.Schema())
+			if err != nil {
+				return nil, fmt.Errorf("computing checksum of migration %q: %w", local[i].Name, err)
+			}
+		}
+
+		if checksum != h.Checksum {
+			mismatches = append(mismatches, &MismatchedMigrationError{
+				Remote:         h.Migration.Name,
+				Local:          local[i].Name,
+				File:           files[i],
+				RemoteChecksum: h.Checksum,
+				LocalChecksum:  checksum,
+			})
+		}
+	}
+
+	return mismatches, nil
+}
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package roll_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/xataio/pgroll/internal/testutils"
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/roll"
+)
+
+func TestVerifyMigrations(t *testing.T) {
+	t.Parallel()
+
+	applied := fstest.MapFS{
+		"01_migration_1.json": &fstest.MapFile{Data: []byte(`{"operations": [{"sql": {"up": "SELECT 1"}}]}`)},
+		"02_migration_2.json": &fstest.MapFile{Data: []byte(`{"operations": [{"sql": {"up": "SELECT 2"}}]}`)},
+	}
+
+	applyAll := func(t *testing.T, m *roll.Roll) {
+		t.Helper()
+		ctx := context.Background()
+
+		for _, file := range []string{"01_migration_1.json", "02_migration_2.json"} {
+			mig, err := migrations.ReadMigration(applied, file)
+			require.NoError(t, err)
+			require.NoError(t, m.Start(ctx, mig, backfill.NewConfig()))
+			require.NoError(t, m.Complete(ctx))
+		}
+	}
+
+	t.Run("unmodified migrations match", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(m *roll.Roll, _ *sql.DB) {
+			applyAll(t, m)
+
+			// Reformat one migration and convert the other to YAML
+			local := fstest.MapFS{
+				"01_migration_1.json": &fstest.MapFile{Data: []byte(`{
+					"operations": [
+						{"sql": {"up": "SELECT 1"}}
+					]
+				}`)},
+				"02_migration_2.yaml": &fstest.MapFile{Data: []byte("operations:\n  - sql:\n      up: SELECT 2\n")},
+				"03_migration_3.json": &fstest.MapFile{Data: []byte(`{"operations": [{"sql": {"up": "SELECT 3"}}]}`)},
+			}
+
+			mismatches, err := m.VerifyMigrations(context.Background(), local)
+			require.NoError(t, err)
+			require.Empty(t, mismatches)
+		})
+	})
+
+	t.Run("modified migrations are reported", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(m *roll.Roll, _ *sql.DB) {
+			applyAll(t, m)
+
+			local := fstest.MapFS{
+				"01_migration_1.json": applied["01_migration_1.json"],
+				"02_migration_2.json": &fstest.MapFile{Data: []byte(`{"operations": [{"sql": {"up": "SELECT 42"}}]}`)},
+			}
+
+			mismatches, err := m.VerifyMigrations(context.Background(), local)
+			require.NoError(t, err)
+			require.Len(t, mismatches, 1)
+			require.ErrorIs(t, mismatches[0], roll.ErrMismatchedMigration)
+			require.Equal(t, "02_migration_2", mismatches[0].Local)
+			require.Equal(t, "02_migration_2.json", mismatches[0].File)
+			require.NotEmpty(t, mismatches[0].RemoteChecksum)
+			require.NotEmpty(t, mismatches[0].LocalChecksum)
+			require.NotEqual(t, mismatches[0].RemoteChecksum, mismatches[0].LocalChecksum)
+		})
+	})
+
+	t.Run("migrations applied without a checksum are not checked", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(m *roll.Roll, db *sql.DB) {
+			ctx := context.Background()
+			applyAll(t, m)
+
+			// Migrations applied by older versions of pgroll have no checksum
+			_, err := db.ExecContext(ctx, "UPDATE pgroll.migrations SET checksum = NULL")
+			require.NoError(t, err)
+
+			local := fstest.MapFS{
+				"01_migration_1.json": applied["01_migration_1.json"],
+				"02_migration_2.json": &fstest.MapFile{Data: []byte(`{"operations": [{"sql": {"up": "SELECT 42"}}]}`)},
+			}
+
+			mismatches, err := m.VerifyMigrations(ctx, local)
+			require.NoError(t, err)
+			require.Empty(t, mismatches)
+		})
+	})
+
+	t.Run("migrations out of order are reported", func(t *testing.T) {
+		testutils.WithMigratorAndConnectionToContainer(t, func(m *roll.Roll, _ *sql.DB) {
+			applyAll(t, m)
+
+			local := fstest.MapFS{
+				"01_migration_1.json":  applied["01_migration_1.json"],
+				"01a_migration_1.json": &fstest.MapFile{Data: []byte(`{"operations": [{"sql": {"up": "SELECT 1"}}]}`)},
+				"02_migration_2.json":  applied["02_migration_2.json"],
+			}
+
+			mismatches, err := m.VerifyMigrations(context.Background(), local)
+			require.NoError(t, err)
+			require.Len(t, mismatches, 1)
+			require.Equal(t, "02_migration_2", mismatches[0].Remote)
+			require.Equal(t, "01a_migration_1", mismatches[0].Local)
+		})
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"strings"
 )
 
 // XXX we create a view of the schema with the minimum required for us to
@@ -28,6 +29,52 @@ type Schema struct {
 	Name string `json:"name"`
 	// Tables is a map of virtual table name -> table mapping
 	Tables map[string]*Table `json:"tables"`
+	// Enums is a map of enum type name -> enum type
+	Enums map[string]*Enum `json:"enums,omitempty"`
+	// Views is a map of view name -> view
+	Views map[string]*View `json:"views,omitempty"`
+	// Sequences is a map of sequence name -> sequence. Sequences that
+	// implement identity columns are not included.
+	Sequences map[string]*Sequence `json:"sequences,omitempty"`
+
+	// Others is a map of schema name -> schema of the other schemas that a
+	// migration reads, through which operations reach tables in those schemas
+	Others map[string]*Schema `json:"-"`
+}
+
+// View represents a view in the schema
+type View struct {
+	// Name is the name of the view in postgres
+	Name string `json:"name"`
+
+	// Definition is the SELECT statement that defines the view. It refers to
+	// tables and columns by the names they have in the virtual schema.
+	Definition string `json:"definition"`
+}
+
+// Sequence represents a sequence in the schema
+type Sequence struct {
+	// Name is the name of the sequence in postgres
+	Name string `json:"name"`
+
+	// Type is the data type of the sequence
+	Type string `json:"type"`
+
+	Start     int64 `json:"start"`
+	Increment int64 `json:"increment"`
+	MinValue  int64 `json:"minValue"`
+	MaxValue  int64 `json:"maxValue"`
+	Cache     int64 `json:"cache"`
+	Cycle     bool  `json:"cycle"`
+}
+
+// Enum represents an enum type in the schema
+type Enum struct {
+	// Name is the name of the enum type in postgres
+	Name string `json:"name"`
+
+	// Values are the labels of the enum type, in sort order
+	Values []string `json:"values"`
 }
 
 // Table represents a table in the schema
@@ -62,6 +109,35 @@ type Table struct {
 	// ExcludeConstraints is a map of all exclude constraints defined on the table
 	ExcludeConstraints map[string]*ExcludeConstraint `json:"excludeConstraints"`
 
+	// Partitioning describes how the table is partitioned, if it is a
+	// partitioned table
+	Partitioning *Partitioning `json:"partitioning,omitempty"`
+
+	// PartitionOf is the name of the partitioned table that the table is a
+	// partition of, if it is a partition
+	PartitionOf string `json:"partitionOf,omitempty"`
+
+	// Triggers are the names of the triggers defined on the table, other than
+	// those created by pgroll
+	Triggers []string `json:"triggers,omitempty"`
+
+	// Publications are the names of the publications that the table has been
+	// added to
+	Publications []string `json:"publications,omitempty"`
+
+	// Whether row level security is enabled on the table
+	RowLevelSecurity bool `json:"rowLevelSecurity"`
+
+	// Whether row level security is enforced for the table owner too
+	ForceRowLevelSecurity bool `json:"forceRowLevelSecurity"`
+
+	// Policies is a map of the row level security policies defined on the table
+	Policies map[string]*Policy `json:"policies"`
+
+	// Privileges is a map of role name -> privileges granted to the role on
+	// the table, in lower case. Privileges of the table owner are not included.
+	Privileges map[string][]string `json:"privileges,omitempty"`
+
 	// Whether or not the table has been deleted in the virtual schema
 	Deleted bool `json:"-"`
 }
@@ -89,6 +165,18 @@ type Column struct {
 
 	// Postgres type type, e.g enum, composite, range
 	PostgresType string `json:"postgresType"`
+
+	// Identity is the generation of an identity column, either "ALWAYS" or
+	// "BY DEFAULT", or empty if the column is not an identity column
+	Identity string `json:"identity,omitempty"`
+
+	// Sequence is the name of the sequence owned by the column, such as the
+	// sequence of a serial or identity column
+	Sequence string `json:"sequence,omitempty"`
+
+	// Generated is the generation expression of a stored generated column, or
+	// empty if the column is not a generated column
+	Generated string `json:"generated,omitempty"`
 }
 
 // Index represents an index on a table
@@ -126,6 +214,10 @@ type ForeignKey struct {
 	// The table that the foreign key references
 	ReferencedTable string `json:"referencedTable"`
 
+	// The schema of the table that the foreign key references, if it is not
+	// the schema of the table with the foreign key
+	ReferencedSchema string `json:"referencedSchema,omitempty"`
+
 	// The columns in the referenced table that the foreign key references
 	ReferencedColumns []string `json:"referencedColumns"`
 
@@ -184,6 +276,53 @@ type ExcludeConstraint struct {
 	Definition string `json:"definition"`
 }
 
+// Policy represents a row level security policy on a table
+type Policy struct {
+	// Name is the name of the policy in postgres
+	Name string `json:"name"`
+
+	// Command is the command the policy applies to: ALL, SELECT, INSERT,
+	// UPDATE or DELETE
+	Command string `json:"command"`
+
+	// Permissive is false for restrictive policies
+	Permissive bool `json:"permissive"`
+
+	// Roles are the roles the policy applies to
+	Roles []string `json:"roles"`
+
+	// Using is the expression that rows must satisfy to be visible
+	Using string `json:"using,omitempty"`
+
+	// WithCheck is the expression that new rows must satisfy
+	WithCheck string `json:"withCheck,omitempty"`
+
+	// The columns that the policy expressions refer to
+	Columns []string `json:"columns"`
+}
+
+// Partitioning describes how a partitioned table is partitioned
+type Partitioning struct {
+	// Strategy is the partitioning strategy: range, list or hash
+	Strategy string `json:"strategy"`
+
+	// The columns that make up the partition key
+	Columns []string `json:"columns"`
+
+	// Partitions is a map of the partitions of the table
+	Partitions map[string]*Partition `json:"partitions,omitempty"`
+}
+
+// Partition represents a partition of a partitioned table
+type Partition struct {
+	// Name is the name of the partition in postgres
+	Name string `json:"name"`
+
+	// Bound is the partition bound, for example
+	// `FOR VALUES FROM (1) TO (10)` or `DEFAULT`
+	Bound string `json:"bound"`
+}
+
 // GetTable returns a table by name
 func (s *Schema) GetTable(name string) *Table {
 	if s.Tables == nil {
@@ -196,6 +335,28 @@ func (s *Schema) GetTable(name string) *Table {
 	return t
 }
 
+// GetSchema returns the schema called `name`: `s` itself if `name` is empty
+// or the name of `s`, or else one of the other schemas linked to `s` by
+// LinkSchemas. It returns nil if there is no such schema.
+func (s *Schema) GetSchema(name string) *Schema {
+	if name == "" || name == s.Name {
+		return s
+	}
+	return s.Others[name]
+}
+
+// LinkSchemas links each of `schemas`, keyed by schema name, to the others so
+// that GetSchema can return them. Schemas without a name are given the name
+// they are keyed by.
+func LinkSchemas(schemas map[string]*Schema) {
+	for name, s := range schemas {
+		if s.Name == "" {
+			s.Name = name
+		}
+		s.Others = schemas
+	}
+}
+
 // AddTable adds a table to the schema
 func (s *Schema) AddTable(name string, t *Table) {
 	if s.Tables == nil {
@@ -235,6 +396,108 @@ func (s *Schema) UnRemoveTable(name string) {
 	}
 }
 
+// GetEnum returns an enum type by name
+func (s *Schema) GetEnum(name string) *Enum {
+	if s.Enums == nil {
+		return nil
+	}
+	return s.Enums[name]
+}
+
+// AddEnum adds an enum type to the schema
+func (s *Schema) AddEnum(e *Enum) {
+	if s.Enums == nil {
+		s.Enums = make(map[string]*Enum)
+	}
+	s.Enums[e.Name] = e
+}
+
+// RemoveEnum removes an enum type from the schema
+func (s *Schema) RemoveEnum(name string) {
+	delete(s.Enums, name)
+}
+
+// GetView returns a view by name
+func (s *Schema) GetView(name string) *View {
+	if s.Views == nil {
+		return nil
+	}
+	return s.Views[name]
+}
+
+// AddView adds a view to the schema, replacing any view with the same name
+func (s *Schema) AddView(v *View) {
+	if s.Views == nil {
+		s.Views = make(map[string]*View)
+	}
+	s.Views[v.Name] = v
+}
+
+// RemoveView removes a view from the schema
+func (s *Schema) RemoveView(name string) {
+	delete(s.Views, name)
+}
+
+// GetSequence returns a sequence by name
+func (s *Schema) GetSequence(name string) *Sequence {
+	if s.Sequences == nil {
+		return nil
+	}
+	return s.Sequences[name]
+}
+
+// AddSequence adds a sequence to the schema
+func (s *Schema) AddSequence(seq *Sequence) {
+	if s.Sequences == nil {
+		s.Sequences = make(map[string]*Sequence)
+	}
+	s.Sequences[seq.Name] = seq
+}
+
+// RemoveSequence removes a sequence from the schema
+func (s *Schema) RemoveSequence(name string) {
+	delete(s.Sequences, name)
+}
+
+// SequenceOwner returns the table and column that own the sequence `name`,
+// or nil if the sequence is not owned by a column
+func (s *Schema) SequenceOwner(name string) (*Table, *Column) {
+	for _, table := range s.Tables {
+		for _, column := range table.Columns {
+			if !column.Deleted && column.Sequence == name {
+				return table, column
+			}
+		}
+	}
+	return nil, nil
+}
+
+// EnumIsUsed returns true if any column in the schema has the enum type
+// `name`, or is an array of it
+func (s *Schema) EnumIsUsed(name string) bool {
+	for _, table := range s.Tables {
+		if table.Deleted {
+			continue
+		}
+		for _, column := range table.Columns {
+			if column.Deleted {
+				continue
+			}
+			typ := strings.TrimSuffix(column.Type, "[]")
+			typ = strings.TrimPrefix(typ, s.Name+".")
+			if strings.Trim(typ, `"`) == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasValue returns true if `value` is one of the enum type's labels
+func (e *Enum) HasValue(value string) bool {
+	return slices.Contains(e.Values, value)
+}
+
 // GetColumn returns a column by name
 func (t *Table) GetColumn(name string) *Column {
 	if t.Columns == nil {
@@ -300,6 +563,9 @@ func (t *Table) RenameConstraintColumns(from, to string) {
 	for _, fk := range t.ForeignKeys {
 		updateColumns(fk.Columns)
 	}
+	for _, p := range t.Policies {
+		updateColumns(p.Columns)
+	}
 }
 
 // GetPrimaryKey returns the columns that make up the primary key
@@ -350,6 +616,51 @@ func (t *Table) PhysicalColumnNamesFor(columnNames ...string) []string {
 	return physicalNames
 }
 
+// GetPartition returns a partition of the table by name, or nil if the table
+// is not partitioned or has no such partition
+func (t *Table) GetPartition(name string) *Partition {
+	if t.Partitioning == nil {
+		return nil
+	}
+	return t.Partitioning.Partitions[name]
+}
+
+// AddPartition adds a partition to the partitioned table
+func (t *Table) AddPartition(p *Partition) {
+	if t.Partitioning.Partitions == nil {
+		t.Partitioning.Partitions = make(map[string]*Partition)
+	}
+	t.Partitioning.Partitions[p.Name] = p
+}
+
+// RemovePartition removes a partition from the partitioned table
+func (t *Table) RemovePartition(name string) {
+	if t.Partitioning != nil {
+		delete(t.Partitioning.Partitions, name)
+	}
+}
+
+// GetPolicy returns a row level security policy of the table by name
+func (t *Table) GetPolicy(name string) *Policy {
+	if t.Policies == nil {
+		return nil
+	}
+	return t.Policies[name]
+}
+
+// AddPolicy adds a row level security policy to the table
+func (t *Table) AddPolicy(p *Policy) {
+	if t.Policies == nil {
+		t.Policies = make(map[string]*Policy)
+	}
+	t.Policies[p.Name] = p
+}
+
+// RemovePolicy removes a row level security policy from the table
+func (t *Table) RemovePolicy(name string) {
+	delete(t.Policies, name)
+}
+
 // Make the Schema struct implement the driver.Valuer interface. This method
 // simply returns the JSON-encoded representation of the struct.
 func (s Schema) Value() (driver.Value, error) {
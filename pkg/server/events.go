@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType identifies the kind of progress event
+type EventType string
+
+const (
+	EventJobStarted       EventType = "job_started"
+	EventJobSucceeded     EventType = "job_succeeded"
+	EventJobFailed        EventType = "job_failed"
+	EventBackfillProgress EventType = "backfill_progress"
+)
+
+// Event describes progress made by a job. Events are streamed to clients of
+// the `/v1/events` endpoint.
+type Event struct {
+	Type   EventType `json:"type"`
+	Time   time.Time `json:"time"`
+	Schema string    `json:"schema"`
+	JobID  string    `json:"job_id"`
+
+	// The job's action and migration, set for job events
+	Action    Action `json:"action,omitempty"`
+	Migration string `json:"migration,omitempty"`
+
+	// The error that caused the job to fail, set for failure events
+	Error string `json:"error,omitempty"`
+
+	// The table being backfilled and its progress, set for backfill events
+	Table string `json:"table,omitempty"`
+	Done  int64  `json:"done,omitempty"`
+	Total int64  `json:"total,omitempty"`
+}
+
+// subscriberBuffer is the number of events buffered for each subscriber.
+// Events are dropped for subscribers that fall further behind than this.
+const subscriberBuffer = 256
+
+// broker fans out events to subscribers
+type broker struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+func newBroker() *broker {
+	return &broker{subscribers: make(map[chan Event]struct{})}
+}
+
+// subscribe returns a channel that receives all published events, and a
+// function that unsubscribes it.
+func (b *broker) subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// publish sends the event to all subscribers without blocking
+func (b *broker) publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
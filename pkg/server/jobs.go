@@ -0,0 +1,214 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+// Action is the migration action performed by a job
+type Action string
+
+const (
+	ActionStart    Action = "start"
+	ActionComplete Action = "complete"
+	ActionRollback Action = "rollback"
+)
+
+// JobState is the state of a job
+type JobState string
+
+const (
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+)
+
+// Job describes a migration action run by the server in the background
+type Job struct {
+	ID        string   `json:"id"`
+	Schema    string   `json:"schema"`
+	Action    Action   `json:"action"`
+	Migration string   `json:"migration,omitempty"`
+	State     JobState `json:"state"`
+	Error     string   `json:"error,omitempty"`
+
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// Backfill progress of each table, keyed by table name
+	Backfill map[string]TableProgress `json:"backfill,omitempty"`
+}
+
+// TableProgress is the backfill progress of a table. `Done` may exceed
+// `Total`, which is an estimate.
+type TableProgress struct {
+	Done  int64 `json:"done"`
+	Total int64 `json:"total"`
+}
+
+// schemaRunner runs jobs against a single schema, one at a time
+type schemaRunner struct {
+	schema string
+	roll   Roller
+
+	mu  sync.Mutex
+	job *Job
+}
+
+var jobSeq atomic.Int64
+
+// ErrJobRunning is returned when a job is submitted for a schema that is
+// already running one.
+type ErrJobRunning struct {
+	Job Job
+}
+
+func (e ErrJobRunning) Error() string {
+	return fmt.Sprintf("job %s (%s) is already running for schema %q", e.Job.ID, e.Job.Action, e.Job.Schema)
+}
+
+// currentJob returns a copy of the running or most recently finished job, if
+// any.
+func (r *schemaRunner) currentJob() *Job {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.job == nil {
+		return nil
+	}
+	return r.job.copy()
+}
+
+// submit starts `action` in the background and returns a copy of the new
+// job, or an ErrJobRunning error if a job is already running for the schema.
+func (r *schemaRunner) submit(ctx context.Context, s *Server, action Action, migration *migrations.Migration, complete bool) (*Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.job != nil && r.job.State == JobRunning {
+		return nil, ErrJobRunning{Job: *r.job.copy()}
+	}
+
+	job := &Job{
+		ID:        strconv.FormatInt(jobSeq.Add(1), 10),
+		Schema:    r.schema,
+		Action:    action,
+		State:     JobRunning,
+		StartedAt: time.Now().UTC(),
+	}
+	if migration != nil {
+		job.Migration = migration.Name
+	}
+	r.job = job
+	s.publish(job.event(EventJobStarted))
+
+	s.jobs.Add(1)
+	go func() {
+		defer s.jobs.Done()
+		err := r.run(ctx, s, job, migration, complete)
+		r.finish(s, job, err)
+	}()
+
+	return job.copy(), nil
+}
+
+func (r *schemaRunner) run(ctx context.Context, s *Server, job *Job, migration *migrations.Migration, complete bool) error {
+	switch job.Action {
+	case ActionStart:
+		cfg := s.backfillConfig()
+		cfg.AddCallback(func(table string, done, total int64) {
+			r.progress(s, job, table, done, total)
+		})
+		if err := r.roll.Start(ctx, migration, cfg); err != nil {
+			return err
+		}
+		if complete {
+			return r.roll.Complete(ctx)
+		}
+		return nil
+	case ActionComplete:
+		return r.roll.Complete(ctx)
+	case ActionRollback:
+		// A rollback is not cancelled when the server stops, so that it isn't
+		// left half done
+		return r.roll.Rollback(context.WithoutCancel(ctx))
+	default:
+		return fmt.Errorf("unknown action %q", job.Action)
+	}
+}
+
+func (r *schemaRunner) progress(s *Server, job *Job, table string, done, total int64) {
+	r.mu.Lock()
+	if job.Backfill == nil {
+		job.Backfill = make(map[string]TableProgress)
+	}
+	job.Backfill[table] = TableProgress{Done: done, Total: total}
+	event := job.event(EventBackfillProgress)
+	r.mu.Unlock()
+
+	event.Table = table
+	event.Done = done
+	event.Total = total
+	s.publish(event)
+}
+
+func (r *schemaRunner) finish(s *Server, job *Job, err error) {
+	r.mu.Lock()
+	now := time.Now().UTC()
+	job.FinishedAt = &now
+	eventType := EventJobSucceeded
+	job.State = JobSucceeded
+	if err != nil {
+		eventType = EventJobFailed
+		job.State = JobFailed
+		job.Error = err.Error()
+	}
+	event := job.event(eventType)
+	r.mu.Unlock()
+
+	s.publish(event)
+}
+
+// copy returns a deep copy of the job. The caller must hold the runner's lock.
+func (j *Job) copy() *Job {
+	c := *j
+	if j.Backfill != nil {
+		c.Backfill = make(map[string]TableProgress, len(j.Backfill))
+		for k, v := range j.Backfill {
+			c.Backfill[k] = v
+		}
+	}
+	if j.FinishedAt != nil {
+		t := *j.FinishedAt
+		c.FinishedAt = &t
+	}
+	return &c
+}
+
+// event returns an event of type `t` for the job. The caller must hold the
+// runner's lock.
+func (j *Job) event(t EventType) Event {
+	return Event{
+		Type:      t,
+		Time:      time.Now().UTC(),
+		Schema:    j.Schema,
+		JobID:     j.ID,
+		Action:    j.Action,
+		Migration: j.Migration,
+		Error:     j.Error,
+	}
+}
+
+// defaultBackfillConfig is used when the server is not given a backfill
+// configuration
+func defaultBackfillConfig() *backfill.Config {
+	return backfill.NewConfig()
+}
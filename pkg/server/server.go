@@ -0,0 +1,424 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package server implements an HTTP JSON API to control and observe pgroll
+// migrations in one or more schemas.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/roll"
+)
+
+// Roller is the subset of the methods of *roll.Roll used by the server to
+// manage migrations in a single schema
+type Roller interface {
+	Schema() string
+	Start(ctx context.Context, migration *migrations.Migration, cfg *backfill.Config) error
+	Complete(ctx context.Context) error
+	Rollback(ctx context.Context) error
+	Status(ctx context.Context, schema string) (*roll.Status, error)
+	UnappliedMigrations(ctx context.Context, dir fs.FS) ([]*migrations.RawMigration, error)
+}
+
+var _ Roller = (*roll.Roll)(nil)
+
+// Server serves the pgroll HTTP API
+type Server struct {
+	ctx            context.Context
+	token          string
+	runners        map[string]*schemaRunner
+	schemas        []string
+	migrationsDir  fs.FS
+	backfillConfig func() *backfill.Config
+	events         *broker
+	mux            *http.ServeMux
+
+	// jobs tracks the jobs running in the background
+	jobs sync.WaitGroup
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithMigrationsDir sets the directory from which migrations are read when
+// they are started by name, and from which unapplied migrations are listed.
+func WithMigrationsDir(dir fs.FS) Option {
+	return func(s *Server) {
+		s.migrationsDir = dir
+	}
+}
+
+// WithBackfillConfig sets the function used to create the backfill
+// configuration for each migration started by the server.
+func WithBackfillConfig(fn func() *backfill.Config) Option {
+	return func(s *Server) {
+		s.backfillConfig = fn
+	}
+}
+
+// New creates a server that manages migrations using `rollers`, one for each
+// schema. Jobs run in the background with `ctx`, so that they are not
+// cancelled when the request that started them finishes. All requests other
+// than health checks must present `token` as a bearer token.
+func New(ctx context.Context, token string, rollers []Roller, opts ...Option) (*Server, error) {
+	if token == "" {
+		return nil, errors.New("an API token is required")
+	}
+	if len(rollers) == 0 {
+		return nil, errors.New("at least one schema is required")
+	}
+
+	s := &Server{
+		ctx:            ctx,
+		token:          token,
+		runners:        make(map[string]*schemaRunner, len(rollers)),
+		backfillConfig: defaultBackfillConfig,
+		events:         newBroker(),
+	}
+	for _, r := range rollers {
+		if _, ok := s.runners[r.Schema()]; ok {
+			return nil, fmt.Errorf("schema %q is configured more than once", r.Schema())
+		}
+		s.runners[r.Schema()] = &schemaRunner{schema: r.Schema(), roll: r}
+		s.schemas = append(s.schemas, r.Schema())
+	}
+	slices.Sort(s.schemas)
+
+	for _, o := range opts {
+		o(s)
+	}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("GET /healthz", s.handleHealth)
+	s.mux.Handle("GET /v1/schemas", s.authenticated(s.handleListSchemas))
+	s.mux.Handle("GET /v1/schemas/{schema}/status", s.authenticated(s.handleStatus))
+	s.mux.Handle("GET /v1/schemas/{schema}/job", s.authenticated(s.handleJob))
+	s.mux.Handle("GET /v1/schemas/{schema}/migrations/unapplied", s.authenticated(s.handleUnapplied))
+	s.mux.Handle("POST /v1/schemas/{schema}/start", s.authenticated(s.handleStart))
+	s.mux.Handle("POST /v1/schemas/{schema}/complete", s.authenticated(s.handleAction(ActionComplete)))
+	s.mux.Handle("POST /v1/schemas/{schema}/rollback", s.authenticated(s.handleAction(ActionRollback)))
+	s.mux.Handle("GET /v1/events", s.authenticated(s.handleEvents))
+
+	return s, nil
+}
+
+// Wait waits for the jobs running in the background to finish, or until
+// `ctx` is done. Cancelling the context the server was created with cancels
+// the running jobs, except for rollbacks, which always run to completion.
+func (s *Server) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.jobs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("running jobs did not finish: %w", ctx.Err())
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// SchemaStatus is the status of a schema, including the job the server is
+// running, or last ran, against it
+type SchemaStatus struct {
+	roll.Status
+	Job *Job `json:"job,omitempty"`
+}
+
+// StartRequest is the body of a request to start a migration. Either
+// `Migration` is set, or the migration named `Name` is read from the
+// server's migrations directory.
+type StartRequest struct {
+	// The name of the migration
+	Name string `json:"name"`
+
+	// The migration to start, in the same format as a migration file
+	Migration *migrations.RawMigration `json:"migration,omitempty"`
+
+	// Whether to complete the migration after it has started
+	Complete bool `json:"complete"`
+}
+
+// UnappliedMigration is a migration in the server's migrations directory that
+// has not been applied
+type UnappliedMigration struct {
+	Name          string          `json:"name"`
+	VersionSchema string          `json:"version_schema,omitempty"`
+	Operations    json.RawMessage `json:"operations"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+	Job   *Job   `json:"job,omitempty"`
+}
+
+func (s *Server) publish(e Event) {
+	s.events.publish(e)
+}
+
+func (s *Server) authenticated(h http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="pgroll"`)
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid API token"))
+			return
+		}
+		h(w, r)
+	})
+}
+
+func (s *Server) runner(w http.ResponseWriter, r *http.Request) (*schemaRunner, bool) {
+	runner, ok := s.runners[r.PathValue("schema")]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("schema %q is not managed by this server", r.PathValue("schema")))
+	}
+	return runner, ok
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleListSchemas(w http.ResponseWriter, r *http.Request) {
+	statuses := make([]SchemaStatus, 0, len(s.schemas))
+	for _, schema := range s.schemas {
+		status, err := s.status(r.Context(), s.runners[schema])
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		statuses = append(statuses, *status)
+	}
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	runner, ok := s.runner(w, r)
+	if !ok {
+		return
+	}
+	status, err := s.status(r.Context(), runner)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) status(ctx context.Context, runner *schemaRunner) (*SchemaStatus, error) {
+	status, err := runner.roll.Status(ctx, runner.schema)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read status of schema %q: %w", runner.schema, err)
+	}
+	return &SchemaStatus{Status: *status, Job: runner.currentJob()}, nil
+}
+
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	runner, ok := s.runner(w, r)
+	if !ok {
+		return
+	}
+	job := runner.currentJob()
+	if job == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no job has run for schema %q", runner.schema))
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (s *Server) handleUnapplied(w http.ResponseWriter, r *http.Request) {
+	runner, ok := s.runner(w, r)
+	if !ok {
+		return
+	}
+	if s.migrationsDir == nil {
+		writeError(w, http.StatusNotFound, errors.New("the server has no migrations directory"))
+		return
+	}
+
+	raws, err := runner.roll.UnappliedMigrations(r.Context(), s.migrationsDir)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	unapplied := make([]UnappliedMigration, 0, len(raws))
+	for _, raw := range raws {
+		unapplied = append(unapplied, UnappliedMigration{
+			Name:          raw.Name,
+			VersionSchema: raw.VersionSchema,
+			Operations:    raw.Operations,
+		})
+	}
+	writeJSON(w, http.StatusOK, unapplied)
+}
+
+func (s *Server) handleStart(w http.ResponseWriter, r *http.Request) {
+	runner, ok := s.runner(w, r)
+	if !ok {
+		return
+	}
+
+	var req StartRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	migration, err := s.requestedMigration(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.submit(w, runner, ActionStart, migration, req.Complete)
+}
+
+// requestedMigration returns the migration to start for `req`
+func (s *Server) requestedMigration(req StartRequest) (*migrations.Migration, error) {
+	if req.Name == "" {
+		return nil, errors.New("the migration name is required")
+	}
+
+	raw := req.Migration
+	if raw == nil {
+		if s.migrationsDir == nil {
+			return nil, errors.New("the migration is required as the server has no migrations directory")
+		}
+		var err error
+		raw, err = s.readMigration(req.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	raw.Name = req.Name
+
+	migration, err := migrations.ParseMigration(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid migration %q: %w", req.Name, err)
+	}
+	return migration, nil
+}
+
+// readMigration reads the migration named `name` from the migrations
+// directory
+func (s *Server) readMigration(name string) (*migrations.RawMigration, error) {
+	files, err := migrations.CollectFilesFromDir(s.migrationsDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations directory: %w", err)
+	}
+	for _, file := range files {
+		if strings.TrimSuffix(file, path.Ext(file)) == name {
+			return migrations.ReadRawMigration(s.migrationsDir, file)
+		}
+	}
+	return nil, fmt.Errorf("migration %q not found in the migrations directory", name)
+}
+
+func (s *Server) handleAction(action Action) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		runner, ok := s.runner(w, r)
+		if !ok {
+			return
+		}
+		s.submit(w, runner, action, nil, false)
+	}
+}
+
+func (s *Server) submit(w http.ResponseWriter, runner *schemaRunner, action Action, migration *migrations.Migration, complete bool) {
+	job, err := runner.submit(s.ctx, s, action, migration, complete)
+	if err != nil {
+		var running ErrJobRunning
+		if errors.As(err, &running) {
+			writeJSON(w, http.StatusConflict, errorResponse{Error: err.Error(), Job: &running.Job})
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// handleEvents streams events to the client as server-sent events until the
+// client disconnects or the server shuts down. Events can be filtered to a
+// single schema with the `schema` query parameter.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	schema := r.URL.Query().Get("schema")
+	if schema != "" {
+		if _, ok := s.runners[schema]; !ok {
+			writeError(w, http.StatusNotFound, fmt.Errorf("schema %q is not managed by this server", schema))
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+
+	events, unsubscribe := s.events.subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.ctx.Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if schema != "" && e.Schema != schema {
+				continue
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,346 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package server_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xataio/pgroll/pkg/backfill"
+	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/roll"
+	"github.com/xataio/pgroll/pkg/server"
+)
+
+const token = "secret"
+
+func TestAuthentication(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer(t, []server.Roller{&fakeRoller{schema: "public"}})
+
+	tests := map[string]struct {
+		path   string
+		header string
+		status int
+	}{
+		"health checks are not authenticated": {path: "/healthz", status: http.StatusOK},
+		"missing token":                       {path: "/v1/schemas", status: http.StatusUnauthorized},
+		"invalid token":                       {path: "/v1/schemas", header: "Bearer wrong", status: http.StatusUnauthorized},
+		"valid token":                         {path: "/v1/schemas", header: "Bearer " + token, status: http.StatusOK},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+tc.path, nil)
+			require.NoError(t, err)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tc.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer(t, []server.Roller{&fakeRoller{schema: "public"}, &fakeRoller{schema: "other"}})
+
+	var statuses []server.SchemaStatus
+	resp := do(t, srv, http.MethodGet, "/v1/schemas", nil, &statuses)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "other", statuses[0].Schema)
+	assert.Equal(t, "public", statuses[1].Schema)
+
+	var status server.SchemaStatus
+	resp = do(t, srv, http.MethodGet, "/v1/schemas/public/status", nil, &status)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, roll.CompleteMigrationStatus, status.Status.Status)
+
+	resp = do(t, srv, http.MethodGet, "/v1/schemas/unknown/status", nil, nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestStartMigration(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	roller := &fakeRoller{schema: "public", block: release}
+	srv := newTestServer(t, []server.Roller{roller})
+
+	events := subscribe(t, srv, "public")
+
+	var job server.Job
+	resp := do(t, srv, http.MethodPost, "/v1/schemas/public/start", map[string]any{
+		"name":     "01_create_table",
+		"complete": true,
+		"migration": map[string]any{
+			"operations": []any{
+				map[string]any{"create_table": map[string]any{
+					"name":    "items",
+					"columns": []any{map[string]any{"name": "id", "type": "int", "pk": true}},
+				}},
+			},
+		},
+	}, &job)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Equal(t, server.ActionStart, job.Action)
+	assert.Equal(t, "01_create_table", job.Migration)
+	assert.Equal(t, server.JobRunning, job.State)
+
+	// A second job can't be started while the first is running
+	resp = do(t, srv, http.MethodPost, "/v1/schemas/public/rollback", nil, nil)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	close(release)
+
+	assert.Equal(t, server.EventJobStarted, (<-events).Type)
+	progress := <-events
+	assert.Equal(t, server.EventBackfillProgress, progress.Type)
+	assert.Equal(t, "items", progress.Table)
+	assert.Equal(t, int64(10), progress.Total)
+	assert.Equal(t, server.EventJobSucceeded, (<-events).Type)
+
+	resp = do(t, srv, http.MethodGet, "/v1/schemas/public/job", nil, &job)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, server.JobSucceeded, job.State)
+	assert.Equal(t, server.TableProgress{Done: 10, Total: 10}, job.Backfill["items"])
+	assert.NotNil(t, job.FinishedAt)
+
+	assert.Equal(t, []string{"start 01_create_table", "complete"}, roller.calls())
+}
+
+func TestStartMigrationFromDirectory(t *testing.T) {
+	t.Parallel()
+
+	roller := &fakeRoller{schema: "public", fail: errors.New("boom")}
+	srv := newTestServer(t, []server.Roller{roller}, server.WithMigrationsDir(fstest.MapFS{
+		"01_create_table.yaml": &fstest.MapFile{Data: []byte("operations:\n  - drop_table:\n      name: items\n")},
+	}))
+
+	events := subscribe(t, srv, "")
+
+	resp := do(t, srv, http.MethodPost, "/v1/schemas/public/start", map[string]any{"name": "02_missing"}, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = do(t, srv, http.MethodPost, "/v1/schemas/public/start", map[string]any{"name": "01_create_table"}, nil)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	assert.Equal(t, server.EventJobStarted, (<-events).Type)
+	failed := <-events
+	assert.Equal(t, server.EventJobFailed, failed.Type)
+	assert.Equal(t, "boom", failed.Error)
+
+	var unapplied []server.UnappliedMigration
+	resp = do(t, srv, http.MethodGet, "/v1/schemas/public/migrations/unapplied", nil, &unapplied)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, unapplied, 1)
+	assert.Equal(t, "01_create_table", unapplied[0].Name)
+}
+
+func TestShutdownWaitsForRollback(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	roller := &fakeRoller{schema: "public", block: release}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s, err := server.New(ctx, token, []server.Roller{roller})
+	require.NoError(t, err)
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	resp := do(t, srv, http.MethodPost, "/v1/schemas/public/rollback", nil, nil)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	// Stopping the server doesn't cancel the rollback, and the server waits
+	// for it to finish
+	cancel()
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer waitCancel()
+	assert.ErrorIs(t, s.Wait(waitCtx), context.DeadlineExceeded)
+
+	close(release)
+	require.NoError(t, s.Wait(context.Background()))
+
+	var job server.Job
+	resp = do(t, srv, http.MethodGet, "/v1/schemas/public/job", nil, &job)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, server.JobSucceeded, job.State)
+}
+
+func TestNewRequiresToken(t *testing.T) {
+	t.Parallel()
+
+	_, err := server.New(context.Background(), "", []server.Roller{&fakeRoller{schema: "public"}})
+	assert.Error(t, err)
+
+	_, err = server.New(context.Background(), token, []server.Roller{&fakeRoller{schema: "public"}, &fakeRoller{schema: "public"}})
+	assert.Error(t, err)
+}
+
+func newTestServer(t *testing.T, rollers []server.Roller, opts ...server.Option) *httptest.Server {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s, err := server.New(ctx, token, rollers, opts...)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(s)
+	t.Cleanup(func() {
+		cancel()
+		srv.Close()
+	})
+	return srv
+}
+
+func do(t *testing.T, srv *httptest.Server, method, path string, body, out any) *http.Response {
+	t.Helper()
+
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		r = strings.NewReader(string(b))
+	}
+
+	req, err := http.NewRequest(method, srv.URL+path, r)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	if out != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp
+}
+
+// subscribe returns a channel of the events streamed by the server
+func subscribe(t *testing.T, srv *httptest.Server, schema string) <-chan server.Event {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/v1/events?schema="+schema, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	events := make(chan server.Event, 16)
+	go func() {
+		defer resp.Body.Close()
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			var e server.Event
+			if err := json.Unmarshal([]byte(data), &e); err == nil {
+				events <- e
+			}
+		}
+	}()
+
+	// The server subscribes before it sends the response headers, so no
+	// events are missed
+	return events
+}
+
+// fakeRoller records the calls made to it. Migrations started with it
+// backfill a single table of 10 rows.
+type fakeRoller struct {
+	schema string
+	block  chan struct{}
+	fail   error
+
+	mu  sync.Mutex
+	log []string
+}
+
+func (f *fakeRoller) Schema() string { return f.schema }
+
+func (f *fakeRoller) Start(ctx context.Context, m *migrations.Migration, cfg *backfill.Config) error {
+	f.record("start " + m.Name)
+	if f.block != nil {
+		<-f.block
+	}
+	if f.fail != nil {
+		return f.fail
+	}
+	for _, cb := range cfg.Callbacks() {
+		cb("items", 10, 10)
+	}
+	return nil
+}
+
+func (f *fakeRoller) Complete(ctx context.Context) error {
+	f.record("complete")
+	return nil
+}
+
+func (f *fakeRoller) Rollback(ctx context.Context) error {
+	f.record("rollback")
+	if f.block != nil {
+		<-f.block
+	}
+	return ctx.Err()
+}
+
+func (f *fakeRoller) Status(ctx context.Context, schema string) (*roll.Status, error) {
+	return &roll.Status{Schema: schema, Version: "01_create_table", Status: roll.CompleteMigrationStatus}, nil
+}
+
+func (f *fakeRoller) UnappliedMigrations(ctx context.Context, dir fs.FS) ([]*migrations.RawMigration, error) {
+	files, err := migrations.CollectFilesFromDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var raws []*migrations.RawMigration
+	for _, file := range files {
+		raw, err := migrations.ReadRawMigration(dir, file)
+		if err != nil {
+			return nil, err
+		}
+		raws = append(raws, raw)
+	}
+	return raws, nil
+}
+
+func (f *fakeRoller) record(call string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.log = append(f.log, call)
+}
+
+func (f *fakeRoller) calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.log...)
+}
@@ -13,7 +13,7 @@ import (
 
 const (
 	PlaceHolderColumnName = "placeholder"
-	PlaceHolderSQL        = "TODO: Implement SQL data migration"
+	PlaceHolderSQL        = migrations.PlaceHolderSQL
 )
 
 // convertAlterTableStmt converts an ALTER TABLE statement to pgroll operations.
@@ -37,6 +37,10 @@ func Convert(sql string) (migrations.Operations, error) {
 			ops, err = convertDropStatement(node.DropStmt)
 		case *pgq.Node_IndexStmt:
 			ops, err = convertCreateIndexStmt(node.IndexStmt)
+		case *pgq.Node_CreateEnumStmt:
+			ops, err = convertCreateEnumStmt(node.CreateEnumStmt)
+		case *pgq.Node_AlterEnumStmt:
+			ops, err = convertAlterEnumStmt(node.AlterEnumStmt)
 		default:
 			// SQL statement cannot be transformed to pgroll operation
 			// so we will use raw SQL operation
@@ -126,10 +126,10 @@ SECURITY DEFINER`,
 			expectedErr: false,
 		},
 		"multiple unknown DDL statements": {
-			sql: "CREATE TYPE t1 AS ENUM ('a', 'b'); CREATE DOMAIN d1 AS TEXT; CREATE SCHEMA s1; CREATE EXTENSION e1;",
+			sql: "CREATE TYPE t1 AS (a int, b text); CREATE DOMAIN d1 AS TEXT; CREATE SCHEMA s1; CREATE EXTENSION e1;",
 			expectedOps: migrations.Operations{
 				&migrations.OpRawSQL{
-					Up: "CREATE TYPE t1 AS ENUM ('a', 'b')",
+					Up: "CREATE TYPE t1 AS (a int, b text)",
 				},
 				&migrations.OpRawSQL{
 					Up: "CREATE DOMAIN d1 AS TEXT",
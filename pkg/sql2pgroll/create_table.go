@@ -63,15 +63,51 @@ func convertCreateStmt(stmt *pgq.CreateStmt) (migrations.Operations, error) {
 		}
 	}
 
+	var partitionBy *migrations.PartitionBy
+	if stmt.GetPartspec() != nil {
+		partitionBy = convertPartitionSpec(stmt.GetPartspec())
+	}
+
 	return migrations.Operations{
 		&migrations.OpCreateTable{
 			Name:        getQualifiedRelationName(stmt.GetRelation()),
 			Columns:     columns,
 			Constraints: constraints,
+			PartitionBy: partitionBy,
 		},
 	}, nil
 }
 
+var partitionStrategies = map[pgq.PartitionStrategy]migrations.PartitionByStrategy{
+	pgq.PartitionStrategy_PARTITION_STRATEGY_LIST:  migrations.PartitionByStrategyList,
+	pgq.PartitionStrategy_PARTITION_STRATEGY_RANGE: migrations.PartitionByStrategyRange,
+	pgq.PartitionStrategy_PARTITION_STRATEGY_HASH:  migrations.PartitionByStrategyHash,
+}
+
+// convertPartitionSpec converts the PARTITION BY clause of a CREATE TABLE
+// statement. It returns nil if the table is partitioned by anything other
+// than plain columns.
+func convertPartitionSpec(spec *pgq.PartitionSpec) *migrations.PartitionBy {
+	strategy, ok := partitionStrategies[spec.GetStrategy()]
+	if !ok {
+		return nil
+	}
+
+	columns := make([]string, 0, len(spec.GetPartParams()))
+	for _, param := range spec.GetPartParams() {
+		elem := param.GetPartitionElem()
+		if elem.GetName() == "" || len(elem.GetCollation()) != 0 || len(elem.GetOpclass()) != 0 {
+			return nil
+		}
+		columns = append(columns, elem.GetName())
+	}
+
+	return &migrations.PartitionBy{
+		Strategy: strategy,
+		Columns:  columns,
+	}
+}
+
 // canConvertCreateTableStatement returns true iff `stmt` can be converted to a
 // pgroll operation.
 func canConvertCreateStatement(stmt *pgq.CreateStmt) bool {
@@ -81,8 +117,9 @@ func canConvertCreateStatement(stmt *pgq.CreateStmt) bool {
 		stmt.GetRelation().GetRelpersistence() != "p",
 		// Table inheritance is not supported
 		len(stmt.GetInhRelations()) != 0,
-		// Paritioned tables are not supported
-		stmt.GetPartspec() != nil,
+		// Partitioning by expressions, or with collations or operator classes,
+		// is not supported
+		stmt.GetPartspec() != nil && convertPartitionSpec(stmt.GetPartspec()) == nil,
 		// Specifying an access method is not supported
 		stmt.GetAccessMethod() != "",
 		// Specifying storage options is not supported
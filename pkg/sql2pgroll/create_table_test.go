@@ -261,6 +261,22 @@ func TestConvertCreateTableStatements(t *testing.T) {
 			sql:        "CREATE TABLE IF NOT EXISTS foo(a int)",
 			expectedOp: expect.CreateTableOp1,
 		},
+		{
+			sql:        "CREATE TABLE foo(a int) PARTITION BY RANGE (a)",
+			expectedOp: expect.CreateTableOp38(migrations.PartitionByStrategyRange),
+		},
+		{
+			sql:        "CREATE TABLE foo(a int) PARTITION BY LIST (a)",
+			expectedOp: expect.CreateTableOp38(migrations.PartitionByStrategyList),
+		},
+		{
+			sql:        "CREATE TABLE foo(a int) PARTITION BY HASH (a)",
+			expectedOp: expect.CreateTableOp38(migrations.PartitionByStrategyHash),
+		},
+		{
+			sql:        "CREATE TABLE foo(a int, b int) PARTITION BY RANGE (a, b)",
+			expectedOp: expect.CreateTableOp39,
+		},
 	}
 
 	for _, tc := range tests {
@@ -290,9 +306,15 @@ func TestUnconvertableCreateTableStatements(t *testing.T) {
 		// Table inheritance is not supported
 		"CREATE TABLE foo(a int) INHERITS (bar)",
 
-		// Any kind of partitioning is not supported
-		"CREATE TABLE foo(a int) PARTITION BY RANGE (a)",
-		"CREATE TABLE foo(a int) PARTITION BY LIST (a)",
+		// Partitioning by expressions is not supported
+		"CREATE TABLE foo(a int) PARTITION BY RANGE ((a + 1))",
+		"CREATE TABLE foo(a text) PARTITION BY LIST (lower(a))",
+
+		// Partitioning with collations or operator classes is not supported
+		"CREATE TABLE foo(a text) PARTITION BY RANGE (a COLLATE \"C\")",
+		"CREATE TABLE foo(a text) PARTITION BY HASH (a text_pattern_ops)",
+
+		// Creating partitions is not supported
 		"CREATE TABLE foo PARTITION OF bar FOR VALUES FROM (1) to (10)",
 
 		// Specifying a table access method is not supported
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package sql2pgroll
+
+import (
+	"strings"
+
+	pgq "github.com/xataio/pg_query_go/v6"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+// convertCreateEnumStmt converts CREATE TYPE ... AS ENUM statements to pgroll
+// operations
+func convertCreateEnumStmt(stmt *pgq.CreateEnumStmt) (migrations.Operations, error) {
+	values := make([]string, 0, len(stmt.GetVals()))
+	for _, v := range stmt.GetVals() {
+		values = append(values, v.GetString_().GetSval())
+	}
+
+	return migrations.Operations{
+		&migrations.OpCreateEnum{
+			Name:   typeName(stmt.GetTypeName()),
+			Values: values,
+		},
+	}, nil
+}
+
+// convertAlterEnumStmt converts ALTER TYPE ... ADD VALUE and ALTER TYPE ...
+// RENAME VALUE statements to pgroll operations
+func convertAlterEnumStmt(stmt *pgq.AlterEnumStmt) (migrations.Operations, error) {
+	name := typeName(stmt.GetTypeName())
+
+	// ALTER TYPE ... RENAME VALUE
+	if stmt.GetOldVal() != "" {
+		return migrations.Operations{
+			&migrations.OpAlterEnum{
+				Name: name,
+				RenameValue: &migrations.OpAlterEnumRenameValue{
+					From: stmt.GetOldVal(),
+					To:   stmt.GetNewVal(),
+				},
+			},
+		}, nil
+	}
+
+	// ALTER TYPE ... ADD VALUE
+	if !canConvertAddEnumValue(stmt) {
+		return nil, nil
+	}
+
+	addValue := &migrations.OpAlterEnumAddValue{Value: stmt.GetNewVal()}
+	if stmt.GetNewValNeighbor() != "" {
+		if stmt.GetNewValIsAfter() {
+			addValue.After = stmt.GetNewValNeighbor()
+		} else {
+			addValue.Before = stmt.GetNewValNeighbor()
+		}
+	}
+
+	return migrations.Operations{
+		&migrations.OpAlterEnum{
+			Name:     name,
+			AddValue: addValue,
+		},
+	}, nil
+}
+
+// canConvertAddEnumValue checks whether we can convert the statement without
+// losing any information. `IF NOT EXISTS` is not supported, as the `alter_enum`
+// operation requires that the value does not already exist.
+func canConvertAddEnumValue(stmt *pgq.AlterEnumStmt) bool {
+	return !stmt.GetSkipIfNewValExists()
+}
+
+// typeName returns the, possibly schema-qualified, name of a type
+func typeName(nodes []*pgq.Node) string {
+	parts := make([]string, len(nodes))
+	for i, node := range nodes {
+		parts[i] = node.GetString_().GetSval()
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package sql2pgroll_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+	"github.com/xataio/pgroll/pkg/sql2pgroll"
+	"github.com/xataio/pgroll/pkg/sql2pgroll/expect"
+)
+
+func TestConvertEnumStatements(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		sql        string
+		expectedOp migrations.Operation
+	}{
+		{
+			sql:        "CREATE TYPE mood AS ENUM ('sad', 'ok', 'happy')",
+			expectedOp: expect.CreateEnumOp1,
+		},
+		{
+			sql:        "CREATE TYPE myschema.mood AS ENUM ('sad', 'ok', 'happy')",
+			expectedOp: expect.CreateEnumOp2,
+		},
+		{
+			sql:        "ALTER TYPE mood ADD VALUE 'meh'",
+			expectedOp: expect.AlterEnumOp1,
+		},
+		{
+			sql:        "ALTER TYPE mood ADD VALUE 'meh' BEFORE 'ok'",
+			expectedOp: expect.AlterEnumOp2,
+		},
+		{
+			sql:        "ALTER TYPE mood ADD VALUE 'meh' AFTER 'sad'",
+			expectedOp: expect.AlterEnumOp3,
+		},
+		{
+			sql:        "ALTER TYPE mood RENAME VALUE 'ok' TO 'fine'",
+			expectedOp: expect.AlterEnumOp4,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.sql, func(t *testing.T) {
+			ops, err := sql2pgroll.Convert(tc.sql)
+			require.NoError(t, err)
+
+			require.Len(t, ops, 1)
+
+			assert.Equal(t, tc.expectedOp, ops[0])
+		})
+	}
+}
+
+func TestUnconvertableEnumStatements(t *testing.T) {
+	t.Parallel()
+
+	tests := []string{
+		// The value may already exist
+		"ALTER TYPE mood ADD VALUE IF NOT EXISTS 'meh'",
+	}
+
+	for _, sql := range tests {
+		t.Run(sql, func(t *testing.T) {
+			ops, err := sql2pgroll.Convert(sql)
+			require.NoError(t, err)
+
+			require.Len(t, ops, 1)
+
+			assert.Equal(t, expect.RawSQLOp(sql), ops[0])
+		})
+	}
+}
@@ -752,3 +752,40 @@ var CreateTableOp37 = &migrations.OpCreateTable{
 		},
 	},
 }
+
+func CreateTableOp38(strategy migrations.PartitionByStrategy) *migrations.OpCreateTable {
+	return &migrations.OpCreateTable{
+		Name: "foo",
+		Columns: []migrations.Column{
+			{
+				Name:     "a",
+				Type:     "int",
+				Nullable: true,
+			},
+		},
+		PartitionBy: &migrations.PartitionBy{
+			Strategy: strategy,
+			Columns:  []string{"a"},
+		},
+	}
+}
+
+var CreateTableOp39 = &migrations.OpCreateTable{
+	Name: "foo",
+	Columns: []migrations.Column{
+		{
+			Name:     "a",
+			Type:     "int",
+			Nullable: true,
+		},
+		{
+			Name:     "b",
+			Type:     "int",
+			Nullable: true,
+		},
+	},
+	PartitionBy: &migrations.PartitionBy{
+		Strategy: migrations.PartitionByStrategyRange,
+		Columns:  []string{"a", "b"},
+	},
+}
@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package expect
+
+import (
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+var CreateEnumOp1 = &migrations.OpCreateEnum{
+	Name:   "mood",
+	Values: []string{"sad", "ok", "happy"},
+}
+
+var CreateEnumOp2 = &migrations.OpCreateEnum{
+	Name:   "myschema.mood",
+	Values: []string{"sad", "ok", "happy"},
+}
+
+var AlterEnumOp1 = &migrations.OpAlterEnum{
+	Name:     "mood",
+	AddValue: &migrations.OpAlterEnumAddValue{Value: "meh"},
+}
+
+var AlterEnumOp2 = &migrations.OpAlterEnum{
+	Name:     "mood",
+	AddValue: &migrations.OpAlterEnumAddValue{Value: "meh", Before: "ok"},
+}
+
+var AlterEnumOp3 = &migrations.OpAlterEnum{
+	Name:     "mood",
+	AddValue: &migrations.OpAlterEnumAddValue{Value: "meh", After: "sad"},
+}
+
+var AlterEnumOp4 = &migrations.OpAlterEnum{
+	Name: "mood",
+	RenameValue: &migrations.OpAlterEnumRenameValue{
+		From: "ok",
+		To:   "fine",
+	},
+}
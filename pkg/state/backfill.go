@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package state
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/xataio/pgroll/pkg/backfill"
+)
+
+// backfillCheckpointer is a backfill.Checkpointer that records backfill
+// progress in the `backfill_progress` table of the state schema.
+type backfillCheckpointer struct {
+	state     *State
+	schema    string
+	migration string
+}
+
+// BackfillCheckpointer returns a backfill.Checkpointer that records the
+// progress of the backfills for `migration` in `schema`.
+func (s *State) BackfillCheckpointer(schema, migration string) backfill.Checkpointer {
+	return &backfillCheckpointer{
+		state:     s,
+		schema:    schema,
+		migration: migration,
+	}
+}
+
+// Load returns the progress recorded for `table`, or nil if there is none.
+func (c *backfillCheckpointer) Load(ctx context.Context, table string) (*backfill.Checkpoint, error) {
+	var lastValue []string
+	var done bool
+	err := c.state.pgConn.QueryRowContext(ctx,
+		fmt.Sprintf("SELECT last_value, done FROM %s.backfill_progress WHERE schema=$1 AND migration=$2 AND table_name=$3",
+			pq.QuoteIdentifier(c.state.schema)),
+		c.schema, c.migration, table).Scan(pq.Array(&lastValue), &done)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &backfill.Checkpoint{
+		LastValue: lastValue,
+		Done:      done,
+	}, nil
+}
+
+// Save records the progress of `table` using the transaction `tx`.
+func (c *backfillCheckpointer) Save(ctx context.Context, tx *sql.Tx, table string, cp backfill.Checkpoint) error {
+	_, err := tx.ExecContext(ctx,
+		fmt.Sprintf(`INSERT INTO %s.backfill_progress (schema, migration, table_name, last_value, done)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (schema, migration, table_name)
+			DO UPDATE SET last_value = COALESCE(EXCLUDED.last_value, backfill_progress.last_value), done = EXCLUDED.done, updated_at = CURRENT_TIMESTAMP`,
+			pq.QuoteIdentifier(c.state.schema)),
+		c.schema, c.migration, table, pq.Array(cp.LastValue), cp.Done)
+	return err
+}
+
+// ClearBackfillProgress removes all backfill progress recorded for `migration`
+// in `schema`.
+func (s *State) ClearBackfillProgress(ctx context.Context, schema, migration string) error {
+	_, err := s.pgConn.ExecContext(ctx,
+		fmt.Sprintf("DELETE FROM %s.backfill_progress WHERE schema=$1 AND migration=$2",
+			pq.QuoteIdentifier(s.schema)),
+		schema, migration)
+	return err
+}
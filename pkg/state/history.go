@@ -20,6 +20,15 @@ import (
 type HistoryEntry struct {
 	Migration migrations.RawMigration
 	CreatedAt time.Time
+
+	// Schemas lists every schema affected by the migration if it spans
+	// several schemas, and is nil otherwise
+	Schemas []string
+
+	// Checksum is the checksum of the migration recorded when it was applied.
+	// It is empty for inferred migrations and for migrations applied by
+	// versions of pgroll that did not record checksums.
+	Checksum string
 }
 
 // BaselineMigration represents a baseline migration record
@@ -33,7 +42,7 @@ type BaselineMigration struct {
 // recent baseline in ascending timestamp order
 func (s *State) SchemaHistory(ctx context.Context, schema string) ([]HistoryEntry, error) {
 	rows, err := s.pgConn.QueryContext(ctx,
-		fmt.Sprintf(`SELECT name, migration, created_at
+		fmt.Sprintf(`SELECT name, migration, created_at, schemas, checksum
 			FROM %[1]s.migrations
 			WHERE schema=$1
 			AND created_at > COALESCE(
@@ -53,8 +62,10 @@ func (s *State) SchemaHistory(ctx context.Context, schema string) ([]HistoryEntr
 	for rows.Next() {
 		var name, rawMigration string
 		var createdAt time.Time
+		var schemas []string
+		var checksum sql.NullString
 
-		if err := rows.Scan(&name, &rawMigration, &createdAt); err != nil {
+		if err := rows.Scan(&name, &rawMigration, &createdAt, (*pq.StringArray)(&schemas), &checksum); err != nil {
 			return nil, fmt.Errorf("row scan: %w", err)
 		}
 
@@ -68,6 +79,8 @@ func (s *State) SchemaHistory(ctx context.Context, schema string) ([]HistoryEntr
 		entries = append(entries, HistoryEntry{
 			Migration: mig,
 			CreatedAt: createdAt,
+			Schemas:   schemas,
+			Checksum:  checksum.String,
 		})
 	}
 
@@ -171,6 +171,24 @@ ALTER TABLE placeholder.migrations
     ALTER COLUMN created_at SET DATA TYPE timestamptz USING created_at AT TIME ZONE 'UTC',
     ALTER COLUMN updated_at SET DATA TYPE timestamptz USING updated_at AT TIME ZONE 'UTC';
 
+-- Add a column listing every schema affected by a migration that spans
+-- several schemas. Each affected schema has its own row for the migration.
+ALTER TABLE placeholder.migrations
+    ADD COLUMN IF NOT EXISTS schemas name[];
+
+-- Add a column holding a checksum of the content of each pgroll migration,
+-- used to detect migration files that are modified after being applied.
+ALTER TABLE placeholder.migrations
+    ADD COLUMN IF NOT EXISTS checksum text;
+
+-- Add columns recording the progress of a migration started in resumable mode:
+-- the number of its operations whose start phase has completed, and the error
+-- with which its start failed. A migration whose start failed remains active
+-- so that it can be resumed from the failed operation.
+ALTER TABLE placeholder.migrations
+    ADD COLUMN IF NOT EXISTS completed_operations integer,
+    ADD COLUMN IF NOT EXISTS start_error text;
+
 -- Table to track pgroll binary version
 CREATE TABLE IF NOT EXISTS placeholder.pgroll_version (
     version text NOT NULL,
@@ -178,6 +196,19 @@ CREATE TABLE IF NOT EXISTS placeholder.pgroll_version (
     PRIMARY KEY (version)
 );
 
+-- Table to track the progress of backfills so that interrupted backfills can
+-- be resumed from the last committed batch
+CREATE TABLE IF NOT EXISTS placeholder.backfill_progress (
+    schema NAME NOT NULL,
+    migration text NOT NULL,
+    table_name text NOT NULL,
+    last_value text[],
+    done boolean NOT NULL DEFAULT FALSE,
+    updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (schema, migration, table_name),
+    FOREIGN KEY (schema, migration) REFERENCES placeholder.migrations (schema, name) ON DELETE CASCADE
+);
+
 -- Helper functions
 -- Are we in the middle of a migration?
 CREATE OR REPLACE FUNCTION placeholder.is_active_migration_period (schemaname name)
@@ -317,6 +348,8 @@ CREATE OR REPLACE FUNCTION placeholder.read_schema (schemaname text)
     AS $$
 DECLARE
     tables jsonb;
+    views jsonb;
+    old_search_path text;
 BEGIN
     SELECT
         json_build_object('name', schemaname, 'tables', (
@@ -373,7 +406,25 @@ BEGIN
                                     'range'
                                 WHEN tp.typtype = 'm' THEN
                                     'multirange'
-                                END AS postgresType FROM pg_attribute AS attr
+                                END AS postgresType, CASE WHEN attr.attidentity = 'a' THEN
+                                    'ALWAYS'
+                                WHEN attr.attidentity = 'd' THEN
+                                    'BY DEFAULT'
+                                END AS identity, CASE WHEN attr.attgenerated = 's' THEN
+                                    pg_get_expr(def.adbin, def.adrelid)
+                                END AS generated, (
+                                    SELECT
+                                        seq.relname
+                                    FROM pg_depend AS seq_dep
+                                    INNER JOIN pg_class AS seq ON seq.oid = seq_dep.objid
+                                WHERE
+                                    seq_dep.classid = 'pg_class'::regclass
+                                    AND seq_dep.refclassid = 'pg_class'::regclass
+                                    AND seq_dep.refobjid = attr.attrelid
+                                    AND seq_dep.refobjsubid = attr.attnum
+                                    AND seq_dep.deptype IN ('a', 'i')
+                                    AND seq.relkind = 'S'
+                                LIMIT 1) AS sequence FROM pg_attribute AS attr
                                 INNER JOIN pg_type AS tp ON attr.atttypid = tp.oid
                                 LEFT JOIN pg_attrdef AS def ON attr.attrelid = def.adrelid
                                     AND attr.attnum = def.adnum
@@ -438,10 +489,10 @@ BEGIN
                                 xc_constraint.conrelid = t.oid
                                 AND xc_constraint.contype = 'x' GROUP BY xc_constraint.oid, xc_constraint.conname, pi.indpred, pi.indexrelid, am.amname) AS xc_details), 'foreignKeys', (
                             SELECT
-                                json_object_agg(fk_details.conname, json_build_object('name', fk_details.conname, 'columns', fk_details.columns, 'referencedTable', fk_details.referencedTable, 'referencedColumns', fk_details.referencedColumns, 'matchType', fk_details.matchType, 'onDelete', fk_details.onDelete, 'onUpdate', fk_details.onUpdate))
+                                json_object_agg(fk_details.conname, json_build_object('name', fk_details.conname, 'columns', fk_details.columns, 'referencedTable', fk_details.referencedTable, 'referencedSchema', fk_details.referencedSchema, 'referencedColumns', fk_details.referencedColumns, 'matchType', fk_details.matchType, 'onDelete', fk_details.onDelete, 'onUpdate', fk_details.onUpdate))
                             FROM (
                                 SELECT
-                                    fk_info.conname AS conname, fk_info.columns AS columns, fk_info.relname AS referencedTable, array_agg(ref_attr.attname ORDER BY ref_attr.attname) AS referencedColumns, CASE WHEN fk_info.confmatchtype = 'f' THEN
+                                    fk_info.conname AS conname, fk_info.columns AS columns, fk_info.relname AS referencedTable, fk_info.referencedSchema AS referencedSchema, array_agg(ref_attr.attname ORDER BY ref_attr.attname) AS referencedColumns, CASE WHEN fk_info.confmatchtype = 'f' THEN
                                     'FULL'
                                 WHEN fk_info.confmatchtype = 'p' THEN
                                     'PARTIAL'
@@ -469,16 +520,107 @@ BEGIN
                                     'SET NULL'
                                 END AS onUpdate FROM (
                                     SELECT
-                                        fk_constraint.conname, fk_constraint.conrelid, fk_constraint.confrelid, fk_constraint.confkey, fk_cl.relname, fk_constraint.confmatchtype, fk_constraint.confdeltype, fk_constraint.confupdtype, array_agg(fk_attr.attname ORDER BY fk_attr.attname) AS columns FROM pg_constraint AS fk_constraint
+                                        fk_constraint.conname, fk_constraint.conrelid, fk_constraint.confrelid, fk_constraint.confkey, fk_cl.relname, CASE WHEN fk_cl.relnamespace <> t.relnamespace THEN
+                                            fk_ns.nspname
+                                        END AS referencedSchema, fk_constraint.confmatchtype, fk_constraint.confdeltype, fk_constraint.confupdtype, array_agg(fk_attr.attname ORDER BY fk_attr.attname) AS columns FROM pg_constraint AS fk_constraint
                                     INNER JOIN pg_class fk_cl ON fk_constraint.confrelid = fk_cl.oid -- join the referenced table
+                                    INNER JOIN pg_namespace fk_ns ON fk_cl.relnamespace = fk_ns.oid -- join the schema of the referenced table
                                     INNER JOIN pg_attribute fk_attr ON fk_attr.attrelid = fk_constraint.conrelid
                                         AND fk_attr.attnum = ANY (fk_constraint.conkey) -- join the columns of the referencing table
                                     WHERE
                                         fk_constraint.conrelid = t.oid
-                                        AND fk_constraint.contype = 'f' GROUP BY fk_constraint.conrelid, fk_constraint.conname, fk_constraint.confrelid, fk_cl.relname, fk_constraint.confkey, fk_constraint.confmatchtype, fk_constraint.confdeltype, fk_constraint.confupdtype) AS fk_info
+                                        AND fk_constraint.contype = 'f' GROUP BY fk_constraint.conrelid, fk_constraint.conname, fk_constraint.confrelid, fk_cl.relname, fk_cl.relnamespace, fk_ns.nspname, fk_constraint.confkey, fk_constraint.confmatchtype, fk_constraint.confdeltype, fk_constraint.confupdtype) AS fk_info
                                     INNER JOIN pg_attribute ref_attr ON ref_attr.attrelid = fk_info.confrelid
                                         AND ref_attr.attnum = ANY (fk_info.confkey) -- join the columns of the referenced table
-                                GROUP BY fk_info.conname, fk_info.conrelid, fk_info.columns, fk_info.confrelid, fk_info.confmatchtype, fk_info.confdeltype, fk_info.confupdtype, fk_info.relname) AS fk_details)))), '{}'::json)
+                                GROUP BY fk_info.conname, fk_info.conrelid, fk_info.columns, fk_info.confrelid, fk_info.confmatchtype, fk_info.confdeltype, fk_info.confupdtype, fk_info.relname, fk_info.referencedSchema) AS fk_details), 'partitioning', (
+                                SELECT
+                                    jsonb_build_object('strategy', CASE WHEN pt.partstrat = 'r' THEN
+                                            'range'
+                                        WHEN pt.partstrat = 'l' THEN
+                                            'list'
+                                        WHEN pt.partstrat = 'h' THEN
+                                            'hash'
+                                        END, 'columns', (
+                                            SELECT
+                                                json_agg(pt_attr.attname ORDER BY pt_key.ord)
+                                            FROM unnest(pt.partattrs::int2[]) WITH ORDINALITY AS pt_key (attnum, ord)
+                                            INNER JOIN pg_attribute AS pt_attr ON pt_attr.attrelid = t.oid
+                                                AND pt_attr.attnum = pt_key.attnum), 'partitions', (
+                                                SELECT
+                                                    json_object_agg(part.relname, json_build_object('name', part.relname, 'bound', pg_get_expr(part.relpartbound, part.oid)))
+                                                FROM pg_inherits AS inh
+                                                INNER JOIN pg_class AS part ON part.oid = inh.inhrelid
+                                            WHERE
+                                                inh.inhparent = t.oid))
+                                FROM pg_partitioned_table AS pt
+                            WHERE
+                                pt.partrelid = t.oid), 'partitionOf', (
+                                SELECT
+                                    parent.relname
+                                FROM pg_inherits AS inh
+                                INNER JOIN pg_class AS parent ON parent.oid = inh.inhparent
+                            WHERE
+                                inh.inhrelid = t.oid
+                                AND t.relispartition), 'triggers', (
+                                SELECT
+                                    json_agg(tg.tgname ORDER BY tg.tgname)
+                                FROM pg_trigger AS tg
+                            WHERE
+                                tg.tgrelid = t.oid
+                                AND NOT tg.tgisinternal
+                                AND tg.tgname NOT LIKE '\_pgroll\_%'), 'publications', (
+                                SELECT
+                                    json_agg(pub.pubname ORDER BY pub.pubname)
+                                FROM pg_publication_rel AS pubrel
+                                INNER JOIN pg_publication AS pub ON pub.oid = pubrel.prpubid
+                            WHERE
+                                pubrel.prrelid = t.oid), 'rowLevelSecurity', t.relrowsecurity, 'forceRowLevelSecurity', t.relforcerowsecurity, 'policies', (
+                                SELECT
+                                    json_object_agg(pol.polname, jsonb_strip_nulls (jsonb_build_object('name', pol.polname, 'command', CASE pol.polcmd
+                                                WHEN 'r' THEN
+                                                    'SELECT'
+                                                WHEN 'a' THEN
+                                                    'INSERT'
+                                                WHEN 'w' THEN
+                                                    'UPDATE'
+                                                WHEN 'd' THEN
+                                                    'DELETE'
+                                                ELSE
+                                                    'ALL'
+                                                END, 'permissive', pol.polpermissive, 'roles', (
+                                                SELECT
+                                                    json_agg(
+                                                        CASE WHEN pol_role.oid = 0 THEN
+                                                            'public'
+                                                        ELSE
+                                                            pg_get_userbyid(pol_role.oid)
+                                                        END ORDER BY pol_role.ord)
+                                                FROM unnest(pol.polroles) WITH ORDINALITY AS pol_role (oid, ord)), 'using', pg_get_expr(pol.polqual, pol.polrelid), 'withCheck', pg_get_expr(pol.polwithcheck, pol.polrelid), 'columns', (
+                                                SELECT
+                                                    json_agg(DISTINCT pol_attr.attname)
+                                                FROM pg_depend AS pol_dep
+                                                INNER JOIN pg_attribute AS pol_attr ON pol_attr.attrelid = pol_dep.refobjid
+                                                    AND pol_attr.attnum = pol_dep.refobjsubid
+                                            WHERE
+                                                pol_dep.classid = 'pg_policy'::regclass
+                                                AND pol_dep.objid = pol.oid
+                                                AND pol_dep.refobjid = t.oid
+                                                AND pol_dep.refobjsubid > 0))))
+                                FROM pg_policy AS pol
+                            WHERE
+                                pol.polrelid = t.oid), 'privileges', (
+                                SELECT
+                                    json_object_agg(priv.grantee, priv.privileges)
+                                FROM (
+                                    SELECT
+                                        CASE WHEN acl.grantee = 0 THEN
+                                            'public'
+                                        ELSE
+                                            pg_get_userbyid(acl.grantee)
+                                        END AS grantee, json_agg(lower(acl.privilege_type) ORDER BY acl.privilege_type) AS privileges
+                                    FROM aclexplode(t.relacl) AS acl
+                                WHERE
+                                    acl.grantee <> t.relowner GROUP BY acl.grantee) AS priv)))), '{}'::json)
             FROM pg_class AS t
             INNER JOIN pg_namespace AS ns ON t.relnamespace = ns.oid
             LEFT JOIN pg_description AS descr ON t.oid = descr.objoid
@@ -486,9 +628,55 @@ BEGIN
             WHERE
                 ns.nspname = schemaname
                 AND t.relkind IN ('r', 'p') -- tables only (ignores views, materialized views & foreign tables)
-))
+), 'enums', (
+            SELECT
+                json_object_agg(tp.typname, json_build_object('name', tp.typname, 'values', (
+                            SELECT
+                                json_agg(e.enumlabel ORDER BY e.enumsortorder)
+                            FROM pg_enum AS e
+                        WHERE
+                            e.enumtypid = tp.oid)))
+            FROM pg_type AS tp
+            INNER JOIN pg_namespace AS ns ON tp.typnamespace = ns.oid
+        WHERE
+            ns.nspname = schemaname
+            AND tp.typtype = 'e'), 'sequences', (
+            SELECT
+                json_object_agg(seq.relname, json_build_object('name', seq.relname, 'type', format_type(sq.seqtypid, NULL), 'start', sq.seqstart, 'increment', sq.seqincrement, 'minValue', sq.seqmin, 'maxValue', sq.seqmax, 'cache', sq.seqcache, 'cycle', sq.seqcycle))
+            FROM pg_sequence AS sq
+            INNER JOIN pg_class AS seq ON seq.oid = sq.seqrelid
+            INNER JOIN pg_namespace AS ns ON seq.relnamespace = ns.oid
+        WHERE
+            ns.nspname = schemaname
+            -- sequences of identity columns are part of the column
+            AND NOT EXISTS (
+                SELECT
+                    1
+                FROM pg_depend AS seq_dep
+            WHERE
+                seq_dep.classid = 'pg_class'::regclass
+                AND seq_dep.objid = seq.oid
+                AND seq_dep.deptype = 'i')))
     INTO
         tables;
+    -- Deparse view definitions with only the schema on the search path, so
+    -- that they refer to the schema's tables and views by unqualified name
+    old_search_path := current_setting('search_path');
+    PERFORM
+        set_config('search_path', quote_ident(schemaname), TRUE);
+    SELECT
+        json_object_agg(v.relname, json_build_object('name', v.relname, 'definition', rtrim(btrim(pg_get_viewdef(v.oid), E' \n'), ';')))
+    FROM
+        pg_class AS v
+        INNER JOIN pg_namespace AS ns ON v.relnamespace = ns.oid
+    WHERE
+        ns.nspname = schemaname
+        AND v.relkind = 'v' INTO views;
+    PERFORM
+        set_config('search_path', old_search_path, TRUE);
+    -- The views key is always set, so that schemas read before views were
+    -- supported can be told apart
+    tables := tables || jsonb_build_object('views', views);
     RETURN tables;
 END;
 $$;
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package state
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// StartProgress records how far the start of an active migration run in
+// resumable mode has got
+type StartProgress struct {
+	// The number of operations, in order, whose start phase has completed
+	CompletedOperations int
+
+	// The error with which the start failed, or empty if it has not failed
+	Error string
+}
+
+// Failed returns true if the start of the migration failed
+func (p *StartProgress) Failed() bool {
+	return p.Error != ""
+}
+
+// StartProgress returns the progress of the start of the active migration
+// `name` in `schema`, or nil if the migration was not started in resumable
+// mode.
+func (s *State) StartProgress(ctx context.Context, schema, name string) (*StartProgress, error) {
+	var completed sql.NullInt64
+	var startError sql.NullString
+	err := s.pgConn.QueryRowContext(ctx,
+		fmt.Sprintf("SELECT completed_operations, start_error FROM %s.migrations WHERE schema=$1 AND name=$2 AND done=false",
+			pq.QuoteIdentifier(s.schema)),
+		schema, name).Scan(&completed, &startError)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoActiveMigration
+		}
+		return nil, err
+	}
+
+	if !completed.Valid {
+		return nil, nil
+	}
+
+	return &StartProgress{
+		CompletedOperations: int(completed.Int64),
+		Error:               startError.String,
+	}, nil
+}
+
+// RecordStartProgress records that the start phase of the first `completed`
+// operations of the active migration `name` in `schema` has completed, and
+// clears any error with which its start previously failed.
+func (s *State) RecordStartProgress(ctx context.Context, schema, name string, completed int) error {
+	return s.updateStartProgress(ctx, schema, name, completed, nil)
+}
+
+// RecordStartFailure records that the start of the active migration `name` in
+// `schema` failed with `failure` after the start phase of the first
+// `completed` operations had completed.
+func (s *State) RecordStartFailure(ctx context.Context, schema, name string, completed int, failure error) error {
+	msg := failure.Error()
+	return s.updateStartProgress(ctx, schema, name, completed, &msg)
+}
+
+func (s *State) updateStartProgress(ctx context.Context, schema, name string, completed int, startError *string) error {
+	res, err := s.pgConn.ExecContext(ctx,
+		fmt.Sprintf("UPDATE %s.migrations SET completed_operations=$3, start_error=$4, updated_at=CURRENT_TIMESTAMP WHERE schema=$1 AND name=$2 AND done=false",
+			pq.QuoteIdentifier(s.schema)),
+		schema, name, completed, startError)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNoActiveMigration
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package state
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/lib/pq"
+
+	"github.com/xataio/pgroll/pkg/migrations"
+)
+
+// SquashMigrations replaces the consecutive migrations `names` in the history
+// of `schema` with the single migration `squashed`, which takes the place of
+// the last of them and keeps its resulting schema. It returns false without
+// changing the history if none of the migrations have been applied.
+//
+// The rows of the squashed migrations and of every migration after them are
+// deleted and reinserted, as the constraints that keep the history linear
+// can't be deferred.
+func (s *State) SquashMigrations(ctx context.Context, schema string, names []string, squashed *migrations.Migration) (bool, error) {
+	if len(names) == 0 {
+		return false, fmt.Errorf("no migrations to squash")
+	}
+
+	rawMigration, err := json.Marshal(squashed)
+	if err != nil {
+		return false, fmt.Errorf("unable to marshal migration: %w", err)
+	}
+
+	checksum, err := squashed.Checksum(schema)
+	if err != nil {
+		return false, fmt.Errorf("unable to compute migration checksum: %w", err)
+	}
+
+	applied := false
+	err = s.inTx(ctx, func(tx *sql.Tx) error {
+		var active bool
+		err := tx.QueryRowContext(ctx,
+			fmt.Sprintf("SELECT %s.is_active_migration_period($1)", pq.QuoteIdentifier(s.schema)),
+			schema).Scan(&active)
+		if err != nil {
+			return err
+		}
+		if active {
+			return fmt.Errorf("a migration for schema %q is in progress", schema)
+		}
+
+		chain, err := s.squashedHistory(ctx, tx, schema, names)
+		if err != nil {
+			return err
+		}
+		if chain == nil {
+			return nil
+		}
+
+		last := names[len(names)-1]
+		stmts := []struct {
+			sql  string
+			args []any
+		}{
+			{
+				sql: fmt.Sprintf("CREATE TEMPORARY TABLE pgroll_squash (LIKE %s.migrations) ON COMMIT DROP", pq.QuoteIdentifier(s.schema)),
+			},
+			{
+				sql:  fmt.Sprintf("INSERT INTO pgroll_squash SELECT * FROM %s.migrations WHERE schema = $1 AND name = ANY($2)", pq.QuoteIdentifier(s.schema)),
+				args: []any{schema, pq.Array(chain)},
+			},
+			{
+				sql: `UPDATE pgroll_squash SET migration = $1, checksum = $2, updated_at = CURRENT_TIMESTAMP,
+					parent = (SELECT parent FROM pgroll_squash WHERE name = $3)
+					WHERE name = $4`,
+				args: []any{rawMigration, checksum, names[0], last},
+			},
+			{
+				sql:  "DELETE FROM pgroll_squash WHERE name = ANY($1)",
+				args: []any{pq.Array(names[:len(names)-1])},
+			},
+			{
+				sql:  fmt.Sprintf("DELETE FROM %s.migrations WHERE schema = $1 AND name = ANY($2)", pq.QuoteIdentifier(s.schema)),
+				args: []any{schema, pq.Array(chain)},
+			},
+			{
+				sql: fmt.Sprintf("INSERT INTO %s.migrations SELECT * FROM pgroll_squash", pq.QuoteIdentifier(s.schema)),
+			},
+		}
+		for _, stmt := range stmts {
+			if _, err := tx.ExecContext(ctx, stmt.sql, stmt.args...); err != nil {
+				return fmt.Errorf("unable to squash migration history: %w", err)
+			}
+		}
+
+		applied = true
+		return nil
+	})
+
+	return applied, err
+}
+
+// CheckSquashable returns an error if the history of `schema` contains only
+// some of the consecutive migrations `names`, or contains them out of order,
+// so that they can't be replaced by a single squashed migration.
+func (s *State) CheckSquashable(ctx context.Context, schema string, names []string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := s.squashedHistory(ctx, tx, schema, names)
+		return err
+	})
+}
+
+// squashedHistory returns the history of `schema` from the first of the
+// migrations `names` onwards, or nil if none of them have been applied. It
+// returns an error unless the history starts with all of `names` in order.
+func (s *State) squashedHistory(ctx context.Context, tx *sql.Tx, schema string, names []string) ([]string, error) {
+	// Follow the history from the first squashed migration onwards
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`WITH RECURSIVE chain AS (
+			SELECT name, 0 AS depth FROM %[1]s.migrations WHERE schema = $1 AND name = $2
+			UNION ALL
+			SELECT m.name, chain.depth + 1 FROM %[1]s.migrations m
+			JOIN chain ON m.schema = $1 AND m.parent = chain.name
+		) SELECT name FROM chain ORDER BY depth`,
+		pq.QuoteIdentifier(s.schema)), schema, names[0])
+	if err != nil {
+		return nil, err
+	}
+	var chain []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		chain = append(chain, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(chain) == 0 {
+		// The history must then contain none of the squashed migrations
+		var found int
+		err := tx.QueryRowContext(ctx,
+			fmt.Sprintf("SELECT count(*) FROM %s.migrations WHERE schema = $1 AND name = ANY($2)", pq.QuoteIdentifier(s.schema)),
+			schema, pq.Array(names)).Scan(&found)
+		if err != nil {
+			return nil, err
+		}
+		if found > 0 {
+			return nil, fmt.Errorf("migration %q is missing from the history of schema %q", names[0], schema)
+		}
+		return nil, nil
+	}
+
+	if len(chain) < len(names) {
+		return nil, fmt.Errorf("only %d of the %d squashed migrations have been applied to schema %q: apply the remaining migrations before squashing",
+			len(chain), len(names), schema)
+	}
+	if !slices.Equal(chain[:len(names)], names) {
+		return nil, fmt.Errorf("the history of schema %q does not match the squashed migrations: %v", schema, chain[:len(names)])
+	}
+
+	return chain, nil
+}
@@ -233,37 +233,77 @@ func (s *State) GetActiveMigration(ctx context.Context, schema string) (*migrati
 // until the migration is completed
 // This method will return the current schema (before the migration is applied)
 func (s *State) Start(ctx context.Context, schemaname string, migration *migrations.Migration) error {
+	return s.StartSchemas(ctx, []string{schemaname}, migration)
+}
+
+// StartSchemas creates a new migration in each of `schemas`, as a single
+// unit. A migration that spans several schemas records all of them in the
+// history of each schema.
+func (s *State) StartSchemas(ctx context.Context, schemas []string, migration *migrations.Migration) error {
 	rawMigration, err := json.Marshal(migration)
 	if err != nil {
 		return fmt.Errorf("unable to marshal migration: %w", err)
 	}
 
+	checksum, err := migration.Checksum(schemas[0])
+	if err != nil {
+		return fmt.Errorf("unable to compute migration checksum: %w", err)
+	}
+
+	var affected any
+	if len(schemas) > 1 {
+		affected = pq.Array(schemas)
+	}
+
 	// create a new migration object and return the previous known schema
 	// if there is no previous migration, read the schema from postgres
-	stmt := fmt.Sprintf(`INSERT INTO %[1]s.migrations (schema, name, parent, migration) VALUES ($1, $2, %[1]s.latest_migration($1), $3)`,
+	stmt := fmt.Sprintf(`INSERT INTO %[1]s.migrations (schema, name, parent, migration, schemas, checksum) VALUES ($1, $2, %[1]s.latest_migration($1), $3, $4, $5)`,
 		pq.QuoteIdentifier(s.schema))
 
-	_, err = s.pgConn.ExecContext(ctx, stmt, schemaname, migration.Name, rawMigration)
-	return err
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, schemaname := range schemas {
+			if _, err := tx.ExecContext(ctx, stmt, schemaname, migration.Name, rawMigration, affected, checksum); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // Complete marks a migration as completed
 func (s *State) Complete(ctx context.Context, schema, name string) error {
-	res, err := s.pgConn.ExecContext(ctx, fmt.Sprintf("UPDATE %[1]s.migrations SET done=$1, resulting_schema=(SELECT %[1]s.read_schema($2)) WHERE schema=$2 AND name=$3 AND done=$4", pq.QuoteIdentifier(s.schema)), true, schema, name, false)
-	if err != nil {
-		return err
-	}
-
-	rows, err := res.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rows == 0 {
-		return fmt.Errorf("no migration found with name %s", name)
-	}
+	return s.CompleteSchemas(ctx, []string{schema}, name)
+}
 
-	return err
+// CompleteSchemas marks a migration as completed in each of `schemas`, as a
+// single unit
+func (s *State) CompleteSchemas(ctx context.Context, schemas []string, name string) error {
+	stmt := fmt.Sprintf("UPDATE %[1]s.migrations SET done=$1, resulting_schema=(SELECT %[1]s.read_schema($2)) WHERE schema=$2 AND name=$3 AND done=$4", pq.QuoteIdentifier(s.schema))
+	clearProgress := fmt.Sprintf("DELETE FROM %s.backfill_progress WHERE schema=$1 AND migration=$2", pq.QuoteIdentifier(s.schema))
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, schema := range schemas {
+			res, err := tx.ExecContext(ctx, stmt, true, schema, name, false)
+			if err != nil {
+				return err
+			}
+
+			rows, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+
+			if rows == 0 {
+				return fmt.Errorf("no migration found with name %s", name)
+			}
+
+			// The backfill progress of a completed migration is no longer needed
+			if _, err := tx.ExecContext(ctx, clearProgress, schema, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // ReadSchema reads the schema for the specified schema name
@@ -286,10 +326,7 @@ func (s *State) ReadSchema(ctx context.Context, schemaName string) (*schema.Sche
 // SchemaAfterMigration reads the schema after the migration `version` was
 // applied to `schemaName`
 func (s *State) SchemaAfterMigration(ctx context.Context, schemaName, version string) (*schema.Schema, error) {
-	sql := fmt.Sprintf("SELECT resulting_schema FROM %s.migrations WHERE schema=$1 AND name=$2", pq.QuoteIdentifier(s.schema))
-
-	var rawSchema []byte
-	err := s.pgConn.QueryRowContext(ctx, sql, schemaName, version).Scan(&rawSchema)
+	rawSchema, err := s.RawSchemaAfterMigration(ctx, schemaName, version)
 	if err != nil {
 		return nil, err
 	}
@@ -303,23 +340,62 @@ func (s *State) SchemaAfterMigration(ctx context.Context, schemaName, version st
 	return &sc, nil
 }
 
-// Rollback removes a migration from the state (we consider it rolled back, as if it never started)
-func (s *State) Rollback(ctx context.Context, schema, name string) error {
-	res, err := s.pgConn.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s.migrations WHERE schema=$1 AND name=$2 AND done=$3", pq.QuoteIdentifier(s.schema)), schema, name, false)
+// RawSchemaAfterMigration reads the schema after the migration `version` was
+// applied to `schemaName`, as the JSON document recorded at the time
+func (s *State) RawSchemaAfterMigration(ctx context.Context, schemaName, version string) ([]byte, error) {
+	sql := fmt.Sprintf("SELECT resulting_schema FROM %s.migrations WHERE schema=$1 AND name=$2", pq.QuoteIdentifier(s.schema))
+
+	var rawSchema []byte
+	err := s.pgConn.QueryRowContext(ctx, sql, schemaName, version).Scan(&rawSchema)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return rawSchema, nil
+}
+
+// Rollback removes a migration from the state (we consider it rolled back, as if it never started)
+func (s *State) Rollback(ctx context.Context, schema, name string) error {
+	return s.RollbackSchemas(ctx, []string{schema}, name)
+}
 
-	rows, err := res.RowsAffected()
+// RollbackSchemas removes a migration from the state of each of `schemas`, as
+// a single unit
+func (s *State) RollbackSchemas(ctx context.Context, schemas []string, name string) error {
+	stmt := fmt.Sprintf("DELETE FROM %s.migrations WHERE schema=$1 AND name=$2 AND done=$3", pq.QuoteIdentifier(s.schema))
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, schema := range schemas {
+			res, err := tx.ExecContext(ctx, stmt, schema, name, false)
+			if err != nil {
+				return err
+			}
+
+			rows, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+
+			if rows == 0 {
+				return fmt.Errorf("no migration found with name %s", name)
+			}
+		}
+		return nil
+	})
+}
+
+// inTx runs `fn` in a transaction, which is committed if `fn` succeeds and
+// rolled back otherwise
+func (s *State) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.pgConn.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	if rows == 0 {
-		return fmt.Errorf("no migration found with name %s", name)
+	if err := fn(tx); err != nil {
+		return errors.Join(err, tx.Rollback())
 	}
 
-	return nil
+	return tx.Commit()
 }
 
 // CreateBaseline creates a baseline migration that captures the current state of the schema.
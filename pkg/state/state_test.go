@@ -1199,6 +1199,9 @@ func TestReadSchema(t *testing.T) {
 				createStmt: "CREATE TYPE review AS ENUM ('good', 'bad', 'ugly'); CREATE TABLE public.table1 (name text, review review);",
 				wantSchema: &schema.Schema{
 					Name: "public",
+					Enums: map[string]*schema.Enum{
+						"review": {Name: "review", Values: []string{"good", "bad", "ugly"}},
+					},
 					Tables: map[string]*schema.Table{
 						"table1": {
 							Name: "table1",
@@ -1221,6 +1224,245 @@ func TestReadSchema(t *testing.T) {
 					},
 				},
 			},
+			{
+				name:       "views",
+				createStmt: "CREATE TABLE public.table1 (id int); CREATE VIEW public.answer AS SELECT 42 AS answer;",
+				wantSchema: &schema.Schema{
+					Name: "public",
+					Views: map[string]*schema.View{
+						"answer": {Name: "answer", Definition: "SELECT 42 AS answer"},
+					},
+					Tables: map[string]*schema.Table{
+						"table1": {
+							Name: "table1",
+							Columns: map[string]*schema.Column{
+								"id": {
+									Name:         "id",
+									Type:         "integer",
+									Nullable:     true,
+									PostgresType: "base",
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				name: "partitioned table",
+				createStmt: `
+					CREATE TABLE public.events (id int, created_at date) PARTITION BY RANGE (created_at);
+					CREATE TABLE public.events_2024 PARTITION OF public.events FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');`,
+				wantSchema: &schema.Schema{
+					Name: "public",
+					Tables: map[string]*schema.Table{
+						"events": {
+							Name: "events",
+							Columns: map[string]*schema.Column{
+								"id": {
+									Name:         "id",
+									Type:         "integer",
+									Nullable:     true,
+									PostgresType: "base",
+								},
+								"created_at": {
+									Name:         "created_at",
+									Type:         "date",
+									Nullable:     true,
+									PostgresType: "base",
+								},
+							},
+							Partitioning: &schema.Partitioning{
+								Strategy: "range",
+								Columns:  []string{"created_at"},
+								Partitions: map[string]*schema.Partition{
+									"events_2024": {
+										Name:  "events_2024",
+										Bound: "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')",
+									},
+								},
+							},
+						},
+						"events_2024": {
+							Name:        "events_2024",
+							PartitionOf: "events",
+							Columns: map[string]*schema.Column{
+								"id": {
+									Name:         "id",
+									Type:         "integer",
+									Nullable:     true,
+									PostgresType: "base",
+								},
+								"created_at": {
+									Name:         "created_at",
+									Type:         "date",
+									Nullable:     true,
+									PostgresType: "base",
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				name: "table with row level security policies",
+				createStmt: `
+					CREATE TABLE public.documents (id int, owner text);
+					ALTER TABLE public.documents ENABLE ROW LEVEL SECURITY;
+					CREATE POLICY owner_only ON public.documents USING (owner = current_user);
+					CREATE POLICY no_deletes ON public.documents AS RESTRICTIVE FOR DELETE TO pgroll USING (false);`,
+				wantSchema: &schema.Schema{
+					Name: "public",
+					Tables: map[string]*schema.Table{
+						"documents": {
+							Name: "documents",
+							Columns: map[string]*schema.Column{
+								"id": {
+									Name:         "id",
+									Type:         "integer",
+									Nullable:     true,
+									PostgresType: "base",
+								},
+								"owner": {
+									Name:         "owner",
+									Type:         "text",
+									Nullable:     true,
+									PostgresType: "base",
+								},
+							},
+							RowLevelSecurity: true,
+							Policies: map[string]*schema.Policy{
+								"owner_only": {
+									Name:       "owner_only",
+									Command:    "ALL",
+									Permissive: true,
+									Roles:      []string{"public"},
+									Using:      "(owner = (CURRENT_USER)::text)",
+									Columns:    []string{"owner"},
+								},
+								"no_deletes": {
+									Name:       "no_deletes",
+									Command:    "DELETE",
+									Permissive: false,
+									Roles:      []string{"pgroll"},
+									Using:      "false",
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				name: "table with privileges",
+				createStmt: `
+					CREATE TABLE public.documents (id int);
+					GRANT SELECT, INSERT ON public.documents TO pgroll;
+					GRANT SELECT ON public.documents TO PUBLIC;`,
+				wantSchema: &schema.Schema{
+					Name: "public",
+					Tables: map[string]*schema.Table{
+						"documents": {
+							Name: "documents",
+							Columns: map[string]*schema.Column{
+								"id": {
+									Name:         "id",
+									Type:         "integer",
+									Nullable:     true,
+									PostgresType: "base",
+								},
+							},
+							Privileges: map[string][]string{
+								"pgroll": {"insert", "select"},
+								"public": {"select"},
+							},
+						},
+					},
+				},
+			},
+			{
+				name: "serial and identity columns and sequences",
+				createStmt: `
+					CREATE TABLE public.orders (id serial, number bigint GENERATED ALWAYS AS IDENTITY);
+					CREATE SEQUENCE public.invoice_numbers START 1000 INCREMENT 10 CACHE 5;`,
+				wantSchema: &schema.Schema{
+					Name: "public",
+					Tables: map[string]*schema.Table{
+						"orders": {
+							Name: "orders",
+							Columns: map[string]*schema.Column{
+								"id": {
+									Name:         "id",
+									Type:         "integer",
+									Default:      ptr("nextval('orders_id_seq'::regclass)"),
+									Nullable:     false,
+									PostgresType: "base",
+									Sequence:     "orders_id_seq",
+								},
+								"number": {
+									Name:         "number",
+									Type:         "bigint",
+									Nullable:     false,
+									PostgresType: "base",
+									Identity:     "ALWAYS",
+									Sequence:     "orders_number_seq",
+								},
+							},
+						},
+					},
+					Sequences: map[string]*schema.Sequence{
+						"orders_id_seq": {
+							Name:      "orders_id_seq",
+							Type:      "integer",
+							Start:     1,
+							Increment: 1,
+							MinValue:  1,
+							MaxValue:  2147483647,
+							Cache:     1,
+						},
+						"invoice_numbers": {
+							Name:      "invoice_numbers",
+							Type:      "bigint",
+							Start:     1000,
+							Increment: 10,
+							MinValue:  1,
+							MaxValue:  9223372036854775807,
+							Cache:     5,
+						},
+					},
+				},
+			},
+			{
+				name:       "stored generated columns",
+				createStmt: `CREATE TABLE public.items (price integer, quantity integer, total integer GENERATED ALWAYS AS (price * quantity) STORED);`,
+				wantSchema: &schema.Schema{
+					Name: "public",
+					Tables: map[string]*schema.Table{
+						"items": {
+							Name: "items",
+							Columns: map[string]*schema.Column{
+								"price": {
+									Name:         "price",
+									Type:         "integer",
+									Nullable:     true,
+									PostgresType: "base",
+								},
+								"quantity": {
+									Name:         "quantity",
+									Type:         "integer",
+									Nullable:     true,
+									PostgresType: "base",
+								},
+								"total": {
+									Name:         "total",
+									Type:         "integer",
+									Nullable:     true,
+									PostgresType: "base",
+									Generated:    "(price * quantity)",
+								},
+							},
+						},
+					},
+				},
+			},
 			{
 				name: "postgres type types",
 				createStmt: `
@@ -1235,6 +1477,9 @@ func TestReadSchema(t *testing.T) {
 					CREATE TABLE public.table1 (id bigint, comp_col comptype, enum_col review, range_col float8_range, domain_col us_postal_code);`,
 				wantSchema: &schema.Schema{
 					Name: "public",
+					Enums: map[string]*schema.Enum{
+						"review": {Name: "review", Values: []string{"good", "bad", "ugly"}},
+					},
 					Tables: map[string]*schema.Table{
 						"table1": {
 							Name: "table1",
@@ -1455,6 +1700,42 @@ func TestSchemaAfterMigration(t *testing.T) {
 	})
 }
 
+func TestCompleteRemovesBackfillProgress(t *testing.T) {
+	t.Parallel()
+
+	testutils.WithStateAndConnectionToContainer(t, func(st *state.State, db *sql.DB) {
+		ctx := context.Background()
+
+		migration := &migrations.Migration{
+			Name: "01_create_table",
+			Operations: migrations.Operations{
+				&migrations.OpCreateTable{
+					Name:    "items",
+					Columns: []migrations.Column{{Name: "id", Type: "serial", Pk: true}},
+				},
+			},
+		}
+		err := st.Start(ctx, "public", migration)
+		require.NoError(t, err)
+
+		// Record some backfill progress for the migration
+		tx, err := db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		err = st.BackfillCheckpointer("public", migration.Name).Save(ctx, tx, "items", backfill.Checkpoint{Done: true})
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+
+		err = st.Complete(ctx, "public", migration.Name)
+		require.NoError(t, err)
+
+		// The progress of the completed migration is removed
+		var rows int
+		err = db.QueryRowContext(ctx, "SELECT count(*) FROM pgroll.backfill_progress").Scan(&rows)
+		require.NoError(t, err)
+		assert.Equal(t, 0, rows)
+	})
+}
+
 func clearOIDS(s *schema.Schema) {
 	for k := range s.Tables {
 		c := s.Tables[k]
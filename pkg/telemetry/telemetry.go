@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package telemetry provides the OpenTelemetry spans and metrics emitted by
+// pgroll.
+//
+// Spans and metrics are recorded using the global tracer and meter providers,
+// which are no-ops unless the application configures them. The `pgroll` CLI
+// configures them from its flags and the standard OTEL_* environment
+// variables.
+package telemetry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of the tracer and meter used by pgroll
+const InstrumentationName = "github.com/xataio/pgroll"
+
+// Attribute keys used on pgroll spans and metrics
+const (
+	MigrationKey      = attribute.Key("pgroll.migration")
+	PhaseKey          = attribute.Key("pgroll.phase")
+	OperationKey      = attribute.Key("pgroll.operation")
+	OperationIndexKey = attribute.Key("pgroll.operation.index")
+	ActionKey         = attribute.Key("pgroll.action")
+	TableKey          = attribute.Key("pgroll.table")
+	BatchSizeKey      = attribute.Key("pgroll.backfill.batch_size")
+	OutcomeKey        = attribute.Key("pgroll.outcome")
+)
+
+const (
+	outcomeSucceeded = "success"
+	outcomeFailed    = "failure"
+)
+
+// Migration phases
+const (
+	PhaseStart    = "start"
+	PhaseComplete = "complete"
+	PhaseRollback = "rollback"
+)
+
+// StartSpan starts a span named `name` as a child of any span in `ctx`.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(InstrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan ends the span, recording `err` on it if it is not nil.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+type instruments struct {
+	migrationDuration metric.Float64Histogram
+	rowsBackfilled    metric.Int64Counter
+	batchDuration     metric.Float64Histogram
+	lockRetries       metric.Int64Counter
+}
+
+// getInstruments creates the metric instruments on first use. Instruments
+// created from the global meter provider forward to any provider that is
+// registered later, so they only need to be created once.
+var getInstruments = sync.OnceValue(func() *instruments {
+	meter := otel.Meter(InstrumentationName)
+
+	// Errors creating instruments are reported to the global error handler
+	// and a no-op instrument is returned, so they can be ignored here.
+	migrationDuration, _ := meter.Float64Histogram("pgroll.migration.duration",
+		metric.WithDescription("Duration of each phase of a migration"),
+		metric.WithUnit("s"))
+	rowsBackfilled, _ := meter.Int64Counter("pgroll.backfill.rows",
+		metric.WithDescription("Number of rows backfilled"),
+		metric.WithUnit("{row}"))
+	batchDuration, _ := meter.Float64Histogram("pgroll.backfill.batch.duration",
+		metric.WithDescription("Duration of each backfill batch"),
+		metric.WithUnit("s"))
+	lockRetries, _ := meter.Int64Counter("pgroll.db.lock_retries",
+		metric.WithDescription("Number of statements retried after failing to acquire a lock"),
+		metric.WithUnit("{retry}"))
+
+	return &instruments{
+		migrationDuration: migrationDuration,
+		rowsBackfilled:    rowsBackfilled,
+		batchDuration:     batchDuration,
+		lockRetries:       lockRetries,
+	}
+})
+
+// RecordMigrationDuration records how long a phase of a migration took and
+// whether it succeeded.
+func RecordMigrationDuration(ctx context.Context, phase string, d time.Duration, err error) {
+	outcome := outcomeSucceeded
+	if err != nil {
+		outcome = outcomeFailed
+	}
+	getInstruments().migrationDuration.Record(ctx, d.Seconds(),
+		metric.WithAttributes(PhaseKey.String(phase), OutcomeKey.String(outcome)))
+}
+
+// RecordBackfillBatch records the number of rows updated by a backfill batch
+// and how long the batch took.
+func RecordBackfillBatch(ctx context.Context, table string, rows int64, d time.Duration) {
+	attrs := metric.WithAttributes(TableKey.String(table))
+	getInstruments().rowsBackfilled.Add(ctx, rows, attrs)
+	getInstruments().batchDuration.Record(ctx, d.Seconds(), attrs)
+}
+
+// RecordLockRetry records that a statement is retried because it could not
+// acquire a lock within the lock timeout.
+func RecordLockRetry(ctx context.Context) {
+	getInstruments().lockRetries.Add(ctx, 1)
+	trace.SpanFromContext(ctx).AddEvent("lock timeout, retrying")
+}
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/xataio/pgroll/pkg/telemetry"
+)
+
+func TestTelemetry(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	ctx := context.Background()
+
+	t.Run("spans record errors", func(t *testing.T) {
+		phaseCtx, phase := telemetry.StartSpan(ctx, "pgroll.start", telemetry.MigrationKey.String("01_create_table"))
+		_, op := telemetry.StartSpan(phaseCtx, "pgroll.operation", telemetry.OperationKey.String("create_table"))
+		telemetry.EndSpan(op, errors.New("boom"))
+		telemetry.EndSpan(phase, nil)
+
+		ended := spans.Ended()
+		require.Len(t, ended, 2)
+
+		assert.Equal(t, "pgroll.operation", ended[0].Name())
+		assert.Equal(t, codes.Error, ended[0].Status().Code)
+		assert.Equal(t, ended[1].SpanContext().SpanID(), ended[0].Parent().SpanID())
+
+		assert.Equal(t, "pgroll.start", ended[1].Name())
+		assert.Equal(t, codes.Unset, ended[1].Status().Code)
+	})
+
+	t.Run("metrics are recorded", func(t *testing.T) {
+		telemetry.RecordMigrationDuration(ctx, telemetry.PhaseStart, time.Second, nil)
+		telemetry.RecordBackfillBatch(ctx, "users", 100, time.Millisecond)
+		telemetry.RecordBackfillBatch(ctx, "users", 50, time.Millisecond)
+		telemetry.RecordLockRetry(ctx)
+
+		var rm metricdata.ResourceMetrics
+		require.NoError(t, reader.Collect(ctx, &rm))
+		require.Len(t, rm.ScopeMetrics, 1)
+
+		metrics := make(map[string]metricdata.Aggregation)
+		for _, m := range rm.ScopeMetrics[0].Metrics {
+			metrics[m.Name] = m.Data
+		}
+
+		rows := metrics["pgroll.backfill.rows"].(metricdata.Sum[int64])
+		require.Len(t, rows.DataPoints, 1)
+		assert.Equal(t, int64(150), rows.DataPoints[0].Value)
+
+		batches := metrics["pgroll.backfill.batch.duration"].(metricdata.Histogram[float64])
+		require.Len(t, batches.DataPoints, 1)
+		assert.Equal(t, uint64(2), batches.DataPoints[0].Count)
+
+		retries := metrics["pgroll.db.lock_retries"].(metricdata.Sum[int64])
+		require.Len(t, retries.DataPoints, 1)
+		assert.Equal(t, int64(1), retries.DataPoints[0].Value)
+
+		durations := metrics["pgroll.migration.duration"].(metricdata.Histogram[float64])
+		require.Len(t, durations.DataPoints, 1)
+		assert.Equal(t, 1.0, durations.DataPoints[0].Sum)
+	})
+}